@@ -0,0 +1,117 @@
+// Package cache implements storage for the results of analyzing
+// packages, which can be kept on local disk or shared between
+// machines via a remote server.
+package cache // import "honnef.co/go/tools/cache"
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by Get if the cache has no entry for an
+// ID.
+var ErrNotFound = errors.New("cache entry not found")
+
+// An ActionID identifies a cache entry. It is the hash of all inputs
+// that went into computing the entry.
+type ActionID [32]byte
+
+func (id ActionID) String() string { return hex.EncodeToString(id[:]) }
+
+// A Cache stores opaque data, keyed by action IDs. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+	// Get returns the data stored for id, or ErrNotFound.
+	Get(id ActionID) ([]byte, error)
+	// Put stores data for id.
+	Put(id ActionID, data []byte) error
+}
+
+// Environment variables that configure the default cache.
+const (
+	// EnvDir is the directory of the local cache. If set to "off",
+	// the local cache is disabled.
+	EnvDir = "STATICCHECK_CACHE"
+	// EnvURL is the base URL of a remote cache, see HTTP.
+	EnvURL = "STATICCHECK_CACHE_URL"
+)
+
+// Default returns the cache configured by the environment. A remote
+// cache is only used if EnvURL is set. The local cache is only used
+// if EnvDir is set, or if a remote cache is used, in which case it
+// defaults to a directory in the user's cache directory. Default
+// returns nil if no cache is configured.
+func Default() (Cache, error) {
+	dir := os.Getenv(EnvDir)
+	url := os.Getenv(EnvURL)
+	if dir == "" && url == "" {
+		return nil, nil
+	}
+
+	var local, remote Cache
+	if dir != "off" {
+		if dir == "" {
+			dir = defaultDir()
+		}
+		c, err := Open(dir)
+		if err != nil {
+			return nil, err
+		}
+		local = c
+	}
+	if url != "" {
+		remote = NewHTTP(url)
+	}
+	switch {
+	case local != nil && remote != nil:
+		return Tiered{local, remote}, nil
+	case local != nil:
+		return local, nil
+	default:
+		return remote, nil
+	}
+}
+
+// defaultDir returns the default directory of the local cache.
+func defaultDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "staticcheck")
+	}
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return filepath.Join(dir, "staticcheck")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".cache", "staticcheck")
+}
+
+// Tiered combines a fast local cache with a slower, shared remote
+// cache. Entries found in the remote cache are copied to the local
+// one, and new entries are stored in both.
+type Tiered struct {
+	Local  Cache
+	Remote Cache
+}
+
+func (t Tiered) Get(id ActionID) ([]byte, error) {
+	data, err := t.Local.Get(id)
+	if err == nil {
+		return data, nil
+	}
+	data, err = t.Remote.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to populate the local cache only costs performance.
+	_ = t.Local.Put(id, data)
+	return data, nil
+}
+
+func (t Tiered) Put(id ActionID, data []byte) error {
+	err1 := t.Local.Put(id, data)
+	err2 := t.Remote.Put(id, data)
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func tempDisk(t *testing.T) (*Disk, func()) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := Open(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return d, func() { os.RemoveAll(dir) }
+}
+
+func TestDisk(t *testing.T) {
+	d, cleanup := tempDisk(t)
+	defer cleanup()
+
+	id := ActionID(sha256.Sum256([]byte("a")))
+	if _, err := d.Get(id); err != ErrNotFound {
+		t.Fatalf("Get of missing entry: got error %v, want ErrNotFound", err)
+	}
+	for _, data := range []string{"first", "second"} {
+		if err := d.Put(id, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != data {
+			t.Errorf("got %q, want %q", got, data)
+		}
+	}
+	other := ActionID(sha256.Sum256([]byte("b")))
+	if _, err := d.Get(other); err != ErrNotFound {
+		t.Errorf("Get of other entry: got error %v, want ErrNotFound", err)
+	}
+
+	// Entries persist across instances.
+	d2, err := Open(d.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := d2.Get(id); err != nil || string(got) != "second" {
+		t.Errorf("reopened cache: got %q, %v, want %q", got, err, "second")
+	}
+}
+
+// remoteServer is an HTTP cache server that fails all requests with
+// status, if it is nonzero.
+type remoteServer struct {
+	mu      sync.Mutex
+	status  int
+	entries map[string][]byte
+}
+
+func (s *remoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case "GET":
+		data, ok := s.entries[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case "PUT":
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.entries[key] = data
+	}
+}
+
+func TestTiered(t *testing.T) {
+	local, cleanup := tempDisk(t)
+	defer cleanup()
+	srv := &remoteServer{entries: map[string][]byte{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	c := Tiered{Local: local, Remote: NewHTTP(ts.URL + "/")}
+
+	a := ActionID(sha256.Sum256([]byte("a")))
+	b := ActionID(sha256.Sum256([]byte("b")))
+	if _, err := c.Get(a); err != ErrNotFound {
+		t.Fatalf("Get of missing entry: got error %v, want ErrNotFound", err)
+	}
+	if err := c.Put(a, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if string(srv.entries[a.String()]) != "a" {
+		t.Errorf("Put didn't store the entry remotely")
+	}
+
+	// Remote entries are copied to the local cache.
+	srv.entries[b.String()] = []byte("b")
+	if got, err := c.Get(b); err != nil || string(got) != "b" {
+		t.Fatalf("Get of remote entry: got %q, %v", got, err)
+	}
+	if got, err := local.Get(b); err != nil || string(got) != "b" {
+		t.Errorf("remote entry wasn't copied to the local cache: got %q, %v", got, err)
+	}
+
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway} {
+		srv.status = status
+		// Local entries don't depend on the remote cache.
+		for _, id := range []ActionID{a, b} {
+			if _, err := c.Get(id); err != nil {
+				t.Errorf("status %d: Get of local entry: %v", status, err)
+			}
+		}
+		id := ActionID(sha256.Sum256([]byte(http.StatusText(status))))
+		_, err := c.Get(id)
+		if status == http.StatusNotFound {
+			if err != ErrNotFound {
+				t.Errorf("status %d: got error %v, want ErrNotFound", status, err)
+			}
+		} else if err == nil || err == ErrNotFound {
+			t.Errorf("status %d: got error %v, want server error", status, err)
+		}
+
+		// Entries are stored locally even if the remote cache fails.
+		err = c.Put(id, []byte("c"))
+		if err == nil {
+			t.Errorf("status %d: Put succeeded, want server error", status)
+		}
+		if got, err := c.Get(id); err != nil || string(got) != "c" {
+			t.Errorf("status %d: Get after Put: got %q, %v", status, got, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Disk is a cache that stores entries as files in a directory.
+type Disk struct {
+	dir string
+}
+
+// Open opens the cache in dir, creating the directory if necessary.
+func Open(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir}, nil
+}
+
+func (d *Disk) path(id ActionID) string {
+	s := id.String()
+	return filepath.Join(d.dir, s[:2], s)
+}
+
+func (d *Disk) Get(id ActionID) ([]byte, error) {
+	data, err := ioutil.ReadFile(d.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (d *Disk) Put(id ActionID, data []byte) error {
+	path := d.path(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that concurrent readers
+	// never observe partial entries.
+	f, err := ioutil.TempFile(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
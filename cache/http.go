@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTP is a cache backed by an HTTP server. Entries are retrieved
+// with GET requests to <url>/<id> and stored with PUT requests to the
+// same URL. The server must respond with 404 Not Found to requests
+// for missing entries.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP returns a cache that uses the server at url.
+func NewHTTP(url string) *HTTP {
+	return &HTTP{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (h *HTTP) Get(id ActionID) ([]byte, error) {
+	resp, err := h.client.Get(h.url + "/" + id.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("remote cache: GET %s: %s", id, resp.Status)
+	}
+}
+
+func (h *HTTP) Put(id ActionID, data []byte) error {
+	req, err := http.NewRequest("PUT", h.url+"/"+id.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote cache: PUT %s: %s", id, resp.Status)
+	}
+	return nil
+}
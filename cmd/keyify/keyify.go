@@ -9,12 +9,16 @@ import (
 	"go/ast"
 	"go/build"
 	"go/constant"
+	"go/format"
+	"go/parser"
 	"go/printer"
 	"go/token"
 	"go/types"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"honnef.co/go/tools/version"
 
@@ -29,20 +33,23 @@ var (
 	fJSON      bool
 	fMinify    bool
 	fModified  bool
+	fWrite     bool
 	fVersion   bool
 )
 
 func init() {
-	flag.BoolVar(&fRecursive, "r", false, "keyify struct initializers recursively")
+	flag.BoolVar(&fRecursive, "r", false, "keyify struct initializers recursively; if given a package instead of a position, keyify all of the package's struct initializers")
 	flag.BoolVar(&fOneLine, "o", false, "print new struct initializer on a single line")
 	flag.BoolVar(&fJSON, "json", false, "print new struct initializer as JSON")
 	flag.BoolVar(&fMinify, "m", false, "omit fields that are set to their zero value")
 	flag.BoolVar(&fModified, "modified", false, "read an archive of modified files from standard input")
+	flag.BoolVar(&fWrite, "w", false, "write result to the source files instead of standard output")
 	flag.BoolVar(&fVersion, "version", false, "Print version and exit")
 }
 
 func usage() {
-	fmt.Printf("Usage: %s [flags] <position>\n\n", os.Args[0])
+	fmt.Printf("Usage: %s [flags] <position>\n", os.Args[0])
+	fmt.Printf("       %s [flags] -r <package>\n\n", os.Args[0])
 	flag.PrintDefaults()
 }
 
@@ -61,18 +68,6 @@ func main() {
 		os.Exit(2)
 	}
 	pos := flag.Args()[0]
-	name, start, _, err := parsePos(pos)
-	if err != nil {
-		log.Fatal(err)
-	}
-	eval, err := filepath.EvalSymlinks(name)
-	if err != nil {
-		log.Fatal(err)
-	}
-	name, err = filepath.Abs(eval)
-	if err != nil {
-		log.Fatal(err)
-	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Fatal(err)
@@ -85,21 +80,32 @@ func main() {
 		}
 		ctx = buildutil.OverlayContext(ctx, overlay)
 	}
-	bpkg, err := buildutil.ContainingPackage(ctx, cwd, name)
+	if fRecursive && !strings.Contains(pos, ":#") {
+		// Not a position, but a package
+		bpkg, err := ctx.Import(pos, cwd, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keyifyPackage(ctx, bpkg)
+		return
+	}
+	name, start, _, err := parsePos(pos)
 	if err != nil {
 		log.Fatal(err)
 	}
-	conf := &loader.Config{
-		Build: ctx,
+	eval, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		log.Fatal(err)
 	}
-	conf.TypeCheckFuncBodies = func(s string) bool {
-		return s == bpkg.ImportPath || s == bpkg.ImportPath+"_test"
+	name, err = filepath.Abs(eval)
+	if err != nil {
+		log.Fatal(err)
 	}
-	conf.ImportWithTests(bpkg.ImportPath)
-	lprog, err := conf.Load()
+	bpkg, err := buildutil.ContainingPackage(ctx, cwd, name)
 	if err != nil {
 		log.Fatal(err)
 	}
+	lprog := load(ctx, bpkg)
 	var tf *token.File
 	var af *ast.File
 	var pkg *loader.PackageInfo
@@ -133,7 +139,7 @@ outer:
 		log.Fatal("no composite literal found near point")
 	}
 	if len(complit.Elts) == 0 {
-		printComplit(complit, complit, lprog.Fset, lprog.Fset)
+		printComplit(ctx, complit, complit, lprog.Fset, lprog.Fset)
 		return
 	}
 	if _, ok := complit.Elts[0].(*ast.KeyValueExpr); ok {
@@ -141,11 +147,10 @@ outer:
 		if fOneLine {
 			lit = copyExpr(complit, 1).(*ast.CompositeLit)
 		}
-		printComplit(complit, lit, lprog.Fset, lprog.Fset)
+		printComplit(ctx, complit, lit, lprog.Fset, lprog.Fset)
 		return
 	}
-	_, ok := pkg.TypeOf(complit).Underlying().(*types.Struct)
-	if !ok {
+	if !isStructLit(pkg, complit) {
 		log.Fatal("not a struct initialiser")
 		return
 	}
@@ -156,7 +161,125 @@ outer:
 	for i := 1; i <= lines; i++ {
 		newFile.AddLine(i)
 	}
-	printComplit(complit, newComplit, lprog.Fset, newFset)
+	printComplit(ctx, complit, newComplit, lprog.Fset, newFset)
+}
+
+func load(ctx *build.Context, bpkg *build.Package) *loader.Program {
+	conf := &loader.Config{
+		Build:      ctx,
+		ParserMode: parser.ParseComments,
+	}
+	conf.TypeCheckFuncBodies = func(s string) bool {
+		return s == bpkg.ImportPath || s == bpkg.ImportPath+"_test"
+	}
+	conf.ImportWithTests(bpkg.ImportPath)
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return lprog
+}
+
+// keyifyPackage keyifies all unkeyed struct literals in all files of
+// a package, including nested ones, and prints the resulting files
+// or, with -w, writes them back to disk.
+func keyifyPackage(ctx *build.Context, bpkg *build.Package) {
+	lprog := load(ctx, bpkg)
+	for _, f := range keyifyFiles(lprog, bpkg.Dir) {
+		if fWrite {
+			if err := ioutil.WriteFile(f.name, f.src, 0644); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		fmt.Printf("// %s\n", f.name)
+		os.Stdout.Write(f.src)
+	}
+}
+
+type keyifiedFile struct {
+	name string
+	src  []byte
+}
+
+// keyifyFiles keyifies all unkeyed struct literals in the files of
+// the initial packages of lprog that are in dir, and returns the
+// files that changed, formatted.
+func keyifyFiles(lprog *loader.Program, dir string) []keyifiedFile {
+	var out []keyifiedFile
+	for _, pkg := range lprog.InitialPackages() {
+		for _, f := range pkg.Files {
+			tf := lprog.Fset.File(f.Pos())
+			if filepath.Dir(tf.Name()) != dir {
+				continue
+			}
+			changed := false
+			ast.Inspect(f, func(node ast.Node) bool {
+				complit, ok := node.(*ast.CompositeLit)
+				if !ok || !isUnkeyed(pkg, complit) {
+					return true
+				}
+				// Modifying the literal in place is safe: ast.Inspect
+				// visits the new elements after visiting the literal,
+				// which takes care of nested literals.
+				keyifyInPlace(pkg, complit)
+				changed = true
+				return true
+			})
+			if !changed {
+				continue
+			}
+			buf := &bytes.Buffer{}
+			if err := format.Node(buf, lprog.Fset, f); err != nil {
+				log.Fatal(err)
+			}
+			out = append(out, keyifiedFile{tf.Name(), buf.Bytes()})
+		}
+	}
+	return out
+}
+
+// keyifyInPlace turns an unkeyed struct literal into a keyed one by
+// modifying it. The new keys reuse the positions of the values they
+// belong to, so that the printed literal keeps its original layout.
+func keyifyInPlace(pkg *loader.PackageInfo, complit *ast.CompositeLit) {
+	st := pkg.TypeOf(complit).Underlying().(*types.Struct)
+	var elts []ast.Expr
+	for i, val := range complit.Elts {
+		field := st.Field(i)
+		_, isIface := field.Type().Underlying().(*types.Interface)
+		if fMinify && (isNil(val, pkg) || (!isIface && isZero(val, pkg))) {
+			continue
+		}
+		elts = append(elts, &ast.KeyValueExpr{
+			Key:   &ast.Ident{NamePos: val.Pos(), Name: field.Name()},
+			Colon: val.Pos(),
+			Value: val,
+		})
+	}
+	complit.Elts = elts
+}
+
+func isStructLit(pkg *loader.PackageInfo, complit *ast.CompositeLit) bool {
+	// Use the type of the literal, not of its type expression, so
+	// that literals with elided types, such as the elements in
+	// []T{{1, 2}}, are handled, too.
+	T := pkg.TypeOf(complit)
+	if T == nil {
+		return false
+	}
+	_, ok := T.Underlying().(*types.Struct)
+	return ok
+}
+
+func isUnkeyed(pkg *loader.PackageInfo, complit *ast.CompositeLit) bool {
+	if len(complit.Elts) == 0 {
+		return false
+	}
+	if _, ok := complit.Elts[0].(*ast.KeyValueExpr); ok {
+		return false
+	}
+	return isStructLit(pkg, complit)
 }
 
 func keyify(
@@ -185,13 +308,7 @@ func keyify(
 		field := st.Field(i)
 		val := complit.Elts[i]
 		if fRecursive {
-			if val2, ok := val.(*ast.CompositeLit); ok {
-				if _, ok := pkg.TypeOf(val2.Type).Underlying().(*types.Struct); ok {
-					var lines int
-					numLines += lines
-					val, lines = keyify(pkg, val2)
-				}
-			}
+			val = keyifyNested(pkg, val, &numLines)
 		}
 		_, isIface := st.Field(i).Type().Underlying().(*types.Interface)
 		if fMinify && (isNil(val, pkg) || (!isIface && isZero(val, pkg))) {
@@ -207,6 +324,42 @@ func keyify(
 	return newComplit, numLines
 }
 
+// keyifyNested keyifies the unkeyed struct literals contained in
+// val, which may be a literal itself, a pointer to one, or a slice,
+// array or map literal with struct literals as elements.
+func keyifyNested(pkg *loader.PackageInfo, val ast.Expr, numLines *int) ast.Expr {
+	switch val := val.(type) {
+	case *ast.UnaryExpr:
+		if val.Op != token.AND {
+			return val
+		}
+		cp := *val
+		cp.X = keyifyNested(pkg, val.X, numLines)
+		return &cp
+	case *ast.KeyValueExpr:
+		cp := *val
+		cp.Value = keyifyNested(pkg, val.Value, numLines)
+		return &cp
+	case *ast.CompositeLit:
+		if isUnkeyed(pkg, val) {
+			lit, lines := keyify(pkg, val)
+			*numLines += lines
+			return lit
+		}
+		if isStructLit(pkg, val) {
+			return val
+		}
+		cp := *val
+		cp.Elts = make([]ast.Expr, len(val.Elts))
+		for i, elt := range val.Elts {
+			cp.Elts[i] = keyifyNested(pkg, elt, numLines)
+		}
+		return &cp
+	default:
+		return val
+	}
+}
+
 func isNil(val ast.Expr, pkg *loader.PackageInfo) bool {
 	ident, ok := val.(*ast.Ident)
 	if !ok {
@@ -258,10 +411,21 @@ func isZero(val ast.Expr, pkg *loader.PackageInfo) bool {
 	return false
 }
 
-func printComplit(oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.FileSet) {
+func printComplit(ctx *build.Context, oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.FileSet) {
 	buf := &bytes.Buffer{}
 	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
 	_ = cfg.Fprint(buf, newfset, newlit)
+	if fWrite {
+		name := oldfset.Position(oldlit.Pos()).Filename
+		out, err := replaceComplit(ctx, oldlit, buf.Bytes(), oldfset)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, out, 0644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if fJSON {
 		output := struct {
 			Start       int    `json:"start"`
@@ -278,6 +442,29 @@ func printComplit(oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.File
 	}
 }
 
+// replaceComplit returns the contents of oldlit's file, with oldlit
+// replaced by repl, formatted to fix up indentation. The file is read
+// through ctx, so that with -modified, the offsets of oldlit refer to
+// the same contents.
+func replaceComplit(ctx *build.Context, oldlit *ast.CompositeLit, repl []byte, fset *token.FileSet) ([]byte, error) {
+	start := fset.Position(oldlit.Pos())
+	end := fset.Position(oldlit.End())
+	rc, err := buildutil.OpenFile(ctx, start.Filename)
+	if err != nil {
+		return nil, err
+	}
+	src, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	out = append(out, src[:start.Offset]...)
+	out = append(out, repl...)
+	out = append(out, src[end.Offset:]...)
+	return format.Source(out)
+}
+
 func copyExpr(expr ast.Expr, line token.Pos) ast.Expr {
 	switch expr := expr.(type) {
 	case *ast.BasicLit:
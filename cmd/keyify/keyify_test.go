@@ -0,0 +1,128 @@
+package main
+
+import (
+	"go/ast"
+	"testing"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+func TestKeyifyFiles(t *testing.T) {
+	ctx := buildutil.FakeContext(map[string]map[string]string{
+		"a": {
+			"a.go": `package a
+
+type T struct {
+	A int
+	B string
+}
+
+type U struct {
+	T  T
+	Ts []T
+	P  *T
+}
+
+var x = U{T{1, "a"}, []T{{2, "b"}}, &T{3, "c"}}
+`,
+			"b.go": `package a
+
+var y = T{4, "d"}
+var z = T{A: 5}
+`,
+			"c.go": `package a
+
+var w = []int{1, 2}
+`,
+		},
+	})
+	bpkg, err := ctx.Import("a", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := keyifyFiles(load(ctx, bpkg), bpkg.Dir)
+	want := map[string]string{
+		"/go/src/a/a.go": `package a
+
+type T struct {
+	A int
+	B string
+}
+
+type U struct {
+	T  T
+	Ts []T
+	P  *T
+}
+
+var x = U{T: T{A: 1, B: "a"}, Ts: []T{{A: 2, B: "b"}}, P: &T{A: 3, B: "c"}}
+`,
+		"/go/src/a/b.go": `package a
+
+var y = T{A: 4, B: "d"}
+var z = T{A: 5}
+`,
+	}
+	if len(files) != len(want) {
+		t.Errorf("got %d changed files, want %d", len(files), len(want))
+	}
+	for _, f := range files {
+		if string(f.src) != want[f.name] {
+			t.Errorf("got %s:\n%s\nwant:\n%s", f.name, f.src, want[f.name])
+		}
+	}
+}
+
+func TestReplaceComplitModified(t *testing.T) {
+	const name = "/go/src/a/a.go"
+	ctx := buildutil.FakeContext(map[string]map[string]string{
+		"a": {
+			"a.go": `package a
+
+// T is a type whose comment hasn't been removed on disk yet, which
+// moves the literal below.
+type T struct{ A, B int }
+
+var x = T{1, 2}
+`,
+		},
+	})
+	ctx = buildutil.OverlayContext(ctx, map[string][]byte{
+		name: []byte(`package a
+
+type T struct{ A, B int }
+
+var x = T{1, 2}
+`),
+	})
+	bpkg, err := ctx.Import("a", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lprog := load(ctx, bpkg)
+	var complit *ast.CompositeLit
+	for _, f := range lprog.InitialPackages()[0].Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			if lit, ok := node.(*ast.CompositeLit); ok {
+				complit = lit
+			}
+			return true
+		})
+	}
+	if complit == nil {
+		t.Fatal("no composite literal found")
+	}
+	got, err := replaceComplit(ctx, complit, []byte("T{A: 1, B: 2}"), lprog.Fset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `package a
+
+type T struct{ A, B int }
+
+var x = T{A: 1, B: 2}
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+// lint-diff compares two sets of results of staticcheck and related
+// tools, such as those of the main branch and of a change to it, and
+// reports new, fixed and moved problems.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/version"
+)
+
+var (
+	fOldRoot   string
+	fNewRoot   string
+	fFormat    string
+	fShowMoved bool
+	fVersion   bool
+)
+
+func init() {
+	flag.StringVar(&fOldRoot, "old-root", "", "Directory that the files of the old results are relative to (default: the current directory)")
+	flag.StringVar(&fNewRoot, "new-root", "", "Directory that the files of the new results are relative to (default: the current directory)")
+	flag.StringVar(&fFormat, "f", "text", "Output `format`: 'text' or 'json'")
+	flag.BoolVar(&fShowMoved, "show-moved", false, "Also print problems that only moved")
+	flag.BoolVar(&fVersion, "version", false, "Print version and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: lint-diff [flags] old.json new.json\n\n")
+		fmt.Fprintf(os.Stderr, "Compares results in the json or jsonl format. Exits with status 1 if there are new problems.\n\n")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Parse()
+
+	if fVersion {
+		version.Print()
+		os.Exit(0)
+	}
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if fFormat != "text" && fFormat != "json" {
+		log.Printf("unsupported output format %q", fFormat)
+		os.Exit(2)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	oldRoot, newRoot := root(fOldRoot, cwd), root(fNewRoot, cwd)
+
+	old, err := readResults(flag.Arg(0))
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	new, err := readResults(flag.Arg(1))
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	d := lintutil.DiffResults(old, new, oldRoot, newRoot)
+
+	switch fFormat {
+	case "json":
+		if err := lintutil.WriteDiffJSON(os.Stdout, d, oldRoot, newRoot); err != nil {
+			log.Print(err)
+			os.Exit(2)
+		}
+	default:
+		writeText(os.Stdout, d, oldRoot, newRoot)
+	}
+	if len(d.New) > 0 {
+		os.Exit(1)
+	}
+}
+
+func root(dir, cwd string) string {
+	if dir == "" {
+		return cwd
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+func readResults(path string) ([]lint.Problem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ps, _, err := lintutil.ReadResults(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return ps, nil
+}
+
+// position formats the position of p relative to root.
+func position(p lint.Problem, root string) string {
+	pos := p.Position
+	name := pos.Filename
+	if rel, err := filepath.Rel(root, name); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	if name == "" {
+		name = "-"
+	}
+	if pos.Line > 0 {
+		name += fmt.Sprintf(":%d", pos.Line)
+		if pos.Column > 0 {
+			name += fmt.Sprintf(":%d", pos.Column)
+		}
+	}
+	return name
+}
+
+func writeText(w io.Writer, d lintutil.ResultDiff, oldRoot, newRoot string) {
+	for _, p := range d.New {
+		fmt.Fprintf(w, "new: %s: %s (%s)\n", position(p, newRoot), p.Text, p.Check)
+	}
+	for _, p := range d.Fixed {
+		fmt.Fprintf(w, "fixed: %s: %s (%s)\n", position(p, oldRoot), p.Text, p.Check)
+	}
+	if fShowMoved {
+		for _, m := range d.Moved {
+			fmt.Fprintf(w, "moved: %s -> %s: %s (%s)\n", position(m.Old, oldRoot), position(m.New, newRoot), m.New.Text, m.New.Check)
+		}
+	}
+	fmt.Fprintf(w, "%d new, %d fixed, %d moved\n", len(d.New), len(d.Fixed), len(d.Moved))
+}
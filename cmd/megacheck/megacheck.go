@@ -32,6 +32,7 @@ func main() {
 			debug        string
 			wholeProgram bool
 			reflection   bool
+			unreachable  bool
 			exitNonZero  bool
 		}
 	}
@@ -66,6 +67,8 @@ func main() {
 		"unused.exported", false, "Treat arguments as a program and report unused exported identifiers")
 	fs.BoolVar(&flags.unused.reflection,
 		"unused.reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+	fs.BoolVar(&flags.unused.unreachable,
+		"unused.unreachable", false, "Report functions that are only used by code that is never executed, using a call graph of the whole program")
 	fs.BoolVar(&flags.unused.exitNonZero,
 		"unused.exit-non-zero", true, "Exit non-zero if any problems were found")
 
@@ -111,6 +114,7 @@ func main() {
 		uc := unused.NewChecker(mode)
 		uc.WholeProgram = flags.unused.wholeProgram
 		uc.ConsiderReflection = flags.unused.reflection
+		uc.Unreachable = flags.unused.unreachable
 		checkers = append(checkers, lintutil.CheckerConfig{
 			Checker:     unused.NewLintChecker(uc),
 			ExitNonZero: flags.unused.exitNonZero,
@@ -0,0 +1,340 @@
+// staticcheckd is a long-running daemon that lints code on request.
+// It keeps loaded packages in memory, so that editor integrations
+// get quick responses on large code bases.
+package main // import "honnef.co/go/tools/cmd/staticcheckd"
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+)
+
+// newCheckers returns the checkers to run. Checkers keep state
+// between runs, so each request gets fresh ones.
+func newCheckers() []lint.Checker {
+	return []lint.Checker{
+		staticcheck.NewChecker(),
+		simple.NewChecker(),
+		stylecheck.NewChecker(),
+		unused.NewLintChecker(unused.NewChecker(unused.CheckAll)),
+	}
+}
+
+// Service implements the daemon's RPC methods.
+type Service struct {
+	docsURL string
+
+	// mu serializes requests, which change the working directory.
+	mu      sync.Mutex
+	session *lintutil.Session
+}
+
+type LintArgs struct {
+	// Dir is the directory relative to which Args are resolved, and
+	// in which configuration files are looked up.
+	Dir string
+	// Args are packages, directories or files, as accepted on the
+	// command line of staticcheck.
+	Args   []string
+	Tags   []string
+	Tests  bool
+	Checks []string
+	// Overlay maps absolute file names to contents that replace the
+	// files on disk, such as unsaved editor buffers.
+	Overlay map[string]string
+}
+
+type Location struct {
+	File   string
+	Line   int
+	Column int
+	// Offset is the byte offset in the file.
+	Offset int
+}
+
+type Related struct {
+	Location Location
+	Message  string
+}
+
+// An Edit replaces the text between Start and End, which are in the
+// same file, with NewText.
+type Edit struct {
+	Start   Location
+	End     Location
+	NewText string
+}
+
+// A Fix is a suggested fix of a problem, which can be applied with
+// ApplyFix.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+type Problem struct {
+	Checker  string
+	Code     string
+	Location Location
+	Message  string
+	URL      string    `json:",omitempty"`
+	Related  []Related `json:",omitempty"`
+	Fixes    []Fix     `json:",omitempty"`
+}
+
+type LintReply struct {
+	Problems []Problem
+}
+
+func location(pos token.Position) Location {
+	name := pos.Filename
+	if abs, err := filepath.Abs(name); err == nil {
+		name = abs
+	}
+	return Location{name, pos.Line, pos.Column, pos.Offset}
+}
+
+func overlay(files map[string]string) map[string][]byte {
+	if len(files) == 0 {
+		return nil
+	}
+	out := map[string][]byte{}
+	for name, src := range files {
+		out[name] = []byte(src)
+	}
+	return out
+}
+
+// Lint lints packages or files.
+func (s *Service) Lint(args *LintArgs, reply *LintReply) error {
+	if args.Dir == "" || !filepath.IsAbs(args.Dir) {
+		return errors.New("Dir must be an absolute path")
+	}
+	if len(args.Args) == 0 {
+		return errors.New("nothing to lint")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Chdir(args.Dir); err != nil {
+		return err
+	}
+	opt := &lintutil.Options{
+		Tags:      args.Tags,
+		LintTests: args.Tests,
+		DocsURL:   s.docsURL,
+		Checks:    args.Checks,
+	}
+	if err := opt.DiscoverProjectFiles(args.Dir); err != nil {
+		return err
+	}
+	opt.Overlay = overlay(args.Overlay)
+	pss, err := s.session.Lint(context.Background(), newCheckers(), args.Args, opt)
+	if err != nil {
+		return err
+	}
+	reply.Problems = []Problem{}
+	for _, ps := range pss {
+		for _, p := range ps {
+			rp := Problem{
+				Checker:  p.Checker,
+				Code:     p.Check,
+				Location: location(p.Position),
+				Message:  p.Text,
+				URL:      p.URL,
+			}
+			for _, r := range p.Related {
+				rp.Related = append(rp.Related, Related{location(r.Position), r.Text})
+			}
+			for _, fix := range p.Fixes {
+				rf := Fix{Message: fix.Message}
+				for _, e := range fix.Edits {
+					rf.Edits = append(rf.Edits, Edit{location(e.Position), location(e.End), e.NewText})
+				}
+				rp.Fixes = append(rp.Fixes, rf)
+			}
+			reply.Problems = append(reply.Problems, rp)
+		}
+	}
+	return nil
+}
+
+type ApplyFixArgs struct {
+	// Fix is one of the fixes of a problem returned by Lint.
+	Fix Fix
+	// Overlay maps absolute file names to contents that the edits
+	// are applied to instead of the files on disk. It should match
+	// the overlay that the fix was computed with.
+	Overlay map[string]string
+	// Write writes the changed files to disk.
+	Write bool
+}
+
+type ApplyFixReply struct {
+	// Files maps the absolute names of the changed files to their
+	// new contents.
+	Files map[string]string
+}
+
+// ApplyFix applies the edits of a fix and returns the changed files,
+// optionally writing them to disk.
+func (s *Service) ApplyFix(args *ApplyFixArgs, reply *ApplyFixReply) error {
+	edits := map[string][]lint.Edit{}
+	var names []string
+	for _, e := range args.Fix.Edits {
+		if e.Start.File == "" || !filepath.IsAbs(e.Start.File) || e.End.File != e.Start.File {
+			return fmt.Errorf("invalid edit at %s:%d:%d", e.Start.File, e.Start.Line, e.Start.Column)
+		}
+		name := e.Start.File
+		if _, ok := edits[name]; !ok {
+			names = append(names, name)
+		}
+		edits[name] = append(edits[name], lint.Edit{
+			Position: token.Position{Filename: name, Offset: e.Start.Offset, Line: e.Start.Line, Column: e.Start.Column},
+			End:      token.Position{Filename: name, Offset: e.End.Offset, Line: e.End.Line, Column: e.End.Column},
+			NewText:  e.NewText,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Compute all changes before writing any of them, so that an
+	// invalid fix doesn't leave files half-changed.
+	files := map[string][]byte{}
+	for _, name := range names {
+		var src []byte
+		if text, ok := args.Overlay[name]; ok {
+			src = []byte(text)
+		} else {
+			var err error
+			src, err = ioutil.ReadFile(name)
+			if err != nil {
+				return err
+			}
+		}
+		out, err := lint.ApplyEdits(src, edits[name])
+		if err != nil {
+			return err
+		}
+		files[name] = out
+	}
+	reply.Files = map[string]string{}
+	for _, name := range names {
+		if args.Write {
+			fi, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(name, files[name], fi.Mode()); err != nil {
+				return err
+			}
+		}
+		reply.Files[name] = string(files[name])
+	}
+	return nil
+}
+
+type ExplainArgs struct {
+	Check string
+}
+
+type ExplainReply struct {
+	Checker    string
+	Title      string
+	Text       string
+	URL        string `json:",omitempty"`
+	NonDefault bool
+}
+
+// Explain returns the documentation of a check.
+func (s *Service) Explain(args *ExplainArgs, reply *ExplainReply) error {
+	for _, c := range newCheckers() {
+		dc, ok := c.(lint.DocumentedChecker)
+		if !ok {
+			continue
+		}
+		doc, ok := dc.Docs()[args.Check]
+		if !ok {
+			continue
+		}
+		reply.Checker = c.Name()
+		reply.Title = doc.Title
+		reply.Text = doc.Text
+		reply.NonDefault = doc.NonDefault
+		if s.docsURL != "" {
+			reply.URL = s.docsURL + "#" + args.Check
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown check %q", args.Check)
+}
+
+type ResetArgs struct{}
+
+type ResetReply struct{}
+
+// Reset drops all cached packages.
+func (s *Service) Reset(args *ResetArgs, reply *ResetReply) error {
+	s.session.Forget()
+	return nil
+}
+
+// stdio combines standard input and output into a single connection.
+type stdio struct {
+	io.Reader
+	io.Writer
+}
+
+func (stdio) Close() error { return nil }
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:7878", "TCP `address` to listen on")
+	useStdio := flag.Bool("stdio", false, "Serve a single client on standard input and output instead of listening")
+	docsURL := flag.String("docs-url", "https://staticcheck.io/docs/checks", "Base `URL` of the checks' documentation")
+	flag.Parse()
+
+	srv := rpc.NewServer()
+	svc := &Service{
+		docsURL: *docsURL,
+		session: lintutil.NewSession(),
+	}
+	if err := srv.RegisterName("Staticcheck", svc); err != nil {
+		log.Fatal(err)
+	}
+
+	if *useStdio {
+		srv.ServeCodec(jsonrpc.NewServerCodec(stdio{os.Stdin, os.Stdout}))
+		return
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+		go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
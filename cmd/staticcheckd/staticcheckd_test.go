@@ -0,0 +1,136 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint/lintutil"
+)
+
+const src = `package a
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrA = errors.New("a")
+var ErrB = fmt.Errorf("b")
+
+var s = fmt.Sprint(1)
+`
+
+const fixed = `package a
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrA = errors.New("a")
+var ErrB = errors.New("b")
+
+var s = fmt.Sprint(1)
+`
+
+func TestService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheckd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The symlinks of temporary directories would make the file
+	// names of problems differ from those we expect.
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "a.go")
+	if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Staticcheck", &Service{session: lintutil.NewSession()}); err != nil {
+		t.Fatal(err)
+	}
+	sconn, cconn := net.Pipe()
+	go srv.ServeCodec(jsonrpc.NewServerCodec(sconn))
+	client := jsonrpc.NewClient(cconn)
+	defer client.Close()
+
+	var lr LintReply
+	largs := &LintArgs{Dir: dir, Args: []string{"a.go"}, Checks: []string{"none", "SA6008"}}
+	if err := client.Call("Staticcheck.Lint", largs, &lr); err != nil {
+		t.Fatal(err)
+	}
+	if len(lr.Problems) != 1 {
+		t.Fatalf("got problems %+v, want one", lr.Problems)
+	}
+	p := lr.Problems[0]
+	if p.Code != "SA6008" || p.Location.File != name || p.Location.Line != 9 {
+		t.Errorf("got problem %+v, want SA6008 at %s:9", p, name)
+	}
+	if len(p.Fixes) != 1 {
+		t.Fatalf("got fixes %+v, want one", p.Fixes)
+	}
+
+	// Fixes are applied to overlays, if any, and to the files on
+	// disk otherwise.
+	var fr ApplyFixReply
+	if err := client.Call("Staticcheck.ApplyFix", &ApplyFixArgs{Fix: p.Fixes[0]}, &fr); err != nil {
+		t.Fatal(err)
+	}
+	if len(fr.Files) != 1 || fr.Files[name] != fixed {
+		t.Errorf("got files %q, want %s changed to %q", fr.Files, name, fixed)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != src {
+		t.Errorf("file was changed without Write")
+	}
+	fr = ApplyFixReply{}
+	overlaid := strings.Replace(src, `"b"`, `"c"`, 1)
+	fargs := &ApplyFixArgs{Fix: p.Fixes[0], Overlay: map[string]string{name: overlaid}}
+	if err := client.Call("Staticcheck.ApplyFix", fargs, &fr); err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(fixed, `"b"`, `"c"`, 1); fr.Files[name] != want {
+		t.Errorf("got files %q, want %s changed to %q", fr.Files, name, want)
+	}
+	fr = ApplyFixReply{}
+	if err := client.Call("Staticcheck.ApplyFix", &ApplyFixArgs{Fix: p.Fixes[0], Write: true}, &fr); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != fixed {
+		t.Errorf("got file %q after Write, want %q", data, fixed)
+	}
+
+	lr = LintReply{}
+	if err := client.Call("Staticcheck.Lint", largs, &lr); err != nil {
+		t.Fatal(err)
+	}
+	if len(lr.Problems) != 0 {
+		t.Errorf("got problems %+v after applying the fix, want none", lr.Problems)
+	}
+
+	var er ExplainReply
+	if err := client.Call("Staticcheck.Explain", &ExplainArgs{Check: "SA6008"}, &er); err != nil {
+		t.Fatal(err)
+	}
+	if er.Checker != "staticcheck" || er.Title == "" || !er.NonDefault {
+		t.Errorf("got explanation %+v", er)
+	}
+	if err := client.Call("Staticcheck.Explain", &ExplainArgs{Check: "XX9999"}, &er); err == nil {
+		t.Errorf("explaining an unknown check succeeded")
+	}
+}
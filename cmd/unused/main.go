@@ -19,6 +19,7 @@ var (
 	fDebug        string
 	fWholeProgram bool
 	fReflection   bool
+	fUnreachable  bool
 )
 
 func newChecker(mode unused.CheckMode) *unused.Checker {
@@ -34,6 +35,7 @@ func newChecker(mode unused.CheckMode) *unused.Checker {
 
 	checker.WholeProgram = fWholeProgram
 	checker.ConsiderReflection = fReflection
+	checker.Unreachable = fUnreachable
 	return checker
 }
 
@@ -49,6 +51,7 @@ func main() {
 	fs.StringVar(&fDebug, "debug", "", "Write a debug graph to `file`. Existing files will be overwritten.")
 	fs.BoolVar(&fWholeProgram, "exported", false, "Treat arguments as a program and report unused exported identifiers")
 	fs.BoolVar(&fReflection, "reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+	fs.BoolVar(&fUnreachable, "unreachable", false, "Report functions that are only used by code that is never executed, using a call graph of the whole program")
 	fs.Parse(os.Args[1:])
 
 	var mode unused.CheckMode
@@ -0,0 +1,451 @@
+// Package config implements the loading of staticcheck.conf
+// configuration files.
+package config // import "honnef.co/go/tools/config"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ConfigName is the name of configuration files.
+const ConfigName = "staticcheck.conf"
+
+// Config is the configuration of a run of the linters.
+//
+// Configuration files apply to the directory they are in and all of
+// its subdirectories. A file inherits the settings of the closest
+// configuration file in a parent directory, unless it sets Root. See
+// Merge for how settings are combined.
+type Config struct {
+	// Root stops the inheritance of settings from configuration
+	// files in parent directories.
+	Root bool `toml:"root"`
+	// Checks selects the checks to run, using the same syntax as the
+	// -checks flag. Flags are applied after the configuration file.
+	Checks []string `toml:"checks"`
+	// Dictionary lists additional words that the spell checker
+	// accepts.
+	Dictionary []string `toml:"dictionary"`
+	// ImportGroups lists the groups that imports must be arranged
+	// in, in order, if the check for it is enabled: "std" for the
+	// standard library, "*" for all imports that no other group
+	// matches, and import paths, with a trailing "/..." to include
+	// subpackages, such as "example.com/project/...". The default is
+	// "std" and "*". Unlike other lists, a child's groups replace the
+	// parent's.
+	ImportGroups []string `toml:"import_groups"`
+	// Exhaustive selects the enum types whose switch statements must
+	// be exhaustive, if the check for them is enabled: "all" for all
+	// enum types, the default, or "annotated" for only those whose
+	// declaration is annotated with a //lint:enum comment.
+	Exhaustive string `toml:"exhaustive"`
+	// ExhaustiveFields lists struct types, as import path and type
+	// name, whose composite literals must set all fields.
+	ExhaustiveFields []string `toml:"exhaustive_fields"`
+	// ErrorWrapping is the project's policy for formatting errors
+	// with fmt.Errorf, if the check for it is enabled: "wrap" to
+	// require wrapping them with %w, or "any", the default, for no
+	// policy.
+	ErrorWrapping string `toml:"error_wrapping"`
+	// OpaqueErrorPackages lists the import paths of packages, such as
+	// those at the boundary of an API, that must not wrap errors,
+	// so that callers can't come to depend on the wrapped errors. A
+	// trailing "/..." includes subpackages.
+	OpaqueErrorPackages []string `toml:"opaque_error_packages"`
+	// InsecureTLSPackages lists the import paths of packages, such as
+	// test helpers and tools for talking to legacy servers, that may
+	// use weak TLS configurations. A trailing "/..." includes
+	// subpackages.
+	InsecureTLSPackages []string `toml:"insecure_tls_packages"`
+	// SQLQuoteFunctions lists functions, such as
+	// "github.com/lib/pq.QuoteIdentifier", whose results may be
+	// concatenated into SQL queries, because they quote identifiers
+	// or otherwise make values safe to use in queries.
+	SQLQuoteFunctions []string `toml:"sql_quote_functions"`
+	// UnmarshalFunctions lists additional functions, such as those
+	// of in-house codec packages, that unmarshal into their last
+	// argument. A trailing "*" matches any function whose name starts
+	// with the prefix, as in "example.com/codec.Unmarshal*". Only
+	// functions whose last parameter is an empty interface and that
+	// return an error are checked.
+	UnmarshalFunctions []string `toml:"unmarshal_functions"`
+	// InitAllowedFunctions lists functions, such as
+	// "example.com/config.MustLoad", that init functions may call
+	// even though they do I/O or panic on errors. A trailing "*"
+	// matches any function whose name starts with the prefix.
+	InitAllowedFunctions []string `toml:"init_allowed_functions"`
+	// MutableGlobals lists package-level variables, as import path
+	// and name, such as "example.com/pkg.registry", that may be
+	// modified outside of init functions. A trailing "*" matches any
+	// variable whose name starts with the prefix.
+	MutableGlobals []string `toml:"mutable_globals"`
+	// SecretNames lists additional words that, in the names of
+	// variables, fields and parameters, hint at secrets, such as
+	// "pin".
+	SecretNames []string `toml:"secret_names"`
+	// MustUseFunctions lists additional functions, such as
+	// "(*example.com/pkg.Builder).Build", whose results must not be
+	// discarded. A trailing "*" matches any function whose name
+	// starts with the prefix. Functions can also be marked with a
+	// //lint:must-use comment in their documentation.
+	MustUseFunctions []string `toml:"must_use_functions"`
+	// ImportRules restrict which packages may be imported, if the
+	// check for them is enabled. Rules of all configuration files
+	// apply.
+	ImportRules []ImportRule `toml:"import_rules"`
+	// Exclude lists gitignore-like patterns, relative to the
+	// directory of the configuration file, of files and directories
+	// to exclude from analysis, such as generated code and vendored
+	// third-party code.
+	Exclude []string `toml:"exclude"`
+	// IgnoreReasonMinLength is the minimum length of the reasons of
+	// //lint:ignore and //lint:file-ignore directives.
+	IgnoreReasonMinLength int `toml:"ignore_reason_min_length"`
+	// IgnoreReasonPattern is a regular expression that the reasons
+	// of directives must contain a match of, such as the ID of a
+	// ticket.
+	IgnoreReasonPattern string `toml:"ignore_reason_pattern"`
+	// MaxFunctionStatements and MaxFunctionLines are the maximum
+	// length of functions, if the check for it is enabled. Lines of
+	// tables, such as long composite literals of test cases, don't
+	// count. The defaults are 50 statements and 100 lines.
+	MaxFunctionStatements int `toml:"max_function_statements"`
+	MaxFunctionLines      int `toml:"max_function_lines"`
+	// MaxFileLines is the maximum length of files, if the check for
+	// it is enabled, not counting lines of tables. The default is
+	// 1000 lines.
+	MaxFileLines int `toml:"max_file_lines"`
+	// TodoPattern is a regular expression that TODO comments, starting
+	// at the word TODO, must match, if the check for it is enabled.
+	// The default, `^TODO\([^)]+\)`, requires an owner or a ticket in
+	// parentheses, as in TODO(name).
+	TodoPattern string `toml:"todo_pattern"`
+	// Messages replaces the templates of templated messages. Keys
+	// are check IDs, to replace all messages of a check, or check
+	// IDs and message IDs separated by a dot, as in
+	// "SA4006.unused-value", to replace a single message.
+	Messages map[string]string `toml:"messages"`
+	// Profiles are named variations of the configuration, selected
+	// with the -profile flag.
+	Profiles map[string]Profile `toml:"profiles"`
+}
+
+// A Profile adjusts the configuration for a particular use, such as
+// quick pre-commit runs or strict CI runs.
+type Profile struct {
+	// Checks are applied after the configuration's checks and before
+	// the -checks flag.
+	Checks []string `toml:"checks"`
+	// Format is the default output, in the syntax of the -f flag.
+	Format string `toml:"format"`
+	// Severity maps check patterns, such as "ST*", to severities.
+	// The most specific matching pattern applies; exact matches
+	// take precedence over patterns with wildcards.
+	Severity map[string]string `toml:"severity"`
+}
+
+// An ImportRule forbids imports of packages, such as deprecated
+// in-house libraries, dependencies with unsuitable licenses, or
+// unsafe. Patterns are import paths, with a trailing "/..." to
+// include subpackages; the pattern "std" matches the standard
+// library. Paths of vendored packages are matched without their
+// vendor directory prefix.
+type ImportRule struct {
+	// Forbid lists the packages that must not be imported.
+	Forbid []string `toml:"forbid"`
+	// Allow, if not empty, lists the only packages that may be
+	// imported.
+	Allow []string `toml:"allow"`
+	// Except lists the importing packages that the rule doesn't
+	// apply to.
+	Except []string `toml:"except"`
+	// Reason explains the rule and is included in reports.
+	Reason string `toml:"reason"`
+}
+
+// Severities are the valid values of Profile.Severity. Only problems
+// with the severity "error", the default, cause a non-zero exit
+// status.
+var Severities = []string{"error", "warning", "info"}
+
+// Exhaustives are the valid values of Config.Exhaustive.
+var Exhaustives = []string{"all", "annotated"}
+
+// ErrorWrappings are the valid values of Config.ErrorWrapping.
+var ErrorWrappings = []string{"any", "wrap"}
+
+// Merge returns the configuration that results from child inheriting
+// the settings of parent. The child's checks are applied after the
+// parent's, other lists such as dictionaries are combined, and other
+// options set by the child, including message templates, take
+// precedence. Profiles of the same name
+// are merged like configurations, with the child's severities and
+// format taking precedence.
+func Merge(parent, child Config) Config {
+	out := Config{
+		Checks:               append(append([]string(nil), parent.Checks...), child.Checks...),
+		Dictionary:           append(append([]string(nil), parent.Dictionary...), child.Dictionary...),
+		Exhaustive:           parent.Exhaustive,
+		ExhaustiveFields:     append(append([]string(nil), parent.ExhaustiveFields...), child.ExhaustiveFields...),
+		ErrorWrapping:        parent.ErrorWrapping,
+		OpaqueErrorPackages:  append(append([]string(nil), parent.OpaqueErrorPackages...), child.OpaqueErrorPackages...),
+		InsecureTLSPackages:  append(append([]string(nil), parent.InsecureTLSPackages...), child.InsecureTLSPackages...),
+		SQLQuoteFunctions:    append(append([]string(nil), parent.SQLQuoteFunctions...), child.SQLQuoteFunctions...),
+		UnmarshalFunctions:   append(append([]string(nil), parent.UnmarshalFunctions...), child.UnmarshalFunctions...),
+		InitAllowedFunctions: append(append([]string(nil), parent.InitAllowedFunctions...), child.InitAllowedFunctions...),
+		MutableGlobals:       append(append([]string(nil), parent.MutableGlobals...), child.MutableGlobals...),
+		SecretNames:          append(append([]string(nil), parent.SecretNames...), child.SecretNames...),
+		MustUseFunctions:     append(append([]string(nil), parent.MustUseFunctions...), child.MustUseFunctions...),
+		ImportRules:          append(append([]ImportRule(nil), parent.ImportRules...), child.ImportRules...),
+		Exclude:              append(append([]string(nil), parent.Exclude...), child.Exclude...),
+	}
+	if child.Exhaustive != "" {
+		out.Exhaustive = child.Exhaustive
+	}
+	out.ImportGroups = parent.ImportGroups
+	if len(child.ImportGroups) > 0 {
+		out.ImportGroups = append([]string(nil), child.ImportGroups...)
+	}
+	if child.ErrorWrapping != "" {
+		out.ErrorWrapping = child.ErrorWrapping
+	}
+	out.IgnoreReasonMinLength = parent.IgnoreReasonMinLength
+	if child.IgnoreReasonMinLength != 0 {
+		out.IgnoreReasonMinLength = child.IgnoreReasonMinLength
+	}
+	out.MaxFunctionStatements = parent.MaxFunctionStatements
+	if child.MaxFunctionStatements != 0 {
+		out.MaxFunctionStatements = child.MaxFunctionStatements
+	}
+	out.MaxFunctionLines = parent.MaxFunctionLines
+	if child.MaxFunctionLines != 0 {
+		out.MaxFunctionLines = child.MaxFunctionLines
+	}
+	out.MaxFileLines = parent.MaxFileLines
+	if child.MaxFileLines != 0 {
+		out.MaxFileLines = child.MaxFileLines
+	}
+	out.TodoPattern = parent.TodoPattern
+	if child.TodoPattern != "" {
+		out.TodoPattern = child.TodoPattern
+	}
+	out.IgnoreReasonPattern = parent.IgnoreReasonPattern
+	if child.IgnoreReasonPattern != "" {
+		out.IgnoreReasonPattern = child.IgnoreReasonPattern
+	}
+	if len(parent.Messages) > 0 || len(child.Messages) > 0 {
+		out.Messages = map[string]string{}
+	}
+	for k, v := range parent.Messages {
+		out.Messages[k] = v
+	}
+	for k, v := range child.Messages {
+		out.Messages[k] = v
+	}
+	if len(parent.Profiles) > 0 || len(child.Profiles) > 0 {
+		out.Profiles = map[string]Profile{}
+	}
+	for name, p := range parent.Profiles {
+		out.Profiles[name] = p
+	}
+	for name, c := range child.Profiles {
+		p := out.Profiles[name]
+		p.Checks = append(append([]string(nil), p.Checks...), c.Checks...)
+		if c.Format != "" {
+			p.Format = c.Format
+		}
+		if len(c.Severity) > 0 {
+			sev := map[string]string{}
+			for k, v := range p.Severity {
+				sev[k] = v
+			}
+			for k, v := range c.Severity {
+				sev[k] = v
+			}
+			p.Severity = sev
+		}
+		out.Profiles[name] = p
+	}
+	return out
+}
+
+// A Scoped configuration is the effective configuration of a
+// directory, merged from all the files that apply to it.
+type Scoped struct {
+	Config
+	// Files are the paths of the merged files, from the outermost
+	// to the innermost one.
+	Files []string
+}
+
+// LoadScoped loads the effective configuration of dir. If no
+// configuration file applies to dir, Files is empty.
+func LoadScoped(dir string) (Scoped, error) {
+	var cfgs []Config
+	var files []string
+	for {
+		path, ok := Find(dir)
+		if !ok {
+			break
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			return Scoped{}, err
+		}
+		cfgs = append(cfgs, cfg)
+		files = append(files, path)
+		if cfg.Root {
+			break
+		}
+		parent := filepath.Dir(filepath.Dir(path))
+		if parent == filepath.Dir(path) {
+			break
+		}
+		dir = parent
+	}
+	var out Scoped
+	for i := len(cfgs) - 1; i >= 0; i-- {
+		out.Config = Merge(out.Config, cfgs[i])
+		out.Files = append(out.Files, files[i])
+	}
+	return out, nil
+}
+
+// Profile returns the profile with the given name.
+func (cfg Config) Profile(name string) (Profile, error) {
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	for pattern, sev := range p.Severity {
+		if !isSeverity(sev) {
+			return Profile{}, fmt.Errorf("profile %q: invalid severity %q for %s, must be one of %s", name, sev, pattern, strings.Join(Severities, ", "))
+		}
+	}
+	return p, nil
+}
+
+func isSeverity(s string) bool {
+	for _, sev := range Severities {
+		if s == sev {
+			return true
+		}
+	}
+	return false
+}
+
+func isExhaustive(s string) bool {
+	for _, e := range Exhaustives {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isErrorWrapping(s string) bool {
+	for _, w := range ErrorWrappings {
+		if s == w {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIgnoreReason checks the reason of a //lint:ignore or
+// //lint:file-ignore directive against the requirements of the
+// configuration. It returns a description of the violated
+// requirement, or the empty string.
+func (cfg Config) CheckIgnoreReason(reason string) string {
+	if n := cfg.IgnoreReasonMinLength; n > 0 && len(strings.TrimSpace(reason)) < n {
+		return fmt.Sprintf("the reason of the linter directive must be at least %d characters long", n)
+	}
+	if cfg.IgnoreReasonPattern != "" {
+		re, err := regexp.Compile(cfg.IgnoreReasonPattern)
+		if err == nil && !re.MatchString(reason) {
+			return fmt.Sprintf("the reason of the linter directive must match %q", cfg.IgnoreReasonPattern)
+		}
+	}
+	return ""
+}
+
+// Find looks for a configuration file in dir and all of its parents,
+// returning the path of the first one found.
+func Find(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, ConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// An Error is an error in a configuration file.
+type Error struct {
+	Path string
+	Msg  string
+}
+
+func (err *Error) Error() string {
+	return err.Path + ": " + err.Msg
+}
+
+// Load parses the configuration file at path. Unknown keys are
+// reported as errors.
+func Load(path string) (Config, error) {
+	var cfg Config
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return Config{}, &Error{path, err.Error()}
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		var keys []string
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return Config{}, &Error{path, "unknown keys: " + strings.Join(keys, ", ")}
+	}
+	if cfg.Exhaustive != "" && !isExhaustive(cfg.Exhaustive) {
+		return Config{}, &Error{path, fmt.Sprintf("invalid value %q for exhaustive, must be one of %s", cfg.Exhaustive, strings.Join(Exhaustives, ", "))}
+	}
+	if cfg.IgnoreReasonMinLength < 0 {
+		return Config{}, &Error{path, fmt.Sprintf("invalid value %d for ignore_reason_min_length, must not be negative", cfg.IgnoreReasonMinLength)}
+	}
+	for _, limit := range []struct {
+		key string
+		n   int
+	}{
+		{"max_function_statements", cfg.MaxFunctionStatements},
+		{"max_function_lines", cfg.MaxFunctionLines},
+		{"max_file_lines", cfg.MaxFileLines},
+	} {
+		if limit.n < 0 {
+			return Config{}, &Error{path, fmt.Sprintf("invalid value %d for %s, must not be negative", limit.n, limit.key)}
+		}
+	}
+	if _, err := regexp.Compile(cfg.IgnoreReasonPattern); err != nil {
+		return Config{}, &Error{path, fmt.Sprintf("invalid ignore_reason_pattern: %s", err)}
+	}
+	if _, err := regexp.Compile(cfg.TodoPattern); err != nil {
+		return Config{}, &Error{path, fmt.Sprintf("invalid todo_pattern: %s", err)}
+	}
+	for i, rule := range cfg.ImportRules {
+		if len(rule.Forbid) == 0 && len(rule.Allow) == 0 {
+			return Config{}, &Error{path, fmt.Sprintf("import rule %d has neither forbid nor allow", i+1)}
+		}
+	}
+	if cfg.ErrorWrapping != "" && !isErrorWrapping(cfg.ErrorWrapping) {
+		return Config{}, &Error{path, fmt.Sprintf("invalid value %q for error_wrapping, must be one of %s", cfg.ErrorWrapping, strings.Join(ErrorWrappings, ", "))}
+	}
+	return cfg, nil
+}
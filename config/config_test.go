@@ -0,0 +1,195 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadScoped(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		dir   string
+		want  Config
+		// merged are the directories of the merged files, from the
+		// outermost to the innermost one.
+		merged []string
+	}{
+		{
+			name:   "none",
+			dir:    "a/b",
+			want:   Config{},
+			merged: nil,
+		},
+		{
+			name: "inherited",
+			files: map[string]string{
+				"": `checks = ["all", "-ST1000"]
+dictionary = ["staticcheck"]
+max_file_lines = 500`,
+			},
+			dir: "a/b",
+			want: Config{
+				Checks:       []string{"all", "-ST1000"},
+				Dictionary:   []string{"staticcheck"},
+				MaxFileLines: 500,
+			},
+			merged: []string{""},
+		},
+		{
+			name: "lists are combined",
+			files: map[string]string{
+				"": `checks = ["all", "-ST1000"]
+dictionary = ["staticcheck"]
+secret_names = ["pin"]
+exclude = ["gen/"]`,
+				"a": `checks = ["ST1000", "-SA1019"]
+dictionary = ["gopher"]
+secret_names = ["passphrase"]`,
+			},
+			dir: "a/b",
+			want: Config{
+				Checks:      []string{"all", "-ST1000", "ST1000", "-SA1019"},
+				Dictionary:  []string{"staticcheck", "gopher"},
+				SecretNames: []string{"pin", "passphrase"},
+				Exclude:     []string{"gen/"},
+			},
+			merged: []string{"", "a"},
+		},
+		{
+			name: "options are overridden",
+			files: map[string]string{
+				"": `exhaustive = "annotated"
+error_wrapping = "wrap"
+max_function_lines = 80
+max_file_lines = 500
+todo_pattern = "^TODO"`,
+				"a": `exhaustive = "all"
+max_function_lines = 120`,
+			},
+			dir: "a",
+			want: Config{
+				Exhaustive:       "all",
+				ErrorWrapping:    "wrap",
+				MaxFunctionLines: 120,
+				MaxFileLines:     500,
+				TodoPattern:      "^TODO",
+			},
+			merged: []string{"", "a"},
+		},
+		{
+			name: "import groups are replaced",
+			files: map[string]string{
+				"":    `import_groups = ["std", "*", "example.com/..."]`,
+				"a":   `dictionary = ["gopher"]`,
+				"a/b": `import_groups = ["std", "*"]`,
+			},
+			dir: "a/b/c",
+			want: Config{
+				Dictionary:   []string{"gopher"},
+				ImportGroups: []string{"std", "*"},
+			},
+			merged: []string{"", "a", "a/b"},
+		},
+		{
+			name: "root",
+			files: map[string]string{
+				"":  `checks = ["all"]`,
+				"a": "root = true\n" + `checks = ["SA*"]`,
+				"a/b": `checks = ["-SA1019"]
+dictionary = ["gopher"]`,
+			},
+			dir: "a/b",
+			want: Config{
+				Checks:     []string{"SA*", "-SA1019"},
+				Dictionary: []string{"gopher"},
+			},
+			merged: []string{"a", "a/b"},
+		},
+		{
+			name: "messages and profiles",
+			files: map[string]string{
+				"": `[messages]
+SA4006 = "unused {{.name}}"
+"SA4006.unused-value" = "parent"
+
+[profiles.ci]
+checks = ["all"]
+format = "json"
+severity = { "ST*" = "warning", SA1019 = "info" }`,
+				"a": `[messages]
+"SA4006.unused-value" = "child"
+
+[profiles.ci]
+checks = ["-ST1000"]
+severity = { SA1019 = "error" }
+
+[profiles.quick]
+checks = ["-U1000"]`,
+			},
+			dir: "a",
+			want: Config{
+				Messages: map[string]string{
+					"SA4006":              "unused {{.name}}",
+					"SA4006.unused-value": "child",
+				},
+				Profiles: map[string]Profile{
+					"ci": {
+						Checks:   []string{"all", "-ST1000"},
+						Format:   "json",
+						Severity: map[string]string{"ST*": "warning", "SA1019": "error"},
+					},
+					"quick": {Checks: []string{"-U1000"}},
+				},
+			},
+			merged: []string{"", "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(root)
+			for dir, data := range tt.files {
+				dir = filepath.Join(root, filepath.FromSlash(dir))
+				if err := os.MkdirAll(dir, 0777); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dir := filepath.Join(root, filepath.FromSlash(tt.dir))
+			if err := os.MkdirAll(dir, 0777); err != nil {
+				t.Fatal(err)
+			}
+
+			sc, err := LoadScoped(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sc.Config, tt.want) {
+				t.Errorf("got %+v, want %+v", sc.Config, tt.want)
+			}
+			var merged []string
+			for _, f := range sc.Files {
+				rel, err := filepath.Rel(root, filepath.Dir(f))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rel == "." {
+					rel = ""
+				}
+				merged = append(merged, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(merged, tt.merged) {
+				t.Errorf("merged files in %q, want %q", merged, tt.merged)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package errcheck
+
+import "honnef.co/go/tools/lint"
+
+// Docs documents the checks and categories of checks of this checker.
+var Docs = map[string]*lint.Documentation{
+	"ERR1": {
+		Title: "Unchecked errors",
+	},
+	"ERR1000": {
+		Title: "Unchecked error",
+	},
+	"ERR1001": {
+		Title: "Deferred Close or Flush of a writer discards its error",
+		Text: "Closing a writable file or flushing a buffered writer is where\n" +
+			"errors of earlier writes are reported. Deferring the call discards\n" +
+			"that error, and data may be lost silently. Assign the error to a\n" +
+			"named error result of the function instead:\n" +
+			"\n" +
+			"    defer func() {\n" +
+			"        if cerr := f.Close(); err == nil {\n" +
+			"            err = cerr\n" +
+			"        }\n" +
+			"    }()\n",
+	},
+}
@@ -1,7 +1,9 @@
 package errcheck
 
 import (
+	"go/ast"
 	"go/types"
+	"os"
 
 	"honnef.co/go/tools/functions"
 	"honnef.co/go/tools/lint"
@@ -20,9 +22,12 @@ func NewChecker() *Checker {
 func (*Checker) Name() string   { return "errcheck" }
 func (*Checker) Prefix() string { return "ERR" }
 
+func (*Checker) Docs() map[string]*lint.Documentation { return Docs }
+
 func (c *Checker) Funcs() map[string]lint.Func {
 	return map[string]lint.Func{
 		"ERR1000": c.CheckErrcheck,
+		"ERR1001": c.CheckDeferredClose,
 	}
 }
 
@@ -56,6 +61,10 @@ func (c *Checker) CheckErrcheck(j *lint.Job) {
 					}
 				case ssa.Instruction:
 					// will be a 'go' or 'defer', neither of which has usable return values
+					if ins, ok := ins.(*ssa.Defer); ok && discardsWriteError(ins) {
+						// Reported by CheckDeferredClose
+						continue
+					}
 				default:
 					// shouldn't happen
 					continue
@@ -156,3 +165,88 @@ func isReadOnlyFile(val ssa.Value, seen map[ssa.Value]bool) bool {
 	}
 	return false
 }
+
+func isWritableFile(val ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen == nil {
+		seen = map[ssa.Value]bool{}
+	}
+	if seen[val] {
+		return false
+	}
+	seen[val] = true
+	switch val := val.(type) {
+	case *ssa.Phi:
+		for _, edge := range val.Edges {
+			if isWritableFile(edge, seen) {
+				return true
+			}
+		}
+		return false
+	case *ssa.Extract:
+		call, ok := val.Tuple.(*ssa.Call)
+		if !ok {
+			return false
+		}
+		switch CallName(call.Common()) {
+		case "os.Create", "os.CreateTemp", "io/ioutil.TempFile":
+			return true
+		case "os.OpenFile":
+			flags, ok := call.Common().Args[1].(*ssa.Const)
+			return ok && flags.Uint64()&(uint64(os.O_WRONLY)|uint64(os.O_RDWR)) != 0
+		}
+		return false
+	}
+	return false
+}
+
+// writerMethods are the methods that flush buffered data of writers
+// and that report errors of earlier writes.
+var writerMethods = map[string]bool{
+	"(*bufio.Writer).Flush":          true,
+	"(*compress/gzip.Writer).Close":  true,
+	"(*compress/gzip.Writer).Flush":  true,
+	"(*compress/zlib.Writer).Close":  true,
+	"(*compress/zlib.Writer).Flush":  true,
+	"(*compress/flate.Writer).Close": true,
+	"(*compress/flate.Writer).Flush": true,
+	"(*archive/tar.Writer).Close":    true,
+	"(*archive/tar.Writer).Flush":    true,
+	"(*archive/zip.Writer).Close":    true,
+	"(*archive/zip.Writer).Flush":    true,
+}
+
+// discardsWriteError reports whether the deferred call closes a
+// writable file or flushes a writer, discarding the error that
+// reports lost writes.
+func discardsWriteError(ins *ssa.Defer) bool {
+	call := ins.Common()
+	name := CallName(call)
+	if name == "(*os.File).Close" {
+		return isWritableFile(call.Args[0], nil)
+	}
+	return writerMethods[name]
+}
+
+func (c *Checker) CheckDeferredClose(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, b := range ssafn.Blocks {
+			for _, ins := range b.Instrs {
+				ins, ok := ins.(*ssa.Defer)
+				if !ok || !discardsWriteError(ins) {
+					continue
+				}
+				fun := ins.Common().StaticCallee().Name() + "()"
+				if syntax := ssafn.Syntax(); syntax != nil {
+					ast.Inspect(syntax, func(node ast.Node) bool {
+						if stmt, ok := node.(*ast.DeferStmt); ok && stmt.Defer == ins.Pos() {
+							fun = Render(j, stmt.Call)
+						}
+						return true
+					})
+				}
+				j.Errorf(ins, "deferring %s discards its error, which may be the only report of lost writes; "+
+					"capture it in a named error result: defer func() { if cerr := %s; err == nil { err = cerr } }()", fun, fun)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+func fn1() error {
+	f, err := os.Create("")
+	if err != nil {
+		return err
+	}
+	defer f.Close() // MATCH /deferring f.Close\(\) discards its error/
+	_, err = f.Write(nil)
+	return err
+}
+
+func fn2() error {
+	f, err := os.Open("")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Read(nil)
+	return err
+}
+
+func fn3() error {
+	f, err := os.OpenFile("", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // MATCH /named error result/
+	w := bufio.NewWriter(f)
+	defer w.Flush() // MATCH /deferring w.Flush\(\) discards its error/
+	_, err = w.WriteString("")
+	return err
+}
+
+func fn4(w io.Writer) (err error) {
+	zw := gzip.NewWriter(w)
+	defer func() {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	_, err = zw.Write(nil)
+	return err
+}
+
+func fn5(w io.WriteCloser) {
+	defer w.Close() // MATCH /unchecked error/
+}
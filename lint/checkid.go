@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Check IDs consist of an optional namespace, a prefix of letters that
+// identifies the checker, and a number, as in "SA1000" or
+// "acme/SA001". Namespaces allow third-party checkers to use any
+// prefix, including those of this repository's checkers, without
+// colliding with other checkers. Checkers without a namespace must
+// use a prefix of their own, such as "ACME" in "ACME1001". A checker's
+// Prefix includes its namespace, as in "acme/SA".
+
+// SplitCheckID splits the check ID id into its namespace, which is
+// empty for checks without one, its prefix and its number. It reports
+// whether id is well-formed.
+func SplitCheckID(id string) (namespace, prefix, number string, ok bool) {
+	local := id
+	if i := strings.LastIndex(id, "/"); i != -1 {
+		namespace, local = id[:i], id[i+1:]
+		if namespace == "" {
+			return "", "", "", false
+		}
+	}
+	i := strings.IndexFunc(local, func(r rune) bool { return r < 'A' || r > 'Z' })
+	if i <= 0 {
+		return "", "", "", false
+	}
+	prefix, number = local[:i], local[i:]
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return "", "", "", false
+		}
+	}
+	return namespace, prefix, number, number != ""
+}
+
+// checkerPrefix returns the prefix, including the namespace, of the
+// checker that the check ID or pattern id refers to, or the empty
+// string if id doesn't name one, as in "SA*".
+func checkerPrefix(id string) string {
+	namespace, local := "", id
+	if i := strings.LastIndex(id, "/"); i != -1 {
+		namespace, local = id[:i+1], id[i+1:]
+	}
+	i := strings.IndexFunc(local, func(r rune) bool { return r >= '0' && r <= '9' })
+	if i <= 0 {
+		return ""
+	}
+	return namespace + local[:i]
+}
+
+// MatchCheck reports whether the pattern, which may contain the
+// wildcards of filepath.Match, matches the check ID id. Wildcards
+// don't match the slashes of namespaces: patterns without a namespace
+// only match checks without one, except for "*", which matches all
+// checks.
+func MatchCheck(pattern, id string) bool {
+	if pattern == "*" {
+		return true
+	}
+	m, _ := filepath.Match(pattern, id)
+	return m
+}
+
+// ValidateCheckers verifies that the IDs of the checks of cs are
+// well-formed, start with the prefixes of their checkers, and that no
+// two checkers share a prefix, which would make their checks
+// indistinguishable.
+func ValidateCheckers(cs []Checker) error {
+	prefixes := map[string]Checker{}
+	for _, c := range cs {
+		if other, ok := prefixes[c.Prefix()]; ok {
+			return fmt.Errorf("checkers %s and %s use the same prefix %q; use a namespace, as in \"example/%s\", to distinguish them",
+				other.Name(), c.Name(), c.Prefix(), c.Prefix())
+		}
+		prefixes[c.Prefix()] = c
+		for id := range c.Funcs() {
+			if _, _, _, ok := SplitCheckID(id); !ok || checkerPrefix(id) != c.Prefix() {
+				return fmt.Errorf("check %q of checker %s doesn't consist of the checker's prefix %q and a number", id, c.Name(), c.Prefix())
+			}
+		}
+	}
+	return nil
+}
@@ -8,18 +8,21 @@
 package lint // import "honnef.co/go/tools/lint"
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/build"
 	"go/token"
 	"go/types"
 	"path/filepath"
+	"runtime/debug"
 	"sort"
 	"strings"
 	"sync"
-	"unicode"
 
 	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/ssa"
 	"honnef.co/go/tools/ssa/ssautil"
 )
@@ -27,9 +30,12 @@ import (
 type Job struct {
 	Program *Program
 
+	ctx      context.Context
 	checker  string
 	check    string
 	problems []Problem
+	// finished is set when the check has returned.
+	finished bool
 }
 
 type Ignore interface {
@@ -49,7 +55,7 @@ func (li *LineIgnore) Match(p Problem) bool {
 		return false
 	}
 	for _, c := range li.Checks {
-		if m, _ := filepath.Match(c, p.Check); m {
+		if MatchCheck(c, p.Check) {
 			li.matched = true
 			return true
 		}
@@ -75,7 +81,7 @@ func (fi *FileIgnore) Match(p Problem) bool {
 		return false
 	}
 	for _, c := range fi.Checks {
-		if m, _ := filepath.Match(c, p.Check); m {
+		if MatchCheck(c, p.Check) {
 			return true
 		}
 	}
@@ -99,7 +105,7 @@ func (gi *GlobIgnore) Match(p Problem) bool {
 		}
 	}
 	for _, c := range gi.Checks {
-		if m, _ := filepath.Match(c, p.Check); m {
+		if MatchCheck(c, p.Check) {
 			return true
 		}
 	}
@@ -116,6 +122,10 @@ type Program struct {
 	Files            []*ast.File
 	Info             *types.Info
 	GoVersion        int
+	// Build is the build context that was used to load the program.
+	Build *build.Context
+	// Config is the configuration of the current run.
+	Config config.Config
 
 	tokenFileMap map[*token.File]*ast.File
 	astFileMap   map[*ast.File]*Pkg
@@ -127,11 +137,101 @@ type Func func(*Job)
 type Problem struct {
 	pos      token.Pos
 	Position token.Position // position in source file
+	End      token.Position // end of the reported range, if known
 	Text     string         // the prose that describes the problem
 	Check    string
 	Checker  string
 	Package  *types.Package
 	Ignored  bool
+	// URL links to the documentation of the check, if any.
+	URL string
+	// Related lists other positions that are relevant to the
+	// problem, such as the declaration of an involved identifier.
+	Related []Related
+	// Fixes are suggested changes that resolve the problem. Each
+	// fix is an alternative to the others; the first one is
+	// preferred.
+	Fixes []Fix
+	// Severity is the severity of the problem, as configured by the
+	// user. The empty string means "error".
+	Severity string
+	// Variant names the build variants of the package, such as
+	// "test", that reported the problem, if it wasn't reported by
+	// all of them. It is only set if Linter.AnnotateVariants is.
+	Variant string
+	// MessageID identifies the message of problems reported with
+	// templated messages, among the messages of the check. Args
+	// are the arguments that Text was rendered with. See
+	// Job.Reportf.
+	MessageID string
+	Args      map[string]string
+	// Owners are the owners of the problem's file, such as teams
+	// named in a CODEOWNERS file. They are set by tools that know
+	// about ownership; the linter doesn't set them.
+	Owners []string
+	// Stack is the stack trace of problems with the check
+	// InternalErrorCheck.
+	Stack string
+}
+
+// InternalErrorCheck is the check of problems that report panics of
+// checks. Their arguments "check", "package" and "panic" name the
+// check that panicked, the package it was checking and the value it
+// panicked with.
+const InternalErrorCheck = "linter-internal-error"
+
+// A Fix is a suggested change to the source code, consisting of one or
+// more edits.
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
+// An Edit replaces the text between Position and End with NewText.
+// Positions refer to the actual files, ignoring //line directives.
+type Edit struct {
+	Position token.Position
+	End      token.Position
+	NewText  string
+}
+
+// ApplyEdits applies edits to src. Identical edits are applied once;
+// other overlapping edits are an error.
+func ApplyEdits(src []byte, edits []Edit) ([]byte, error) {
+	sort.Sort(byOffset(edits))
+	var out bytes.Buffer
+	last := 0
+	for i, e := range edits {
+		if i > 0 && e == edits[i-1] {
+			continue
+		}
+		start, end := e.Position.Offset, e.End.Offset
+		if start < last || end < start || end > len(src) {
+			return nil, fmt.Errorf("edit at %s overlaps another edit", e.Position)
+		}
+		out.Write(src[last:start])
+		out.WriteString(e.NewText)
+		last = end
+	}
+	out.Write(src[last:])
+	return out.Bytes(), nil
+}
+
+type byOffset []Edit
+
+func (es byOffset) Len() int      { return len(es) }
+func (es byOffset) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
+func (es byOffset) Less(i, j int) bool {
+	if es[i].Position.Offset != es[j].Position.Offset {
+		return es[i].Position.Offset < es[j].Position.Offset
+	}
+	return es[i].End.Offset < es[j].End.Offset
+}
+
+// Related describes a position related to a problem.
+type Related struct {
+	Position token.Position
+	Text     string
 }
 
 func (p *Problem) String() string {
@@ -148,14 +248,114 @@ type Checker interface {
 	Funcs() map[string]Func
 }
 
+// Documentation describes a check or a category of checks.
+type Documentation struct {
+	// Title is a single line summary.
+	Title string
+	// Text is an optional, longer description, formatted as
+	// Markdown.
+	Text string
+	// NonDefault marks checks that are disabled unless explicitly
+	// enabled.
+	NonDefault bool
+}
+
+// A DocumentedChecker is a Checker that provides documentation for
+// its checks. The returned map is keyed by check ID. Categories,
+// such as "SA1", may be documented in the same map.
+type DocumentedChecker interface {
+	Checker
+	Docs() map[string]*Documentation
+}
+
+// A SyntacticChecker is a Checker some of whose checks only depend on
+// the syntax of files. These checks will also be run on packages that
+// failed to type-check, if the loader was configured to allow
+// errors. They must not depend on state set up by Init.
+type SyntacticChecker interface {
+	Checker
+	SyntacticChecks() []string
+}
+
+// A ProgramChecker is a Checker some of whose checks look at all
+// packages that are linted together, so that the problems they report
+// in one package depend on which other packages are being linted.
+// Tools that cache results per package must take this into account.
+type ProgramChecker interface {
+	Checker
+	ProgramChecks() []string
+}
+
+// A Scope overrides the checks and configuration for some packages,
+// such as those in a directory tree with its own configuration file.
+type Scope struct {
+	// Checks selects the checks to run, like Linter.Checks.
+	Checks []string
+	Config config.Config
+}
+
 // A Linter lints Go source code.
 type Linter struct {
 	Checker       Checker
 	Ignores       []Ignore
 	GoVersion     int
 	ReturnIgnored bool
+	// DocsURL is the base URL of the checks' documentation. If set,
+	// problems of documented checks link to DocsURL#<check>.
+	DocsURL string
+	// Checks selects the checks to run. See EnabledChecks.
+	Checks []string
+	// Config is made available to checks via Program.Config.
+	Config config.Config
+	// Scope, if set, returns the scope of the package in dir, or
+	// nil if Checks and Config apply to it.
+	Scope func(dir string) (*Scope, error)
+	// AnnotateVariants causes problems that were found in only some
+	// build variants of a package to record those variants in
+	// Problem.Variant.
+	AnnotateVariants bool
+
+	// Progress, if set, is called with the number of finished and
+	// total jobs, once before the checks start running and after
+	// each check finishes. Checks with syntactic variants count
+	// twice. Calls are serialized.
+	Progress func(done, total int)
+
+	// Suppressions is set by Lint and records, for each ignore,
+	// including the ones created by linter directives, how many
+	// problems it suppressed.
+	Suppressions []Suppression
 
 	automaticIgnores []Ignore
+	suppressed       map[Ignore]int
+}
+
+// concerns reports whether a linter directive may apply to checks of
+// the linter's checker.
+func (l *Linter) concerns(ig Ignore) bool {
+	var checks []string
+	switch ig := ig.(type) {
+	case *LineIgnore:
+		checks = ig.Checks
+	case *FileIgnore:
+		checks = ig.Checks
+	default:
+		return true
+	}
+	for _, c := range checks {
+		// Patterns that don't name a checker, such as "*", may
+		// apply to any checker.
+		if prefix := checkerPrefix(c); prefix == "" || prefix == l.Checker.Prefix() {
+			return true
+		}
+	}
+	return false
+}
+
+// A Suppression records how many problems an ignore suppressed.
+type Suppression struct {
+	Ignore Ignore
+	Count  int
 }
 
 func (l *Linter) ignore(p Problem) bool {
@@ -164,6 +364,7 @@ func (l *Linter) ignore(p Problem) bool {
 		// We cannot short-circuit these, as we want to record, for
 		// each ignore, whether it matched or not.
 		if ig.Match(p) {
+			l.suppressed[ig]++
 			ignored = true
 		}
 	}
@@ -173,9 +374,10 @@ func (l *Linter) ignore(p Problem) bool {
 		return true
 	}
 	for _, ig := range l.Ignores {
-		// We can short-circuit here, as we aren't tracking any
-		// information.
+		// We can short-circuit here, as we only credit the first
+		// matching ignore.
 		if ig.Match(p) {
+			l.suppressed[ig]++
 			return true
 		}
 	}
@@ -221,6 +423,57 @@ func (ps byPosition) Swap(i int, j int) {
 	ps.ps[i], ps.ps[j] = ps.ps[j], ps.ps[i]
 }
 
+// EnabledChecks returns the sorted IDs of the checks of c that are
+// enabled by patterns. Patterns are applied in order to the default
+// set of checks, which consists of all checks not marked as
+// NonDefault. "all" enables all checks, "none" disables all checks,
+// a pattern prefixed with "-" disables matching checks and any other
+// pattern enables matching checks. Patterns may contain wildcards.
+func EnabledChecks(c Checker, patterns []string) []string {
+	var docs map[string]*Documentation
+	if dc, ok := c.(DocumentedChecker); ok {
+		docs = dc.Docs()
+	}
+	funcs := c.Funcs()
+	enabled := map[string]bool{}
+	for id := range funcs {
+		if doc := docs[id]; doc == nil || !doc.NonDefault {
+			enabled[id] = true
+		}
+	}
+	for _, pat := range patterns {
+		switch pat {
+		case "all":
+			for id := range funcs {
+				enabled[id] = true
+			}
+			continue
+		case "none":
+			enabled = map[string]bool{}
+			continue
+		}
+		value := true
+		if strings.HasPrefix(pat, "-") {
+			value = false
+			pat = pat[1:]
+		}
+		for id := range funcs {
+			if MatchCheck(pat, id) {
+				enabled[id] = value
+			}
+		}
+	}
+
+	var out []string
+	for id, ok := range enabled {
+		if ok && funcs[id] != nil {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 func parseDirective(s string) (cmd string, args []string) {
 	if !strings.HasPrefix(s, "//lint:") {
 		return "", nil
@@ -230,44 +483,85 @@ func parseDirective(s string) (cmd string, args []string) {
 	return fields[0], fields[1:]
 }
 
-func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
+// BuildSSA builds the SSA form of lprog, as analyzed by checkers.
+func BuildSSA(lprog *loader.Program) *ssa.Program {
 	ssaprog := ssautil.CreateProgram(lprog, ssa.GlobalDebug)
 	ssaprog.Build()
-	pkgMap := map[*ssa.Package]*Pkg{}
-	var pkgs []*Pkg
+	return ssaprog
+}
+
+func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
+	ps, _ := l.LintContext(context.Background(), lprog, conf)
+	return ps
+}
+
+// LintContext is like Lint, but stops early and returns the context's
+// error if the context gets canceled, together with the problems of
+// the checks that had finished by then. Checks that are already
+// running can observe the cancellation via Job.Context.
+func (l *Linter) LintContext(ctx context.Context, lprog *loader.Program, conf *loader.Config) ([]Problem, error) {
+	ssaprog := BuildSSA(lprog)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bctx := conf.Build
+	if bctx == nil {
+		bctx = &build.Default
+	}
+	var pkgs, illTyped []*Pkg
+	var scopes []*Scope
 	for _, pkginfo := range lprog.InitialPackages() {
 		ssapkg := ssaprog.Package(pkginfo.Pkg)
+		typed := ssapkg != nil
+		if !typed {
+			// Packages with type errors don't get built by SSA. Give
+			// them an empty package so that they can still be
+			// subjected to syntactic checks.
+			ssapkg = &ssa.Package{
+				Prog:    ssaprog,
+				Pkg:     pkginfo.Pkg,
+				Members: map[string]ssa.Member{},
+			}
+		}
 		var bp *build.Package
+		var scope *Scope
 		if len(pkginfo.Files) != 0 {
 			path := lprog.Fset.Position(pkginfo.Files[0].Pos()).Filename
 			dir := filepath.Dir(path)
 			var err error
-			ctx := conf.Build
-			if ctx == nil {
-				ctx = &build.Default
-			}
-			bp, err = ctx.ImportDir(dir, 0)
+			bp, err = bctx.ImportDir(dir, 0)
 			if err != nil {
 				// shouldn't happen
 			}
+			if l.Scope != nil {
+				scope, err = l.Scope(dir)
+				if err != nil {
+					return nil, err
+				}
+			}
 		}
 		pkg := &Pkg{
 			Package:  ssapkg,
 			Info:     pkginfo,
 			BuildPkg: bp,
+			Config:   l.Config,
+		}
+		if scope != nil {
+			pkg.Config = scope.Config
+			pkg.checks = map[string]bool{}
+			for _, k := range EnabledChecks(l.Checker, scope.Checks) {
+				pkg.checks[k] = true
+			}
+			scopes = append(scopes, scope)
+		}
+		if typed {
+			pkgs = append(pkgs, pkg)
+		} else {
+			illTyped = append(illTyped, pkg)
 		}
-		pkgMap[ssapkg] = pkg
-		pkgs = append(pkgs, pkg)
-	}
-	prog := &Program{
-		SSA:          ssaprog,
-		Prog:         lprog,
-		Packages:     pkgs,
-		Info:         &types.Info{},
-		GoVersion:    l.GoVersion,
-		tokenFileMap: map[*token.File]*ast.File{},
-		astFileMap:   map[*ast.File]*Pkg{},
 	}
+	prog := newProgram(ssaprog, lprog, pkgs, l.GoVersion, bctx)
+	prog.Config = l.Config
 
 	initial := map[*types.Package]struct{}{}
 	for _, pkg := range pkgs {
@@ -282,24 +576,27 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 			prog.InitialFunctions = append(prog.InitialFunctions, fn)
 		}
 	}
-	for _, pkg := range pkgs {
-		prog.Files = append(prog.Files, pkg.Info.Files...)
 
-		ssapkg := ssaprog.Package(pkg.Info.Pkg)
-		for _, f := range pkg.Info.Files {
-			prog.astFileMap[f] = pkgMap[ssapkg]
-		}
+	// Packages with type errors are linted as a separate program,
+	// which contains no functions and only partial type
+	// information.
+	var syntaxProg *Program
+	if len(illTyped) > 0 {
+		syntaxProg = newProgram(ssaprog, lprog, illTyped, l.GoVersion, bctx)
+		syntaxProg.Config = l.Config
 	}
-
-	for _, pkginfo := range lprog.AllPackages {
-		for _, f := range pkginfo.Files {
-			tf := lprog.Fset.File(f.Pos())
-			prog.tokenFileMap[tf] = f
+	// displayPosition is like Program.DisplayPosition, but also
+	// handles positions in packages with type errors.
+	displayPosition := func(pos token.Pos) token.Position {
+		if syntaxProg != nil && prog.packageAt(pos) == nil {
+			return syntaxProg.DisplayPosition(pos)
 		}
+		return prog.DisplayPosition(pos)
 	}
 
 	var out []Problem
 	l.automaticIgnores = nil
+	l.suppressed = map[Ignore]int{}
 	for _, pkginfo := range lprog.InitialPackages() {
 		for _, f := range pkginfo.Files {
 			cm := ast.NewCommentMap(lprog.Fset, f, f.Comments)
@@ -316,7 +613,7 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 								// FIXME(dh): this causes duplicated warnings when using megacheck
 								p := Problem{
 									pos:      c.Pos(),
-									Position: prog.DisplayPosition(c.Pos()),
+									Position: displayPosition(c.Pos()),
 									Text:     "malformed linter directive; missing the required reason field?",
 									Check:    "",
 									Checker:  l.Checker.Name(),
@@ -325,12 +622,30 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 								out = append(out, p)
 								continue
 							}
+							cfg := l.Config
+							if pkg := prog.packageAt(c.Pos()); pkg != nil {
+								cfg = pkg.Config
+							} else if syntaxProg != nil {
+								if pkg := syntaxProg.packageAt(c.Pos()); pkg != nil {
+									cfg = pkg.Config
+								}
+							}
+							if msg := cfg.CheckIgnoreReason(strings.Join(args[1:], " ")); msg != "" {
+								// The directive still applies; the
+								// problem makes it auditable.
+								out = append(out, Problem{
+									pos:      c.Pos(),
+									Position: displayPosition(c.Pos()),
+									Text:     msg,
+									Checker:  l.Checker.Name(),
+								})
+							}
 						default:
 							// unknown directive, ignore
 							continue
 						}
 						checks := strings.Split(args[0], ",")
-						pos := prog.DisplayPosition(node.Pos())
+						pos := displayPosition(node.Pos())
 						var ig Ignore
 						switch cmd {
 						case "ignore":
@@ -353,87 +668,166 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 		}
 	}
 
-	sizes := struct {
-		types      int
-		defs       int
-		uses       int
-		implicits  int
-		selections int
-		scopes     int
-	}{}
-	for _, pkg := range pkgs {
-		sizes.types += len(pkg.Info.Info.Types)
-		sizes.defs += len(pkg.Info.Info.Defs)
-		sizes.uses += len(pkg.Info.Info.Uses)
-		sizes.implicits += len(pkg.Info.Info.Implicits)
-		sizes.selections += len(pkg.Info.Info.Selections)
-		sizes.scopes += len(pkg.Info.Info.Scopes)
-	}
-	prog.Info.Types = make(map[ast.Expr]types.TypeAndValue, sizes.types)
-	prog.Info.Defs = make(map[*ast.Ident]types.Object, sizes.defs)
-	prog.Info.Uses = make(map[*ast.Ident]types.Object, sizes.uses)
-	prog.Info.Implicits = make(map[ast.Node]types.Object, sizes.implicits)
-	prog.Info.Selections = make(map[*ast.SelectorExpr]*types.Selection, sizes.selections)
-	prog.Info.Scopes = make(map[ast.Node]*types.Scope, sizes.scopes)
-	for _, pkg := range pkgs {
-		for k, v := range pkg.Info.Info.Types {
-			prog.Info.Types[k] = v
-		}
-		for k, v := range pkg.Info.Info.Defs {
-			prog.Info.Defs[k] = v
-		}
-		for k, v := range pkg.Info.Info.Uses {
-			prog.Info.Uses[k] = v
-		}
-		for k, v := range pkg.Info.Info.Implicits {
-			prog.Info.Implicits[k] = v
-		}
-		for k, v := range pkg.Info.Info.Selections {
-			prog.Info.Selections[k] = v
-		}
-		for k, v := range pkg.Info.Info.Scopes {
-			prog.Info.Scopes[k] = v
-		}
-	}
 	l.Checker.Init(prog)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	funcs := l.Checker.Funcs()
+	enabled := map[string]bool{}
+	for _, k := range EnabledChecks(l.Checker, l.Checks) {
+		enabled[k] = true
+	}
+	// Run all checks that are enabled for any package, and filter
+	// problems by the checks enabled for their packages.
+	run := map[string]bool{}
+	for k := range enabled {
+		run[k] = true
+	}
+	for _, scope := range scopes {
+		for _, k := range EnabledChecks(l.Checker, scope.Checks) {
+			run[k] = true
+		}
+	}
 	var keys []string
-	for k := range funcs {
+	for k := range run {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+	checkEnabled := func(pos token.Pos, check string) bool {
+		pkg := prog.packageAt(pos)
+		if pkg == nil && syntaxProg != nil {
+			pkg = syntaxProg.packageAt(pos)
+		}
+		if pkg != nil && pkg.checks != nil {
+			return pkg.checks[check]
+		}
+		return enabled[check]
+	}
+
+	var syntactic map[string]bool
+	if sc, ok := l.Checker.(SyntacticChecker); ok && syntaxProg != nil {
+		syntactic = map[string]bool{}
+		for _, check := range sc.SyntacticChecks() {
+			syntactic[check] = true
+		}
+	}
 
 	var jobs []*Job
 	for _, k := range keys {
 		j := &Job{
 			Program: prog,
+			ctx:     ctx,
 			checker: l.Checker.Name(),
 			check:   k,
 		}
 		jobs = append(jobs, j)
+		if syntactic[k] {
+			jobs = append(jobs, &Job{
+				Program: syntaxProg,
+				ctx:     ctx,
+				checker: l.Checker.Name(),
+				check:   k,
+			})
+		}
+	}
+	var (
+		mu       sync.Mutex
+		finished int
+	)
+	if l.Progress != nil {
+		l.Progress(0, len(jobs))
 	}
 	wg := &sync.WaitGroup{}
 	for _, j := range jobs {
 		wg.Add(1)
 		go func(j *Job) {
 			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				j.finished = true
+				finished++
+				if l.Progress != nil {
+					l.Progress(finished, len(jobs))
+				}
+			}()
 			fn := funcs[j.check]
-			if fn == nil {
+			if fn == nil || ctx.Err() != nil {
 				return
 			}
-			fn(j)
+			runCheck(fn, j)
 		}(j)
 	}
-	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	for _, j := range jobs {
-		for _, p := range j.problems {
-			p.Ignored = l.ignore(p)
-			if l.ReturnIgnored || !p.Ignored {
-				out = append(out, p)
+	var docs map[string]*Documentation
+	if dc, ok := l.Checker.(DocumentedChecker); ok && l.DocsURL != "" {
+		docs = dc.Docs()
+	}
+	// panicked are the checks that panicked on some package.
+	panicked := map[string]bool{}
+	collect := func(jobs []*Job) {
+		for _, j := range jobs {
+			for _, p := range j.problems {
+				if j.Program.isCgoGenerated(p.pos) {
+					// Users can't act on problems in code generated by
+					// cgo.
+					continue
+				}
+				if p.Check == InternalErrorCheck {
+					// Internal errors can't be disabled or ignored.
+					panicked[p.Args["check"]] = true
+					out = append(out, p)
+					continue
+				}
+				if !checkEnabled(p.pos, p.Check) {
+					continue
+				}
+				if docs[p.Check] != nil {
+					p.URL = l.DocsURL + "#" + p.Check
+				}
+				p.Ignored = l.ignore(p)
+				if l.ReturnIgnored || !p.Ignored {
+					out = append(out, p)
+				}
+			}
+		}
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Return the problems of the checks that finished, so that
+		// interrupted runs can still report them. Checks that are
+		// still running may continue to append to their own jobs,
+		// which aren't looked at.
+		mu.Lock()
+		var finishedJobs []*Job
+		for _, j := range jobs {
+			if j.finished {
+				finishedJobs = append(finishedJobs, j)
 			}
 		}
+		mu.Unlock()
+		collect(finishedJobs)
+		return out, ctx.Err()
+	}
+	collect(jobs)
+
+	l.Suppressions = nil
+	for _, ig := range l.automaticIgnores {
+		if l.suppressed[ig] == 0 && !l.concerns(ig) {
+			// directive for a different checker
+			continue
+		}
+		l.Suppressions = append(l.Suppressions, Suppression{ig, l.suppressed[ig]})
+	}
+	for _, ig := range l.Ignores {
+		l.Suppressions = append(l.Suppressions, Suppression{ig, l.suppressed[ig]})
 	}
 
 	for _, ig := range l.automaticIgnores {
@@ -445,20 +839,31 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 			continue
 		}
 		for _, c := range ig.Checks {
-			idx := strings.IndexFunc(c, func(r rune) bool {
-				return unicode.IsNumber(r)
-			})
-			if idx == -1 {
+			prefix := checkerPrefix(c)
+			if prefix == "" {
 				// malformed check name, backing out
 				continue
 			}
-			if c[:idx] != l.Checker.Prefix() {
+			if prefix != l.Checker.Prefix() {
 				// not for this checker
 				continue
 			}
+			if _, ok := funcs[c]; ok && !checkEnabled(ig.pos, c) {
+				// the check didn't run
+				continue
+			}
+			if panicked[c] {
+				// the check didn't finish
+				continue
+			}
+			if prog.packageAt(ig.pos) == nil && !syntactic[c] {
+				// the check didn't run on the package, which has
+				// type errors
+				continue
+			}
 			p := Problem{
 				pos:      ig.pos,
-				Position: prog.DisplayPosition(ig.pos),
+				Position: displayPosition(ig.pos),
 				Text:     "this linter directive didn't match anything; should it be removed?",
 				Check:    "",
 				Checker:  l.Checker.Name(),
@@ -468,15 +873,281 @@ func (l *Linter) Lint(lprog *loader.Program, conf *loader.Config) []Problem {
 		}
 	}
 
+	out = dedupVariants(prog, append(pkgs, illTyped...), out, l.AnnotateVariants)
 	sort.Sort(byPosition{lprog.Fset, out})
+	return out, nil
+}
+
+// variantName returns the name of the build variant of pkg: "test"
+// if it includes test files, "base" otherwise.
+func variantName(pkg *Pkg) string {
+	for _, f := range pkg.Info.Files {
+		if strings.HasSuffix(pkg.Prog.Fset.File(f.Pos()).Name(), "_test.go") {
+			return "test"
+		}
+	}
+	return "base"
+}
+
+type variantKey struct {
+	file  string
+	line  int
+	check string
+	text  string
+}
+
+// dedupVariants removes duplicate problems that stem from linting
+// several build variants of a package, such as the package on its own
+// and the package augmented with its tests. The variants parse their
+// shared files separately, so duplicates are identified by file, line,
+// check and message instead of by exact position.
+//
+// If annotate is true, problems in files shared by several variants,
+// but reported by only some of them, are annotated with the names of
+// those variants.
+func dedupVariants(prog *Program, pkgs []*Pkg, ps []Problem, annotate bool) []Problem {
+	variants := map[*types.Package]string{}
+	fileVariants := map[string]map[string]bool{}
+	paths := map[string]int{}
+	for _, pkg := range pkgs {
+		paths[pkg.Pkg.Path()]++
+	}
+	for _, pkg := range pkgs {
+		if paths[pkg.Pkg.Path()] < 2 {
+			continue
+		}
+		name := variantName(pkg)
+		variants[pkg.Pkg] = name
+		for _, f := range pkg.Info.Files {
+			file := prog.DisplayPosition(f.Pos()).Filename
+			if fileVariants[file] == nil {
+				fileVariants[file] = map[string]bool{}
+			}
+			fileVariants[file][name] = true
+		}
+	}
+	if len(variants) == 0 {
+		return ps
+	}
+
+	seen := map[variantKey]int{}
+	reporters := map[variantKey]map[string]bool{}
+	out := ps[:0]
+	for _, p := range ps {
+		k := variantKey{p.Position.Filename, p.Position.Line, p.Check, p.Text}
+		if reporters[k] == nil {
+			reporters[k] = map[string]bool{}
+		}
+		if name, ok := variants[p.Package]; ok {
+			reporters[k][name] = true
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = len(out)
+		out = append(out, p)
+	}
+	if !annotate {
+		return out
+	}
+	for k, i := range seen {
+		all := fileVariants[k.file]
+		names := reporters[k]
+		if len(all) < 2 || len(names) == 0 || len(names) == len(all) {
+			continue
+		}
+		var list []string
+		for name := range names {
+			list = append(list, name)
+		}
+		sort.Strings(list)
+		out[i].Variant = strings.Join(list, ",")
+	}
 	return out
 }
 
+// A checkPanic is a recovered panic of a check.
+type checkPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// tryCheck runs the check fn on the job's program, recovering from
+// panics.
+func tryCheck(fn Func, j *Job) (p *checkPanic) {
+	defer func() {
+		if r := recover(); r != nil {
+			p = &checkPanic{r, debug.Stack()}
+		}
+	}()
+	fn(j)
+	return nil
+}
+
+// runCheck runs the check fn on the job's program. If the check
+// panics, it is run on each package of the program on its own, so
+// that the panic can be attributed to packages and that the other
+// packages still get checked. Panics are reported as problems with
+// the check InternalErrorCheck.
+func runCheck(fn Func, j *Job) {
+	p := tryCheck(fn, j)
+	if p == nil {
+		return
+	}
+	j.problems = nil
+	if len(j.Program.Packages) < 2 {
+		var pkg *Pkg
+		if len(j.Program.Packages) == 1 {
+			pkg = j.Program.Packages[0]
+		}
+		j.problems = append(j.problems, internalError(j, pkg, p))
+		return
+	}
+	for _, pkg := range j.Program.Packages {
+		if j.ctx.Err() != nil {
+			return
+		}
+		sub := &Job{
+			Program: j.Program.subProgram(pkg),
+			ctx:     j.ctx,
+			checker: j.checker,
+			check:   j.check,
+		}
+		if p := tryCheck(fn, sub); p != nil {
+			j.problems = append(j.problems, internalError(j, pkg, p))
+			continue
+		}
+		j.problems = append(j.problems, sub.problems...)
+	}
+}
+
+// internalError returns the problem that reports the panic p of the
+// job's check on the package pkg, which may be nil if the panic can't
+// be attributed to a package.
+func internalError(j *Job, pkg *Pkg, p *checkPanic) Problem {
+	args := map[string]string{
+		"check": j.check,
+		"panic": fmt.Sprint(p.value),
+	}
+	problem := Problem{
+		Text:      fmt.Sprintf("internal error: check %s panicked: %v", j.check, p.value),
+		Check:     InternalErrorCheck,
+		Checker:   j.checker,
+		MessageID: "panic",
+		Args:      args,
+		Stack:     string(p.stack),
+	}
+	if pkg != nil {
+		args["package"] = pkg.Info.Pkg.Path()
+		problem.Text = fmt.Sprintf("internal error: check %s panicked while checking package %s: %v", j.check, pkg.Info.Pkg.Path(), p.value)
+		problem.Package = pkg.Info.Pkg
+		if len(pkg.Info.Files) > 0 {
+			f := pkg.Info.Files[0]
+			problem.pos = f.Package
+			problem.Position = j.Program.DisplayPosition(f.Package)
+		}
+	}
+	return problem
+}
+
+// subProgram returns the program that consists of only the package
+// pkg of prog.
+func (prog *Program) subProgram(pkg *Pkg) *Program {
+	sub := newProgram(prog.SSA, prog.Prog, []*Pkg{pkg}, prog.GoVersion, prog.Build)
+	sub.Config = prog.Config
+	sub.AllFunctions = prog.AllFunctions
+	for _, fn := range prog.InitialFunctions {
+		if fn.Pkg == pkg.Package {
+			sub.InitialFunctions = append(sub.InitialFunctions, fn)
+		}
+	}
+	return sub
+}
+
+func newProgram(ssaprog *ssa.Program, lprog *loader.Program, pkgs []*Pkg, goVersion int, ctx *build.Context) *Program {
+	prog := &Program{
+		SSA:          ssaprog,
+		Prog:         lprog,
+		Packages:     pkgs,
+		Info:         &types.Info{},
+		GoVersion:    goVersion,
+		Build:        ctx,
+		tokenFileMap: map[*token.File]*ast.File{},
+		astFileMap:   map[*ast.File]*Pkg{},
+	}
+
+	for _, pkg := range pkgs {
+		prog.Files = append(prog.Files, pkg.Info.Files...)
+		for _, f := range pkg.Info.Files {
+			prog.astFileMap[f] = pkg
+		}
+	}
+
+	for _, pkginfo := range lprog.AllPackages {
+		for _, f := range pkginfo.Files {
+			tf := lprog.Fset.File(f.Pos())
+			prog.tokenFileMap[tf] = f
+		}
+	}
+
+	sizes := struct {
+		types      int
+		defs       int
+		uses       int
+		implicits  int
+		selections int
+		scopes     int
+	}{}
+	for _, pkg := range pkgs {
+		sizes.types += len(pkg.Info.Info.Types)
+		sizes.defs += len(pkg.Info.Info.Defs)
+		sizes.uses += len(pkg.Info.Info.Uses)
+		sizes.implicits += len(pkg.Info.Info.Implicits)
+		sizes.selections += len(pkg.Info.Info.Selections)
+		sizes.scopes += len(pkg.Info.Info.Scopes)
+	}
+	prog.Info.Types = make(map[ast.Expr]types.TypeAndValue, sizes.types)
+	prog.Info.Defs = make(map[*ast.Ident]types.Object, sizes.defs)
+	prog.Info.Uses = make(map[*ast.Ident]types.Object, sizes.uses)
+	prog.Info.Implicits = make(map[ast.Node]types.Object, sizes.implicits)
+	prog.Info.Selections = make(map[*ast.SelectorExpr]*types.Selection, sizes.selections)
+	prog.Info.Scopes = make(map[ast.Node]*types.Scope, sizes.scopes)
+	for _, pkg := range pkgs {
+		for k, v := range pkg.Info.Info.Types {
+			prog.Info.Types[k] = v
+		}
+		for k, v := range pkg.Info.Info.Defs {
+			prog.Info.Defs[k] = v
+		}
+		for k, v := range pkg.Info.Info.Uses {
+			prog.Info.Uses[k] = v
+		}
+		for k, v := range pkg.Info.Info.Implicits {
+			prog.Info.Implicits[k] = v
+		}
+		for k, v := range pkg.Info.Info.Selections {
+			prog.Info.Selections[k] = v
+		}
+		for k, v := range pkg.Info.Info.Scopes {
+			prog.Info.Scopes[k] = v
+		}
+	}
+	return prog
+}
+
 // Pkg represents a package being linted.
 type Pkg struct {
 	*ssa.Package
 	Info     *loader.PackageInfo
 	BuildPkg *build.Package
+	// Config is the configuration that applies to the package,
+	// which may differ from Program.Config if the package is in a
+	// different scope.
+	Config config.Config
+
+	// checks are the checks enabled for the package, if it is in a
+	// scope.
+	checks map[string]bool
 }
 
 type Positioner interface {
@@ -500,14 +1171,15 @@ func (prog *Program) DisplayPosition(p token.Pos) token.Position {
 	// information stored within problems. With this implementation, a
 	// user will ignore foo.go, not foo.y
 
-	pkg := prog.astFileMap[prog.tokenFileMap[prog.Prog.Fset.File(p)]]
-	bp := pkg.BuildPkg
+	pkg := prog.packageAt(p)
 	adjPos := prog.Prog.Fset.Position(p)
-	if bp == nil {
-		// couldn't find the package for some reason (deleted? faulty
-		// file system?)
+	if pkg == nil || pkg.BuildPkg == nil {
+		// the position is in a package that isn't part of the
+		// program, such as one with type errors, or we couldn't find
+		// the package for some reason (deleted? faulty file system?)
 		return adjPos
 	}
+	bp := pkg.BuildPkg
 	base := filepath.Base(adjPos.Filename)
 	for _, f := range bp.CgoFiles {
 		if f == base {
@@ -519,6 +1191,71 @@ func (prog *Program) DisplayPosition(p token.Pos) token.Position {
 	return prog.Prog.Fset.PositionFor(p, false)
 }
 
+// packageAt returns the package that contains pos, if it is one of
+// the program's packages.
+func (prog *Program) packageAt(pos token.Pos) *Pkg {
+	return prog.astFileMap[prog.tokenFileMap[prog.Prog.Fset.File(pos)]]
+}
+
+// isCgoGenerated reports whether p is in code that was generated by
+// cgo, such as the declarations of C functions and variables, as
+// opposed to code from one of the package's cgo files.
+func (prog *Program) isCgoGenerated(p token.Pos) bool {
+	pkg := prog.packageAt(p)
+	if pkg == nil || pkg.BuildPkg == nil || len(pkg.BuildPkg.CgoFiles) == 0 {
+		return false
+	}
+	base := filepath.Base(prog.DisplayPosition(p).Filename)
+	for _, files := range [][]string{pkg.BuildPkg.GoFiles, pkg.BuildPkg.CgoFiles, pkg.BuildPkg.TestGoFiles, pkg.BuildPkg.XTestGoFiles} {
+		for _, f := range files {
+			if f == base {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AddRelated adds a related position to a problem previously returned
+// by Errorf.
+func (j *Job) AddRelated(p *Problem, node Positioner, format string, args ...interface{}) {
+	p.Related = append(p.Related, Related{
+		Position: j.Program.DisplayPosition(node.Pos()),
+		Text:     fmt.Sprintf(format, args...),
+	})
+}
+
+// Replace returns an edit that replaces node with text.
+func (j *Job) Replace(node ast.Node, text string) Edit {
+	return j.ReplaceRange(node.Pos(), node.End(), text)
+}
+
+// ReplaceRange returns an edit that replaces the source between pos
+// and end with text.
+func (j *Job) ReplaceRange(pos, end token.Pos, text string) Edit {
+	fset := j.Program.SSA.Fset
+	return Edit{
+		Position: fset.PositionFor(pos, false),
+		End:      fset.PositionFor(end, false),
+		NewText:  text,
+	}
+}
+
+// AddFix adds a suggested fix to a problem previously returned by
+// Errorf.
+func (j *Job) AddFix(p *Problem, message string, edits ...Edit) {
+	p.Fixes = append(p.Fixes, Fix{Message: message, Edits: edits})
+}
+
+// Context returns the context of the current run. Long-running checks
+// should stop early once it has been canceled.
+func (j *Job) Context() context.Context {
+	if j.ctx == nil {
+		return context.Background()
+	}
+	return j.ctx
+}
+
 func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem {
 	tf := j.Program.SSA.Fset.File(n.Pos())
 	f := j.Program.tokenFileMap[tf]
@@ -533,6 +1270,9 @@ func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem
 		Checker:  j.checker,
 		Package:  pkg,
 	}
+	if n, ok := n.(interface{ End() token.Pos }); ok && n.End().IsValid() {
+		problem.End = j.Program.DisplayPosition(n.End())
+	}
 	j.problems = append(j.problems, problem)
 	return &j.problems[len(j.problems)-1]
 }
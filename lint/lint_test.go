@@ -1,9 +1,12 @@
 package lint_test
 
 import (
+	"context"
 	"testing"
 
+	"honnef.co/go/tools/config"
 	. "honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
 	"honnef.co/go/tools/lint/testutil"
 )
 
@@ -32,3 +35,185 @@ func TestAll(t *testing.T) {
 	c := testChecker{}
 	testutil.TestAll(t, c, "")
 }
+
+func TestIgnoreReasons(t *testing.T) {
+	c := testChecker{}
+	cfg := config.Config{
+		IgnoreReasonMinLength: 10,
+		IgnoreReasonPattern:   `[A-Z]+-[0-9]+`,
+	}
+	testutil.TestAllConfig(t, c, "ignore-reasons", cfg)
+}
+
+func TestPartial(t *testing.T) {
+	testutil.TestAllPartial(t, testChecker{}, "partial")
+}
+
+func TestMemoryLoader(t *testing.T) {
+	opt := &lintutil.Options{
+		Loader: lintutil.MemoryLoader{Files: map[string]string{
+			"example.com/a/a.go": "package a\n\nimport \"example.com/b\"\n\nfunc A() string { return b.B() }\n",
+			"example.com/b/b.go": "package b\n\nimport \"strings\"\n\nfunc B() string { return strings.ToUpper(\"b\") }\n",
+		}},
+	}
+	res, err := lintutil.LintContext(context.Background(), []Checker{testChecker{}}, []string{"example.com/a"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range res[0] {
+		got = append(got, p.Position.String())
+	}
+	want := "/lintutil-memory/src/example.com/a/a.go:5:6"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got problems at %q, want one at %q", got, want)
+	}
+}
+
+type panickingChecker struct{}
+
+func (panickingChecker) Name() string       { return "panicking" }
+func (panickingChecker) Prefix() string     { return "PANIC" }
+func (panickingChecker) Init(prog *Program) {}
+
+func (panickingChecker) Funcs() map[string]Func {
+	return map[string]Func{
+		"PANIC1000": func(j *Job) {
+			for _, pkg := range j.Program.Packages {
+				if pkg.Pkg.Name() == "b" {
+					panic("unexpected package")
+				}
+			}
+			testLint(j)
+		},
+	}
+}
+
+func TestPanickingCheck(t *testing.T) {
+	opt := &lintutil.Options{
+		Loader: lintutil.MemoryLoader{Files: map[string]string{
+			"example.com/a/a.go": "package a\n\nfunc A() {}\n",
+			"example.com/b/b.go": "package b\n\nfunc B() {}\n",
+		}},
+	}
+	res, err := lintutil.LintContext(context.Background(), []Checker{panickingChecker{}}, []string{"example.com/a", "example.com/b"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range res[0] {
+		got = append(got, p.Position.String()+": "+p.Check)
+		if p.Check == InternalErrorCheck {
+			if p.Args["check"] != "PANIC1000" || p.Args["package"] != "example.com/b" || p.Stack == "" {
+				t.Errorf("internal error %q has args %v and stack %q", p.Text, p.Args, p.Stack)
+			}
+		}
+	}
+	want := []string{
+		"/lintutil-memory/src/example.com/a/a.go:3:6: PANIC1000",
+		"/lintutil-memory/src/example.com/b/b.go:1:1: " + InternalErrorCheck,
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got problems %q, want %q", got, want)
+	}
+}
+
+type namespacedChecker struct {
+	prefix string
+	ids    []string
+}
+
+func (namespacedChecker) Name() string       { return "acme" }
+func (c namespacedChecker) Prefix() string   { return c.prefix }
+func (namespacedChecker) Init(prog *Program) {}
+
+func (c namespacedChecker) Funcs() map[string]Func {
+	funcs := map[string]Func{}
+	for _, id := range c.ids {
+		funcs[id] = testLint
+	}
+	return funcs
+}
+
+func TestSplitCheckID(t *testing.T) {
+	tests := []struct {
+		id                        string
+		namespace, prefix, number string
+		ok                        bool
+	}{
+		{"SA1000", "", "SA", "1000", true},
+		{"ACME1001", "", "ACME", "1001", true},
+		{"acme/SA001", "acme", "SA", "001", true},
+		{"example.com/acme/ST1", "example.com/acme", "ST", "1", true},
+		{"SA", "", "", "", false},
+		{"1000", "", "", "", false},
+		{"SA1*", "", "", "", false},
+		{"/SA1000", "", "", "", false},
+	}
+	for _, tt := range tests {
+		namespace, prefix, number, ok := SplitCheckID(tt.id)
+		if namespace != tt.namespace || prefix != tt.prefix || number != tt.number || ok != tt.ok {
+			t.Errorf("SplitCheckID(%q) = %q, %q, %q, %t, want %q, %q, %q, %t",
+				tt.id, namespace, prefix, number, ok, tt.namespace, tt.prefix, tt.number, tt.ok)
+		}
+	}
+}
+
+func TestMatchCheck(t *testing.T) {
+	tests := []struct {
+		pattern, id string
+		want        bool
+	}{
+		{"*", "acme/SA001", true},
+		{"SA*", "SA1000", true},
+		{"SA*", "acme/SA001", false},
+		{"acme/*", "acme/SA001", true},
+		{"acme/SA001", "acme/SA001", true},
+		{"*/SA001", "acme/SA001", true},
+		{"SA001", "acme/SA001", false},
+	}
+	for _, tt := range tests {
+		if got := MatchCheck(tt.pattern, tt.id); got != tt.want {
+			t.Errorf("MatchCheck(%q, %q) = %t, want %t", tt.pattern, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCheckers(t *testing.T) {
+	ok := []Checker{
+		testChecker{},
+		namespacedChecker{"acme/TEST", []string{"acme/TEST1000"}},
+		namespacedChecker{"ACME", []string{"ACME1000"}},
+	}
+	if err := ValidateCheckers(ok); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	bad := [][]Checker{
+		{testChecker{}, namespacedChecker{"TEST", []string{"TEST1001"}}},
+		{namespacedChecker{"acme/SA", []string{"SA1000"}}},
+		{namespacedChecker{"ACME", []string{"ACME"}}},
+	}
+	for _, cs := range bad {
+		if err := ValidateCheckers(cs); err == nil {
+			t.Errorf("expected an error for %v", cs)
+		}
+	}
+}
+
+func TestRenderMessage(t *testing.T) {
+	tmpl, err := ParseMessage(`{{code .name}} is unused, see {{quote .url}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := RenderMessage(tmpl, map[string]string{"name": "fn", "url": "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`fn` is unused, see \"https://example.com\""; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := RenderMessage(tmpl, map[string]string{"name": "fn"}); err == nil {
+		t.Error("expected an error for a missing argument")
+	}
+}
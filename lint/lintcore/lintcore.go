@@ -0,0 +1,93 @@
+// Package lintcore runs linters from within other programs, such as
+// bots and servers. Unlike the command line helpers in lintutil, it
+// doesn't parse flags, write to standard output or exit the process;
+// all results and errors are returned to the caller.
+package lintcore // import "honnef.co/go/tools/lint/lintcore"
+
+import (
+	"context"
+	"errors"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+)
+
+// A Runner lints packages with a fixed set of checkers and options.
+// A Runner may be used for multiple runs, but not concurrently.
+type Runner struct {
+	checkers   []lint.Checker
+	opt        lintutil.Options
+	formatters []lintutil.OutputFormatter
+}
+
+// New returns a Runner that lints with the checkers cs, configured by
+// opt. Options that only make sense on the command line, such as the
+// -f flag, have no counterpart; use AddFormatter instead. To honour
+// configuration files, call opt.DiscoverProjectFiles first.
+func New(cs []lint.Checker, opt lintutil.Options) *Runner {
+	return &Runner{checkers: cs, opt: opt}
+}
+
+// SetLoader replaces the loader of packages, which by default loads
+// packages from GOPATH, or the packages of Options.PackageSpec. Runs
+// with a custom loader don't use Options.Cache.
+func (r *Runner) SetLoader(l lintutil.Loader) {
+	r.opt.Loader = l
+}
+
+// AddFormatter adds a formatter that is given all problems of each
+// run. Formatters that have a Flush method, such as summaries, are
+// flushed at the end of each run.
+func (r *Runner) AddFormatter(f lintutil.OutputFormatter) {
+	r.formatters = append(r.formatters, f)
+}
+
+// A Result is the result of a run.
+type Result struct {
+	// Problems contains the problems of all checkers, in the order
+	// of the checkers.
+	Problems []lint.Problem
+	// Partial lists the packages that failed to type-check, with
+	// Options.Partial, and were only checked syntactically.
+	Partial []lintutil.PartialPackage
+}
+
+// Run lints the packages named by patterns and returns the problems of
+// all checkers. Checkers whose check IDs collide are rejected; see
+// lint.ValidateCheckers. Errors in the code, such as type errors, are
+// returned as a lintutil.ErrorList. If ctx gets canceled after linting
+// started, Run returns the problems found until then, together with
+// the context's error; formatters are only given the problems of
+// complete runs.
+func (r *Runner) Run(ctx context.Context, patterns []string) (*Result, error) {
+	if len(r.checkers) == 0 {
+		return nil, errors.New("no checkers to run")
+	}
+	if err := lint.ValidateCheckers(r.checkers); err != nil {
+		return nil, err
+	}
+	opt := r.opt
+	lres, err := lintutil.LintResult(ctx, r.checkers, patterns, &opt)
+	if lres == nil {
+		return nil, err
+	}
+
+	res := &Result{Partial: lres.Partial}
+	for _, cps := range lres.Problems {
+		res.Problems = append(res.Problems, cps...)
+	}
+	if err != nil {
+		return res, err
+	}
+	for _, f := range r.formatters {
+		for _, p := range res.Problems {
+			f.Format(p)
+		}
+		if f, ok := f.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
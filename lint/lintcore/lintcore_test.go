@@ -0,0 +1,43 @@
+package lintcore
+
+import (
+	"context"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+)
+
+// fileChecker reports a problem in every file it checks, including
+// those of packages that failed to type-check.
+type fileChecker struct{}
+
+func (fileChecker) Name() string              { return "files" }
+func (fileChecker) Prefix() string            { return "F" }
+func (fileChecker) Init(*lint.Program)        {}
+func (fileChecker) SyntacticChecks() []string { return []string{"F1000"} }
+func (fileChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{"F1000": func(j *lint.Job) {
+		for _, f := range j.Program.Files {
+			j.Errorf(f, "checked")
+		}
+	}}
+}
+
+func TestRunPartial(t *testing.T) {
+	r := New([]lint.Checker{fileChecker{}}, lintutil.Options{Partial: true})
+	r.SetLoader(lintutil.MemoryLoader{Files: map[string]string{
+		"example.com/a/a.go": "package a\n",
+		"example.com/b/b.go": "package b\n\nvar x int = \"s\"\n",
+	}})
+	res, err := r.Run(context.Background(), []string{"example.com/a", "example.com/b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) != 2 {
+		t.Errorf("got problems %v, want one in each package", res.Problems)
+	}
+	if len(res.Partial) != 1 || res.Partial[0].Path != "example.com/b" || res.Partial[0].Err == nil {
+		t.Errorf("got partial packages %+v, want example.com/b", res.Partial)
+	}
+}
@@ -73,6 +73,11 @@ func IsPointerLike(T types.Type) bool {
 
 func IsGenerated(f *ast.File) bool {
 	comments := f.Comments
+	if len(comments) > 0 && strings.HasPrefix(comments[0].Text(), "Code generated by cmd/cgo") {
+		// Files processed by cgo are the user's code. Problems in
+		// the parts that cgo generated get filtered by the linter.
+		comments = comments[1:]
+	}
 	if len(comments) > 0 {
 		comment := comments[0].Text()
 		return strings.Contains(comment, "Code generated by") ||
@@ -0,0 +1,337 @@
+package lintutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// apiManifestVersion is the version of the format of API manifests.
+const apiManifestVersion = 1
+
+// An apiManifest records the exported API of a set of packages, as
+// written by -api-snapshot and compared against by -api-check.
+type apiManifest struct {
+	Version int `json:"version"`
+	// Packages maps import paths to the exported objects of the
+	// packages, keyed by names such as "Fn", "T", "T.Field" and
+	// "T.Method".
+	Packages map[string]map[string]apiObject `json:"packages"`
+}
+
+// An apiObject describes an exported object. Types are printed
+// relative to the object's package, without parameter names, so that
+// only changes that affect users of the API change them.
+type apiObject struct {
+	// Kind is one of "const", "var", "func", "type", "field",
+	// "method" and "interface method".
+	Kind string `json:"kind"`
+	// Type is the type of the object. For named types, it is the
+	// underlying type, except that structs are described as
+	// "struct", since their fields are recorded separately, and
+	// interfaces as "interface", or "sealed interface" if they have
+	// unexported methods and thus can't be implemented by other
+	// packages.
+	Type string `json:"type"`
+	// Pointer is set for methods that are only in the method set of
+	// pointers to their type.
+	Pointer bool `json:"pointer,omitempty"`
+
+	// pos is the position of the object in the current program. It
+	// is not part of manifests.
+	pos token.Pos
+}
+
+// snapshotAPI returns the exported API of the initial packages of
+// lprog. Test files and external test packages are not part of the
+// API.
+func snapshotAPI(lprog *loader.Program) apiManifest {
+	m := apiManifest{
+		Version:  apiManifestVersion,
+		Packages: map[string]map[string]apiObject{},
+	}
+	for _, pkg := range lprog.InitialPackages() {
+		if strings.HasSuffix(pkg.Pkg.Path(), "_test") {
+			continue
+		}
+		objs := map[string]apiObject{}
+		api := apiWriter{pkg: pkg.Pkg, objs: objs}
+		scope := pkg.Pkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !obj.Exported() || isTestFile(lprog.Fset, obj.Pos()) {
+				continue
+			}
+			api.object(obj)
+		}
+		m.Packages[pkg.Pkg.Path()] = objs
+	}
+	return m
+}
+
+// isTestFile reports whether pos is in a _test.go file.
+func isTestFile(fset *token.FileSet, pos token.Pos) bool {
+	return strings.HasSuffix(fset.Position(pos).Filename, "_test.go")
+}
+
+// apiWriter records the exported objects of a package.
+type apiWriter struct {
+	pkg  *types.Package
+	objs map[string]apiObject
+}
+
+func (api apiWriter) typeString(T types.Type) string {
+	return types.TypeString(unnamedParams(T), types.RelativeTo(api.pkg))
+}
+
+func (api apiWriter) object(obj types.Object) {
+	switch obj := obj.(type) {
+	case *types.Const:
+		api.objs[obj.Name()] = apiObject{Kind: "const", Type: api.typeString(obj.Type()), pos: obj.Pos()}
+	case *types.Var:
+		api.objs[obj.Name()] = apiObject{Kind: "var", Type: api.typeString(obj.Type()), pos: obj.Pos()}
+	case *types.Func:
+		api.objs[obj.Name()] = apiObject{Kind: "func", Type: api.typeString(obj.Type()), pos: obj.Pos()}
+	case *types.TypeName:
+		api.typeName(obj)
+	}
+}
+
+func (api apiWriter) typeName(obj *types.TypeName) {
+	name := obj.Name()
+	if obj.IsAlias() {
+		api.objs[name] = apiObject{Kind: "type", Type: "= " + api.typeString(aliased(obj.Type())), pos: obj.Pos()}
+		return
+	}
+	T := obj.Type()
+	switch u := T.Underlying().(type) {
+	case *types.Struct:
+		api.objs[name] = apiObject{Kind: "type", Type: "struct", pos: obj.Pos()}
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			api.objs[name+"."+f.Name()] = apiObject{Kind: "field", Type: api.typeString(f.Type()), pos: f.Pos()}
+		}
+	case *types.Interface:
+		kind := "interface"
+		for i := 0; i < u.NumMethods(); i++ {
+			m := u.Method(i)
+			if !m.Exported() {
+				kind = "sealed interface"
+				continue
+			}
+			api.objs[name+"."+m.Name()] = apiObject{Kind: "interface method", Type: api.typeString(m.Type()), pos: m.Pos()}
+		}
+		api.objs[name] = apiObject{Kind: "type", Type: kind, pos: obj.Pos()}
+		return
+	default:
+		api.objs[name] = apiObject{Kind: "type", Type: api.typeString(u), pos: obj.Pos()}
+	}
+
+	// Promoted methods are part of the API, too.
+	value := types.NewMethodSet(T)
+	ptr := types.NewMethodSet(types.NewPointer(T))
+	for i := 0; i < ptr.Len(); i++ {
+		m := ptr.At(i).Obj()
+		if !m.Exported() {
+			continue
+		}
+		api.objs[name+"."+m.Name()] = apiObject{
+			Kind:    "method",
+			Type:    api.typeString(withoutRecv(m.Type().(*types.Signature))),
+			Pointer: value.Lookup(m.Pkg(), m.Name()) == nil,
+			pos:     m.Pos(),
+		}
+	}
+}
+
+// aliased returns the type that the alias type T denotes. Versions of
+// go/types that represent aliases as types of their own print them by
+// their names.
+func aliased(T types.Type) types.Type {
+	for {
+		alias, ok := T.(interface{ Rhs() types.Type })
+		if !ok {
+			return T
+		}
+		T = alias.Rhs()
+	}
+}
+
+// withoutRecv returns sig without its receiver.
+func withoutRecv(sig *types.Signature) *types.Signature {
+	return types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())
+}
+
+// unnamedParams returns T with the names of parameters and results
+// of function types removed, as renaming them doesn't affect users.
+func unnamedParams(T types.Type) types.Type {
+	sig, ok := T.(*types.Signature)
+	if !ok {
+		return T
+	}
+	unnamed := func(tup *types.Tuple) *types.Tuple {
+		vars := make([]*types.Var, tup.Len())
+		for i := range vars {
+			v := tup.At(i)
+			vars[i] = types.NewParam(v.Pos(), v.Pkg(), "", unnamedParams(v.Type()))
+		}
+		return types.NewTuple(vars...)
+	}
+	return types.NewSignature(sig.Recv(), unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+}
+
+// writeAPISnapshot loads pkgs and writes a manifest of their exported
+// API to path.
+func writeAPISnapshot(ctx context.Context, path string, pkgs []string, opt *Options) error {
+	lprog, _, err := opt.loader().Load(ctx, pkgs, opt)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(snapshotAPI(lprog), "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0666)
+}
+
+// readAPIManifest reads a manifest written by writeAPISnapshot.
+func readAPIManifest(path string) (apiManifest, error) {
+	var m apiManifest
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("can't parse API manifest %s: %v", path, err)
+	}
+	if m.Version != apiManifestVersion {
+		return m, fmt.Errorf("API manifest %s has version %d, expected %d; regenerate it with -api-snapshot", path, m.Version, apiManifestVersion)
+	}
+	return m, nil
+}
+
+// checkAPI loads pkgs and compares their exported API with the
+// manifest at path, reporting removed objects and incompatible
+// changes as problems of the check "api". Additions are compatible,
+// except for methods added to interfaces that other packages may
+// implement.
+func checkAPI(ctx context.Context, path string, pkgs []string, opt *Options) (*lintResult, error) {
+	old, err := readAPIManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	lprog, _, err := opt.loader().Load(ctx, pkgs, opt)
+	if err != nil {
+		return nil, err
+	}
+	cur := snapshotAPI(lprog)
+
+	res := &lintResult{}
+	packages := map[string]*loader.PackageInfo{}
+	for _, pkg := range lprog.InitialPackages() {
+		packages[pkg.Pkg.Path()] = pkg
+		res.packages = append(res.packages, pkg.Pkg.Path())
+	}
+	sort.Strings(res.packages)
+
+	var ps []lint.Problem
+	problem := func(pkg *types.Package, pos token.Pos, format string, args ...interface{}) {
+		ps = append(ps, lint.Problem{
+			Position: lprog.Fset.Position(pos),
+			Text:     fmt.Sprintf(format, args...),
+			Checker:  "api",
+			Check:    "api",
+			Package:  pkg,
+		})
+	}
+
+	var paths []string
+	for path := range old.Packages {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		oldObjs := old.Packages[path]
+		curObjs, ok := cur.Packages[path]
+		if !ok {
+			problem(nil, token.NoPos, "package %s was removed", path)
+			continue
+		}
+		pkg := packages[path]
+		// Removed objects are reported at the package clause, or
+		// at their type, if it still exists.
+		pkgPos := token.NoPos
+		if len(pkg.Files) > 0 {
+			pkgPos = pkg.Files[0].Name.Pos()
+		}
+		parentPos := func(name string) token.Pos {
+			if i := strings.IndexByte(name, '.'); i >= 0 {
+				if parent, ok := curObjs[name[:i]]; ok {
+					return parent.pos
+				}
+			}
+			return pkgPos
+		}
+
+		var names []string
+		for name := range oldObjs {
+			names = append(names, name)
+		}
+		for name := range curObjs {
+			if _, ok := oldObjs[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			o, inOld := oldObjs[name]
+			c, inCur := curObjs[name]
+			switch {
+			case !inCur:
+				if i := strings.IndexByte(name, '.'); i >= 0 {
+					if _, ok := oldObjs[name[:i]]; ok {
+						if _, ok := curObjs[name[:i]]; !ok {
+							// Already reported as part of its type.
+							continue
+						}
+					}
+				}
+				problem(pkg.Pkg, parentPos(name), "%s %s was removed", o.Kind, name)
+			case !inOld:
+				if c.Kind != "interface method" {
+					continue
+				}
+				i := strings.IndexByte(name, '.')
+				parent, ok := oldObjs[name[:i]]
+				if !ok || parent.Type != "interface" {
+					continue
+				}
+				problem(pkg.Pkg, c.pos, "method %s was added to interface %s, which breaks its implementations in other packages", name[i+1:], name[:i])
+			case o.Kind != c.Kind:
+				problem(pkg.Pkg, c.pos, "%s changed from %s to %s", name, o.Kind, c.Kind)
+			case o.Type != c.Type:
+				if o.Type == "sealed interface" && c.Type == "interface" {
+					// Unsealing an interface is compatible.
+					continue
+				}
+				problem(pkg.Pkg, c.pos, "type of %s %s changed from %s to %s", o.Kind, name, o.Type, c.Type)
+			case !o.Pointer && c.Pointer:
+				problem(pkg.Pkg, c.pos, "method %s now has a pointer receiver and is no longer in the method set of %s", name, name[:strings.IndexByte(name, '.')])
+			}
+		}
+	}
+	res.problems = [][]lint.Problem{ps}
+	return res, nil
+}
@@ -0,0 +1,105 @@
+package lintutil
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"go/parser"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// filesLoader loads a single package from a list of files.
+type filesLoader struct {
+	path  string
+	files []string
+}
+
+func (l filesLoader) Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	conf := &loader.Config{ParserMode: parser.ParseComments}
+	conf.CreateFromFilenames(l.path, l.files...)
+	lprog, err := conf.Load()
+	return lprog, conf, err
+}
+
+// checkGolden compares got with the golden file name, or updates the
+// file if the -update flag is set.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := ioutil.WriteFile(name, got, 0666); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs, got:\n%s", name, got)
+	}
+}
+
+func TestAPISnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testdata := filepath.Join("testdata", "api")
+	manifest := filepath.Join(dir, "api.json")
+	opt := func(version string) *Options {
+		files := []string{filepath.Join(testdata, version, "api.go")}
+		return &Options{Loader: filesLoader{"example.com/api", files}}
+	}
+
+	for _, version := range []string{"new", "old"} {
+		if err := writeAPISnapshot(context.Background(), manifest, nil, opt(version)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, filepath.Join(testdata, version+".golden"), got)
+	}
+
+	// Additions are compatible, except for methods of interfaces;
+	// removals and changes are reported.
+	res, err := checkAPI(context.Background(), manifest, nil, opt("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, ps := range res.problems {
+		for _, p := range ps {
+			pos := p.Position
+			pos.Filename = relativeTo(pos.Filename, wd)
+			fmt.Fprintf(&buf, "%s: %s\n", pos, p.Text)
+		}
+	}
+	checkGolden(t, filepath.Join(testdata, "check.golden"), buf.Bytes())
+
+	// The API is compatible with itself.
+	res, err = checkAPI(context.Background(), manifest, nil, opt("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ps := range res.problems {
+		for _, p := range ps {
+			t.Errorf("unchanged API: %s: %s", p.Position, p.Text)
+		}
+	}
+}
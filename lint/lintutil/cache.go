@@ -0,0 +1,573 @@
+package lintutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"go/token"
+	"go/types"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/cache"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/version"
+)
+
+// A cachedProblem is the serialized form of a lint.Problem.
+type cachedProblem struct {
+	Position    token.Position
+	End         token.Position
+	Text        string
+	Checker     string
+	Check       string
+	Ignored     bool
+	URL         string
+	Related     []lint.Related
+	Fixes       []lint.Fix
+	Variant     string
+	MessageID   string
+	Args        map[string]string
+	Stack       string
+	PackagePath string
+	PackageName string
+}
+
+// A cacheEntry holds the results of linting a single package, and
+// its tests, with all checkers. File names are stored relative to
+// the package's directory, so that entries can be shared between
+// machines.
+type cacheEntry struct {
+	// Packages are the paths of the analyzed packages, including
+	// test packages.
+	Packages []string
+	// Problems contains the problems of each checker.
+	Problems [][]cachedProblem
+
+	dir string
+}
+
+// cacheFormat is the version of the format of cache entries. It must
+// be incremented whenever the encoding of cacheEntry changes
+// incompatibly.
+const cacheFormat = 4
+
+// A cacheEnvelope wraps every cache entry. Entries are JSON-encoded
+// envelopes, whose Format identifies the encoding of Entry. Entries
+// of other formats, whether older or newer, are treated as missing;
+// the cache key makes such collisions unlikely, but they can happen
+// between development builds.
+type cacheEnvelope struct {
+	Format int
+	// Version is the version of staticcheck that wrote the entry.
+	// It is informational only.
+	Version string
+	Entry   json.RawMessage
+}
+
+var errCacheFormat = errors.New("cache entry has an unsupported format")
+
+func encodeEntry(e *cacheEntry) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cacheEnvelope{
+		Format:  cacheFormat,
+		Version: version.Version,
+		Entry:   data,
+	})
+}
+
+func decodeEnvelope(data []byte) (*cacheEnvelope, error) {
+	var env cacheEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Format != cacheFormat {
+		return &env, errCacheFormat
+	}
+	return &env, nil
+}
+
+func decodeEntry(data []byte) (*cacheEntry, error) {
+	env, err := decodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(env.Entry, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// cacheKeys computes the cache keys of the packages named by paths.
+// A package's key covers the contents of its files and those of its
+// dependencies, as well as everything else that influences the
+// result of linting it.
+type cacheKeys struct {
+	bctx  *build.Context
+	tests bool
+	salt  []byte
+	// scoped is set if packages may have their own configuration
+	// files, which then become part of their keys.
+	scoped bool
+	// program is set if checks that look at all linted packages are
+	// enabled. The set of linted packages then becomes part of every
+	// key.
+	program bool
+	// deps caches the hashes of dependencies, by directory.
+	deps map[string][]byte
+}
+
+// cacheSalt returns the inputs shared by all keys of a run.
+func cacheSalt(cs []lint.Checker, opt *Options, bctx *build.Context) ([]byte, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s %s\n", version.Version, runtime.Version())
+	if version.Version == "devel" {
+		// Without a version, the binary itself identifies the
+		// implementation of the checks.
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		if err := hashFile(h, exe); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range cs {
+		fmt.Fprintf(h, "checker %s\n", c.Name())
+	}
+	fmt.Fprintf(h, "config %s\n", configHash(opt, enabledChecks(cs, opt.Checks)))
+	fmt.Fprintf(h, "docs-url %q\n", opt.DocsURL)
+	fmt.Fprintf(h, "key %q\n", opt.CacheKey)
+	fmt.Fprintf(h, "build %s %s %t\n", bctx.GOOS, bctx.GOARCH, bctx.CgoEnabled)
+	return h.Sum(nil), nil
+}
+
+func newCacheKeys(cs []lint.Checker, opt *Options) (*cacheKeys, error) {
+	bctx := buildContext(opt)
+	salt, err := cacheSalt(cs, opt, &bctx)
+	if err != nil {
+		return nil, err
+	}
+	return &cacheKeys{
+		bctx:    &bctx,
+		tests:   opt.LintTests,
+		salt:    salt,
+		scoped:  opt.scoped,
+		program: programChecks(cs, opt),
+		deps:    map[string][]byte{},
+	}, nil
+}
+
+// programChecks reports whether any of the enabled checks is a
+// program check, as declared by lint.ProgramChecker. Scoped
+// configurations may enable any check.
+func programChecks(cs []lint.Checker, opt *Options) bool {
+	for _, c := range cs {
+		pc, ok := c.(lint.ProgramChecker)
+		if !ok {
+			continue
+		}
+		ids := pc.ProgramChecks()
+		if len(ids) > 0 && opt.scoped {
+			return true
+		}
+		enabled := map[string]bool{}
+		for _, id := range lint.EnabledChecks(c, opt.Checks) {
+			enabled[id] = true
+		}
+		for _, id := range ids {
+			if enabled[id] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withPackages mixes the sorted import paths of the linted packages
+// into the key id, if program checks are enabled.
+func (ck *cacheKeys) withPackages(id cache.ActionID, paths []string) cache.ActionID {
+	if !ck.program {
+		return id
+	}
+	h := sha256.New()
+	h.Write(id[:])
+	for _, path := range paths {
+		fmt.Fprintf(h, "linted %s\n", path)
+	}
+	copy(id[:], h.Sum(nil))
+	return id
+}
+
+func hashFile(h io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(h, f)
+	return err
+}
+
+// key returns the cache key of the package at path, resolved relative
+// to srcDir, and the package's import path.
+func (ck *cacheKeys) key(path, srcDir string) (cache.ActionID, string, error) {
+	bp, err := ck.bctx.Import(path, srcDir, 0)
+	if err != nil {
+		return cache.ActionID{}, "", err
+	}
+	h := sha256.New()
+	h.Write(ck.salt)
+	if ck.scoped {
+		sc, err := config.LoadScoped(bp.Dir)
+		if err != nil {
+			return cache.ActionID{}, "", err
+		}
+		for _, f := range sc.Files {
+			fmt.Fprintf(h, "config %s\n", f)
+			if err := hashFile(h, f); err != nil {
+				return cache.ActionID{}, "", err
+			}
+		}
+	}
+	if err := ck.hashPackage(h, bp, ck.tests); err != nil {
+		return cache.ActionID{}, "", err
+	}
+	var id cache.ActionID
+	copy(id[:], h.Sum(nil))
+	return id, bp.ImportPath, nil
+}
+
+// depHash returns the hash of a dependency, which is cached.
+func (ck *cacheKeys) depHash(path, srcDir string) ([]byte, error) {
+	bp, err := ck.bctx.Import(path, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if sum, ok := ck.deps[bp.Dir]; ok {
+		return sum, nil
+	}
+	h := sha256.New()
+	if bp.Goroot {
+		// The standard library only changes with the Go version.
+		fmt.Fprintf(h, "goroot %s %s %s\n", runtime.Version(), ck.bctx.GOROOT, bp.ImportPath)
+	} else if err := ck.hashPackage(h, bp, false); err != nil {
+		return nil, err
+	}
+	sum := h.Sum(nil)
+	ck.deps[bp.Dir] = sum
+	return sum, nil
+}
+
+func (ck *cacheKeys) hashPackage(h hash.Hash, bp *build.Package, tests bool) error {
+	fmt.Fprintf(h, "package %s\n", bp.ImportPath)
+	files := [][]string{bp.GoFiles, bp.CgoFiles, bp.SFiles, bp.CFiles, bp.HFiles}
+	imports := [][]string{bp.Imports}
+	if tests {
+		files = append(files, bp.TestGoFiles, bp.XTestGoFiles)
+		imports = append(imports, bp.TestImports, bp.XTestImports)
+	}
+	for _, names := range files {
+		for _, name := range names {
+			fmt.Fprintf(h, "file %s\n", name)
+			if err := hashFile(h, filepath.Join(bp.Dir, name)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, paths := range imports {
+		for _, path := range paths {
+			if path == "C" || path == "unsafe" || path == bp.ImportPath {
+				continue
+			}
+			sum, err := ck.depHash(path, bp.Dir)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "import %s %x\n", path, sum)
+		}
+	}
+	return nil
+}
+
+// relPosition makes the file name of pos relative to dir.
+func relPosition(pos token.Position, dir string) token.Position {
+	if filepath.IsAbs(pos.Filename) {
+		if rel, err := filepath.Rel(dir, pos.Filename); err == nil {
+			pos.Filename = filepath.ToSlash(rel)
+		}
+	}
+	return pos
+}
+
+// absPosition reverses relPosition.
+func absPosition(pos token.Position, dir string) token.Position {
+	if pos.Filename != "" && !filepath.IsAbs(pos.Filename) {
+		pos.Filename = filepath.Join(dir, filepath.FromSlash(pos.Filename))
+	}
+	return pos
+}
+
+func encodeProblem(p lint.Problem, dir string) cachedProblem {
+	cp := cachedProblem{
+		Position:  relPosition(p.Position, dir),
+		End:       relPosition(p.End, dir),
+		Text:      p.Text,
+		Checker:   p.Checker,
+		Check:     p.Check,
+		Ignored:   p.Ignored,
+		URL:       p.URL,
+		Variant:   p.Variant,
+		MessageID: p.MessageID,
+		Args:      p.Args,
+		Stack:     p.Stack,
+	}
+	for _, r := range p.Related {
+		cp.Related = append(cp.Related, lint.Related{Position: relPosition(r.Position, dir), Text: r.Text})
+	}
+	for _, fix := range p.Fixes {
+		var edits []lint.Edit
+		for _, e := range fix.Edits {
+			edits = append(edits, lint.Edit{Position: relPosition(e.Position, dir), End: relPosition(e.End, dir), NewText: e.NewText})
+		}
+		cp.Fixes = append(cp.Fixes, lint.Fix{Message: fix.Message, Edits: edits})
+	}
+	if p.Package != nil {
+		cp.PackagePath = p.Package.Path()
+		cp.PackageName = p.Package.Name()
+	}
+	return cp
+}
+
+func decodeProblem(cp cachedProblem, dir string) lint.Problem {
+	p := lint.Problem{
+		Position:  absPosition(cp.Position, dir),
+		End:       absPosition(cp.End, dir),
+		Text:      cp.Text,
+		Checker:   cp.Checker,
+		Check:     cp.Check,
+		Ignored:   cp.Ignored,
+		URL:       cp.URL,
+		Variant:   cp.Variant,
+		MessageID: cp.MessageID,
+		Args:      cp.Args,
+		Stack:     cp.Stack,
+	}
+	for _, r := range cp.Related {
+		p.Related = append(p.Related, lint.Related{Position: absPosition(r.Position, dir), Text: r.Text})
+	}
+	for _, fix := range cp.Fixes {
+		var edits []lint.Edit
+		for _, e := range fix.Edits {
+			edits = append(edits, lint.Edit{Position: absPosition(e.Position, dir), End: absPosition(e.End, dir), NewText: e.NewText})
+		}
+		p.Fixes = append(p.Fixes, lint.Fix{Message: fix.Message, Edits: edits})
+	}
+	if cp.PackagePath != "" {
+		p.Package = types.NewPackage(cp.PackagePath, cp.PackageName)
+	}
+	return p
+}
+
+// cachedLint lints the packages named by paths, reusing cached
+// results if all packages are in the cache. Otherwise, it lints all
+// packages and stores their results in the cache.
+func cachedLint(ctx context.Context, cs []lint.Checker, paths []string, opt *Options, run func() (*lintResult, error)) (*lintResult, error) {
+	ck, err := newCacheKeys(cs, opt)
+	if err != nil {
+		return run()
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return run()
+	}
+	type target struct {
+		id   cache.ActionID
+		path string
+		dir  string
+	}
+	var targets []target
+	for _, path := range paths {
+		id, ipath, err := ck.key(path, cwd)
+		if err != nil {
+			// Let the loader report the error.
+			return run()
+		}
+		bp, err := ck.bctx.Import(ipath, cwd, build.FindOnly)
+		if err != nil {
+			return run()
+		}
+		targets = append(targets, target{id, ipath, bp.Dir})
+	}
+	var ipaths []string
+	for _, t := range targets {
+		ipaths = append(ipaths, t.path)
+	}
+	sort.Strings(ipaths)
+	for i := range targets {
+		targets[i].id = ck.withPackages(targets[i].id, ipaths)
+	}
+
+	// Try to satisfy the entire run from the cache.
+	res := &lintResult{problems: make([][]lint.Problem, len(cs))}
+	hit := true
+	for _, t := range targets {
+		data, err := opt.Cache.Get(t.id)
+		if err != nil {
+			hit = false
+			break
+		}
+		entry, err := decodeEntry(data)
+		if err != nil || len(entry.Problems) != len(cs) {
+			hit = false
+			break
+		}
+		res.packages = append(res.packages, entry.Packages...)
+		for i, cps := range entry.Problems {
+			for _, cp := range cps {
+				res.problems[i] = append(res.problems[i], decodeProblem(cp, t.dir))
+			}
+		}
+	}
+	if hit {
+		sort.Strings(res.packages)
+		for _, ps := range res.problems {
+			sort.Sort(byPosition(ps))
+		}
+		return res, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	res, err = run()
+	if err != nil {
+		// Results of canceled runs are incomplete and mustn't be
+		// stored.
+		return res, err
+	}
+
+	// Split the results by package and store them.
+	entries := map[string]*cacheEntry{}
+	for _, t := range targets {
+		entries[t.path] = &cacheEntry{Problems: make([][]cachedProblem, len(cs)), dir: t.dir}
+	}
+	owner := func(path string) *cacheEntry {
+		if e, ok := entries[path]; ok {
+			return e
+		}
+		return entries[strings.TrimSuffix(path, "_test")]
+	}
+	for _, path := range res.packages {
+		e := owner(path)
+		if e == nil {
+			// The loader found a package we didn't expect. Don't
+			// risk storing incomplete results.
+			return res, nil
+		}
+		e.Packages = append(e.Packages, path)
+	}
+	dirs := map[string]*cacheEntry{}
+	for _, t := range targets {
+		dirs[t.dir] = entries[t.path]
+	}
+	for i, ps := range res.problems {
+		for _, p := range ps {
+			var e *cacheEntry
+			if p.Package != nil {
+				e = owner(p.Package.Path())
+			} else {
+				e = dirs[filepath.Dir(p.Position.Filename)]
+			}
+			if e == nil {
+				return res, nil
+			}
+			e.Problems[i] = append(e.Problems[i], encodeProblem(p, e.dir))
+		}
+	}
+	for _, t := range targets {
+		data, err := encodeEntry(entries[t.path])
+		if err != nil {
+			continue
+		}
+		// Failing to store results only costs performance.
+		_ = opt.Cache.Put(t.id, data)
+	}
+	return res, nil
+}
+
+// byPosition sorts problems like lint.Linter does.
+type byPosition []lint.Problem
+
+func (ps byPosition) Len() int      { return len(ps) }
+func (ps byPosition) Swap(i, j int) { ps[i], ps[j] = ps[j], ps[i] }
+func (ps byPosition) Less(i, j int) bool {
+	pi, pj := ps[i].Position, ps[j].Position
+	if pi.Filename != pj.Filename {
+		return pi.Filename < pj.Filename
+	}
+	if pi.Line != pj.Line {
+		return pi.Line < pj.Line
+	}
+	if pi.Column != pj.Column {
+		return pi.Column < pj.Column
+	}
+	return ps[i].Text < ps[j].Text
+}
+
+// dumpCache prints the cache entry of the package at path, as it
+// would be used when linting it on its own with opt. It is meant for
+// debugging stale or incompatible cache entries.
+func dumpCache(w io.Writer, cs []lint.Checker, path string, opt *Options) error {
+	if opt.Cache == nil {
+		return fmt.Errorf("no cache is configured, set %s or %s", cache.EnvDir, cache.EnvURL)
+	}
+	ck, err := newCacheKeys(cs, opt)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	id, ipath, err := ck.key(path, cwd)
+	if err != nil {
+		return err
+	}
+	id = ck.withPackages(id, []string{ipath})
+	fmt.Fprintf(w, "package: %s\n", ipath)
+	fmt.Fprintf(w, "key: %s\n", id)
+	data, err := opt.Cache.Get(id)
+	if err != nil {
+		return err
+	}
+	env, err := decodeEnvelope(data)
+	if env != nil {
+		fmt.Fprintf(w, "format: %d (supported: %d)\n", env.Format, cacheFormat)
+		fmt.Fprintf(w, "version: %s\n", env.Version)
+	}
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, env.Entry, "", "\t"); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err = buf.WriteTo(w)
+	return err
+}
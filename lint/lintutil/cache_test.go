@@ -0,0 +1,110 @@
+package lintutil
+
+import (
+	"encoding/json"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/cache"
+	"honnef.co/go/tools/lint"
+)
+
+func TestCacheEntry(t *testing.T) {
+	dir := "/src/example.com/a"
+	p := lint.Problem{
+		Position: token.Position{Filename: dir + "/a.go", Line: 3, Column: 2},
+		End:      token.Position{Filename: dir + "/a.go", Line: 3, Column: 10},
+		Text:     "something is wrong",
+		Checker:  "staticcheck",
+		Check:    "SA4006",
+		Related:  []lint.Related{{Position: token.Position{Filename: dir + "/b.go", Line: 1}, Text: "here"}},
+		Args:     map[string]string{"name": "x"},
+	}
+	e := &cacheEntry{
+		Packages: []string{"example.com/a", "example.com/a_test"},
+		Problems: [][]cachedProblem{{encodeProblem(p, dir)}, nil},
+	}
+	if got := e.Problems[0][0].Position.Filename; got != "a.go" {
+		t.Errorf("stored file name is %q, want it relative to the package", got)
+	}
+	data, err := encodeEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeEntry(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Packages, e.Packages) || len(got.Problems) != 2 || len(got.Problems[0]) != 1 {
+		t.Fatalf("got entry %+v, want %+v", got, e)
+	}
+	if dp := decodeProblem(got.Problems[0][0], dir); !reflect.DeepEqual(dp, p) {
+		t.Errorf("got problem %+v, want %+v", dp, p)
+	}
+
+	// Entries of other formats are rejected.
+	var env cacheEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []int{0, cacheFormat - 1, cacheFormat + 1} {
+		env.Format = format
+		data, err := json.Marshal(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := decodeEntry(data); err != errCacheFormat {
+			t.Errorf("format %d: got error %v, want errCacheFormat", format, err)
+		}
+	}
+}
+
+type programChecker struct {
+	checks []string
+}
+
+func (programChecker) Name() string              { return "program" }
+func (programChecker) Prefix() string            { return "P" }
+func (programChecker) Init(*lint.Program)        {}
+func (c programChecker) ProgramChecks() []string { return c.checks }
+func (programChecker) Funcs() map[string]lint.Func {
+	fn := func(*lint.Job) {}
+	return map[string]lint.Func{"P1000": fn, "P1001": fn}
+}
+
+func TestProgramChecksKey(t *testing.T) {
+	tests := []struct {
+		checks  []string
+		program []string
+		scoped  bool
+		want    bool
+	}{
+		{nil, nil, false, false},
+		{nil, []string{"P1001"}, false, true},
+		{[]string{"-P1001"}, []string{"P1001"}, false, false},
+		{[]string{"-P1001"}, []string{"P1001"}, true, true},
+		{[]string{"-P1001"}, nil, true, false},
+	}
+	for _, tt := range tests {
+		opt := &Options{Checks: tt.checks, scoped: tt.scoped}
+		cs := []lint.Checker{programChecker{tt.program}}
+		if got := programChecks(cs, opt); got != tt.want {
+			t.Errorf("checks %q, program checks %q, scoped %t: got %t, want %t", tt.checks, tt.program, tt.scoped, got, tt.want)
+		}
+	}
+
+	id := cache.ActionID{1, 2, 3}
+	ck := &cacheKeys{}
+	if got := ck.withPackages(id, []string{"a", "b"}); got != id {
+		t.Errorf("key changed without program checks")
+	}
+	ck.program = true
+	ab := ck.withPackages(id, []string{"a", "b"})
+	if ab == id || ab != ck.withPackages(id, []string{"a", "b"}) {
+		t.Errorf("key doesn't depend on the linted packages deterministically")
+	}
+	if ab == ck.withPackages(id, []string{"a"}) || ab == ck.withPackages(id, []string{"a", "c"}) {
+		t.Errorf("keys of different sets of packages are equal")
+	}
+}
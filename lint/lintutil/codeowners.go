@@ -0,0 +1,148 @@
+package lintutil
+
+import (
+	"bufio"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// codeOwnersLocations are the places, relative to the root of a
+// repository, where CODEOWNERS files are looked for, in the order
+// that GitHub uses.
+var codeOwnersLocations = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+// findCodeOwners looks for a CODEOWNERS file in dir and all of its
+// parents, returning the path of the first one found.
+func findCodeOwners(dir string) (string, bool) {
+	for {
+		for _, loc := range codeOwnersLocations {
+			path := filepath.Join(dir, loc)
+			if _, err := os.Stat(path); err == nil {
+				return path, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// An ownerRule assigns owners to the files that match a pattern.
+type ownerRule struct {
+	re     *regexp.Regexp
+	owners []string
+}
+
+// codeOwners are the rules of a CODEOWNERS file.
+type codeOwners struct {
+	// root is the root of the repository, which patterns are
+	// relative to.
+	root  string
+	rules []ownerRule
+}
+
+// parseCodeOwners parses the CODEOWNERS file at path. Each line
+// consists of a gitignore-like pattern, followed by the owners of the
+// matching files, such as @org/team or email addresses. Empty lines
+// and lines starting with # are skipped. Files in .github and docs
+// directories apply to the repository containing those directories.
+func parseCodeOwners(path string) (*codeOwners, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	if base := filepath.Base(root); base == ".github" || base == "docs" {
+		root = filepath.Dir(root)
+	}
+
+	co := &codeOwners{root: root}
+	var errs ErrorList
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.Index(text, " #"); i != -1 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		re, err := compileIgnorePattern(fields[0])
+		if err != nil {
+			errs = append(errs, &ConfigError{
+				Position: token.Position{Filename: path, Line: line},
+				Msg:      fmt.Sprintf("%v", err),
+			})
+			continue
+		}
+		co.rules = append(co.rules, ownerRule{re, fields[1:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return co, nil
+}
+
+// owners returns the owners of the file name. Like on GitHub, the
+// last matching rule applies, and rules without owners make files
+// unowned.
+func (co *codeOwners) owners(name string) []string {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(co.root, name)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].re.MatchString(rel) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// applyOwners annotates problems with the owners of their files,
+// according to the CODEOWNERS file opt.CodeOwners.
+func applyOwners(problems [][]lint.Problem, opt *Options) error {
+	if opt.CodeOwners == "" {
+		return nil
+	}
+	co, err := parseCodeOwners(opt.CodeOwners)
+	if err != nil {
+		return err
+	}
+	for _, ps := range problems {
+		for i := range ps {
+			if ps[i].Position.Filename != "" {
+				ps[i].Owners = co.owners(ps[i].Position.Filename)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package lintutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	root, err := ioutil.TempDir("", "codeowners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	write := func(name, data string) string {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := write(".github/CODEOWNERS", `# Default owners
+*       @org/everyone
+
+*.go    @org/gophers
+/build/ @org/infra
+docs/   @org/writers
+apps/**/handlers/ @org/web @alice # trailing comment
+internal/legacy/
+/cmd/tool/main.go alice@example.com
+`)
+	co, err := parseCodeOwners(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.root != root {
+		t.Errorf("got root %s, want the directory containing .github", co.root)
+	}
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"README.md", []string{"@org/everyone"}},
+		// The last matching rule wins.
+		{"main.go", []string{"@org/gophers"}},
+		{"pkg/a/a.go", []string{"@org/gophers"}},
+		{"cmd/tool/main.go", []string{"alice@example.com"}},
+		{"cmd/tool/other.go", []string{"@org/gophers"}},
+		// Anchored directory patterns only match at the root.
+		{"build/Makefile", []string{"@org/infra"}},
+		{"build/gen/gen.go", []string{"@org/infra"}},
+		{"pkg/build/b.go", []string{"@org/gophers"}},
+		{"build.go", []string{"@org/gophers"}},
+		// Unanchored ones match at any depth.
+		{"docs/index.md", []string{"@org/writers"}},
+		{"pkg/docs/a.go", []string{"@org/writers"}},
+		{"apps/shop/handlers/cart.go", []string{"@org/web", "@alice"}},
+		{"apps/handlers/cart.go", []string{"@org/web", "@alice"}},
+		{"apps/shop/cart.go", []string{"@org/gophers"}},
+		// Rules without owners make files unowned.
+		{"internal/legacy/old.go", nil},
+		{"internal/new.go", []string{"@org/gophers"}},
+	}
+	for _, tt := range tests {
+		got := co.owners(filepath.Join(root, filepath.FromSlash(tt.file)))
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("owners of %s: got %q, want %q", tt.file, got, tt.want)
+		}
+	}
+	if got := co.owners(filepath.Join(filepath.Dir(root), "other", "a.go")); got != nil {
+		t.Errorf("file outside of the repository has owners %q", got)
+	}
+
+	// Files at the root take precedence over those in docs, but
+	// .github comes first.
+	if found, ok := findCodeOwners(filepath.Join(root, "pkg", "a")); !ok || found != path {
+		t.Errorf("findCodeOwners found %q, want %q", found, path)
+	}
+	os.Remove(path)
+	docs := write("docs/CODEOWNERS", "* @org/docs\n")
+	top := write("CODEOWNERS", "* @org/top\n")
+	if found, ok := findCodeOwners(root); !ok || found != top {
+		t.Errorf("findCodeOwners found %q, want %q", found, top)
+	}
+	co, err = parseCodeOwners(docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.root != root {
+		t.Errorf("got root %s for docs/CODEOWNERS, want %s", co.root, root)
+	}
+
+	bad := write("CODEOWNERS", "* @org/top\n/ @org/nobody\n")
+	if _, err := parseCodeOwners(bad); err == nil || !strings.Contains(err.Error(), "CODEOWNERS:2") {
+		t.Errorf("got error %v, want one on line 2", err)
+	}
+}
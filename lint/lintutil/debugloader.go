@@ -0,0 +1,156 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/loader"
+)
+
+// logBuildContext writes the settings of bctx that decide which
+// packages and files are loaded to w, for -debug.loader.
+func logBuildContext(w io.Writer, bctx *build.Context, opt *Options) {
+	cgo := "0"
+	if bctx.CgoEnabled {
+		cgo = "1"
+	}
+	release := ""
+	if tags := bctx.ReleaseTags; len(tags) > 0 {
+		release = tags[len(tags)-1]
+	}
+	fmt.Fprintf(w, "loader: build context: GOOS=%s GOARCH=%s CGO_ENABLED=%s GOROOT=%s GOPATH=%s compiler=%s release=%s tags=%q\n",
+		bctx.GOOS, bctx.GOARCH, cgo, bctx.GOROOT, bctx.GOPATH, bctx.Compiler, release, bctx.BuildTags)
+	fmt.Fprintf(w, "loader: targeting Go 1.%d, tests included: %t, partial: %t\n", opt.GoVersion, opt.LintTests, opt.Partial)
+}
+
+// logPatterns expands patterns like gotool.ImportPaths and writes the
+// packages that each of them matched to w, as well as the
+// directories below wildcard patterns that contain Go files but were
+// skipped, and why.
+func logPatterns(w io.Writer, bctx *build.Context, patterns []string) []string {
+	if len(patterns) > 0 && strings.HasSuffix(patterns[0], ".go") {
+		fmt.Fprintf(w, "loader: linting files: %s\n", strings.Join(patterns, ", "))
+		return gotool.ImportPaths(patterns)
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(w, "loader: %v\n", err)
+		return gotool.ImportPaths(patterns)
+	}
+	var out []string
+	for _, pattern := range patterns {
+		paths := gotool.ImportPaths([]string{pattern})
+		out = append(out, paths...)
+		if len(paths) == 0 {
+			fmt.Fprintf(w, "loader: pattern %q matched no packages\n", pattern)
+		} else {
+			fmt.Fprintf(w, "loader: pattern %q matched %d packages\n", pattern, len(paths))
+		}
+		matched := map[string]bool{}
+		for _, path := range paths {
+			bp, err := bctx.Import(path, wd, 0)
+			if bp != nil && bp.Dir != "" {
+				matched[bp.Dir] = true
+			}
+			logPackage(w, path, bp, err)
+		}
+		logSkipped(w, bctx, pattern, wd, matched)
+	}
+	return out
+}
+
+// logPackage writes the files of the package bp, which was imported
+// as path, to w.
+func logPackage(w io.Writer, path string, bp *build.Package, err error) {
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); ok {
+			fmt.Fprintf(w, "loader:   %s: skipped, %v\n", path, err)
+			return
+		}
+		fmt.Fprintf(w, "loader:   %s: %v\n", path, err)
+		return
+	}
+	name := bp.ImportPath
+	if name != path {
+		name = fmt.Sprintf("%s (%s)", path, bp.ImportPath)
+	}
+	fmt.Fprintf(w, "loader:   %s: %d Go files, %d cgo files, %d test files\n",
+		name, len(bp.GoFiles), len(bp.CgoFiles), len(bp.TestGoFiles)+len(bp.XTestGoFiles))
+	if len(bp.IgnoredGoFiles) > 0 {
+		fmt.Fprintf(w, "loader:   %s: excluded by build constraints: %s\n", name, strings.Join(bp.IgnoredGoFiles, ", "))
+	}
+	if len(bp.InvalidGoFiles) > 0 {
+		fmt.Fprintf(w, "loader:   %s: invalid files: %s\n", name, strings.Join(bp.InvalidGoFiles, ", "))
+	}
+}
+
+// logSkipped writes the directories below the wildcard pattern that
+// contain Go files, but that the pattern didn't match, to w. matched
+// are the directories of the packages it did match. Only patterns of
+// the form "path/..." are considered.
+func logSkipped(w io.Writer, bctx *build.Context, pattern, wd string, matched map[string]bool) {
+	if !strings.HasSuffix(pattern, "/...") {
+		return
+	}
+	prefix := strings.TrimSuffix(pattern, "/...")
+	root := prefix
+	if !build.IsLocalImport(prefix) && !filepath.IsAbs(prefix) {
+		bp, err := bctx.Import(prefix, wd, build.FindOnly)
+		if err != nil {
+			return
+		}
+		root = bp.Dir
+	} else if !filepath.IsAbs(root) {
+		root = filepath.Join(wd, root)
+	}
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		}
+		if path != root {
+			elem := fi.Name()
+			if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" {
+				return filepath.SkipDir
+			}
+		}
+		if matched[path] {
+			return nil
+		}
+		files, _ := filepath.Glob(filepath.Join(path, "*.go"))
+		if len(files) == 0 {
+			return nil
+		}
+		name := path
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			name = "./" + filepath.ToSlash(rel)
+		}
+		bp, err := bctx.ImportDir(path, 0)
+		if _, ok := err.(*build.NoGoError); ok && len(bp.IgnoredGoFiles) > 0 {
+			fmt.Fprintf(w, "loader:   %s: skipped, all Go files are excluded by build constraints: %s\n", name, strings.Join(bp.IgnoredGoFiles, ", "))
+		} else if err != nil {
+			fmt.Fprintf(w, "loader:   %s: skipped, %v\n", name, err)
+		}
+		return nil
+	})
+}
+
+// logLoaded writes the initial packages of lprog that failed to
+// type-check, and the number of packages loaded, to w.
+func logLoaded(w io.Writer, lprog *loader.Program) {
+	for _, pkg := range lprog.InitialPackages() {
+		if len(pkg.Errors) > 0 {
+			fmt.Fprintf(w, "loader: %s: %d type errors, first: %v\n", pkg.Pkg.Path(), len(pkg.Errors), pkg.Errors[0])
+		} else if !pkg.TransitivelyErrorFree {
+			fmt.Fprintf(w, "loader: %s: dependencies have type errors\n", pkg.Pkg.Path())
+		}
+	}
+	fmt.Fprintf(w, "loader: loaded %d packages, %d in total with dependencies\n", len(lprog.InitialPackages()), len(lprog.AllPackages))
+}
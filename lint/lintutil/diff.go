@@ -0,0 +1,236 @@
+package lintutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// ReadResults reads the problems of a run from r, in the json or
+// jsonl format. The metadata of the run is only available in the
+// jsonl format; it is nil otherwise. Problems read back don't know
+// their packages.
+func ReadResults(r io.Reader) ([]lint.Problem, *Metadata, error) {
+	var ps []lint.Problem
+	var md *Metadata
+	dec := json.NewDecoder(r)
+	for {
+		var rec struct {
+			Type string `json:"type"`
+			jsonProblem
+		}
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			return ps, md, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, nil, err
+		}
+		switch rec.Type {
+		case "metadata":
+			md = new(Metadata)
+			if err := json.Unmarshal(raw, md); err != nil {
+				return nil, nil, err
+			}
+		case "problem", "":
+			ps = append(ps, rec.problem())
+		default:
+			return nil, nil, fmt.Errorf("unknown record type %q", rec.Type)
+		}
+	}
+}
+
+// Fingerprint returns a fingerprint of p that doesn't depend on its
+// position within its file, so that problems can be recognized after
+// unrelated changes moved them. It is derived from the check, the
+// message and the name of the file, relative to root if the file is
+// in it.
+func Fingerprint(p lint.Problem, root string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", p.Check, p.Text, relativeTo(p.Position.Filename, root))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func relativeTo(path, root string) string {
+	if root == "" || !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// A MovedProblem is a problem that exists in both of the compared
+// results, but at different positions.
+type MovedProblem struct {
+	Old, New lint.Problem
+}
+
+// A ResultDiff is the difference between two sets of problems, such
+// as those of the main branch and of a change to it.
+type ResultDiff struct {
+	// New are the problems that only exist in the new results.
+	New []lint.Problem
+	// Fixed are the problems that only exist in the old results.
+	Fixed []lint.Problem
+	// Moved are the problems that exist in both results, but at
+	// different lines or in different files.
+	Moved []MovedProblem
+}
+
+// DiffResults compares the problems old and new, whose files are
+// relative to oldRoot and newRoot. Ignored problems are skipped.
+//
+// Problems with the same fingerprint are paired by position:
+// problems at the same line are unchanged, and the remaining ones
+// are paired in the order of their lines and are considered moved.
+// Problems that are left over are paired with problems of the same
+// check and message in other files, which have probably been
+// renamed, and are considered new or fixed otherwise.
+func DiffResults(old, new []lint.Problem, oldRoot, newRoot string) ResultDiff {
+	var d ResultDiff
+	byFingerprint := func(ps []lint.Problem, root string) map[string][]lint.Problem {
+		m := map[string][]lint.Problem{}
+		for _, p := range ps {
+			if p.Ignored {
+				continue
+			}
+			fp := Fingerprint(p, root)
+			m[fp] = append(m[fp], p)
+		}
+		for _, ps := range m {
+			sort.Sort(byPosition(ps))
+		}
+		return m
+	}
+	olds := byFingerprint(old, oldRoot)
+	news := byFingerprint(new, newRoot)
+
+	var leftOld, leftNew []lint.Problem
+	var fps []string
+	for fp := range olds {
+		fps = append(fps, fp)
+	}
+	for fp := range news {
+		if _, ok := olds[fp]; !ok {
+			fps = append(fps, fp)
+		}
+	}
+	sort.Strings(fps)
+	for _, fp := range fps {
+		ops, nps := olds[fp], news[fp]
+		// Drop unchanged problems.
+		lines := map[int]int{}
+		for _, p := range ops {
+			lines[p.Position.Line]++
+		}
+		var restNew []lint.Problem
+		for _, p := range nps {
+			if lines[p.Position.Line] > 0 {
+				lines[p.Position.Line]--
+				continue
+			}
+			restNew = append(restNew, p)
+		}
+		var restOld []lint.Problem
+		for _, p := range ops {
+			if lines[p.Position.Line] > 0 {
+				lines[p.Position.Line]--
+				restOld = append(restOld, p)
+			}
+		}
+		for len(restOld) > 0 && len(restNew) > 0 {
+			d.Moved = append(d.Moved, MovedProblem{restOld[0], restNew[0]})
+			restOld, restNew = restOld[1:], restNew[1:]
+		}
+		leftOld = append(leftOld, restOld...)
+		leftNew = append(leftNew, restNew...)
+	}
+
+	// Match the remaining problems across files.
+	type key struct{ check, text string }
+	pending := map[key][]lint.Problem{}
+	for _, p := range leftOld {
+		k := key{p.Check, p.Text}
+		pending[k] = append(pending[k], p)
+	}
+	for _, p := range leftNew {
+		k := key{p.Check, p.Text}
+		if ops := pending[k]; len(ops) > 0 {
+			d.Moved = append(d.Moved, MovedProblem{ops[0], p})
+			pending[k] = ops[1:]
+			continue
+		}
+		d.New = append(d.New, p)
+	}
+	for _, ps := range pending {
+		d.Fixed = append(d.Fixed, ps...)
+	}
+
+	sort.Sort(byPosition(d.New))
+	sort.Sort(byPosition(d.Fixed))
+	sort.Sort(byMovedPosition(d.Moved))
+	return d
+}
+
+type byMovedPosition []MovedProblem
+
+func (s byMovedPosition) Len() int      { return len(s) }
+func (s byMovedPosition) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byMovedPosition) Less(i, j int) bool {
+	return byPosition{s[i].New, s[j].New}.Less(0, 1)
+}
+
+// WriteDiffJSON writes the difference d as JSON Lines. Each record has
+// a "type" field, which is "new", "fixed" or "moved". New and fixed
+// records describe problems like the json output format, with an
+// additional fingerprint; moved records have the fields "old" and
+// "new".
+func WriteDiffJSON(w io.Writer, d ResultDiff, oldRoot, newRoot string) error {
+	type fingerprintedProblem struct {
+		Fingerprint string `json:"fingerprint"`
+		jsonProblem
+	}
+	problem := func(p lint.Problem, root string) fingerprintedProblem {
+		return fingerprintedProblem{Fingerprint(p, root), newJSONProblem(p)}
+	}
+	type record struct {
+		Type string `json:"type"`
+		fingerprintedProblem
+	}
+	enc := json.NewEncoder(w)
+	for _, p := range d.New {
+		if err := enc.Encode(record{"new", problem(p, newRoot)}); err != nil {
+			return err
+		}
+	}
+	for _, p := range d.Fixed {
+		if err := enc.Encode(record{"fixed", problem(p, oldRoot)}); err != nil {
+			return err
+		}
+	}
+	for _, m := range d.Moved {
+		rec := struct {
+			Type string               `json:"type"`
+			Old  fingerprintedProblem `json:"old"`
+			New  fingerprintedProblem `json:"new"`
+		}{"moved", problem(m.Old, oldRoot), problem(m.New, newRoot)}
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package lintutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func diffProblem(file string, line int, check, text string) lint.Problem {
+	return lint.Problem{
+		Position: token.Position{Filename: file, Line: line, Column: 2},
+		Check:    check,
+		Text:     text,
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	p := diffProblem("/old/pkg/a.go", 10, "SA4006", "this value of x is never used")
+	fp := Fingerprint(p, "/old")
+
+	same := []lint.Problem{
+		diffProblem("/old/pkg/a.go", 42, "SA4006", "this value of x is never used"),
+		diffProblem("/old/pkg/a.go", 10, "SA4006", "this value of x is never used"),
+	}
+	same[1].Position.Column = 20
+	for _, q := range same {
+		if got := Fingerprint(q, "/old"); got != fp {
+			t.Errorf("fingerprint of problem at %s changed", q.Position)
+		}
+	}
+	// Checkouts in different directories produce the same
+	// fingerprints.
+	if got := Fingerprint(diffProblem("/new/pkg/a.go", 12, "SA4006", "this value of x is never used"), "/new"); got != fp {
+		t.Errorf("fingerprint depends on the root")
+	}
+
+	different := []lint.Problem{
+		diffProblem("/old/pkg/a.go", 10, "SA4006", "this value of y is never used"),
+		diffProblem("/old/pkg/a.go", 10, "SA4010", "this value of x is never used"),
+		diffProblem("/old/pkg/b.go", 10, "SA4006", "this value of x is never used"),
+	}
+	for _, q := range different {
+		if got := Fingerprint(q, "/old"); got == fp {
+			t.Errorf("problem %s in %s has the same fingerprint as %s in %s", q.Text, q.Position.Filename, p.Text, p.Position.Filename)
+		}
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	old := []lint.Problem{
+		diffProblem("/old/a.go", 10, "SA4006", "unused x"),
+		diffProblem("/old/a.go", 20, "SA4006", "unused y"),
+		diffProblem("/old/a.go", 30, "S1000", "use plain channel send"),
+		diffProblem("/old/b.go", 5, "SA5000", "assignment to nil map"),
+		diffProblem("/old/c.go", 7, "ST1005", "error strings should not be capitalized"),
+	}
+	new := []lint.Problem{
+		// Unchanged.
+		diffProblem("/new/a.go", 10, "SA4006", "unused x"),
+		// Moved by lines inserted above it.
+		diffProblem("/new/a.go", 25, "SA4006", "unused y"),
+		// Same position, but a different message.
+		diffProblem("/new/a.go", 30, "S1000", "use plain channel receive"),
+		// b.go was renamed.
+		diffProblem("/new/d.go", 5, "SA5000", "assignment to nil map"),
+		diffProblem("/new/a.go", 40, "SA4006", "unused z"),
+	}
+	ignored := diffProblem("/new/a.go", 50, "SA4006", "unused w")
+	ignored.Ignored = true
+	new = append(new, ignored)
+	d := DiffResults(old, new, "/old", "/new")
+
+	if len(d.Moved) != 2 {
+		t.Fatalf("got %d moved problems, want 2: %v", len(d.Moved), d.Moved)
+	}
+	for i, want := range []struct {
+		old, new string
+	}{
+		{"a.go:20:2", "a.go:25:2"},
+		{"b.go:5:2", "d.go:5:2"},
+	} {
+		m := d.Moved[i]
+		if got := relativePosition(m.Old, "/old"); got != want.old {
+			t.Errorf("moved problem %d: old position is %s, want %s", i, got, want.old)
+		}
+		if got := relativePosition(m.New, "/new"); got != want.new {
+			t.Errorf("moved problem %d: new position is %s, want %s", i, got, want.new)
+		}
+	}
+	texts := func(ps []lint.Problem) []string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Text)
+		}
+		return out
+	}
+	if got := texts(d.New); len(got) != 2 || got[0] != "use plain channel receive" || got[1] != "unused z" {
+		t.Errorf("got new problems %q", got)
+	}
+	if got := texts(d.Fixed); len(got) != 2 || got[0] != "use plain channel send" || got[1] != "error strings should not be capitalized" {
+		t.Errorf("got fixed problems %q", got)
+	}
+}
+
+func relativePosition(p lint.Problem, root string) string {
+	pos := p.Position
+	pos.Filename = relativeTo(pos.Filename, root)
+	return pos.String()
+}
+
+func TestJSONLinesOutput(t *testing.T) {
+	p := diffProblem("/old/a.go", 10, "SA4006", "unused x")
+	var buf bytes.Buffer
+	JSONLinesOutput{&buf}.Format(p)
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["type"] != "problem" || rec["code"] != "SA4006" || rec["message"] != "unused x" {
+		t.Errorf("got record %v", rec)
+	}
+	ps, _, err := ReadResults(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 1 || ps[0].Text != p.Text || ps[0].Position != p.Position {
+		t.Errorf("read back %v, want %v", ps, p)
+	}
+}
+
+func TestWriteDiffJSON(t *testing.T) {
+	old := diffProblem("/old/a.go", 10, "SA4006", "unused x")
+	moved := diffProblem("/new/a.go", 12, "SA4006", "unused x")
+	new := diffProblem("/new/b.go", 5, "SA5000", "assignment to nil map")
+	d := ResultDiff{New: []lint.Problem{new}, Fixed: []lint.Problem{old}, Moved: []MovedProblem{{old, moved}}}
+	var buf bytes.Buffer
+	if err := WriteDiffJSON(&buf, d, "/old", "/new"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d records, want 3:\n%s", len(lines), buf.String())
+	}
+	var recs []map[string]interface{}
+	for _, line := range lines {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+	if recs[0]["type"] != "new" || recs[0]["fingerprint"] != Fingerprint(new, "/new") || recs[0]["code"] != "SA5000" {
+		t.Errorf("got new record %v", recs[0])
+	}
+	if recs[1]["type"] != "fixed" || recs[1]["fingerprint"] != Fingerprint(old, "/old") || recs[1]["code"] != "SA4006" {
+		t.Errorf("got fixed record %v", recs[1])
+	}
+	m, _ := recs[2]["new"].(map[string]interface{})
+	if recs[2]["type"] != "moved" || recs[2]["old"] == nil || m["fingerprint"] != Fingerprint(moved, "/new") {
+		t.Errorf("got moved record %v", recs[2])
+	}
+}
@@ -0,0 +1,263 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+type docCheck struct {
+	ID         string
+	Title      string
+	Text       string
+	NonDefault bool
+}
+
+type docCategory struct {
+	ID     string
+	Title  string
+	Checks []docCheck
+}
+
+// checkerDocs collects the documentation of all of a checker's
+// enabled checks, grouped by category. Checks without documentation
+// are still listed.
+func checkerDocs(c lint.Checker) []docCategory {
+	var docs map[string]*lint.Documentation
+	if dc, ok := c.(lint.DocumentedChecker); ok {
+		docs = dc.Docs()
+	}
+
+	var ids []string
+	for id, fn := range c.Funcs() {
+		if fn == nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var out []docCategory
+	for _, id := range ids {
+		catID := id
+		if len(id) > 3 {
+			catID = id[:len(id)-3]
+		}
+		if len(out) == 0 || out[len(out)-1].ID != catID {
+			cat := docCategory{ID: catID}
+			if doc := docs[catID]; doc != nil {
+				cat.Title = doc.Title
+			}
+			out = append(out, cat)
+		}
+		check := docCheck{ID: id, Title: "Undocumented"}
+		if doc := docs[id]; doc != nil {
+			check.Title = doc.Title
+			check.Text = doc.Text
+			check.NonDefault = doc.NonDefault
+		}
+		cat := &out[len(out)-1]
+		cat.Checks = append(cat.Checks, check)
+	}
+	return out
+}
+
+// WriteDocs renders the documentation of the checks provided by cs
+// into dir, as one Markdown and one HTML page per checker, plus an
+// index. Only checks that are part of the checkers are documented,
+// which allows custom builds to publish documentation that matches
+// the binary.
+func WriteDocs(cs []lint.Checker, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var mdIndex, htmlIndex bytes.Buffer
+	fmt.Fprintf(&mdIndex, "# Checks\n\n")
+	htmlHeader(&htmlIndex, "Checks")
+	fmt.Fprintf(&htmlIndex, "<ul>\n")
+	for _, c := range cs {
+		cats := checkerDocs(c)
+		n := 0
+		for _, cat := range cats {
+			n += len(cat.Checks)
+		}
+		fmt.Fprintf(&mdIndex, "- [%s](%s.md) (%d checks)\n", c.Name(), c.Name(), n)
+		fmt.Fprintf(&htmlIndex, "<li><a href=\"%s.html\">%s</a> (%d checks)</li>\n",
+			html.EscapeString(c.Name()), html.EscapeString(c.Name()), n)
+
+		var md, h bytes.Buffer
+		writeCheckerMarkdown(&md, c.Name(), cats)
+		writeCheckerHTML(&h, c.Name(), cats)
+		if err := ioutil.WriteFile(filepath.Join(dir, c.Name()+".md"), md.Bytes(), 0644); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, c.Name()+".html"), h.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&htmlIndex, "</ul>\n")
+	htmlFooter(&htmlIndex)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.md"), mdIndex.Bytes(), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "index.html"), htmlIndex.Bytes(), 0644)
+}
+
+func writeCheckerMarkdown(buf *bytes.Buffer, name string, cats []docCategory) {
+	fmt.Fprintf(buf, "# %s\n\n", name)
+	for _, cat := range cats {
+		fmt.Fprintf(buf, "- [%s](#%s)\n", categoryHeading(cat), cat.ID)
+		for _, check := range cat.Checks {
+			fmt.Fprintf(buf, "  - [%s – %s](#%s)\n", check.ID, check.Title, check.ID)
+		}
+	}
+	for _, cat := range cats {
+		fmt.Fprintf(buf, "\n<a name=\"%s\"></a>\n## %s\n", cat.ID, categoryHeading(cat))
+		for _, check := range cat.Checks {
+			fmt.Fprintf(buf, "\n<a name=\"%s\"></a>\n### %s – %s\n", check.ID, check.ID, check.Title)
+			if check.NonDefault {
+				fmt.Fprintf(buf, "\n%s\n", nonDefaultNote(check))
+			}
+			if check.Text != "" {
+				fmt.Fprintf(buf, "\n%s", check.Text)
+				if !strings.HasSuffix(check.Text, "\n") {
+					buf.WriteString("\n")
+				}
+			}
+		}
+	}
+}
+
+func writeCheckerHTML(buf *bytes.Buffer, name string, cats []docCategory) {
+	htmlHeader(buf, name)
+	buf.WriteString("<ul>\n")
+	for _, cat := range cats {
+		fmt.Fprintf(buf, "<li><a href=\"#%s\">%s</a>\n<ul>\n",
+			cat.ID, html.EscapeString(categoryHeading(cat)))
+		for _, check := range cat.Checks {
+			fmt.Fprintf(buf, "<li><a href=\"#%s\">%s – %s</a></li>\n",
+				check.ID, check.ID, inlineHTML(check.Title))
+		}
+		buf.WriteString("</ul>\n</li>\n")
+	}
+	buf.WriteString("</ul>\n")
+	for _, cat := range cats {
+		fmt.Fprintf(buf, "<h2 id=\"%s\">%s</h2>\n",
+			cat.ID, html.EscapeString(categoryHeading(cat)))
+		for _, check := range cat.Checks {
+			fmt.Fprintf(buf, "<h3 id=\"%s\">%s – %s</h3>\n",
+				check.ID, check.ID, inlineHTML(check.Title))
+			if check.NonDefault {
+				fmt.Fprintf(buf, "<p>%s</p>\n", inlineHTML(nonDefaultNote(check)))
+			}
+			markdownToHTML(buf, check.Text)
+		}
+	}
+	htmlFooter(buf)
+}
+
+func nonDefaultNote(check docCheck) string {
+	return fmt.Sprintf("**This check is disabled by default.** Enable it with `-checks %s`.", check.ID)
+}
+
+func categoryHeading(cat docCategory) string {
+	if cat.Title == "" {
+		return cat.ID
+	}
+	return cat.ID + " – " + cat.Title
+}
+
+func htmlHeader(buf *bytes.Buffer, title string) {
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n",
+		html.EscapeString(title), html.EscapeString(title))
+}
+
+func htmlFooter(buf *bytes.Buffer) {
+	buf.WriteString("</body>\n</html>\n")
+}
+
+// markdownToHTML renders the small subset of Markdown used by check
+// documentation: paragraphs, fenced code blocks, inline code and
+// bold text.
+func markdownToHTML(buf *bytes.Buffer, text string) {
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		fmt.Fprintf(buf, "<p>%s</p>\n", inlineHTML(strings.Join(para, "\n")))
+		para = para[:0]
+	}
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "```") {
+			if inCode {
+				buf.WriteString("</code></pre>\n")
+			} else {
+				flush()
+				buf.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			buf.WriteString(html.EscapeString(line))
+			buf.WriteString("\n")
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		para = append(para, line)
+	}
+	if inCode {
+		buf.WriteString("</code></pre>\n")
+	}
+	flush()
+}
+
+func inlineHTML(s string) string {
+	var out bytes.Buffer
+	for {
+		i := strings.IndexAny(s, "`*")
+		if i == -1 {
+			break
+		}
+		delim := s[i : i+1]
+		tag := "code"
+		if delim == "*" {
+			if !strings.HasPrefix(s[i:], "**") {
+				out.WriteString(html.EscapeString(s[:i+1]))
+				s = s[i+1:]
+				continue
+			}
+			delim = "**"
+			tag = "strong"
+		}
+		j := strings.Index(s[i+len(delim):], delim)
+		if j == -1 {
+			break
+		}
+		out.WriteString(html.EscapeString(s[:i]))
+		inner := s[i+len(delim) : i+len(delim)+j]
+		if tag == "strong" {
+			inner = inlineHTML(inner)
+		} else {
+			inner = html.EscapeString(inner)
+		}
+		fmt.Fprintf(&out, "<%s>%s</%s>", tag, inner, tag)
+		s = s[i+len(delim)+j+len(delim):]
+	}
+	out.WriteString(html.EscapeString(s))
+	return out.String()
+}
@@ -0,0 +1,124 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+
+	"honnef.co/go/tools/lint"
+)
+
+// An ErrorList is a list of errors, such as all type errors found
+// while loading packages.
+type ErrorList []error
+
+func (errs ErrorList) Error() string {
+	var buf bytes.Buffer
+	for i, err := range errs {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// A ConfigError is an error in the configuration of a run, such as a
+// malformed configuration file or flag.
+type ConfigError struct {
+	// Position is the position of the error, if it is in a file.
+	Position token.Position
+	Msg      string
+}
+
+func (err *ConfigError) Error() string {
+	if err.Position.Filename == "" {
+		return err.Msg
+	}
+	return err.Position.String() + ": " + err.Msg
+}
+
+// A GroupError is an error in one of several packages whose files
+// were specified at once, each of which is loaded and linted on its
+// own.
+type GroupError struct {
+	// Dir and Package are the directory and the package name of
+	// the files.
+	Dir     string
+	Package string
+	Err     error
+}
+
+func (err *GroupError) Error() string {
+	return fmt.Sprintf("package %s in %s: %s", err.Package, err.Dir, err.Err)
+}
+
+// errorProblems turns errors into problems, so that they can be
+// reported by output formatters alongside other problems. Problems
+// in the configuration use the check "config"; all other errors are
+// considered to be errors in the code, using the check "compile".
+func errorProblems(err error) []lint.Problem {
+	problem := func(pos token.Position, msg string) lint.Problem {
+		return lint.Problem{
+			Position: pos,
+			Text:     msg,
+			Checker:  "compile",
+			Check:    "compile",
+		}
+	}
+	switch err := err.(type) {
+	case ErrorList:
+		var ps []lint.Problem
+		for _, err := range err {
+			ps = append(ps, errorProblems(err)...)
+		}
+		return ps
+	case scanner.ErrorList:
+		var ps []lint.Problem
+		for _, err := range err {
+			ps = append(ps, problem(err.Pos, err.Msg))
+		}
+		return ps
+	case *scanner.Error:
+		return []lint.Problem{problem(err.Pos, err.Msg)}
+	case scanner.Error:
+		return []lint.Problem{problem(err.Pos, err.Msg)}
+	case types.Error:
+		return []lint.Problem{problem(err.Fset.Position(err.Pos), err.Msg)}
+	case *GroupError:
+		ps := errorProblems(err.Err)
+		for i := range ps {
+			if !ps[i].Position.IsValid() {
+				ps[i].Text = fmt.Sprintf("package %s in %s: %s", err.Package, err.Dir, ps[i].Text)
+			}
+		}
+		return ps
+	case *ConfigError:
+		return []lint.Problem{{
+			Position: err.Position,
+			Text:     err.Msg,
+			Checker:  "config",
+			Check:    "config",
+		}}
+	default:
+		return []lint.Problem{problem(token.Position{}, err.Error())}
+	}
+}
+
+// writeInternalErrors writes the internal errors among ps, with their
+// stack traces, to w. Only the JSON formats include stack traces in
+// the problems themselves.
+func writeInternalErrors(w io.Writer, ps []lint.Problem) {
+	for _, p := range ps {
+		if p.Check != lint.InternalErrorCheck {
+			continue
+		}
+		if p.Position.IsValid() {
+			fmt.Fprintf(w, "%s: ", p.Position)
+		}
+		fmt.Fprintf(w, "%s\n%s\n", p.Text, p.Stack)
+	}
+}
@@ -0,0 +1,186 @@
+package lintutil
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+// excludeList is the value of the -exclude flag, which may be given
+// multiple times, or with comma-separated patterns.
+type excludeList []string
+
+func (l *excludeList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *excludeList) Set(s string) error {
+	for _, pattern := range strings.Split(s, ",") {
+		if _, err := compileIgnorePattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		*l = append(*l, pattern)
+	}
+	return nil
+}
+
+func (l *excludeList) Get() interface{} {
+	return []string(*l)
+}
+
+// An exclusion removes the files and directories that match a
+// gitignore-like pattern from analysis.
+type exclusion struct {
+	// root is the directory that the pattern is relative to.
+	root    string
+	pattern string
+	re      *regexp.Regexp
+}
+
+// errAllExcluded is returned when loading packages if all the
+// packages that the patterns matched are excluded.
+var errAllExcluded = errors.New("all packages are excluded")
+
+// compileExclusions compiles patterns, which are relative to root.
+func compileExclusions(root string, patterns []string) ([]exclusion, error) {
+	var out []exclusion
+	for _, pattern := range patterns {
+		re, err := compileIgnorePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+		}
+		out = append(out, exclusion{root: root, pattern: pattern, re: re})
+	}
+	return out, nil
+}
+
+// configExclusions returns the exclusions of the configuration files
+// files, each relative to the directory of its file.
+func configExclusions(files []string) ([]exclusion, error) {
+	var out []exclusion
+	for _, path := range files {
+		cfg, err := config.Load(path)
+		if err != nil {
+			return nil, configError(err)
+		}
+		exs, err := compileExclusions(filepath.Dir(path), cfg.Exclude)
+		if err != nil {
+			return nil, &ConfigError{Position: token.Position{Filename: path}, Msg: err.Error()}
+		}
+		out = append(out, exs...)
+	}
+	return out, nil
+}
+
+// exclusions returns the exclusions of opt: those of opt.Exclude,
+// which are relative to the current directory, and those of the
+// configuration files that apply to the project directory.
+func (opt *Options) exclusions() ([]exclusion, error) {
+	if len(opt.Exclude) == 0 {
+		return opt.configExclusions, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	exs, err := compileExclusions(wd, opt.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return append(exs, opt.configExclusions...), nil
+}
+
+// excluded reports whether the file or directory at path matches
+// any of the exclusions.
+func excluded(exs []exclusion, path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, ex := range exs {
+		rel, err := filepath.Rel(ex.root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if ex.re.MatchString(filepath.ToSlash(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludePackages removes the packages whose directories, or all of
+// whose files, are excluded from paths. Packages that can't be found
+// are kept, so that loading them reports the error.
+func excludePackages(exs []exclusion, paths []string, bctx *build.Context, tests bool) []string {
+	if len(exs) == 0 {
+		return paths
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+	var out []string
+	for _, path := range paths {
+		bp, err := bctx.Import(path, wd, 0)
+		if err != nil {
+			out = append(out, path)
+			continue
+		}
+		if excluded(exs, bp.Dir) {
+			continue
+		}
+		files := append(append([]string(nil), bp.GoFiles...), bp.CgoFiles...)
+		if tests {
+			files = append(append(files, bp.TestGoFiles...), bp.XTestGoFiles...)
+		}
+		all := len(files) > 0
+		for _, f := range files {
+			if !excluded(exs, filepath.Join(bp.Dir, f)) {
+				all = false
+				break
+			}
+		}
+		if !all {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// excludeFiles removes the excluded files from files.
+func excludeFiles(exs []exclusion, files []string) []string {
+	var out []string
+	for _, f := range files {
+		if !excluded(exs, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// excludeProblems removes the problems in excluded files. Files
+// can't be removed before type-checking, as the rest of their
+// packages may depend on them.
+func excludeProblems(exs []exclusion, problems [][]lint.Problem) {
+	if len(exs) == 0 {
+		return
+	}
+	for i, ps := range problems {
+		var out []lint.Problem
+		for _, p := range ps {
+			if p.Position.Filename == "" || !excluded(exs, p.Position.Filename) {
+				out = append(out, p)
+			}
+		}
+		problems[i] = out
+	}
+}
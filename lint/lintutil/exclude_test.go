@@ -0,0 +1,169 @@
+package lintutil
+
+import (
+	"go/build"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+func TestExcludeList(t *testing.T) {
+	var l excludeList
+	for _, s := range []string{"gen/", "*.pb.go,third_party/**"} {
+		if err := l.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"gen/", "*.pb.go", "third_party/**"}; !reflect.DeepEqual(l.Get(), want) {
+		t.Errorf("got patterns %q, want %q", l, want)
+	}
+	for _, s := range []string{"a.go,", "/", `a\`} {
+		if err := l.Set(s); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestExclusions(t *testing.T) {
+	root, err := ioutil.TempDir("", "exclude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	// Resolve symlinks, so that paths relative to the working
+	// directory match.
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, data string) {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(config.ConfigName, `exclude = ["vendor/", "*_gen.go"]`)
+	write("sub/"+config.ConfigName, `exclude = ["legacy.go"]`)
+	write("keep/a.go", "package keep\n")
+	write("keep/a_gen.go", "package keep\n")
+	write("vendor/v/v.go", "package v\n")
+	write("gen/a_gen.go", "package gen\n")
+	write("gen/b_gen.go", "package gen\n")
+	write("mixed/a_gen.go", "package mixed\n")
+	write("mixed/a_test.go", "package mixed\n")
+	write("flagged/f.go", "package flagged\n")
+	write("sub/legacy.go", "package sub\n")
+	write("sub/new.go", "package sub\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// Patterns in configuration files are relative to their
+	// directories, and those of the flag to the working directory.
+	// Exclusions of all sources apply.
+	cfgExs, err := configExclusions([]string{filepath.Join(root, config.ConfigName), filepath.Join(root, "sub", config.ConfigName)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := &Options{Exclude: []string{"flagged"}, configExclusions: cfgExs}
+	exs, err := opt.exclusions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exs) != 4 {
+		t.Fatalf("got %d exclusions, want 4", len(exs))
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"keep/a.go", false},
+		{"keep/a_gen.go", true},
+		{"vendor/v/v.go", true},
+		{"vendor", false},
+		{"vendor/v", true},
+		{"flagged", true},
+		{"flagged/f.go", true},
+		{"sub/legacy.go", true},
+		{"sub/new.go", false},
+		// legacy.go is only excluded in sub.
+		{"legacy.go", false},
+		// The roots of exclusions are never excluded.
+		{".", false},
+		{"..", false},
+	}
+	for _, tt := range tests {
+		if got := excluded(exs, tt.path); got != tt.want {
+			t.Errorf("excluded(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+
+	// Packages are excluded if their directories, or all of their
+	// files, are excluded. Tests only count if they are linted.
+	bctx := build.Default
+	bctx.GOPATH = ""
+	paths := []string{"./keep", "./vendor/v", "./gen", "./mixed", "./flagged", "./sub", "./missing"}
+	for _, tt := range []struct {
+		tests bool
+		want  []string
+	}{
+		{true, []string{"./keep", "./mixed", "./sub", "./missing"}},
+		{false, []string{"./keep", "./sub", "./missing"}},
+	} {
+		if got := excludePackages(exs, paths, &bctx, tt.tests); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tests %t: got packages %q, want %q", tt.tests, got, tt.want)
+		}
+	}
+	if got, want := excludeFiles(exs, []string{"keep/a.go", "keep/a_gen.go", "sub/new.go"}), []string{"keep/a.go", "sub/new.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %q, want %q", got, want)
+	}
+
+	// Problems in excluded files are dropped regardless of their
+	// checks. Problems without positions are kept.
+	problem := func(name, check string) lint.Problem {
+		return lint.Problem{Position: token.Position{Filename: filepath.Join(root, filepath.FromSlash(name)), Line: 1}, Check: check}
+	}
+	problems := [][]lint.Problem{
+		{problem("keep/a.go", "SA4006"), problem("keep/a_gen.go", "SA4006"), problem("sub/legacy.go", "SA1019")},
+		{problem("keep/a_gen.go", "ST1000"), {Check: "compile", Text: "no position"}},
+		{problem("flagged/f.go", "U1000")},
+	}
+	excludeProblems(exs, problems)
+	var got [][]string
+	for _, ps := range problems {
+		var checks []string
+		for _, p := range ps {
+			checks = append(checks, p.Check)
+		}
+		got = append(got, checks)
+	}
+	if want := [][]string{{"SA4006"}, {"compile"}, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got remaining problems %q, want %q", got, want)
+	}
+	if problems[0][0].Position.Filename != filepath.Join(root, "keep", "a.go") {
+		t.Errorf("kept the wrong problem: %s", problems[0][0].Position)
+	}
+
+	bad := filepath.Join(root, "bad", config.ConfigName)
+	write("bad/"+config.ConfigName, `exclude = ["/"]`)
+	if _, err := configExclusions([]string{bad}); err == nil {
+		t.Errorf("invalid pattern in configuration file: got no error")
+	} else if cerr, ok := err.(*ConfigError); !ok || cerr.Position.Filename != bad {
+		t.Errorf("got error %v, want a ConfigError in %s", err, bad)
+	}
+}
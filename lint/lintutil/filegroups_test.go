@@ -0,0 +1,139 @@
+package lintutil
+
+import (
+	"context"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+// fileChecker reports a problem in every file it checks.
+type fileChecker struct{}
+
+func (fileChecker) Name() string       { return "files" }
+func (fileChecker) Prefix() string     { return "F" }
+func (fileChecker) Init(*lint.Program) {}
+func (fileChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{"F1000": func(j *lint.Job) {
+		for _, f := range j.Program.Files {
+			j.Errorf(f, "checked")
+		}
+	}}
+}
+
+func TestGroupFiles(t *testing.T) {
+	ctx := &build.Default
+	dir, err := ioutil.TempDir("", "groupfiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"a/a.go":      "package a\n",
+		"a/b.go":      "package a\n",
+		"a/a_test.go": "package a_test\n",
+		"b/b.go":      "package a\n",
+		"b/bad.go":    "pakage b\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, filepath.FromSlash(name)) }
+
+	groups := groupFiles([]string{path("a/a.go"), path("b/b.go"), path("a/a_test.go"), path("b/bad.go"), path("a/b.go")}, ctx)
+	want := []fileGroup{
+		{name: "a", files: []string{path("a/a.go"), path("a/b.go")}},
+		{name: "a", files: []string{path("b/b.go")}},
+		{name: "a_test", files: []string{path("a/a_test.go")}},
+		{name: "", files: []string{path("b/bad.go")}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("got groups %+v, want %+v", groups, want)
+	}
+	seen := map[string]bool{}
+	for _, g := range groups {
+		p := g.path()
+		if seen[p] {
+			t.Errorf("groups share the path %q", p)
+		}
+		seen[p] = true
+		if build.IsLocalImport(p) {
+			t.Errorf("group path %q is a local import path", p)
+		}
+	}
+}
+
+func TestLintFileGroups(t *testing.T) {
+	root, err := ioutil.TempDir("", "filegroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// External tests import the package in their directory from
+	// GOPATH.
+	defer func(gopath, mode string) {
+		build.Default.GOPATH = gopath
+		os.Setenv("GOPATH", gopath)
+		os.Setenv("GO111MODULE", mode)
+	}(build.Default.GOPATH, os.Getenv("GO111MODULE"))
+	build.Default.GOPATH = root
+	os.Setenv("GOPATH", root)
+	os.Setenv("GO111MODULE", "off")
+
+	files := map[string]string{
+		"a/a.go":      "package a\n\nfunc F() {}\n",
+		"a/a_test.go": "package a_test\n\nimport \"example.com/a\"\n\nfunc G() { a.F() }\n",
+		"b/b.go":      "package b\n\nvar x int = \"s\"\n",
+		"c/c.go":      "package c\n",
+	}
+	path := func(name string) string {
+		return filepath.Join(root, "src", "example.com", filepath.FromSlash(name))
+	}
+	var args []string
+	for name, data := range files {
+		if err := os.MkdirAll(filepath.Dir(path(name)), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path(name), []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, path(name))
+	}
+	sort.Strings(args)
+
+	res, err := lintPackages(context.Background(), []lint.Checker{fileChecker{}}, args, &Options{})
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("got error %v, want one error in package b", err)
+	}
+	if gerr, ok := errs[0].(*GroupError); !ok || gerr.Package != "b" || gerr.Dir != filepath.Dir(path("b/b.go")) {
+		t.Errorf("got error %#v, want one in package b", errs[0])
+	}
+	if res == nil {
+		t.Fatal("got no results")
+	}
+	var got []string
+	for _, p := range res.problems[0] {
+		got = append(got, p.Position.Filename)
+	}
+	sort.Strings(got)
+	if want := []string{path("a/a.go"), path("a/a_test.go"), path("c/c.go")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got problems in %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goEnvFile returns the path of the go command's configuration file,
+// as written by 'go env -w', or the empty string if there is none.
+func goEnvFile() string {
+	if path := os.Getenv("GOENV"); path != "" {
+		if path == "off" {
+			return ""
+		}
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go", "env")
+}
+
+// readGoEnv parses the go command's configuration file, which consists
+// of lines of the form KEY=VALUE.
+func readGoEnv() map[string]string {
+	env := map[string]string{}
+	path := goEnvFile()
+	if path == "" {
+		return env
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		// Like the go command, treat unreadable files as empty.
+		return env
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		i := strings.Index(line, "=")
+		if i <= 0 || line[0] < 'A' || line[0] > 'Z' {
+			continue
+		}
+		env[line[:i]] = strings.TrimSpace(line[i+1:])
+	}
+	return env
+}
+
+// getenv returns the value of the go environment variable key: the
+// value from the process's environment, or from the go command's
+// configuration file if the former isn't set.
+func getenv(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return readGoEnv()[key]
+}
+
+// buildContext returns the build context to load packages with. Like
+// go build, it honours settings made with 'go env -w' that aren't
+// overridden by the environment.
+func buildContext(opt *Options) build.Context {
+	bctx := build.Default
+	bctx.BuildTags = opt.Tags
+	env := readGoEnv()
+	if os.Getenv("GOOS") == "" && env["GOOS"] != "" {
+		bctx.GOOS = env["GOOS"]
+	}
+	if os.Getenv("GOARCH") == "" && env["GOARCH"] != "" {
+		bctx.GOARCH = env["GOARCH"]
+	}
+	if os.Getenv("GOPATH") == "" && env["GOPATH"] != "" {
+		bctx.GOPATH = env["GOPATH"]
+	}
+	if os.Getenv("CGO_ENABLED") == "" && env["CGO_ENABLED"] != "" {
+		bctx.CgoEnabled = env["CGO_ENABLED"] == "1"
+	}
+	return bctx
+}
+
+// goFlag returns the value of the build flag name, as set in GOFLAGS,
+// and whether it is set. Only boolean flags may omit their values.
+func goFlag(name string, boolean bool) (string, bool) {
+	return parseGoFlag(getenv("GOFLAGS"), name, boolean)
+}
+
+// parseGoFlag returns the value of the flag name in goflags, which has
+// the syntax of GOFLAGS: flags of the form -name=value or
+// --name=value, separated by spaces. Like the go command, it allows
+// single or double quotes around flags that contain spaces.
+func parseGoFlag(goflags, name string, boolean bool) (string, bool) {
+	fields, err := splitQuoted(goflags)
+	if err != nil {
+		// The go command rejects such GOFLAGS.
+		return "", false
+	}
+	var value string
+	var ok bool
+	for _, f := range fields {
+		f = strings.TrimPrefix(strings.TrimPrefix(f, "-"), "-")
+		if f == name && boolean {
+			value, ok = "true", true
+			continue
+		}
+		if strings.HasPrefix(f, name+"=") {
+			// Later occurrences override earlier ones.
+			value, ok = f[len(name)+1:], true
+		}
+	}
+	return value, ok
+}
+
+// splitQuoted splits s into fields separated by spaces, allowing
+// single or double quotes around fields. There is no escaping inside
+// of quotes.
+func splitQuoted(s string) ([]string, error) {
+	var fields []string
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			return fields, nil
+		}
+		if s[0] == '"' || s[0] == '\'' {
+			i := strings.IndexByte(s[1:], s[0])
+			if i < 0 {
+				return nil, fmt.Errorf("unterminated %c string", s[0])
+			}
+			fields = append(fields, s[1:i+1])
+			s = s[i+2:]
+			continue
+		}
+		i := strings.IndexAny(s, " \t\n\r")
+		if i < 0 {
+			i = len(s)
+		}
+		fields = append(fields, s[:i])
+		s = s[i:]
+	}
+}
+
+// goFlagTags returns the build tags set with -tags in GOFLAGS. Like
+// the go command, it accepts comma- and space-separated lists.
+func goFlagTags() []string {
+	tags, ok := goFlag("tags", false)
+	if !ok {
+		return nil
+	}
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' })
+}
@@ -0,0 +1,72 @@
+package lintutil
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseGoFlag(t *testing.T) {
+	tests := []struct {
+		goflags string
+		name    string
+		boolean bool
+		value   string
+		ok      bool
+	}{
+		{"", "tags", false, "", false},
+		{"-mod=vendor", "tags", false, "", false},
+		{"-tags=a,b", "tags", false, "a,b", true},
+		{"--tags=a,b", "tags", false, "a,b", true},
+		{"-mod=vendor -tags=a -trimpath", "tags", false, "a", true},
+		// Later occurrences override earlier ones.
+		{"-tags=a -tags=b", "tags", false, "b", true},
+		{"-tags=", "tags", false, "", true},
+		// Only boolean flags may omit their values; the value of
+		// -tags is never a separate word.
+		{"-tags", "tags", false, "", false},
+		{"-tags a", "tags", false, "", false},
+		{"-trimpath", "trimpath", true, "true", true},
+		{"-trimpath=false", "trimpath", true, "false", true},
+		{"-tagsx=a -xtags=b", "tags", false, "", false},
+		// Quotes allow spaces in values.
+		{`"-tags=a b" -mod=vendor`, "tags", false, "a b", true},
+		{`'-tags=a b'`, "tags", false, "a b", true},
+		{`-mod=vendor '-tags=a "b"'`, "tags", false, `a "b"`, true},
+		{"\t-tags=a\n", "tags", false, "a", true},
+		// The go command rejects unterminated quotes.
+		{`-tags=a "-mod=vendor`, "tags", false, "", false},
+	}
+	for _, tt := range tests {
+		value, ok := parseGoFlag(tt.goflags, tt.name, tt.boolean)
+		if value != tt.value || ok != tt.ok {
+			t.Errorf("parseGoFlag(%q, %q): got %q, %t, want %q, %t", tt.goflags, tt.name, value, ok, tt.value, tt.ok)
+		}
+	}
+}
+
+func TestGoFlagTags(t *testing.T) {
+	defer os.Setenv("GOFLAGS", os.Getenv("GOFLAGS"))
+	tests := []struct {
+		goflags string
+		want    []string
+	}{
+		{"", nil},
+		{"-tags=", nil},
+		{"-tags=integration", []string{"integration"}},
+		{"-tags=a,b,,c", []string{"a", "b", "c"}},
+		{`"-tags=a b"`, []string{"a", "b"}},
+		{`'-tags=a, b'`, []string{"a", "b"}},
+		{"-tags a", nil},
+	}
+	for _, tt := range tests {
+		os.Setenv("GOFLAGS", tt.goflags)
+		got := goFlagTags()
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GOFLAGS=%s: got tags %q, want %q", tt.goflags, got, tt.want)
+		}
+	}
+}
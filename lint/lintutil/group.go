@@ -0,0 +1,108 @@
+package lintutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// groupings are the valid values of the -group-by flag.
+var groupings = []string{"check", "file", "package", "owner"}
+
+// A problemGroup is a set of problems that share a check, file,
+// package or owners.
+type problemGroup struct {
+	key      string
+	title    string
+	problems []lint.Problem
+}
+
+// byGroupOrder sorts groups of checks by decreasing size, so that the
+// most common problems come first, and other groups by name.
+type byGroupOrder struct {
+	groups []*problemGroup
+	bySize bool
+}
+
+func (s byGroupOrder) Len() int      { return len(s.groups) }
+func (s byGroupOrder) Swap(i, j int) { s.groups[i], s.groups[j] = s.groups[j], s.groups[i] }
+func (s byGroupOrder) Less(i, j int) bool {
+	gi, gj := s.groups[i], s.groups[j]
+	if s.bySize && len(gi.problems) != len(gj.problems) {
+		return len(gi.problems) > len(gj.problems)
+	}
+	return gi.key < gj.key
+}
+
+// groupProblems groups ps by check, file, package or owners.
+// Problems keep their order within groups. Groups of checks are
+// titled with the checks' documentation, taken from cs.
+func groupProblems(cs []lint.Checker, ps []lint.Problem, by string) []*problemGroup {
+	titles := map[string]string{}
+	if by == "check" {
+		for _, c := range cs {
+			for _, cat := range checkerDocs(c) {
+				for _, check := range cat.Checks {
+					titles[check.ID] = check.Title
+				}
+			}
+		}
+	}
+
+	groups := map[string]*problemGroup{}
+	var order []*problemGroup
+	for _, p := range ps {
+		var key string
+		switch by {
+		case "check":
+			key = p.Check
+		case "file":
+			key = shortPath(p.Position.Filename)
+		case "package":
+			if p.Package != nil {
+				key = p.Package.Path()
+			}
+		case "owner":
+			key = strings.Join(p.Owners, " ")
+		}
+		if key == "" {
+			key = "-"
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &problemGroup{key: key, title: titles[key]}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.problems = append(g.problems, p)
+	}
+	sort.Stable(byGroupOrder{order, by == "check"})
+	return order
+}
+
+// writeGrouped writes groups of problems to o, each preceded by a
+// header with the group's name and size.
+func writeGrouped(o TextOutput, groups []*problemGroup) {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(o.w)
+		}
+		header := g.key
+		if g.title != "" {
+			header += ": " + g.title
+		}
+		fmt.Fprintf(o.w, "%s (%d %s)\n", o.paint(colorBold, header), len(g.problems), pluralize(len(g.problems), "problem", "problems"))
+		for _, p := range g.problems {
+			o.Format(p)
+		}
+	}
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
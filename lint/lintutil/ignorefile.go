@@ -0,0 +1,199 @@
+package lintutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// IgnoreFileName is the name of the file that contains ignore rules
+// shared by a project.
+const IgnoreFileName = ".staticcheckignore"
+
+// A pathIgnore ignores problems in files that match a gitignore-like
+// pattern.
+type pathIgnore struct {
+	// root is the directory that contains the ignore file. Patterns
+	// are relative to it.
+	root    string
+	pattern string
+	re      *regexp.Regexp
+	checks  []string
+	// line is the line of the rule in the ignore file.
+	line int
+}
+
+func (pi *pathIgnore) Match(p lint.Problem) bool {
+	name, err := filepath.Abs(p.Position.Filename)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(pi.root, name)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	if !pi.re.MatchString(filepath.ToSlash(rel)) {
+		return false
+	}
+	for _, c := range pi.checks {
+		if lint.MatchCheck(c, p.Check) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pi *pathIgnore) String() string {
+	return fmt.Sprintf("%s %s", pi.pattern, strings.Join(pi.checks, ","))
+}
+
+// findIgnoreFile looks for an ignore file in dir and all of its
+// parents, returning the path of the first one found.
+func findIgnoreFile(dir string) (string, bool) {
+	for {
+		path := filepath.Join(dir, IgnoreFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// parseIgnoreFile parses an ignore file. Each line consists of a
+// gitignore-like pattern, optionally followed by a comma-separated
+// list of checks. If no checks are listed, all checks are ignored.
+// Empty lines and lines starting with # are skipped.
+func parseIgnoreFile(path string) ([]lint.Ignore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	root, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []lint.Ignore
+	var errs ErrorList
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &ConfigError{
+			Position: token.Position{Filename: path, Line: line},
+			Msg:      fmt.Sprintf(format, args...),
+		})
+	}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) > 2 {
+			fail(line, "malformed ignore rule")
+			continue
+		}
+		if strings.HasPrefix(fields[0], "!") {
+			fail(line, "negated patterns are not supported")
+			continue
+		}
+		checks := []string{"*"}
+		if len(fields) == 2 {
+			checks = strings.Split(fields[1], ",")
+		}
+		re, err := compileIgnorePattern(fields[0])
+		if err != nil {
+			fail(line, "%v", err)
+			continue
+		}
+		out = append(out, &pathIgnore{
+			root:    root,
+			pattern: fields[0],
+			re:      re,
+			checks:  checks,
+			line:    line,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// compileIgnorePattern translates a gitignore-like pattern into a
+// regular expression that matches slash-separated paths.
+//
+// A pattern that doesn't contain a slash, other than a trailing one,
+// matches at any depth. A trailing slash matches a directory and
+// everything in it. * and ? don't match slashes, while ** matches
+// any number of directories. A backslash makes the following
+// character match literally.
+func compileIgnorePattern(pattern string) (*regexp.Regexp, error) {
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("^")
+	if !anchored {
+		buf.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					buf.WriteString("(?:.*/)?")
+				} else {
+					buf.WriteString(".*")
+				}
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, errors.New("pattern ends with a backslash")
+			}
+			i++
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dir {
+		buf.WriteString("/.*")
+	} else {
+		// A pattern matching a directory also matches its contents.
+		buf.WriteString("(?:/.*)?")
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
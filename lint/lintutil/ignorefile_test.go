@@ -0,0 +1,134 @@
+package lintutil
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestCompileIgnorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		// Patterns without slashes match at any depth.
+		{"foo.go", []string{"foo.go", "a/foo.go", "a/b/foo.go"}, []string{"afoo.go", "foo.go.orig"}},
+		{"*.pb.go", []string{"x.pb.go", "a/b/x.pb.go", "x.pb.go/y"}, []string{"x.go", "x.pb.go.orig"}},
+		{"legacy", []string{"legacy", "legacy/a.go", "a/legacy/b/c.go"}, []string{"legacy2/a.go"}},
+		// Patterns with slashes are anchored.
+		{"internal/legacy", []string{"internal/legacy/a.go"}, []string{"a/internal/legacy/b.go"}},
+		{"/foo.go", []string{"foo.go"}, []string{"a/foo.go"}},
+		{"a/*.go", []string{"a/b.go"}, []string{"a/b/c.go", "x/a/b.go"}},
+		// A trailing slash only matches the contents of directories.
+		{"gen/", []string{"gen/a.go", "x/gen/a.go"}, []string{"gen", "gen.go"}},
+		{"a/gen/", []string{"a/gen/b/c.go"}, []string{"a/gen", "b/a/gen/c.go"}},
+		// * and ? don't match slashes, ** matches any number of
+		// directories.
+		{"a/*/c.go", []string{"a/b/c.go"}, []string{"a/c.go", "a/b/b/c.go"}},
+		{"a/?.go", []string{"a/b.go"}, []string{"a/bb.go", "a//.go"}},
+		{"a/**/c.go", []string{"a/c.go", "a/b/c.go", "a/b/b/c.go"}, []string{"x/a/c.go"}},
+		{"**/zz_generated*.go", []string{"zz_generated.go", "a/b/zz_generated.deepcopy.go"}, []string{"a/zz_generated/x.go.orig"}},
+		{"third_party/**", []string{"third_party/a.go", "third_party/a/b.go"}, []string{"a/third_party/b.go"}},
+		{"a/**", []string{"a/b", "a/b/c"}, []string{"ab/c"}},
+		// Escaped and regular expression metacharacters match
+		// literally.
+		{`\*.go`, []string{"*.go", "a/*.go"}, []string{"a.go"}},
+		{`a\?.go`, []string{"a?.go"}, []string{"ab.go"}},
+		{`\#notes.go`, []string{"#notes.go"}, []string{"notes.go"}},
+		{`\!important.go`, []string{"!important.go"}, []string{"important.go"}},
+		{"a+b.go", []string{"a+b.go"}, []string{"aab.go"}},
+		{"(x)|y.go", []string{"(x)|y.go"}, []string{"x", "y.go"}},
+		{"v1.0.go", []string{"v1.0.go"}, []string{"v1x0.go"}},
+	}
+	for _, tt := range tests {
+		re, err := compileIgnorePattern(tt.pattern)
+		if err != nil {
+			t.Errorf("compileIgnorePattern(%q): %v", tt.pattern, err)
+			continue
+		}
+		for _, path := range tt.match {
+			if !re.MatchString(path) {
+				t.Errorf("pattern %q doesn't match %q", tt.pattern, path)
+			}
+		}
+		for _, path := range tt.noMatch {
+			if re.MatchString(path) {
+				t.Errorf("pattern %q matches %q", tt.pattern, path)
+			}
+		}
+	}
+
+	for _, pattern := range []string{"", "/", `a\`} {
+		if _, err := compileIgnorePattern(pattern); err == nil {
+			t.Errorf("compileIgnorePattern(%q) succeeded, want error", pattern)
+		}
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignorefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, IgnoreFileName)
+	write := func(data string) {
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("# comment\n\ninternal/legacy/** SA1019,ST1000\n*_generated.go\n")
+	igs, err := parseIgnoreFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	problem := func(name, check string) lint.Problem {
+		return lint.Problem{
+			Position: token.Position{Filename: filepath.Join(dir, filepath.FromSlash(name))},
+			Check:    check,
+		}
+	}
+	tests := []struct {
+		problem lint.Problem
+		want    bool
+	}{
+		{problem("internal/legacy/a/b.go", "SA1019"), true},
+		{problem("internal/legacy/a.go", "ST1000"), true},
+		{problem("internal/legacy/a.go", "SA4006"), false},
+		{problem("internal/a.go", "SA1019"), false},
+		{problem("a/b/x_generated.go", "SA4006"), true},
+		{problem("x_generated.go", "ST1000"), true},
+		{problem("../x_generated.go", "ST1000"), false},
+	}
+	for _, tt := range tests {
+		got := false
+		for _, ig := range igs {
+			if ig.Match(tt.problem) {
+				got = true
+			}
+		}
+		if got != tt.want {
+			t.Errorf("problem of %s in %s: got ignored = %t, want %t", tt.problem.Check, tt.problem.Position.Filename, got, tt.want)
+		}
+	}
+
+	bad := map[string]string{
+		"!internal/legacy/keep.go\n": "negated patterns are not supported",
+		"a.go SA1019 ST1000\n":       "malformed ignore rule",
+		"a\\\n":                      "pattern ends with a backslash",
+	}
+	for data, want := range bad {
+		write("ok.go\n" + data)
+		_, err := parseIgnoreFile(path)
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), ":2:") {
+			t.Errorf("parsing %q: got error %v, want one on line 2 containing %q", data, err, want)
+		}
+	}
+}
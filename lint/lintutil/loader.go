@@ -0,0 +1,99 @@
+package lintutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// A Loader loads the packages that are to be linted, allowing
+// packages to come from sources other than GOPATH, such as build
+// systems or test fixtures.
+type Loader interface {
+	// Load loads the packages named by pkgs, honouring the relevant
+	// options in opt, such as Tags, LintTests and Overlay. It
+	// returns the program and the configuration it was loaded with.
+	Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error)
+}
+
+// loader returns the loader of opt.
+func (opt *Options) loader() Loader {
+	switch {
+	case opt.Loader != nil:
+		return opt.Loader
+	case opt.PackageSpec != "":
+		return SpecLoader{Path: opt.PackageSpec}
+	default:
+		return GOPATHLoader{}
+	}
+}
+
+// GOPATHLoader loads packages from GOPATH, as well as ad hoc packages
+// named by lists of files. It is the default loader.
+type GOPATHLoader struct{}
+
+func (GOPATHLoader) Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	return loadPackages(ctx, pkgs, opt)
+}
+
+// SpecLoader loads the packages described by a package spec, as used
+// by the -package-spec flag. See Options.PackageSpec.
+type SpecLoader struct {
+	// Path is the path of the spec; "-" reads it from standard input.
+	Path string
+}
+
+func (l SpecLoader) Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	if len(pkgs) != 0 {
+		return nil, nil, errors.New("packages can't be named when using a package spec")
+	}
+	return loadSpec(ctx, l.Path, opt)
+}
+
+// MemoryLoader loads packages from sources held in memory, which is
+// useful for tests. The packages live in a GOPATH of their own, which
+// doesn't exist on disk; the standard library is loaded from GOROOT
+// as usual. Packages must be named by import path, not by pattern.
+type MemoryLoader struct {
+	// Files maps the names of files, relative to the src directory
+	// of the GOPATH, such as "example.com/pkg/pkg.go", to their
+	// contents.
+	Files map[string]string
+}
+
+// memoryRoot is the GOPATH of packages loaded by MemoryLoader.
+var memoryRoot = filepath.Join(string(filepath.Separator), "lintutil-memory")
+
+func (l MemoryLoader) Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	files := map[string][]byte{}
+	dirs := map[string]bool{}
+	for name, src := range l.Files {
+		path := filepath.Join(memoryRoot, "src", filepath.FromSlash(name))
+		files[path] = []byte(src)
+		for dir := filepath.Dir(path); dir != memoryRoot; dir = filepath.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	for path, src := range opt.Overlay {
+		files[path] = src
+	}
+
+	bctx := buildContext(opt)
+	bctx.GOPATH = memoryRoot
+	applyOverlay(&bctx, files)
+	bctx.IsDir = func(path string) bool {
+		if dirs[path] {
+			return true
+		}
+		if path == memoryRoot || strings.HasPrefix(path, memoryRoot+string(filepath.Separator)) {
+			return false
+		}
+		fi, err := os.Stat(path)
+		return err == nil && fi.IsDir()
+	}
+	return loadFrom(ctx, &bctx, pkgs, opt)
+}
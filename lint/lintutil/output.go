@@ -0,0 +1,220 @@
+package lintutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// formatList is the value of the -f flag, which may be given multiple
+// times to produce several outputs from a single run.
+type formatList struct {
+	specs []string
+	set   bool
+}
+
+func (l *formatList) String() string {
+	return strings.Join(l.specs, ",")
+}
+
+func (l *formatList) Set(s string) error {
+	if _, err := parseOutputSpec(s); err != nil {
+		return err
+	}
+	if !l.set {
+		// The first use of the flag replaces the default.
+		l.specs = nil
+		l.set = true
+	}
+	l.specs = append(l.specs, s)
+	return nil
+}
+
+func (l *formatList) Get() interface{} {
+	return l.specs
+}
+
+// An outputSpec describes one output of a run: a format and the file
+// to write it to, which is empty for standard output.
+type outputSpec struct {
+	format string
+	path   string
+}
+
+var outputFormats = []string{"text", "json", "jsonl", "summary"}
+
+// parseOutputSpec parses an output in the form format[=file].
+func parseOutputSpec(s string) (outputSpec, error) {
+	spec := outputSpec{format: s}
+	if i := strings.Index(s, "="); i != -1 {
+		spec = outputSpec{format: s[:i], path: s[i+1:]}
+		if spec.path == "" {
+			return outputSpec{}, fmt.Errorf("missing file name in output %q", s)
+		}
+	}
+	for _, f := range outputFormats {
+		if spec.format == f {
+			return spec, nil
+		}
+	}
+	return outputSpec{}, fmt.Errorf("unsupported output format %q", spec.format)
+}
+
+// An output is a destination for problems: a file or standard
+// output. Writes are buffered, and flushed after each problem, so
+// that output can be followed while a run is writing it.
+type output struct {
+	spec outputSpec
+	w    *bufio.Writer
+	// file is the file that w writes to.
+	file *os.File
+	// closer closes file; it is nil for standard output and for
+	// outputs that share the file of an earlier output.
+	closer io.Closer
+}
+
+// openOutputs creates the files of the outputs described by specs.
+// Files are created before linting, so that unwritable files are
+// reported early. Outputs that name the same file, or both use
+// standard output, share a writer and are written one after another.
+func openOutputs(specs []outputSpec) ([]output, error) {
+	var outs []output
+	shared := map[string]output{}
+	for _, spec := range specs {
+		if o, ok := shared[spec.path]; ok {
+			outs = append(outs, output{spec: spec, w: o.w, file: o.file})
+			continue
+		}
+		o := output{spec: spec, file: os.Stdout}
+		if spec.path != "" {
+			f, err := os.Create(spec.path)
+			if err != nil {
+				closeOutputs(outs)
+				return nil, err
+			}
+			o.file, o.closer = f, f
+		}
+		o.w = bufio.NewWriter(o.file)
+		shared[spec.path] = o
+		outs = append(outs, o)
+	}
+	return outs, nil
+}
+
+// formatterOptions configures the formatters of outputs.
+type formatterOptions struct {
+	// metadata is called for formats that start with a description
+	// of the run.
+	metadata func() Metadata
+	// text holds the settings of text output.
+	text TextOutput
+	// color is the value of the -color flag.
+	color string
+	// previousSummary holds the counts of an earlier summary, which
+	// summaries are compared against.
+	previousSummary map[string]int
+}
+
+// formatter returns the OutputFormatter of o.
+func (o output) formatter(opts formatterOptions) OutputFormatter {
+	switch o.spec.format {
+	case "json":
+		return JSONOutput{o.w}
+	case "jsonl":
+		f := JSONLinesOutput{o.w}
+		f.Metadata(opts.metadata())
+		return f
+	case "summary":
+		return &SummaryOutput{w: o.w, previous: opts.previousSummary, counts: map[string]int{}}
+	default:
+		text := opts.text
+		text.w = o.w
+		text.color = useColor(opts.color, o.file)
+		return text
+	}
+}
+
+// NewFormatter returns a formatter that writes problems to w in the
+// given format, which is one of "text", "json", "jsonl" and "summary".
+// Text is written without colors. JSON Lines output lacks the initial
+// description of the run, which callers may write with Metadata.
+// Summaries are only written when their Flush method is called.
+func NewFormatter(format string, w io.Writer) (OutputFormatter, error) {
+	switch format {
+	case "text":
+		return TextOutput{w: w}, nil
+	case "json":
+		return JSONOutput{w}, nil
+	case "jsonl":
+		return JSONLinesOutput{w}, nil
+	case "summary":
+		return &SummaryOutput{w: w, counts: map[string]int{}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// colorModes are the valid values of the -color flag.
+var colorModes = []string{"always", "never", "auto"}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+func severityColor(severity string) string {
+	switch severity {
+	case "warning":
+		return colorYellow
+	case "info":
+		return colorCyan
+	default:
+		return colorRed
+	}
+}
+
+// useColor reports whether text written to w should be colored,
+// according to the -color flag. In auto mode, only terminals get
+// colors, and setting the environment variable NO_COLOR disables
+// them.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// closeOutputs flushes the outputs outs and closes their files,
+// returning the first error.
+func closeOutputs(outs []output) error {
+	var first error
+	for _, o := range outs {
+		if err := o.w.Flush(); err != nil && first == nil {
+			first = err
+		}
+		if o.closer == nil {
+			continue
+		}
+		if err := o.closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
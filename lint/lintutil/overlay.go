@@ -0,0 +1,104 @@
+package lintutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// readOverlay reads an overlay file in the format of the -overlay flag
+// of go build: a JSON object whose Replace field maps the paths of
+// files to the paths of files with their replacement contents. It
+// returns the replacement contents, keyed by absolute path.
+func readOverlay(path string) (map[string][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overlay struct {
+		Replace map[string]string
+	}
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	out := map[string][]byte{}
+	for from, to := range overlay.Replace {
+		if to == "" {
+			return nil, fmt.Errorf("%s: deleting %s is not supported", path, from)
+		}
+		abs, err := filepath.Abs(from)
+		if err != nil {
+			return nil, err
+		}
+		src, err := ioutil.ReadFile(to)
+		if err != nil {
+			return nil, err
+		}
+		out[abs] = src
+	}
+	return out, nil
+}
+
+// applyOverlay makes bctx use the contents of files in overlay
+// instead of those on disk. Files in the overlay that don't exist on
+// disk are added to their directories.
+func applyOverlay(bctx *build.Context, overlay map[string][]byte) {
+	if len(overlay) == 0 {
+		return
+	}
+	bctx.OpenFile = func(path string) (io.ReadCloser, error) {
+		if abs, err := filepath.Abs(path); err == nil {
+			if src, ok := overlay[abs]; ok {
+				return ioutil.NopCloser(bytes.NewReader(src)), nil
+			}
+		}
+		return os.Open(path)
+	}
+	bctx.ReadDir = func(dir string) ([]os.FileInfo, error) {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for i, fi := range fis {
+			if src, ok := overlay[filepath.Join(abs, fi.Name())]; ok {
+				fis[i] = overlayFileInfo{fi.Name(), int64(len(src))}
+			}
+			seen[fi.Name()] = true
+		}
+		added := false
+		for path, src := range overlay {
+			if filepath.Dir(path) == abs && !seen[filepath.Base(path)] {
+				fis = append(fis, overlayFileInfo{filepath.Base(path), int64(len(src))})
+				added = true
+			}
+		}
+		if err != nil && !added {
+			return nil, err
+		}
+		return fis, nil
+	}
+}
+
+// overlayFileInfo describes a file in an overlay.
+type overlayFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi overlayFileInfo) Name() string       { return fi.name }
+func (fi overlayFileInfo) Size() int64        { return fi.size }
+func (fi overlayFileInfo) Mode() os.FileMode  { return 0444 }
+func (fi overlayFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi overlayFileInfo) IsDir() bool        { return false }
+func (fi overlayFileInfo) Sys() interface{}   { return nil }
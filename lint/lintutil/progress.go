@@ -0,0 +1,187 @@
+package lintutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// A ProgressEvent describes the progress of a run.
+type ProgressEvent struct {
+	// Phase is "load" when a package has been loaded and "lint" when
+	// a checker has started or finished a check. The -progress flag
+	// also writes an event of the phase "done" at the end of a run.
+	Phase string `json:"phase"`
+	// Package is the import path of the package that was loaded.
+	Package string `json:"package,omitempty"`
+	// Checker is the name of the checker that is running.
+	Checker string `json:"checker,omitempty"`
+	// Done is the number of packages loaded so far, or the number of
+	// checks the checker has finished.
+	Done int `json:"done"`
+	// Total is the number of checks the checker runs. It is zero
+	// while loading, as the number of packages isn't known in
+	// advance.
+	Total int `json:"total,omitempty"`
+}
+
+// progressHook makes conf report the packages it loads to
+// opt.Progress.
+func (opt *Options) progressHook(conf *loader.Config) {
+	if opt.Progress == nil {
+		return
+	}
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	conf.AfterTypeCheck = func(info *loader.PackageInfo, files []*ast.File) {
+		mu.Lock()
+		defer mu.Unlock()
+		// Packages are type-checked again when their tests are
+		// added to them.
+		path := info.Pkg.Path()
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		opt.Progress(ProgressEvent{Phase: "load", Package: path, Done: len(seen)})
+	}
+}
+
+// A progressWriter writes progress events to standard error, for the
+// -progress flag.
+type progressWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+	// mode is "json" for JSON Lines, "tty" for a status line that is
+	// rewritten in place, or "text" for plain lines.
+	mode  string
+	start time.Time
+	// checkerStart is the time the current checker started.
+	checkerStart time.Time
+	// loaded is the number of loaded packages.
+	loaded int
+	// line is set if the status line has content that must be
+	// cleared.
+	line bool
+}
+
+// newProgressWriter returns a writer of progress events to w. Events
+// are written as JSON Lines if asJSON is set, and as a status line
+// on terminals.
+func newProgressWriter(w io.Writer, asJSON bool) *progressWriter {
+	mode := "text"
+	if asJSON {
+		mode = "json"
+	} else if f, ok := w.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			mode = "tty"
+		}
+	}
+	return &progressWriter{w: w, mode: mode, start: time.Now()}
+}
+
+func (pw *progressWriter) Event(ev ProgressEvent) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	now := time.Now()
+	if ev.Phase == "lint" && ev.Done == 0 {
+		pw.checkerStart = now
+	}
+	switch pw.mode {
+	case "json":
+		pw.writeJSON(ev, now)
+	case "tty":
+		switch ev.Phase {
+		case "load":
+			pw.loaded = ev.Done
+			pw.status(fmt.Sprintf("loading packages: %d loaded, %s", ev.Done, ev.Package))
+		case "lint":
+			pw.status(fmt.Sprintf("%s %s %d/%d%s", ev.Checker, progressBar(ev.Done, ev.Total, 30), ev.Done, ev.Total, eta(now.Sub(pw.checkerStart), ev.Done, ev.Total)))
+		}
+	default:
+		switch ev.Phase {
+		case "load":
+			pw.loaded = ev.Done
+		case "lint":
+			if ev.Done == 0 {
+				if pw.loaded > 0 {
+					fmt.Fprintf(pw.w, "loaded %d %s in %s\n", pw.loaded, pluralize(pw.loaded, "package", "packages"), roundDuration(now.Sub(pw.start)))
+					pw.loaded = 0
+				}
+				fmt.Fprintf(pw.w, "%s: running %d checks\n", ev.Checker, ev.Total)
+			} else if ev.Done == ev.Total {
+				fmt.Fprintf(pw.w, "%s: finished %d checks in %s\n", ev.Checker, ev.Total, roundDuration(now.Sub(pw.checkerStart)))
+			}
+		}
+	}
+}
+
+// status replaces the status line with s. Long lines are truncated,
+// as lines that wrap can't be replaced.
+func (pw *progressWriter) status(s string) {
+	if len(s) > 79 {
+		s = s[:79]
+	}
+	fmt.Fprintf(pw.w, "\r\x1b[K%s", s)
+	pw.line = true
+}
+
+// Finish ends the progress report, clearing the status line so that
+// it doesn't mix with other output.
+func (pw *progressWriter) Finish() {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.line {
+		fmt.Fprint(pw.w, "\r\x1b[K")
+		pw.line = false
+	}
+	if pw.mode == "json" {
+		pw.writeJSON(ProgressEvent{Phase: "done"}, time.Now())
+	}
+}
+
+// writeJSON writes ev as a line of JSON, recording the seconds since
+// the start of the run.
+func (pw *progressWriter) writeJSON(ev ProgressEvent, now time.Time) {
+	type event struct {
+		ProgressEvent
+		Elapsed float64 `json:"elapsed"`
+	}
+	b, _ := json.Marshal(event{ev, now.Sub(pw.start).Seconds()})
+	fmt.Fprintf(pw.w, "%s\n", b)
+}
+
+// progressBar returns a bar of the given width, filled to the
+// fraction done/total.
+func progressBar(done, total, width int) string {
+	n := width
+	if total > 0 {
+		n = width * done / total
+	}
+	return "[" + strings.Repeat("=", n) + strings.Repeat(" ", width-n) + "]"
+}
+
+// eta estimates the remaining time of a phase that has taken elapsed
+// so far, assuming that the remaining steps take as long as the
+// finished ones did on average.
+func eta(elapsed time.Duration, done, total int) string {
+	if done == 0 || done >= total {
+		return ""
+	}
+	remaining := elapsed / time.Duration(done) * time.Duration(total-done)
+	return " ETA " + roundDuration(remaining).String()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d / time.Millisecond * time.Millisecond
+	}
+	return d / (100 * time.Millisecond) * (100 * time.Millisecond)
+}
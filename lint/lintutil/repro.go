@@ -0,0 +1,382 @@
+package lintutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/version"
+)
+
+// A reproBundle describes the files of a reproduction bundle, which
+// are laid out like a GOPATH so that the packages can be linted
+// without the rest of the project.
+type reproBundle struct {
+	// roots are the source directories of GOPATH workspaces that
+	// contain the bundled packages.
+	roots []string
+	// files maps the absolute paths of bundled files to their names
+	// in the bundle.
+	files map[string]string
+}
+
+// name returns the name of the file path in the bundle: its path in a
+// GOPATH's src directory, or its absolute path if it isn't in one.
+func (b *reproBundle) name(path string) string {
+	best := ""
+	for _, root := range b.roots {
+		if strings.HasPrefix(path, root+string(filepath.Separator)) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return "files/" + strings.TrimPrefix(filepath.ToSlash(path), "/")
+	}
+	rel, _ := filepath.Rel(best, path)
+	return "src/" + filepath.ToSlash(rel)
+}
+
+// add adds the file path to the bundle.
+func (b *reproBundle) add(path string) {
+	if _, ok := b.files[path]; !ok {
+		b.files[path] = b.name(path)
+	}
+}
+
+// addParents adds the files called name in dir and its parents to the
+// bundle. If first is set, only the innermost one is added.
+func (b *reproBundle) addParents(dir, name string, first bool) {
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			b.add(path)
+			if first {
+				return
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return
+		}
+		dir = parent
+	}
+}
+
+// reproPackages returns the packages that have to be bundled to
+// reproduce the problems ps: the packages that contain problems, or
+// all initial packages if none do, and the packages they depend on,
+// except for those in GOROOT.
+func reproPackages(lprog *loader.Program, ps []lint.Problem, goroot string) []*loader.PackageInfo {
+	files := map[string]bool{}
+	for _, p := range ps {
+		if abs, err := filepath.Abs(p.Position.Filename); err == nil && p.Position.Filename != "" {
+			files[abs] = true
+		}
+	}
+	var roots []*loader.PackageInfo
+	for _, pkg := range lprog.InitialPackages() {
+		for _, f := range pkg.Files {
+			if files[lprog.Fset.File(f.Pos()).Name()] {
+				roots = append(roots, pkg)
+				break
+			}
+		}
+	}
+	if len(roots) == 0 {
+		roots = lprog.InitialPackages()
+	}
+
+	goroot = filepath.Join(goroot, "src") + string(filepath.Separator)
+	seen := map[*types.Package]bool{}
+	var out []*loader.PackageInfo
+	var add func(pkg *types.Package)
+	add = func(pkg *types.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		info := lprog.AllPackages[pkg]
+		if info == nil || len(info.Files) == 0 || strings.HasPrefix(lprog.Fset.File(info.Files[0].Pos()).Name(), goroot) {
+			return
+		}
+		out = append(out, info)
+		for _, imp := range pkg.Imports() {
+			add(imp)
+		}
+	}
+	for _, pkg := range roots {
+		add(pkg.Pkg)
+	}
+	return out
+}
+
+// writeRepro writes a zip file to path that contains what is needed
+// to reproduce the problems ps, found by running command on pkgs:
+// the source files of the packages that contain the problems and of
+// their dependencies, go.mod files, configuration files, and a
+// description of the run. If strip is set, the bodies of functions
+// without problems are removed, to keep unrelated code out of bug
+// reports.
+func writeRepro(ctx context.Context, path, command string, ps []lint.Problem, pkgs []string, opt *Options, strip bool) error {
+	lprog, _, err := opt.loader().Load(ctx, pkgs, opt)
+	if err != nil {
+		return err
+	}
+	bctx := buildContext(opt)
+	infos := reproPackages(lprog, ps, bctx.GOROOT)
+
+	b := &reproBundle{files: map[string]string{}}
+	var dirs []string
+	for _, info := range infos {
+		dir := filepath.Dir(lprog.Fset.File(info.Files[0].Pos()).Name())
+		dirs = append(dirs, dir)
+		ipath := filepath.FromSlash(strings.TrimSuffix(info.Pkg.Path(), "_test"))
+		if strings.HasSuffix(dir, string(filepath.Separator)+ipath) {
+			b.roots = append(b.roots, strings.TrimSuffix(dir, string(filepath.Separator)+ipath))
+		}
+	}
+	var src []string
+	for _, info := range infos {
+		for _, f := range info.Files {
+			name := lprog.Fset.File(f.Pos()).Name()
+			if _, ok := opt.Overlay[name]; !ok {
+				if _, err := os.Stat(name); err != nil {
+					// Files generated by cgo
+					continue
+				}
+			}
+			src = append(src, name)
+			b.add(name)
+		}
+	}
+	wd, _ := os.Getwd()
+	if wd != "" {
+		dirs = append(dirs, wd)
+	}
+	for _, dir := range dirs {
+		b.addParents(dir, "go.mod", true)
+		b.addParents(dir, config.ConfigName, false)
+	}
+	if opt.IgnoreFile != "" {
+		if abs, err := filepath.Abs(opt.IgnoreFile); err == nil {
+			b.add(abs)
+		}
+	}
+
+	// keep are the lines of the files that problems refer to.
+	keep := map[string]map[int]bool{}
+	mark := func(pos token.Position) {
+		abs, err := filepath.Abs(pos.Filename)
+		if err != nil || pos.Filename == "" {
+			return
+		}
+		if keep[abs] == nil {
+			keep[abs] = map[int]bool{}
+		}
+		keep[abs][pos.Line] = true
+	}
+	for _, p := range ps {
+		mark(p.Position)
+		mark(p.End)
+		for _, r := range p.Related {
+			mark(r.Position)
+		}
+	}
+	isSource := map[string]bool{}
+	for _, name := range src {
+		isSource[name] = true
+	}
+	pkgNames := map[string]string{}
+	for pkg := range lprog.AllPackages {
+		pkgNames[pkg.Path()] = pkg.Name()
+	}
+
+	var names []string
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(out)
+	for _, name := range names {
+		data, ok := opt.Overlay[name]
+		if !ok {
+			data, err = ioutil.ReadFile(name)
+			if err != nil {
+				out.Close()
+				return err
+			}
+		}
+		if strip && isSource[name] {
+			data, err = stripBodies(data, keep[name], pkgNames)
+			if err != nil {
+				out.Close()
+				return fmt.Errorf("%s: %s", name, err)
+			}
+		}
+		w, err := zw.Create(b.files[name])
+		if err != nil {
+			out.Close()
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			out.Close()
+			return err
+		}
+	}
+
+	w, err := zw.Create("repro.txt")
+	if err != nil {
+		out.Close()
+		return err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Reproduction bundle written by %s %s, built with %s.\n\n", filepath.Base(os.Args[0]), version.Version, runtime.Version())
+	fmt.Fprintf(&buf, "Command:    %s\n", command)
+	if wd != "" {
+		fmt.Fprintf(&buf, "Directory:  %s\n", b.name(wd))
+	}
+	fmt.Fprintf(&buf, "Go version: 1.%d\n", opt.GoVersion)
+	fmt.Fprintf(&buf, "Platform:   %s/%s\n", bctx.GOOS, bctx.GOARCH)
+	fmt.Fprintf(&buf, "Build tags: %s\n", strings.Join(opt.Tags, " "))
+	fmt.Fprintf(&buf, "Tests:      %t\n", opt.LintTests)
+	fmt.Fprintf(&buf, "\nTo reproduce the problems, unpack the bundle, set GOPATH to the\n"+
+		"directory it was unpacked into, change into the directory above\n"+
+		"and run the command.\n")
+	if strip {
+		fmt.Fprintf(&buf, "\nThe bodies of functions without problems have been removed.\n")
+	}
+	fmt.Fprintf(&buf, "\nProblems:\n")
+	for _, p := range ps {
+		pos := p.Position
+		if pos.Filename != "" {
+			if abs, err := filepath.Abs(pos.Filename); err == nil {
+				pos.Filename = b.name(abs)
+			}
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", pos, p.String())
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// An edit replaces the bytes between start and end with text.
+type edit struct {
+	start, end int
+	text       string
+}
+
+type byStart []edit
+
+func (s byStart) Len() int           { return len(s) }
+func (s byStart) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byStart) Less(i, j int) bool { return s[i].start < s[j].start }
+
+// stripBodies replaces the bodies of the function declarations in src
+// that don't contain any of the lines in keep with panics, and blanks
+// the imports that are no longer used. pkgNames maps import paths to
+// package names.
+func stripBodies(src []byte, keep map[int]bool, pkgNames map[string]string) ([]byte, error) {
+	apply := func(src []byte, edits []edit) []byte {
+		sort.Sort(sort.Reverse(byStart(edits)))
+		for _, e := range edits {
+			src = append(src[:e.start], append([]byte(e.text), src[e.end:]...)...)
+		}
+		return src
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var edits []edit
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+		used := false
+		for line := start; line <= end; line++ {
+			if keep[line] {
+				used = true
+				break
+			}
+		}
+		if !used {
+			// Keep the line breaks, so that the positions of
+			// problems don't change.
+			start, end := fset.Position(fn.Body.Lbrace).Offset+1, fset.Position(fn.Body.Rbrace).Offset
+			lines := strings.Repeat("\n", bytes.Count(src[start:end], []byte("\n")))
+			if lines == "" {
+				lines = " "
+			}
+			edits = append(edits, edit{start, end, ` panic("removed")` + lines})
+		}
+	}
+	src = apply(append([]byte(nil), src...), edits)
+
+	fset = token.NewFileSet()
+	f, err = parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	// Package names aren't resolved by the parser.
+	refs := map[string]bool{}
+	ast.Inspect(f, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && id.Obj == nil {
+				refs[id.Name] = true
+			}
+		}
+		return true
+	})
+	edits = nil
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path == "C" {
+			continue
+		}
+		name := pkgNames[path]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "" || name == "_" || name == "." || refs[name] {
+			continue
+		}
+		if spec.Name != nil {
+			edits = append(edits, edit{fset.Position(spec.Name.Pos()).Offset, fset.Position(spec.Name.End()).Offset, "_"})
+		} else {
+			off := fset.Position(spec.Path.Pos()).Offset
+			edits = append(edits, edit{off, off, "_ "})
+		}
+	}
+	return apply(src, edits), nil
+}
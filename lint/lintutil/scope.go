@@ -0,0 +1,178 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+// A scopeResolver determines the scopes of packages, caching the
+// results by directory.
+type scopeResolver struct {
+	opt *Options
+
+	mu     sync.Mutex
+	scopes map[string]*lint.Scope
+}
+
+func newScopeResolver(opt *Options) *scopeResolver {
+	return &scopeResolver{opt: opt, scopes: map[string]*lint.Scope{}}
+}
+
+// scope returns the scope of the package in dir, or nil if the
+// package uses the configuration of the project directory.
+func (r *scopeResolver) scope(dir string) (*lint.Scope, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.scopes[dir]; ok {
+		return s, nil
+	}
+	sc, err := config.LoadScoped(dir)
+	if err != nil {
+		return nil, configError(err)
+	}
+	var s *lint.Scope
+	if len(sc.Files) > 0 && sc.Files[len(sc.Files)-1] != r.opt.configPath {
+		checks, err := r.opt.scopeChecks(sc)
+		if err != nil {
+			return nil, err
+		}
+		s = &lint.Scope{Checks: checks, Config: sc.Config}
+	}
+	r.scopes[dir] = s
+	return s, nil
+}
+
+// printConfig prints the effective configuration of the package at
+// path, for debugging configuration files.
+func printConfig(w io.Writer, cs []lint.Checker, path string, opt *Options) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	bctx := buildContext(opt)
+	bp, err := bctx.Import(path, cwd, build.FindOnly)
+	if err != nil {
+		return err
+	}
+	sc, err := config.LoadScoped(bp.Dir)
+	if err != nil {
+		return configError(err)
+	}
+	cfg, checks := opt.Config, opt.Checks
+	if opt.scoped {
+		s, err := newScopeResolver(opt).scope(bp.Dir)
+		if err != nil {
+			return err
+		}
+		if s != nil {
+			cfg, checks = s.Config, s.Checks
+		}
+	}
+
+	fmt.Fprintf(w, "package: %s\n", bp.ImportPath)
+	fmt.Fprintf(w, "directory: %s\n", bp.Dir)
+	if len(sc.Files) == 0 {
+		fmt.Fprintf(w, "files: none\n")
+	} else {
+		fmt.Fprintf(w, "files, from outermost to innermost:\n")
+		for _, f := range sc.Files {
+			fmt.Fprintf(w, "\t%s\n", f)
+		}
+	}
+	if opt.Profile != "" {
+		fmt.Fprintf(w, "profile: %s\n", opt.Profile)
+	}
+	fmt.Fprintf(w, "checks: %s\n", strings.Join(checks, ","))
+	fmt.Fprintf(w, "enabled checks: %s\n", strings.Join(enabledChecks(cs, checks), " "))
+	fmt.Fprintf(w, "dictionary: %s\n", strings.Join(cfg.Dictionary, " "))
+	if len(cfg.ImportGroups) > 0 {
+		fmt.Fprintf(w, "import groups: %s\n", strings.Join(cfg.ImportGroups, " "))
+	}
+	if cfg.Exhaustive != "" {
+		fmt.Fprintf(w, "exhaustive: %s\n", cfg.Exhaustive)
+	}
+	if len(cfg.ExhaustiveFields) > 0 {
+		fmt.Fprintf(w, "exhaustive fields: %s\n", strings.Join(cfg.ExhaustiveFields, " "))
+	}
+	if cfg.ErrorWrapping != "" {
+		fmt.Fprintf(w, "error wrapping: %s\n", cfg.ErrorWrapping)
+	}
+	if len(cfg.OpaqueErrorPackages) > 0 {
+		fmt.Fprintf(w, "opaque error packages: %s\n", strings.Join(cfg.OpaqueErrorPackages, " "))
+	}
+	if len(cfg.InsecureTLSPackages) > 0 {
+		fmt.Fprintf(w, "insecure TLS packages: %s\n", strings.Join(cfg.InsecureTLSPackages, " "))
+	}
+	if len(cfg.SQLQuoteFunctions) > 0 {
+		fmt.Fprintf(w, "SQL quote functions: %s\n", strings.Join(cfg.SQLQuoteFunctions, " "))
+	}
+	if len(cfg.Exclude) > 0 {
+		fmt.Fprintf(w, "exclude: %s\n", strings.Join(cfg.Exclude, " "))
+	}
+	if len(cfg.UnmarshalFunctions) > 0 {
+		fmt.Fprintf(w, "unmarshal functions: %s\n", strings.Join(cfg.UnmarshalFunctions, " "))
+	}
+	if len(cfg.InitAllowedFunctions) > 0 {
+		fmt.Fprintf(w, "init allowed functions: %s\n", strings.Join(cfg.InitAllowedFunctions, " "))
+	}
+	if len(cfg.MutableGlobals) > 0 {
+		fmt.Fprintf(w, "mutable globals: %s\n", strings.Join(cfg.MutableGlobals, " "))
+	}
+	if len(cfg.SecretNames) > 0 {
+		fmt.Fprintf(w, "secret names: %s\n", strings.Join(cfg.SecretNames, " "))
+	}
+	if len(cfg.MustUseFunctions) > 0 {
+		fmt.Fprintf(w, "must-use functions: %s\n", strings.Join(cfg.MustUseFunctions, " "))
+	}
+	for _, rule := range cfg.ImportRules {
+		fmt.Fprintf(w, "import rule:")
+		if len(rule.Forbid) > 0 {
+			fmt.Fprintf(w, " forbid %s", strings.Join(rule.Forbid, " "))
+		}
+		if len(rule.Allow) > 0 {
+			fmt.Fprintf(w, " allow %s", strings.Join(rule.Allow, " "))
+		}
+		if len(rule.Except) > 0 {
+			fmt.Fprintf(w, " except %s", strings.Join(rule.Except, " "))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	if cfg.MaxFunctionStatements > 0 {
+		fmt.Fprintf(w, "max function statements: %d\n", cfg.MaxFunctionStatements)
+	}
+	if cfg.MaxFunctionLines > 0 {
+		fmt.Fprintf(w, "max function lines: %d\n", cfg.MaxFunctionLines)
+	}
+	if cfg.MaxFileLines > 0 {
+		fmt.Fprintf(w, "max file lines: %d\n", cfg.MaxFileLines)
+	}
+	if cfg.TodoPattern != "" {
+		fmt.Fprintf(w, "TODO pattern: %s\n", cfg.TodoPattern)
+	}
+	if cfg.IgnoreReasonMinLength > 0 {
+		fmt.Fprintf(w, "ignore reason min length: %d\n", cfg.IgnoreReasonMinLength)
+	}
+	if cfg.IgnoreReasonPattern != "" {
+		fmt.Fprintf(w, "ignore reason pattern: %s\n", cfg.IgnoreReasonPattern)
+	}
+	if len(opt.Severity) > 0 {
+		var patterns []string
+		for pattern := range opt.Severity {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		fmt.Fprintf(w, "severity:\n")
+		for _, pattern := range patterns {
+			fmt.Fprintf(w, "\t%s = %s\n", pattern, opt.Severity[pattern])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package lintutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// A Session keeps loaded programs in memory, so that linting the same
+// packages again doesn't require loading them again, unless any of
+// their files changed. It is meant for long-running processes, such
+// as editor integrations.
+type Session struct {
+	mu       sync.Mutex
+	programs map[string]*cachedProgram
+}
+
+// A cachedProgram is a loaded program and the modification times of
+// all of its files and directories at the time it was loaded.
+type cachedProgram struct {
+	lprog  *loader.Program
+	conf   *loader.Config
+	mtimes map[string]time.Time
+}
+
+func NewSession() *Session {
+	return &Session{programs: map[string]*cachedProgram{}}
+}
+
+// Lint is like LintContext, but reuses previously loaded programs.
+// Relative paths in pkgs are resolved relative to the current
+// working directory, which must not change during the session. If
+// pkgs are files of several packages, the problems of the packages
+// that could be linted are returned even if others failed to load.
+func (s *Session) Lint(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) ([][]lint.Problem, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	if opt.Loader == nil && opt.PackageSpec == "" {
+		if groups := splitFileGroups(pkgs, opt); groups != nil {
+			res, err := lintFileGroups(ctx, cs, groups, func(files []string) (*lintResult, error) {
+				return s.lint(ctx, cs, files, opt)
+			})
+			return res.problems, err
+		}
+	}
+	res, err := s.lint(ctx, cs, pkgs, opt)
+	if err != nil {
+		return nil, err
+	}
+	return res.problems, nil
+}
+
+func (s *Session) lint(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) (*lintResult, error) {
+	lprog, conf, err := s.load(ctx, pkgs, opt)
+	if err != nil {
+		return nil, err
+	}
+	return lintProgram(ctx, cs, lprog, conf, opt)
+}
+
+// Forget drops all loaded programs.
+func (s *Session) Forget() {
+	s.mu.Lock()
+	s.programs = map[string]*cachedProgram{}
+	s.mu.Unlock()
+}
+
+func (s *Session) load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	key := strings.Join(pkgs, "\x00") + "\x01" +
+		strings.Join(opt.Tags, " ") + "\x01" +
+		strconv.FormatBool(opt.LintTests) + strconv.FormatBool(opt.Partial)
+
+	if len(opt.Overlay) > 0 || opt.Loader != nil {
+		// Staleness is determined from the files on disk, so
+		// programs using overlays or custom loaders can't be
+		// reused.
+		return Load(ctx, pkgs, opt)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cp, ok := s.programs[key]; ok && !cp.stale() {
+		return cp.lprog, cp.conf, nil
+	}
+	delete(s.programs, key)
+
+	lprog, conf, err := loadPackages(ctx, pkgs, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.programs[key] = &cachedProgram{
+		lprog:  lprog,
+		conf:   conf,
+		mtimes: programMtimes(lprog),
+	}
+	return lprog, conf, nil
+}
+
+// programMtimes returns the modification times of all files of a
+// program, as well as those of their directories, which change when
+// files get added or removed.
+func programMtimes(lprog *loader.Program) map[string]time.Time {
+	mtimes := map[string]time.Time{}
+	for _, pkg := range lprog.AllPackages {
+		for _, f := range pkg.Files {
+			name := lprog.Fset.File(f.Pos()).Name()
+			mtimes[name] = mtime(name)
+			dir := filepath.Dir(name)
+			if _, ok := mtimes[dir]; !ok {
+				mtimes[dir] = mtime(dir)
+			}
+		}
+	}
+	return mtimes
+}
+
+func mtime(name string) time.Time {
+	fi, err := os.Stat(name)
+	if err != nil {
+		// The zero time marks files that don't exist on disk, such
+		// as the output of cgo.
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// stale reports whether any of the program's files or directories
+// changed since it was loaded.
+func (cp *cachedProgram) stale() bool {
+	for name, t := range cp.mtimes {
+		if !mtime(name).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
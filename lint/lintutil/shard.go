@@ -0,0 +1,182 @@
+package lintutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/scanner"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/kisielk/gotool"
+	"honnef.co/go/tools/lint"
+)
+
+// shardDriverFlags are the flags that only concern the driver of a
+// sharded run, which handles output itself, and that aren't passed
+// on to shards. The driver's timeout applies to all shards together;
+// it kills the running shard when the timeout expires.
+var shardDriverFlags = map[string]bool{
+	"timeout":          true,
+	"shard":            true,
+	"f":                true,
+	"o":                true,
+	"group-by":         true,
+	"show-source":      true,
+	"color":            true,
+	"previous-summary": true,
+	"progress":         true,
+}
+
+// splitShards splits paths into at most n chunks of similar size,
+// keeping neighbouring packages, which tend to share dependencies,
+// together.
+func splitShards(paths []string, n int) [][]string {
+	if n > len(paths) {
+		n = len(paths)
+	}
+	var shards [][]string
+	for i := 0; i < n; i++ {
+		lo, hi := i*len(paths)/n, (i+1)*len(paths)/n
+		shards = append(shards, paths[lo:hi])
+	}
+	return shards
+}
+
+// lintShards lints the packages named by pkgs in n child processes,
+// which run the current executable with the flags of fs, one after
+// another, so that only one shard's packages are held in memory at a
+// time. Problems are merged from the shards' JSON output.
+//
+// Errors in the code and configuration reported by shards, as well
+// as shards that fail, such as by running out of memory, are returned
+// as an ErrorList, together with the results of the other shards.
+func lintShards(ctx context.Context, fs *flag.FlagSet, cs []lint.Checker, n int, pkgs []string, opt *Options) (*lintResult, error) {
+	paths := gotool.ImportPaths(pkgs)
+	bctx := buildContext(opt)
+	if goFiles, err := resolveRelative(paths, &bctx); err != nil {
+		return nil, err
+	} else if goFiles {
+		return nil, errors.New("-shard can't be used when linting files")
+	}
+	if len(paths) > 0 {
+		exs, err := opt.exclusions()
+		if err != nil {
+			return nil, err
+		}
+		if paths = excludePackages(exs, paths, &bctx, opt.LintTests); len(paths) == 0 {
+			return &lintResult{problems: make([][]lint.Problem, len(cs))}, nil
+		}
+	}
+	if len(paths) == 0 {
+		if opt.AllowNoPackages {
+			return &lintResult{problems: make([][]lint.Problem, len(cs))}, nil
+		}
+		return nil, ErrNoPackages
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	var flags []string
+	fs.Visit(func(f *flag.Flag) {
+		if !shardDriverFlags[f.Name] {
+			flags = append(flags, fmt.Sprintf("-%s=%s", f.Name, f.Value))
+		}
+	})
+	flags = append(flags, "-f", "jsonl", "--")
+
+	res := &lintResult{problems: make([][]lint.Problem, len(cs))}
+	index := map[string]int{}
+	for i, c := range cs {
+		index[c.Name()] = i
+	}
+	var errs ErrorList
+	shards := splitShards(paths, n)
+	for i, shard := range shards {
+		if err := ctx.Err(); err != nil {
+			res.canceled = true
+			return res, err
+		}
+		var stdout bytes.Buffer
+		cmd := exec.CommandContext(ctx, exe, append(flags, shard...)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			// Shards that were interrupted don't produce usable
+			// output.
+			res.canceled = true
+			return res, ctx.Err()
+		}
+		if err != nil {
+			if err, ok := err.(*exec.ExitError); !ok || err.ExitCode() != 1 {
+				// Exit status 1 means that problems were found; all
+				// other failures mean that the shard didn't finish.
+				errs = append(errs, fmt.Errorf("shard %d of %d, with packages %s to %s, failed: %v", i+1, len(shards), shard[0], shard[len(shard)-1], err))
+				continue
+			}
+		}
+		if err := mergeShard(res, &errs, &stdout, index); err != nil {
+			errs = append(errs, fmt.Errorf("shard %d of %d, with packages %s to %s, failed: %v", i+1, len(shards), shard[0], shard[len(shard)-1], err))
+		}
+	}
+	sort.Strings(res.packages)
+	if len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+// mergeShard adds the problems, packages and partial packages in the
+// JSON Lines output of a shard, read from r, to res. Problems of checkers that aren't
+// in index, the indices of checkers by name, are errors in the code
+// or configuration and are added to errs.
+func mergeShard(res *lintResult, errs *ErrorList, r io.Reader, index map[string]int) error {
+	ps, md, err := ReadResults(r)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		// The shard stopped before writing its output, for example
+		// because it timed out.
+		return errors.New("no results")
+	}
+	res.packages = append(res.packages, md.Packages...)
+	for _, pm := range md.Partial {
+		pkg := PartialPackage{Path: pm.Path}
+		if pm.Error != "" {
+			pkg.Err = errors.New(pm.Error)
+		}
+		res.partial = append(res.partial, pkg)
+	}
+	for _, p := range ps {
+		if i, ok := index[p.Checker]; ok {
+			res.problems[i] = append(res.problems[i], p)
+			continue
+		}
+		switch p.Checker {
+		case "config":
+			*errs = append(*errs, &ConfigError{Position: p.Position, Msg: p.Text})
+		default:
+			*errs = append(*errs, &scanner.Error{Pos: p.Position, Msg: p.Text})
+		}
+	}
+	return nil
+}
+
+// checkShardable reports whether runs with the given options can be
+// sharded.
+func checkShardable(opt *Options, reportSuppressions bool) error {
+	switch {
+	case opt.PackageSpec != "":
+		return errors.New("-shard can't be used with -package-spec")
+	case reportSuppressions:
+		return errors.New("-shard can't be used with -report-suppressions")
+	}
+	return nil
+}
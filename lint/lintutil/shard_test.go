@@ -0,0 +1,192 @@
+package lintutil
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"go/build"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("LINTUTIL_TEST_SHARD") == "1" {
+		// The test binary acts as the shards of TestLintShards.
+		os.Exit(fakeShard(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeShard pretends to lint the packages in args, which follow the
+// flags and a "--" argument, writing JSON Lines output like a shard
+// does, and returns the exit status. Packages named crash make it
+// fail without output; other packages get a problem of fileChecker
+// whose text is the flags. Packages named partial are reported as
+// partial, and packages named broken have an error in the code.
+func fakeShard(args []string) int {
+	var flags, pkgs []string
+	for i, arg := range args {
+		if arg == "--" {
+			flags, pkgs = args[:i], args[i+1:]
+		}
+	}
+	md := Metadata{Packages: pkgs}
+	var ps []lint.Problem
+	for _, pkg := range pkgs {
+		pos := token.Position{Filename: pkg + "/x.go", Line: 1, Column: 1}
+		switch filepath.Base(pkg) {
+		case "crash":
+			return 2
+		case "partial":
+			md.Partial = append(md.Partial, PartialMetadata{Path: pkg, Error: "type error"})
+		case "broken":
+			ps = append(ps, lint.Problem{Position: pos, Text: "syntax error", Check: "compile", Checker: "compile"})
+			continue
+		}
+		ps = append(ps, lint.Problem{Position: pos, Text: strings.Join(flags, " "), Check: "F1000", Checker: "files"})
+	}
+	o := JSONLinesOutput{os.Stdout}
+	o.Metadata(md)
+	for _, p := range ps {
+		o.Format(p)
+	}
+	if len(ps) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestSplitShards(t *testing.T) {
+	paths := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		n    int
+		want [][]string
+	}{
+		{1, [][]string{{"a", "b", "c", "d", "e"}}},
+		{2, [][]string{{"a", "b"}, {"c", "d", "e"}}},
+		{3, [][]string{{"a"}, {"b", "c"}, {"d", "e"}}},
+		{5, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}},
+		// There are never more shards than packages.
+		{8, [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}},
+	}
+	for _, tt := range tests {
+		if got := splitShards(paths, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitShards(%q, %d) = %q, want %q", paths, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMergeShard(t *testing.T) {
+	var buf bytes.Buffer
+	o := JSONLinesOutput{&buf}
+	o.Metadata(Metadata{
+		Packages: []string{"example.com/a", "example.com/b"},
+		Partial:  []PartialMetadata{{Path: "example.com/b", Error: "undeclared name: x"}},
+	})
+	pos := token.Position{Filename: "a.go", Line: 1, Column: 1}
+	o.Format(lint.Problem{Position: pos, Text: "checked", Check: "F1000", Checker: "files"})
+	o.Format(lint.Problem{Position: pos, Text: "unknown check", Check: "config", Checker: "config"})
+	o.Format(lint.Problem{Position: pos, Text: "expected ';'", Check: "compile", Checker: "compile"})
+
+	res := &lintResult{problems: make([][]lint.Problem, 1), packages: []string{"example.com/c"}}
+	var errs ErrorList
+	if err := mergeShard(res, &errs, &buf, map[string]int{"files": 0}); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.problems[0]) != 1 || res.problems[0][0].Text != "checked" {
+		t.Errorf("got problems %v, want the problem of the files checker", res.problems[0])
+	}
+	if want := []string{"example.com/c", "example.com/a", "example.com/b"}; !reflect.DeepEqual(res.packages, want) {
+		t.Errorf("got packages %q, want %q", res.packages, want)
+	}
+	if len(res.partial) != 1 || res.partial[0].Path != "example.com/b" || res.partial[0].Err == nil || res.partial[0].Err.Error() != "undeclared name: x" {
+		t.Errorf("got partial packages %v, want example.com/b", res.partial)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want two", errs)
+	}
+	if _, ok := errs[0].(*ConfigError); !ok {
+		t.Errorf("got error %#v, want a ConfigError", errs[0])
+	}
+	if _, ok := errs[1].(*scanner.Error); !ok {
+		t.Errorf("got error %#v, want a scanner.Error", errs[1])
+	}
+
+	if err := mergeShard(res, &errs, strings.NewReader(""), nil); err == nil {
+		t.Errorf("merging a shard without output succeeded")
+	}
+}
+
+func TestLintShards(t *testing.T) {
+	root, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	defer func(gopath, mode string) {
+		build.Default.GOPATH = gopath
+		os.Setenv("GOPATH", gopath)
+		os.Setenv("GO111MODULE", mode)
+		os.Unsetenv("LINTUTIL_TEST_SHARD")
+	}(build.Default.GOPATH, os.Getenv("GO111MODULE"))
+	build.Default.GOPATH = root
+	os.Setenv("GOPATH", root)
+	os.Setenv("GO111MODULE", "off")
+	os.Setenv("LINTUTIL_TEST_SHARD", "1")
+
+	var pkgs []string
+	for _, name := range []string{"a", "b", "broken", "crash", "partial"} {
+		if err := os.MkdirAll(filepath.Join(root, "src", "example.com", name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		pkgs = append(pkgs, "example.com/"+name)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("checks", "", "")
+	fs.Duration("timeout", 0, "")
+	fs.Int("shard", 0, "")
+	if err := fs.Parse([]string{"-checks=all", "-timeout=1m", "-shard=5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := lintShards(context.Background(), fs, []lint.Checker{fileChecker{}}, 5, pkgs, &Options{})
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("got error %v, want two errors", err)
+	}
+	if _, ok := errs[0].(*scanner.Error); !ok {
+		t.Errorf("got error %v, want the error in the code of example.com/broken", errs[0])
+	}
+	if msg := errs[1].Error(); !strings.HasPrefix(msg, "shard 4 of 5, with packages example.com/crash to example.com/crash, failed") {
+		t.Errorf("got error %q, want shard 4 to fail", msg)
+	}
+
+	var got []string
+	for _, p := range res.problems[0] {
+		got = append(got, p.Position.Filename+": "+p.Text)
+	}
+	// The timeout applies to the run as a whole and isn't passed on
+	// to shards.
+	want := []string{
+		"example.com/a/x.go: -checks=all -f jsonl",
+		"example.com/b/x.go: -checks=all -f jsonl",
+		"example.com/partial/x.go: -checks=all -f jsonl",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got problems %q, want %q", got, want)
+	}
+	if want := []string{"example.com/a", "example.com/b", "example.com/broken", "example.com/partial"}; !reflect.DeepEqual(res.packages, want) {
+		t.Errorf("got packages %q, want %q", res.packages, want)
+	}
+	if len(res.partial) != 1 || res.partial[0].Path != "example.com/partial" {
+		t.Errorf("got partial packages %v, want example.com/partial", res.partial)
+	}
+}
@@ -0,0 +1,89 @@
+package lintutil
+
+import (
+	"bytes"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// A sourceCache reads the lines of source files on demand, for
+// printing them alongside problems.
+type sourceCache struct {
+	// overlay holds replacement contents of files, as set by the
+	// -overlay flag.
+	overlay map[string][]byte
+	files   map[string][]string
+}
+
+func newSourceCache(overlay map[string][]byte) *sourceCache {
+	return &sourceCache{overlay: overlay, files: map[string][]string{}}
+}
+
+// line returns the contents of the given line of file, which is
+// 1-based, or false if the line can't be read.
+func (c *sourceCache) line(file string, line int) (string, bool) {
+	lines, ok := c.files[file]
+	if !ok {
+		var src []byte
+		if abs, err := filepath.Abs(file); err == nil {
+			src = c.overlay[abs]
+		}
+		if src == nil {
+			// Errors are remembered as files without lines.
+			src, _ = ioutil.ReadFile(file)
+		}
+		lines = strings.Split(string(src), "\n")
+		c.files[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[line-1], "\r"), true
+}
+
+// snippet returns the source line at pos and a line underlining the
+// range from pos to end. Ranges that span multiple lines are
+// underlined to the end of the first line.
+func (c *sourceCache) snippet(pos, end token.Position) (src, marker string, ok bool) {
+	if pos.Filename == "" || pos.Line == 0 {
+		return "", "", false
+	}
+	src, ok = c.line(pos.Filename, pos.Line)
+	if !ok {
+		return "", "", false
+	}
+	col := pos.Column
+	if col < 1 {
+		col = 1
+	}
+	if col > len(src)+1 {
+		return "", "", false
+	}
+	last := len(src)
+	if end.Filename == pos.Filename && end.Line == pos.Line && end.Column > col {
+		last = end.Column - 1
+	}
+	if last > len(src) {
+		last = len(src)
+	}
+
+	var buf bytes.Buffer
+	// Keep tabs, so that the marker lines up with the source.
+	for _, r := range src[:col-1] {
+		if r == '\t' {
+			buf.WriteByte('\t')
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+	buf.WriteByte('^')
+	if last >= col {
+		for i := 1; i < utf8.RuneCountInString(src[col-1:last]); i++ {
+			buf.WriteByte('~')
+		}
+	}
+	return src, buf.String(), true
+}
@@ -0,0 +1,267 @@
+package lintutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/loader"
+)
+
+// A packageSpec describes a set of packages and their dependencies,
+// as computed by a build system. It uses the format of the driver
+// protocol of golang.org/x/tools/go/packages, which allows build
+// systems such as Bazel to drive the linter without it having to
+// know how to locate packages.
+type packageSpec struct {
+	// Roots are the IDs of the packages to lint.
+	Roots    []string
+	Packages []*specPackage
+}
+
+type specPackage struct {
+	ID              string
+	Name            string
+	PkgPath         string
+	GoFiles         []string
+	CompiledGoFiles []string
+	// ExportFile is the path of a file containing the package's
+	// export data. Dependencies with export data aren't loaded from
+	// source.
+	ExportFile string
+	// Imports maps import paths, as they appear in the source, to
+	// package IDs.
+	Imports map[string]string
+}
+
+func readPackageSpec(path string) (*packageSpec, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	spec := &packageSpec{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return spec, nil
+}
+
+// specLoader builds a loader.Program from a packageSpec. Root
+// packages and dependencies without export data are type-checked
+// from source; all other dependencies are loaded from export data.
+type specLoader struct {
+	ctx   context.Context
+	opt   *Options
+	bctx  *build.Context
+	fset  *token.FileSet
+	byID  map[string]*specPackage
+	roots map[string]bool
+
+	// exports holds packages read from export data, by path, as
+	// required by gcexportdata.
+	exports map[string]*types.Package
+	infos   map[string]*loader.PackageInfo
+	loading map[string]bool
+}
+
+// loadSpec loads the packages described by the spec in path.
+func loadSpec(ctx context.Context, path string, opt *Options) (*loader.Program, *loader.Config, error) {
+	spec, err := readPackageSpec(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	bctx := buildContext(opt)
+	l := &specLoader{
+		ctx:     ctx,
+		opt:     opt,
+		bctx:    &bctx,
+		fset:    token.NewFileSet(),
+		byID:    map[string]*specPackage{},
+		roots:   map[string]bool{},
+		exports: map[string]*types.Package{},
+		infos:   map[string]*loader.PackageInfo{},
+		loading: map[string]bool{},
+	}
+	for _, p := range spec.Packages {
+		l.byID[p.ID] = p
+	}
+	for _, id := range spec.Roots {
+		l.roots[id] = true
+	}
+
+	lprog := &loader.Program{
+		Fset:        l.fset,
+		Imported:    map[string]*loader.PackageInfo{},
+		AllPackages: map[*types.Package]*loader.PackageInfo{},
+	}
+	for _, id := range spec.Roots {
+		info, err := l.load(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Test variants share the path of the package under test, so
+		// roots can't be keyed by path.
+		lprog.Created = append(lprog.Created, info)
+	}
+	for _, info := range l.infos {
+		lprog.AllPackages[info.Pkg] = info
+	}
+	for _, pkg := range l.exports {
+		if _, ok := lprog.AllPackages[pkg]; ok {
+			continue
+		}
+		// Packages that were only referenced indirectly by export
+		// data.
+		lprog.AllPackages[pkg] = &loader.PackageInfo{
+			Pkg:                   pkg,
+			Importable:            true,
+			TransitivelyErrorFree: true,
+		}
+	}
+	return lprog, &loader.Config{Build: &bctx, Fset: l.fset}, nil
+}
+
+func (l *specLoader) load(id string) (*loader.PackageInfo, error) {
+	if info, ok := l.infos[id]; ok {
+		return info, nil
+	}
+	if err := l.ctx.Err(); err != nil {
+		return nil, err
+	}
+	p, ok := l.byID[id]
+	if !ok {
+		return nil, fmt.Errorf("package spec: no package with ID %q", id)
+	}
+	if l.loading[id] {
+		return nil, fmt.Errorf("package spec: import cycle through %s", id)
+	}
+	l.loading[id] = true
+	defer delete(l.loading, id)
+
+	var info *loader.PackageInfo
+	var err error
+	if !l.roots[id] && p.ExportFile != "" {
+		info, err = l.loadExport(p)
+	} else {
+		info, err = l.loadSource(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.infos[id] = info
+	if l.opt.Progress != nil {
+		l.opt.Progress(ProgressEvent{Phase: "load", Package: info.Pkg.Path(), Done: len(l.infos)})
+	}
+	return info, nil
+}
+
+func (l *specLoader) loadExport(p *specPackage) (*loader.PackageInfo, error) {
+	f, err := os.Open(p.ExportFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gcexportdata.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", p.ExportFile, err)
+	}
+	pkg, err := gcexportdata.Read(r, l.fset, l.exports, p.PkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", p.ExportFile, err)
+	}
+	return &loader.PackageInfo{
+		Pkg:                   pkg,
+		Importable:            true,
+		TransitivelyErrorFree: true,
+	}, nil
+}
+
+func (l *specLoader) loadSource(p *specPackage) (*loader.PackageInfo, error) {
+	names := p.CompiledGoFiles
+	if len(names) == 0 {
+		names = p.GoFiles
+	}
+	var files []*ast.File
+	for _, name := range names {
+		f, err := parser.ParseFile(l.fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	info := &loader.PackageInfo{
+		Files:                 files,
+		Importable:            true,
+		TransitivelyErrorFree: true,
+		Info: types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Implicits:  map[ast.Node]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+			Scopes:     map[ast.Node]*types.Scope{},
+		},
+	}
+	var importErr error
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			id, ok := p.Imports[path]
+			if !ok {
+				return nil, fmt.Errorf("package spec: %s doesn't list import %q", p.ID, path)
+			}
+			dep, err := l.load(id)
+			if err != nil {
+				if importErr == nil {
+					importErr = err
+				}
+				return nil, err
+			}
+			if !dep.TransitivelyErrorFree {
+				info.TransitivelyErrorFree = false
+			}
+			return dep.Pkg, nil
+		}),
+		Sizes: types.SizesFor(l.bctx.Compiler, l.bctx.GOARCH),
+		Error: func(err error) {
+			info.Errors = append(info.Errors, err)
+		},
+	}
+	pkg, _ := conf.Check(p.PkgPath, l.fset, files, &info.Info)
+	if importErr != nil {
+		return nil, importErr
+	}
+	info.Pkg = pkg
+	if _, ok := l.exports[pkg.Path()]; !ok && !l.roots[p.ID] {
+		// Let export data refer to the package we checked.
+		l.exports[pkg.Path()] = pkg
+	}
+	if len(info.Errors) > 0 {
+		info.TransitivelyErrorFree = false
+		if !l.opt.Partial {
+			return nil, ErrorList(info.Errors)
+		}
+	}
+	return info, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
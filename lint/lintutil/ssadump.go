@@ -0,0 +1,106 @@
+package lintutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+// ssaFormats are the valid values of the -debug.ssa-format flag.
+var ssaFormats = []string{"text", "dot"}
+
+type byFunctionName []*ssa.Function
+
+func (s byFunctionName) Len() int           { return len(s) }
+func (s byFunctionName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byFunctionName) Less(i, j int) bool { return s[i].String() < s[j].String() }
+
+// dumpSSA writes the SSA form of the functions called name in pkgs to
+// w, as text or as a Graphviz graph of their control flow. Functions
+// are named like in the text output, such as "example.com/pkg.Fn" and
+// "(*example.com/pkg.T).Method", or relative to their packages, such
+// as "Fn" and "(*T).Method". Closures are named after their enclosing
+// functions, such as "Fn$1".
+func dumpSSA(ctx context.Context, w io.Writer, name, format string, pkgs []string, opt *Options) error {
+	lprog, _, err := opt.loader().Load(ctx, pkgs, opt)
+	if err != nil {
+		return err
+	}
+	prog := lint.BuildSSA(lprog)
+	initial := map[*ssa.Package]bool{}
+	for _, pkg := range lprog.InitialPackages() {
+		if ssapkg := prog.Package(pkg.Pkg); ssapkg != nil {
+			initial[ssapkg] = true
+		}
+	}
+
+	var fns []*ssa.Function
+	for fn := range ssautil.AllFunctions(prog) {
+		if fn.Pkg == nil || !initial[fn.Pkg] {
+			continue
+		}
+		if fn.String() == name || fn.RelString(fn.Pkg.Pkg) == name {
+			fns = append(fns, fn)
+		}
+	}
+	if len(fns) == 0 {
+		return fmt.Errorf("no function named %q in %s", name, strings.Join(pkgs, " "))
+	}
+	sort.Sort(byFunctionName(fns))
+
+	for i, fn := range fns {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		var err error
+		if format == "dot" {
+			err = writeCFG(w, fn)
+		} else {
+			_, err = fn.WriteTo(w)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dotEscaper escapes text in Graphviz labels, left-aligning lines.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`)
+
+// writeCFG writes the control flow graph of fn to w in Graphviz
+// format. Each basic block is a node listing the block's instructions.
+func writeCFG(w io.Writer, fn *ssa.Function) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph %q {\n", fn.String())
+	fmt.Fprintf(&buf, "\tnode [shape=box fontname=monospace];\n")
+	for _, b := range fn.Blocks {
+		var label bytes.Buffer
+		fmt.Fprintf(&label, "%d: %s\n", b.Index, b.Comment)
+		for _, ins := range b.Instrs {
+			if v, ok := ins.(ssa.Value); ok && v.Name() != "" {
+				fmt.Fprintf(&label, "%s = %s\n", v.Name(), ins)
+			} else {
+				fmt.Fprintf(&label, "%s\n", ins)
+			}
+		}
+		fmt.Fprintf(&buf, "\tb%d [label=\"%s\"];\n", b.Index, dotEscaper.Replace(label.String()))
+		for i, succ := range b.Succs {
+			attrs := ""
+			if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If); ok {
+				attrs = fmt.Sprintf(" [label=%q]", []string{"true", "false"}[i])
+			}
+			fmt.Fprintf(&buf, "\tb%d -> b%d%s;\n", b.Index, succ.Index, attrs)
+		}
+	}
+	fmt.Fprintln(&buf, "}")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
@@ -0,0 +1,128 @@
+package lintutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// SummaryOutput prints the number of problems in total, per severity,
+// per check and per package, instead of the problems themselves. Each
+// line has the form "kind key count", which makes summaries easy to
+// process and to compare over time. If a previous summary is known,
+// lines are suffixed with the change since then, and entries that
+// dropped to zero are listed as well.
+type SummaryOutput struct {
+	w        io.Writer
+	previous map[string]int
+	counts   map[string]int
+}
+
+func (o *SummaryOutput) Format(p lint.Problem) {
+	if p.Ignored {
+		return
+	}
+	sev := p.Severity
+	if sev == "" {
+		sev = "error"
+	}
+	o.counts["total -"]++
+	o.counts["severity "+sev]++
+	if p.Check != "" {
+		o.counts["check "+p.Check]++
+	}
+	if p.Package != nil {
+		o.counts["package "+p.Package.Path()]++
+	}
+}
+
+// summaryKinds are the kinds of lines in a summary, in the order in
+// which they are printed.
+var summaryKinds = []string{"total", "severity", "check", "package"}
+
+// bySummaryOrder sorts the keys of summary lines by kind, in the order
+// of summaryKinds, and then by name.
+type bySummaryOrder []string
+
+func (s bySummaryOrder) Len() int      { return len(s) }
+func (s bySummaryOrder) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySummaryOrder) Less(i, j int) bool {
+	ri, rj := summaryRank(s[i]), summaryRank(s[j])
+	if ri != rj {
+		return ri < rj
+	}
+	return s[i] < s[j]
+}
+
+func summaryRank(key string) int {
+	kind := strings.SplitN(key, " ", 2)[0]
+	for i, k := range summaryKinds {
+		if k == kind {
+			return i
+		}
+	}
+	return len(summaryKinds)
+}
+
+// Flush writes the summary.
+func (o *SummaryOutput) Flush() error {
+	keys := map[string]bool{"total -": true}
+	for k := range o.counts {
+		keys[k] = true
+	}
+	for k := range o.previous {
+		keys[k] = true
+	}
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Sort(bySummaryOrder(sorted))
+	for _, k := range sorted {
+		n := o.counts[k]
+		if o.previous == nil {
+			if _, err := fmt.Fprintf(o.w, "%s %d\n", k, n); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(o.w, "%s %d %+d\n", k, n, n-o.previous[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSummary reads a summary written by SummaryOutput.
+func readSummary(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	counts := map[string]int{}
+	sc := bufio.NewScanner(f)
+	line := 0
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("%s:%d: malformed summary line", path, line)
+		}
+		n, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: malformed count %q", path, line, fields[2])
+		}
+		counts[fields[0]+" "+fields[1]] = n
+	}
+	return counts, sc.Err()
+}
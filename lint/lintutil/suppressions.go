@@ -0,0 +1,107 @@
+package lintutil
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"honnef.co/go/tools/lint"
+)
+
+// A suppression describes an ignore and how many problems it
+// suppressed.
+type suppression struct {
+	// source is the mechanism that created the ignore, such as
+	// lint:ignore or -ignore.
+	source string
+	// location identifies the ignore within its source, such as a
+	// file name or a pattern. line is only set for ignores that
+	// apply to a single line.
+	location string
+	line     int
+	checks   string
+	count    int
+}
+
+// describeIgnore returns an ignore's suppression, without a count.
+func describeIgnore(ig lint.Ignore) suppression {
+	switch ig := ig.(type) {
+	case *lint.LineIgnore:
+		return suppression{source: "lint:ignore", location: shortPath(ig.File), line: ig.Line, checks: strings.Join(ig.Checks, ",")}
+	case *lint.FileIgnore:
+		return suppression{source: "lint:file-ignore", location: shortPath(ig.File), checks: strings.Join(ig.Checks, ",")}
+	case *lint.GlobIgnore:
+		return suppression{source: "-ignore", location: ig.Pattern, checks: strings.Join(ig.Checks, ",")}
+	case *pathIgnore:
+		return suppression{source: IgnoreFileName, location: ig.pattern, checks: strings.Join(ig.checks, ",")}
+	default:
+		return suppression{source: "unknown", location: fmt.Sprint(ig)}
+	}
+}
+
+// A suppressionSet merges the suppressions of multiple checkers.
+// Ignores that are shared by checkers, or that describe the same
+// directive, are counted once.
+type suppressionSet struct {
+	keys  []suppression
+	byKey map[suppression]int
+}
+
+func newSuppressionSet() *suppressionSet {
+	return &suppressionSet{byKey: map[suppression]int{}}
+}
+
+func (set *suppressionSet) add(ss []lint.Suppression) {
+	for _, s := range ss {
+		key := describeIgnore(s.Ignore)
+		if _, ok := set.byKey[key]; !ok {
+			set.keys = append(set.keys, key)
+		}
+		set.byKey[key] += s.Count
+	}
+}
+
+// list returns the merged suppressions, sorted by source and
+// location.
+func (set *suppressionSet) list() []suppression {
+	out := make([]suppression, len(set.keys))
+	for i, key := range set.keys {
+		key.count = set.byKey[key]
+		out[i] = key
+	}
+	sort.Sort(bySuppression(out))
+	return out
+}
+
+type bySuppression []suppression
+
+func (ss bySuppression) Len() int      { return len(ss) }
+func (ss bySuppression) Swap(i, j int) { ss[i], ss[j] = ss[j], ss[i] }
+func (ss bySuppression) Less(i, j int) bool {
+	if ss[i].source != ss[j].source {
+		return ss[i].source < ss[j].source
+	}
+	if ss[i].location != ss[j].location {
+		return ss[i].location < ss[j].location
+	}
+	if ss[i].line != ss[j].line {
+		return ss[i].line < ss[j].line
+	}
+	return ss[i].checks < ss[j].checks
+}
+
+// writeSuppressions writes a table of suppressions to w.
+func writeSuppressions(w io.Writer, ss []suppression) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tSOURCE\tLOCATION\tCHECKS")
+	for _, s := range ss {
+		location := s.location
+		if s.line > 0 {
+			location = fmt.Sprintf("%s:%d", location, s.line)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.count, s.source, location, s.checks)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,157 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/lint"
+)
+
+// compileSymbolPattern compiles a pattern of -match-func or
+// -match-type. Patterns are regular expressions that must match
+// entire names, except that a leading receiver in parentheses, as in
+// "(*T).Method", is matched literally.
+func compileSymbolPattern(pattern string) (*regexp.Regexp, error) {
+	expr := pattern
+	if strings.HasPrefix(expr, "(") {
+		if i := strings.Index(expr, ")"); i != -1 {
+			expr = regexp.QuoteMeta(expr[:i+1]) + expr[i+1:]
+		}
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid symbol pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
+
+// A symbolFilter restricts problems to those in the declarations of
+// functions and types whose names match, for -match-func and
+// -match-type.
+type symbolFilter struct {
+	fn  *regexp.Regexp
+	typ *regexp.Regexp
+}
+
+// newSymbolFilter returns the symbol filter of opt, or nil if opt
+// doesn't restrict problems to symbols.
+func newSymbolFilter(opt *Options) (*symbolFilter, error) {
+	if opt.MatchFunc == "" && opt.MatchType == "" {
+		return nil, nil
+	}
+	f := &symbolFilter{}
+	var err error
+	if opt.MatchFunc != "" {
+		if f.fn, err = compileSymbolPattern(opt.MatchFunc); err != nil {
+			return nil, err
+		}
+	}
+	if opt.MatchType != "" {
+		if f.typ, err = compileSymbolPattern(opt.MatchType); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// A symbolRange is the extent of a matching declaration.
+type symbolRange struct {
+	start, end token.Position
+}
+
+func (r symbolRange) contains(pos token.Position) bool {
+	before := func(a, b token.Position) bool {
+		return a.Line < b.Line || (a.Line == b.Line && a.Column <= b.Column)
+	}
+	return before(r.start, pos) && before(pos, r.end)
+}
+
+// ranges returns the extents of the matching declarations in the
+// initial packages of lprog, by file name.
+func (f *symbolFilter) ranges(lprog *loader.Program) map[string][]symbolRange {
+	out := map[string][]symbolRange{}
+	add := func(node ast.Node) {
+		start := lprog.Fset.Position(node.Pos())
+		end := lprog.Fset.Position(node.End())
+		out[start.Filename] = append(out[start.Filename], symbolRange{start, end})
+	}
+	for _, pkg := range lprog.InitialPackages() {
+		path := pkg.Pkg.Path()
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if f.matchFunc(path, decl) {
+						add(decl)
+					}
+				case *ast.GenDecl:
+					if f.typ == nil {
+						continue
+					}
+					for _, spec := range decl.Specs {
+						if spec, ok := spec.(*ast.TypeSpec); ok && f.matchName(f.typ, path, spec.Name.Name) {
+							add(spec)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// matchFunc reports whether the function or method decl in the
+// package path matches the filter. Methods also match if the filter
+// matches their receiver's type.
+func (f *symbolFilter) matchFunc(path string, decl *ast.FuncDecl) bool {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return f.fn != nil && f.matchName(f.fn, path, decl.Name.Name)
+	}
+	typ := decl.Recv.List[0].Type
+	ptr := false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = star.X, true
+	}
+	ident, ok := typ.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if f.typ != nil && f.matchName(f.typ, path, ident.Name) {
+		return true
+	}
+	if f.fn == nil {
+		return false
+	}
+	recv := func(name string) string {
+		if ptr {
+			return "(*" + name + ")." + decl.Name.Name
+		}
+		return "(" + name + ")." + decl.Name.Name
+	}
+	return f.fn.MatchString(recv(ident.Name)) || f.fn.MatchString(recv(path+"."+ident.Name))
+}
+
+// matchName reports whether re matches name, either on its own or
+// qualified with the import path of its package.
+func (f *symbolFilter) matchName(re *regexp.Regexp, path, name string) bool {
+	return re.MatchString(name) || re.MatchString(path+"."+name)
+}
+
+// filter returns the problems that are in the declarations of
+// matching symbols.
+func (f *symbolFilter) filter(ranges map[string][]symbolRange, ps []lint.Problem) []lint.Problem {
+	var out []lint.Problem
+	for _, p := range ps {
+		for _, r := range ranges[p.Position.Filename] {
+			if r.contains(p.Position) {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
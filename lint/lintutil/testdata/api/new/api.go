@@ -0,0 +1,42 @@
+package api
+
+import "time"
+
+const Version = "2"
+
+var Default = &Client{}
+
+// Renaming parameters is compatible.
+func New(address string) *Client { return &Client{Addr: address} }
+
+// Added is a new function.
+func Added() {}
+
+type Client struct {
+	Addr    string
+	Timeout time.Duration
+	// Retries was removed.
+
+	// Proxy is a new field.
+	Proxy string
+}
+
+func (c *Client) Get(key string) string { return "" }
+
+func (c *Client) Close() error { return nil }
+
+type Store interface {
+	Load(key string) ([]byte, error)
+	Store(key string, value []byte) error
+}
+
+type Sealed interface {
+	Exported()
+	Other()
+}
+
+type Level = int
+
+type Handler func(string, ...interface{}) error
+
+var Removed = func() {}
@@ -0,0 +1,36 @@
+package api
+
+const Version = "1"
+
+var Default = &Client{}
+
+func New(addr string) *Client { return &Client{Addr: addr} }
+
+func Removed() {}
+
+type Client struct {
+	Addr    string
+	Timeout int
+	Retries int
+}
+
+func (c Client) Get(key string) string { return "" }
+
+func (c *Client) Close() error { return nil }
+
+type Store interface {
+	Load(key string) ([]byte, error)
+}
+
+type Sealed interface {
+	Exported()
+	unexported()
+}
+
+type Gone struct {
+	Field int
+}
+
+type Level int
+
+type Handler func(string) error
@@ -8,24 +8,39 @@
 package lintutil // import "honnef.co/go/tools/lint/lintutil"
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
 	"go/build"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
 
+	"honnef.co/go/tools/cache"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/registry"
 	"honnef.co/go/tools/version"
 
 	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
 	"golang.org/x/tools/go/loader"
 )
 
@@ -35,50 +50,278 @@ type OutputFormatter interface {
 
 type TextOutput struct {
 	w io.Writer
+	// color enables ANSI colors: positions are dimmed and checks are
+	// colored by severity.
+	color bool
+	// source, if set, is used to print the source line of each
+	// problem.
+	source *sourceCache
 }
 
 func (o TextOutput) Format(p lint.Problem) {
-	fmt.Fprintf(o.w, "%v: %s\n", relativePositionString(p.Position), p.String())
+	var sev string
+	if p.Severity != "" && p.Severity != "error" {
+		sev = o.paint(severityColor(p.Severity), p.Severity) + ": "
+	}
+	text := p.Text
+	if p.Check != "" {
+		text += " (" + o.paint(severityColor(p.Severity), p.Check) + ")"
+	}
+	if p.Variant != "" {
+		text += " " + o.paint(colorDim, "[only in "+p.Variant+"]")
+	}
+	pos := o.paint(colorDim, relativePositionString(p.Position))
+	if p.URL != "" {
+		fmt.Fprintf(o.w, "%v: %s%s %s\n", pos, sev, text, o.paint(colorDim, p.URL))
+	} else {
+		fmt.Fprintf(o.w, "%v: %s%s\n", pos, sev, text)
+	}
+	if o.source != nil {
+		if src, marker, ok := o.source.snippet(p.Position, p.End); ok {
+			i := strings.IndexByte(marker, '^')
+			fmt.Fprintf(o.w, "\t%s\n\t%s%s\n", src, marker[:i], o.paint(severityColor(p.Severity), marker[i:]))
+		}
+	}
+	for _, r := range p.Related {
+		fmt.Fprintf(o.w, "\t%v: %s\n", o.paint(colorDim, relativePositionString(r.Position)), r.Text)
+	}
+}
+
+// paint wraps s in the ANSI escape sequence color, if colors are
+// enabled.
+func (o TextOutput) paint(color, s string) string {
+	if !o.color {
+		return s
+	}
+	return color + s + colorReset
 }
 
 type JSONOutput struct {
 	w io.Writer
 }
 
-func (o JSONOutput) Format(p lint.Problem) {
-	type location struct {
-		File   string `json:"file"`
-		Line   int    `json:"line"`
-		Column int    `json:"column"`
+type jsonLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonRelated struct {
+	Location jsonLocation `json:"location"`
+	Message  string       `json:"message"`
+}
+
+// jsonProblem is the representation of problems in JSON output. It
+// is also read back by sharded runs.
+type jsonProblem struct {
+	Checker  string        `json:"checker"`
+	Code     string        `json:"code"`
+	Severity string        `json:"severity,omitempty"`
+	Location jsonLocation  `json:"location"`
+	End      *jsonLocation `json:"end,omitempty"`
+	Message  string        `json:"message"`
+	Ignored  bool          `json:"ignored"`
+	URL      string        `json:"url,omitempty"`
+	Variant  string        `json:"variant,omitempty"`
+	Related  []jsonRelated `json:"related,omitempty"`
+	// MessageID and Args describe templated messages.
+	MessageID string            `json:"message_id,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+	// Owners are the owners of the problem's file, if requested.
+	Owners []string `json:"owners,omitempty"`
+	// Stack is the stack trace of internal errors.
+	Stack string `json:"stack,omitempty"`
+}
+
+// problem returns the problem described by jp. Problems read back
+// from JSON don't know their packages.
+func (jp jsonProblem) problem() lint.Problem {
+	p := lint.Problem{
+		Position:  token.Position{Filename: jp.Location.File, Line: jp.Location.Line, Column: jp.Location.Column},
+		Text:      jp.Message,
+		Checker:   jp.Checker,
+		Check:     jp.Code,
+		Severity:  jp.Severity,
+		Ignored:   jp.Ignored,
+		URL:       jp.URL,
+		Variant:   jp.Variant,
+		MessageID: jp.MessageID,
+		Args:      jp.Args,
+		Owners:    jp.Owners,
+		Stack:     jp.Stack,
 	}
-	jp := struct {
-		Checker  string   `json:"checker"`
-		Code     string   `json:"code"`
-		Severity string   `json:"severity,omitempty"`
-		Location location `json:"location"`
-		Message  string   `json:"message"`
-		Ignored  bool     `json:"ignored"`
-	}{
+	if jp.End != nil {
+		p.End = token.Position{Filename: jp.End.File, Line: jp.End.Line, Column: jp.End.Column}
+	}
+	for _, r := range jp.Related {
+		p.Related = append(p.Related, lint.Related{
+			Position: token.Position{Filename: r.Location.File, Line: r.Location.Line, Column: r.Location.Column},
+			Text:     r.Message,
+		})
+	}
+	return p
+}
+
+// newJSONProblem returns the JSON representation of p.
+func newJSONProblem(p lint.Problem) jsonProblem {
+	var rel []jsonRelated
+	for _, r := range p.Related {
+		rel = append(rel, jsonRelated{
+			jsonLocation{
+				r.Position.Filename,
+				r.Position.Line,
+				r.Position.Column,
+			},
+			r.Text,
+		})
+	}
+	var end *jsonLocation
+	if p.End.IsValid() {
+		end = &jsonLocation{p.End.Filename, p.End.Line, p.End.Column}
+	}
+	return jsonProblem{
 		p.Checker,
 		p.Check,
-		"", // TODO(dh): support severity
-		location{
+		p.Severity,
+		jsonLocation{
 			p.Position.Filename,
 			p.Position.Line,
 			p.Position.Column,
 		},
+		end,
 		p.Text,
 		p.Ignored,
+		p.URL,
+		p.Variant,
+		rel,
+		p.MessageID,
+		p.Args,
+		p.Owners,
+		p.Stack,
 	}
+}
+
+func (o JSONOutput) Format(p lint.Problem) {
+	_ = json.NewEncoder(o.w).Encode(newJSONProblem(p))
+}
+
+// Metadata describes a single run of the linter. It allows consumers
+// of the results to detect differences in configuration between
+// runs.
+type Metadata struct {
+	Tool       string   `json:"tool"`
+	Version    string   `json:"version"`
+	GoVersion  string   `json:"go_version"`
+	Checks     []string `json:"checks"`
+	ConfigHash string   `json:"config_hash"`
+	Packages   []string `json:"packages"`
+	// Partial lists the packages that failed to type-check and were
+	// only checked syntactically.
+	Partial []PartialMetadata `json:"partial,omitempty"`
+}
+
+// PartialMetadata describes a package that failed to type-check.
+type PartialMetadata struct {
+	Path string `json:"path"`
+	// Error is the first error in the package, or in the dependency
+	// that caused it to fail.
+	Error string `json:"error,omitempty"`
+}
+
+// JSONLinesOutput is like JSONOutput, but prefixes the problems with
+// a metadata record. Each record has a "type" field, which is either
+// "metadata" or "problem".
+type JSONLinesOutput struct {
+	w io.Writer
+}
+
+func (o JSONLinesOutput) Metadata(m Metadata) {
+	jm := struct {
+		Type string `json:"type"`
+		Metadata
+	}{"metadata", m}
+	_ = json.NewEncoder(o.w).Encode(jm)
+}
+
+func (o JSONLinesOutput) Format(p lint.Problem) {
+	jp := struct {
+		Type string `json:"type"`
+		jsonProblem
+	}{"problem", newJSONProblem(p)}
 	_ = json.NewEncoder(o.w).Encode(jp)
 }
+
+// configHash returns a hash of all options that influence the
+// results of a run.
+func configHash(opt *Options, checks []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\n", version.Version)
+	fmt.Fprintf(h, "tags %q\n", opt.Tags)
+	fmt.Fprintf(h, "tests %t\n", opt.LintTests)
+	fmt.Fprintf(h, "ignore %q\n", opt.Ignores)
+	if opt.IgnoreFile != "" {
+		b, _ := ioutil.ReadFile(opt.IgnoreFile)
+		fmt.Fprintf(h, "ignore-file %q\n", b)
+	}
+	fmt.Fprintf(h, "go %d\n", opt.GoVersion)
+	fmt.Fprintf(h, "show-ignored %t\n", opt.ReturnIgnored)
+	fmt.Fprintf(h, "show-variants %t\n", opt.AnnotateVariants)
+	fmt.Fprintf(h, "partial %t\n", opt.Partial)
+	fmt.Fprintf(h, "checks %q\n", checks)
+	fmt.Fprintf(h, "dictionary %q\n", opt.Config.Dictionary)
+	fmt.Fprintf(h, "import-groups %q\n", opt.Config.ImportGroups)
+	fmt.Fprintf(h, "exhaustive %q\n", opt.Config.Exhaustive)
+	fmt.Fprintf(h, "exhaustive-fields %q\n", opt.Config.ExhaustiveFields)
+	fmt.Fprintf(h, "error-wrapping %q\n", opt.Config.ErrorWrapping)
+	fmt.Fprintf(h, "opaque-error-packages %q\n", opt.Config.OpaqueErrorPackages)
+	fmt.Fprintf(h, "insecure-tls-packages %q\n", opt.Config.InsecureTLSPackages)
+	fmt.Fprintf(h, "sql-quote-functions %q\n", opt.Config.SQLQuoteFunctions)
+	fmt.Fprintf(h, "unmarshal-functions %q\n", opt.Config.UnmarshalFunctions)
+	fmt.Fprintf(h, "secret-names %q\n", opt.Config.SecretNames)
+	fmt.Fprintf(h, "init-allowed-functions %q\n", opt.Config.InitAllowedFunctions)
+	fmt.Fprintf(h, "mutable-globals %q\n", opt.Config.MutableGlobals)
+	fmt.Fprintf(h, "must-use-functions %q\n", opt.Config.MustUseFunctions)
+	for _, rule := range opt.Config.ImportRules {
+		fmt.Fprintf(h, "import-rule %q %q %q %q\n", rule.Forbid, rule.Allow, rule.Except, rule.Reason)
+	}
+	fmt.Fprintf(h, "exclude %q\n", opt.Config.Exclude)
+	fmt.Fprintf(h, "max-length %d %d %d\n", opt.Config.MaxFunctionStatements, opt.Config.MaxFunctionLines, opt.Config.MaxFileLines)
+	fmt.Fprintf(h, "todo-pattern %q\n", opt.Config.TodoPattern)
+	fmt.Fprintf(h, "ignore-reason %d %q\n", opt.Config.IgnoreReasonMinLength, opt.Config.IgnoreReasonPattern)
+	var keys []string
+	for key := range opt.Config.Messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "message %q %q\n", key, opt.Config.Messages[key])
+	}
+	var patterns []string
+	for pattern := range opt.Severity {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		fmt.Fprintf(h, "severity %q %q\n", pattern, opt.Severity[pattern])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func enabledChecks(cs []lint.Checker, patterns []string) []string {
+	var checks []string
+	for _, c := range cs {
+		checks = append(checks, lint.EnabledChecks(c, patterns)...)
+	}
+	sort.Strings(checks)
+	return checks
+}
 func usage(name string, flags *flag.FlagSet) func() {
 	return func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", name)
 		fmt.Fprintf(os.Stderr, "\t%s [flags] # runs on package in current directory\n", name)
 		fmt.Fprintf(os.Stderr, "\t%s [flags] packages\n", name)
 		fmt.Fprintf(os.Stderr, "\t%s [flags] directory\n", name)
-		fmt.Fprintf(os.Stderr, "\t%s [flags] files... # must be a single package\n", name)
+		fmt.Fprintf(os.Stderr, "\t%s [flags] files...\n", name)
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flags.PrintDefaults()
 	}
@@ -90,9 +333,15 @@ type runner struct {
 	ignores       []lint.Ignore
 	version       int
 	returnIgnored bool
+	variants      bool
+	docsURL       string
+	checks        []string
+	config        config.Config
+	scopes        *scopeResolver
+	progress      func(done, total int)
 }
 
-func resolveRelative(importPaths []string, tags []string) (goFiles bool, err error) {
+func resolveRelative(importPaths []string, bctx *build.Context) (goFiles bool, err error) {
 	if len(importPaths) == 0 {
 		return false, nil
 	}
@@ -104,10 +353,8 @@ func resolveRelative(importPaths []string, tags []string) (goFiles bool, err err
 	if err != nil {
 		return false, err
 	}
-	ctx := build.Default
-	ctx.BuildTags = tags
 	for i, path := range importPaths {
-		bpkg, err := ctx.Import(path, wd, build.FindOnly)
+		bpkg, err := bctx.Import(path, wd, build.FindOnly)
 		if err != nil {
 			return false, fmt.Errorf("can't load package %q: %v", path, err)
 		}
@@ -116,20 +363,186 @@ func resolveRelative(importPaths []string, tags []string) (goFiles bool, err err
 	return false, nil
 }
 
+// A fileGroup is a set of .go files, specified on the command line,
+// that make up a single package.
+type fileGroup struct {
+	// name is the package name of the files. It is empty for a
+	// single file whose package clause couldn't be parsed, which
+	// loading reports an error for.
+	name  string
+	files []string
+	// dir is the files' directory, as a local import path. It is
+	// only set for groups that use cgo, which the loader can only
+	// process when it imports the directory itself.
+	dir string
+}
+
+// path returns the import path under which the group is loaded when
+// other groups are part of the same program. It isn't a valid import
+// path, so that it can't be the same as that of the real package in
+// the files' directory, which other groups may import.
+func (g fileGroup) path() string {
+	return "adhoc:" + filepath.ToSlash(filepath.Dir(g.files[0])) + ":" + g.name
+}
+
+// groupFiles groups .go files by directory and package name, so that
+// files from multiple packages can be specified at once.
+func groupFiles(files []string, ctx *build.Context) []fileGroup {
+	type key struct {
+		dir  string
+		name string
+	}
+	var keys []key
+	groups := map[key][]string{}
+	cgo := map[key]bool{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		var f *ast.File
+		rd, err := buildutil.OpenFile(ctx, file)
+		if err == nil {
+			f, err = parser.ParseFile(fset, file, rd, parser.ImportsOnly)
+			rd.Close()
+		}
+		k := key{filepath.Dir(file), ""}
+		if err == nil {
+			k.name = f.Name.Name
+		} else {
+			// Keep files that can't be parsed apart, so that
+			// only they fail to load.
+			k.dir = file
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], file)
+		if f == nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if imp.Path.Value == `"C"` {
+				cgo[k] = true
+			}
+		}
+	}
+
+	var out []fileGroup
+	for _, k := range keys {
+		g := fileGroup{name: k.name, files: groups[k]}
+		if cgo[k] {
+			g.dir = localImportPath(k.dir)
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+// splitFileGroups returns the groups of files that pkgs consist of,
+// if pkgs are .go files of more than one package.
+func splitFileGroups(pkgs []string, opt *Options) []fileGroup {
+	paths := gotool.ImportPaths(pkgs)
+	if len(paths) < 2 || !strings.HasSuffix(paths[0], ".go") {
+		return nil
+	}
+	bctx := buildContext(opt)
+	applyOverlay(&bctx, opt.Overlay)
+	groups := groupFiles(paths, &bctx)
+	if len(groups) < 2 {
+		return nil
+	}
+	return groups
+}
+
+// lintFileGroups lints each of groups on its own with run, so that
+// errors in one package don't keep the others from being linted, and
+// merges the results. Errors are returned as an ErrorList of
+// GroupErrors, together with the results of the other groups. If ctx
+// gets canceled, it returns the results until then, together with
+// the context's error.
+func lintFileGroups(ctx context.Context, cs []lint.Checker, groups []fileGroup, run func(files []string) (*lintResult, error)) (*lintResult, error) {
+	res := &lintResult{problems: make([][]lint.Problem, len(cs))}
+	merge := func(gres *lintResult) {
+		for i, ps := range gres.problems {
+			res.problems[i] = append(res.problems[i], ps...)
+		}
+		res.packages = append(res.packages, gres.packages...)
+		res.suppressions = append(res.suppressions, gres.suppressions...)
+		res.partial = append(res.partial, gres.partial...)
+		res.canceled = res.canceled || gres.canceled
+	}
+	var errs ErrorList
+	for _, g := range groups {
+		gres, err := run(g.files)
+		if gres != nil {
+			merge(gres)
+		}
+		if err != nil && err == ctx.Err() {
+			return res, err
+		}
+		if err != nil {
+			if g.name == "" {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, &GroupError{Dir: filepath.Dir(g.files[0]), Package: g.name, Err: err})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return res, errs
+	}
+	return res, nil
+}
+
+// localImportPath returns a local import path, such as ./foo, for
+// the directory dir.
+func localImportPath(dir string) string {
+	if filepath.IsAbs(dir) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return dir
+		}
+		rel, err := filepath.Rel(cwd, dir)
+		if err != nil {
+			return dir
+		}
+		dir = rel
+	}
+	dir = filepath.ToSlash(dir)
+	if !build.IsLocalImport(dir) {
+		dir = "./" + dir
+	}
+	return dir
+}
+
+func parseChecks(s string) []string {
+	var out []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
 func parseIgnore(s string) ([]lint.Ignore, error) {
 	var out []lint.Ignore
+	var errs ErrorList
 	if len(s) == 0 {
 		return nil, nil
 	}
 	for _, part := range strings.Fields(s) {
 		p := strings.Split(part, ":")
 		if len(p) != 2 {
-			return nil, errors.New("malformed ignore string")
+			errs = append(errs, &ConfigError{Msg: fmt.Sprintf("malformed -ignore pattern %q", part)})
+			continue
 		}
 		path := p[0]
 		checks := strings.Split(p[1], ",")
 		out = append(out, &lint.GlobIgnore{Pattern: path, Checks: checks})
 	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	return out, nil
 }
 
@@ -164,10 +577,42 @@ func FlagSet(name string) *flag.FlagSet {
 	flags.Float64("min_confidence", 0, "Deprecated; use -ignore instead")
 	flags.String("tags", "", "List of `build tags`")
 	flags.String("ignore", "", "Space separated list of checks to ignore, in the following format: 'import/path/file.go:Check1,Check2,...' Both the import path and file name sections support globbing, e.g. 'os/exec/*_test.go'")
+	flags.String("checks", "", "Comma-separated list of `checks` to enable or disable, applied in order to the default set. Supports 'all', 'none', wildcards and a '-' prefix for disabling checks, e.g. 'all,-ST1003'")
 	flags.Bool("tests", true, "Include tests")
 	flags.Bool("version", false, "Print version and exit")
 	flags.Bool("show-ignored", false, "Don't filter ignored problems")
-	flags.String("f", "text", "Output `format` (valid choices are 'text' and 'json')")
+	flags.Bool("show-variants", false, "Annotate problems that were found in only some build variants of a package, such as only when including tests")
+	flags.Var(&formatList{specs: []string{"text"}}, "f", "Output `format` (valid choices are 'text', 'json', 'jsonl' and 'summary'). Can be repeated to produce several outputs; 'format=file' writes the output to a file instead of standard output")
+	flags.String("o", "", "Write outputs that don't name a file to `file` instead of standard output")
+	flags.String("previous-summary", "", "Compare output in the summary format with the summary in `file`")
+	flags.String("group-by", "", "Group text output by `key`: 'check', 'file', 'package' or 'owner', which requires -codeowners")
+	flags.Bool("show-source", false, "Print the source line of each problem in text output, underlining the reported range")
+	flags.String("color", "auto", "Whether to color text output: 'always', 'never' or 'auto', which colors output to terminals unless NO_COLOR is set")
+	flags.Duration("timeout", 0, "Abort linting after `duration`; 0 means no timeout")
+	flags.Bool("partial", false, "Run syntactic checks on packages that failed to type-check")
+	flags.String("docs-url", "https://staticcheck.io/docs/checks", "Base `URL` of the checks' documentation, used for linking problems to their documentation. Set to the empty string to disable links")
+	flags.String("docs-dir", "", "Write documentation for all checks to `dir` and exit")
+	flags.Bool("validate-config", false, "Validate the configuration files, ignore file and ignore directives of the project, as well as the -checks and -ignore flags, without linting, and exit")
+	flags.String("codeowners", "", "Annotate problems in JSON output with the owners of their files, according to the CODEOWNERS `file`; 'auto' looks for .github/CODEOWNERS, CODEOWNERS and docs/CODEOWNERS in the current directory and its parents")
+	flags.Int("shard", 0, "Split the packages into `n` shards that are linted one after another by child processes, bounding peak memory use and isolating crashes; 0 disables sharding")
+	flags.Bool("progress", false, "Print the progress of loading packages and running checks to standard error: a progress bar on terminals, and JSON Lines if standard output is in a JSON format")
+	flags.Var(new(excludeList), "exclude", "Exclude files and directories matching the gitignore-like `pattern`, relative to the current directory, from analysis. Can be repeated, or list several comma-separated patterns")
+	flags.String("match-func", "", "Only report problems in functions and methods whose names match the regular expression `pattern`, such as '(*Server).Handle.*'")
+	flags.String("match-type", "", "Only report problems in the declarations and methods of types whose names match the regular expression `pattern`")
+	flags.Bool("allow-no-packages", false, "Succeed when the patterns match no packages, instead of failing")
+	flags.String("api-snapshot", "", "Write a manifest of the exported API of the named packages to `file` and exit")
+	flags.String("api-check", "", "Instead of linting, compare the exported API of the named packages with the manifest in `file`, written by -api-snapshot, and report removals and incompatible changes")
+	flags.Bool("report-suppressions", false, "Print how many problems each ignore directive and rule suppressed")
+	flags.String("debug.print-config", "", "Print the effective configuration of the package at `import path` and exit")
+	flags.String("debug.dump-cache", "", "Print the cache entry of the package at `import path` and exit")
+	flags.String("debug.dump-ssa", "", "Print the SSA form that checks analyze of the `function`, such as 'pkg/path.Fn' or '(*T).Method', in the named packages and exit")
+	flags.Bool("debug.loader", false, "Print to standard error which packages the patterns matched, which were skipped and why, and the build context they were loaded with")
+	flags.String("debug.ssa-format", "text", "Format of -debug.dump-ssa: 'text', or 'dot' for a Graphviz graph of the control flow")
+	flags.String("debug.repro", "", "Write the source files, go.mod and configuration files, and flags needed to reproduce the reported problems to the zip `file`, for attaching to bug reports")
+	flags.Bool("debug.repro-strip", false, "With -debug.repro, remove the bodies of functions that contain no problems, keeping unrelated code out of the bundle. This may change which problems are found")
+	flags.String("profile", "", "Apply the `profile` of that name from the configuration file")
+	flags.String("overlay", "", "Replace the contents of files with those listed in the JSON `file`, which uses the format of go build's -overlay flag")
+	flags.String("package-spec", "", "Lint the root packages described by the JSON `file` instead of loading packages, for use by build systems. The file uses the format of go/packages' driver protocol; '-' reads it from standard input")
 
 	tags := build.Default.ReleaseTags
 	v := tags[len(tags)-1][2:]
@@ -185,16 +630,360 @@ type CheckerConfig struct {
 	ExitNonZero bool
 }
 
+// cliFlags are the values of the flags of FlagSet that select what
+// the command does and how it reports results, rather than how
+// packages are linted.
+type cliFlags struct {
+	printVersion       bool
+	docsDir            string
+	validate           bool
+	checks             []string
+	formats            []string
+	outFile            string
+	previousSummary    string
+	groupBy            string
+	showSource         bool
+	color              string
+	timeout            time.Duration
+	reportSuppressions bool
+	codeOwners         string
+	shards             int
+	progress           bool
+	overlayFile        string
+	dumpCachePath      string
+	printConfigPath    string
+	dumpSSAName        string
+	ssaFormat          string
+	reproPath          string
+	reproStrip         bool
+	apiSnapshot        string
+	apiCheck           string
+}
+
+// parseFlags reads and validates the flags in fs, which must have been
+// created by FlagSet. Flags that configure linting are returned as
+// Options.
+func parseFlags(fs *flag.FlagSet) (*cliFlags, *Options, error) {
+	get := func(name string) interface{} {
+		return fs.Lookup(name).Value.(flag.Getter).Get()
+	}
+	fl := &cliFlags{
+		printVersion:       get("version").(bool),
+		docsDir:            get("docs-dir").(string),
+		validate:           get("validate-config").(bool),
+		checks:             parseChecks(get("checks").(string)),
+		formats:            get("f").([]string),
+		outFile:            get("o").(string),
+		previousSummary:    get("previous-summary").(string),
+		groupBy:            get("group-by").(string),
+		showSource:         get("show-source").(bool),
+		color:              get("color").(string),
+		timeout:            get("timeout").(time.Duration),
+		reportSuppressions: get("report-suppressions").(bool),
+		codeOwners:         get("codeowners").(string),
+		shards:             get("shard").(int),
+		progress:           get("progress").(bool),
+		overlayFile:        get("overlay").(string),
+		dumpCachePath:      get("debug.dump-cache").(string),
+		printConfigPath:    get("debug.print-config").(string),
+		dumpSSAName:        get("debug.dump-ssa").(string),
+		ssaFormat:          get("debug.ssa-format").(string),
+		reproPath:          get("debug.repro").(string),
+		reproStrip:         get("debug.repro-strip").(bool),
+		apiSnapshot:        get("api-snapshot").(string),
+		apiCheck:           get("api-check").(string),
+	}
+
+	tagList := strings.Fields(get("tags").(string))
+	if !isFlagSet(fs, "tags") {
+		// Like go build, fall back to the tags in GOFLAGS.
+		tagList = goFlagTags()
+	}
+	opt := &Options{
+		Tags:             tagList,
+		LintTests:        get("tests").(bool),
+		Ignores:          get("ignore").(string),
+		GoVersion:        get("go").(int),
+		ReturnIgnored:    get("show-ignored").(bool),
+		AnnotateVariants: get("show-variants").(bool),
+		Partial:          get("partial").(bool),
+		DocsURL:          get("docs-url").(string),
+		Checks:           fl.checks,
+		PackageSpec:      get("package-spec").(string),
+		Profile:          get("profile").(string),
+		AllowNoPackages:  get("allow-no-packages").(bool),
+		Exclude:          get("exclude").([]string),
+		MatchFunc:        get("match-func").(string),
+		MatchType:        get("match-type").(string),
+	}
+	if get("debug.loader").(bool) {
+		opt.DebugLoader = os.Stderr
+	}
+
+	if !isOneOf(fl.color, colorModes) {
+		return nil, nil, fmt.Errorf("invalid value %q for -color, must be one of %s", fl.color, strings.Join(colorModes, ", "))
+	}
+	if !isOneOf(fl.ssaFormat, ssaFormats) {
+		return nil, nil, fmt.Errorf("invalid value %q for -debug.ssa-format, must be one of %s", fl.ssaFormat, strings.Join(ssaFormats, ", "))
+	}
+	if fl.groupBy != "" && !isOneOf(fl.groupBy, groupings) {
+		return nil, nil, fmt.Errorf("invalid value %q for -group-by, must be one of %s", fl.groupBy, strings.Join(groupings, ", "))
+	}
+	if _, err := newSymbolFilter(opt); err != nil {
+		return nil, nil, err
+	}
+	if fl.shards < 0 {
+		return nil, nil, fmt.Errorf("invalid value %d for -shard, must not be negative", fl.shards)
+	}
+	if fl.shards > 0 {
+		if err := checkShardable(opt, fl.reportSuppressions); err != nil {
+			return nil, nil, err
+		}
+	}
+	return fl, opt, nil
+}
+
+// resolveConfig applies the files named by flags, or found in the
+// project directory wd, to opt: CODEOWNERS, ignore files and
+// overlays, as well as the cache. Errors are collected, so that they
+// can be reported together with errors in the code.
+func resolveConfig(fs *flag.FlagSet, fl *cliFlags, opt *Options, wd string) ErrorList {
+	var errs ErrorList
+	codeOwners := fl.codeOwners
+	if codeOwners == "auto" {
+		codeOwners = ""
+		if wd != "" {
+			if path, ok := findCodeOwners(wd); ok {
+				codeOwners = path
+			}
+		}
+		if codeOwners == "" {
+			errs = append(errs, &ConfigError{Msg: "-codeowners=auto was given, but there is no CODEOWNERS file"})
+		}
+	}
+	if codeOwners != "" {
+		if _, err := parseCodeOwners(codeOwners); err != nil {
+			if _, ok := err.(ErrorList); !ok {
+				err = &ConfigError{Msg: err.Error()}
+			}
+			errs = append(errs, err)
+		} else {
+			opt.CodeOwners = codeOwners
+		}
+	}
+	if _, err := parseIgnore(opt.Ignores); err != nil {
+		errs = append(errs, err)
+		opt.Ignores = ""
+	}
+	if opt.IgnoreFile != "" {
+		if _, err := parseIgnoreFile(opt.IgnoreFile); err != nil {
+			errs = append(errs, err)
+			opt.IgnoreFile = ""
+		}
+	}
+	if fl.overlayFile != "" {
+		overlay, err := readOverlay(fl.overlayFile)
+		if err != nil {
+			errs = append(errs, &ConfigError{Msg: err.Error()})
+		}
+		opt.Overlay = overlay
+	}
+	if !fl.reportSuppressions && opt.PackageSpec == "" {
+		// Suppressions aren't cached, so we can only report them
+		// when actually linting.
+		c, err := cache.Default()
+		if err != nil {
+			errs = append(errs, &ConfigError{Msg: err.Error()})
+		}
+		opt.Cache = c
+		// Flags may configure checkers, for example whether they
+		// check generated code.
+		fs.Visit(func(f *flag.Flag) {
+			if strings.HasPrefix(f.Name, "debug.") || f.Name == "progress" || f.Name == "codeowners" {
+				return
+			}
+			opt.CacheKey += fmt.Sprintf("%s=%s\n", f.Name, f.Value)
+		})
+	}
+	return errs
+}
+
+// setupOutputs returns the outputs selected by -f and -o, or by the
+// profile, and the previous summary to compare summaries with.
+func setupOutputs(fs *flag.FlagSet, fl *cliFlags, opt *Options) ([]outputSpec, map[string]int, error) {
+	formats := fl.formats
+	if opt.Profile != "" && !isFlagSet(fs, "f") {
+		if p, err := opt.Config.Profile(opt.Profile); err == nil && p.Format != "" {
+			formats = []string{p.Format}
+		}
+	}
+	var outputs []outputSpec
+	for _, format := range formats {
+		spec, err := parseOutputSpec(format)
+		if err != nil {
+			return nil, nil, err
+		}
+		if spec.path == "" {
+			spec.path = fl.outFile
+		}
+		outputs = append(outputs, spec)
+	}
+	var previous map[string]int
+	if fl.previousSummary != "" {
+		var err error
+		previous, err = readSummary(fl.previousSummary)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return outputs, previous, nil
+}
+
+// runDebugCommands runs the command selected by flags such as
+// -debug.print-config and -api-snapshot, which replace linting, and
+// exits. It returns if no such command was selected.
+func runDebugCommands(fs *flag.FlagSet, fl *cliFlags, cs []lint.Checker, opt *Options, errs ErrorList) {
+	var run func() error
+	switch {
+	case fl.printConfigPath != "":
+		run = func() error { return printConfig(os.Stdout, cs, fl.printConfigPath, opt) }
+	case fl.dumpCachePath != "":
+		run = func() error { return dumpCache(os.Stdout, cs, fl.dumpCachePath, opt) }
+	case fl.dumpSSAName != "":
+		run = func() error {
+			return dumpSSA(context.Background(), os.Stdout, fl.dumpSSAName, fl.ssaFormat, fs.Args(), opt)
+		}
+	case fl.apiSnapshot != "":
+		run = func() error { return writeAPISnapshot(context.Background(), fl.apiSnapshot, fs.Args(), opt) }
+	default:
+		return
+	}
+	if len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, errs)
+		os.Exit(1)
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// interruptContext returns a context that is canceled by the first
+// interrupt, which is then sent on the returned channel, or after
+// timeout, if it is positive. The first interrupt stops the run, but
+// the problems found so far are still reported; a second one exits
+// immediately.
+func interruptContext(timeout time.Duration) (context.Context, context.CancelFunc, <-chan os.Signal) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	interrupted := make(chan os.Signal, 1)
+	go func() {
+		sig := <-signals
+		interrupted <- sig
+		cancel()
+		sig = <-signals
+		os.Exit(interruptExitCode(sig))
+	}()
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		return ctx, func() { cancelTimeout(); cancel() }, interrupted
+	}
+	return ctx, cancel, interrupted
+}
+
+// reproCommand returns the command line of the run, without debug
+// flags, for reproduction bundles.
+func reproCommand(fs *flag.FlagSet) string {
+	command := []string{filepath.Base(os.Args[0])}
+	fs.Visit(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "debug.") {
+			command = append(command, fmt.Sprintf("-%s=%s", f.Name, f.Value))
+		}
+	})
+	command = append(command, fs.Args()...)
+	return strings.Join(command, " ")
+}
+
+// newFormatterOptions returns the settings of the formatters of a run
+// with the result res.
+func newFormatterOptions(fl *cliFlags, cs []lint.Checker, opt *Options, res *lintResult, previous map[string]int) formatterOptions {
+	metadata := func() Metadata {
+		checks := enabledChecks(cs, opt.Checks)
+		var partial []PartialMetadata
+		for _, pkg := range res.partial {
+			pm := PartialMetadata{Path: pkg.Path}
+			if pkg.Err != nil {
+				pm.Error = pkg.Err.Error()
+			}
+			partial = append(partial, pm)
+		}
+		return Metadata{
+			Tool:       filepath.Base(os.Args[0]),
+			Version:    version.Version,
+			GoVersion:  fmt.Sprintf("1.%d", opt.GoVersion),
+			Checks:     checks,
+			ConfigHash: configHash(opt, checks),
+			Packages:   res.packages,
+			Partial:    partial,
+		}
+	}
+	var text TextOutput
+	if fl.showSource {
+		text.source = newSourceCache(opt.Overlay)
+	}
+	return formatterOptions{
+		metadata:        metadata,
+		text:            text,
+		color:           fl.color,
+		previousSummary: previous,
+	}
+}
+
+// writeOutputs formats the problems ps with each of outs.
+func writeOutputs(outs []output, fopts formatterOptions, ps []lint.Problem, groups []*problemGroup) error {
+	for _, o := range outs {
+		f := o.formatter(fopts)
+		if t, ok := f.(TextOutput); ok && groups != nil {
+			writeGrouped(t, groups)
+		} else {
+			for _, p := range ps {
+				f.Format(p)
+				o.w.Flush()
+			}
+		}
+		if f, ok := f.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+		if err := o.w.Flush(); err != nil {
+			return err
+		}
+	}
+	return closeOutputs(outs)
+}
+
+// writePartial reports the packages that failed to type-check and were
+// only checked syntactically.
+func writePartial(w io.Writer, pkgs []PartialPackage) {
+	for _, pkg := range pkgs {
+		fmt.Fprintf(w, "%s: package has errors, only running syntactic checks\n", pkg.Path)
+		if pkg.Err != nil {
+			fmt.Fprintf(w, "\t%s\n", pkg.Err)
+		}
+	}
+}
+
 func ProcessFlagSet(confs []CheckerConfig, fs *flag.FlagSet) {
-	tags := fs.Lookup("tags").Value.(flag.Getter).Get().(string)
-	ignore := fs.Lookup("ignore").Value.(flag.Getter).Get().(string)
-	tests := fs.Lookup("tests").Value.(flag.Getter).Get().(bool)
-	goVersion := fs.Lookup("go").Value.(flag.Getter).Get().(int)
-	format := fs.Lookup("f").Value.(flag.Getter).Get().(string)
-	printVersion := fs.Lookup("version").Value.(flag.Getter).Get().(bool)
-	showIgnored := fs.Lookup("show-ignored").Value.(flag.Getter).Get().(bool)
-
-	if printVersion {
+	fl, opt, err := parseFlags(fs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if fl.printVersion {
 		version.Print()
 		os.Exit(0)
 	}
@@ -203,42 +992,196 @@ func ProcessFlagSet(confs []CheckerConfig, fs *flag.FlagSet) {
 	for _, conf := range confs {
 		cs = append(cs, conf.Checker)
 	}
-	pss, err := Lint(cs, fs.Args(), &Options{
-		Tags:          strings.Fields(tags),
-		LintTests:     tests,
-		Ignores:       ignore,
-		GoVersion:     goVersion,
-		ReturnIgnored: showIgnored,
-	})
+	if err := lint.ValidateCheckers(cs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if fl.docsDir != "" {
+		if err := WriteDocs(cs, fl.docsDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	// Configuration errors are collected and reported together with
+	// errors in the code, so that users can fix all of them at once.
+	var errs ErrorList
+	wd, err := os.Getwd()
+	if err == nil {
+		if err := opt.DiscoverProjectFiles(wd); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if fl.validate {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if errs := validateConfig(cs, wd, fl.checks, opt); len(errs) > 0 {
+			fmt.Fprintln(os.Stderr, errs)
+			os.Exit(1)
+		}
+		fmt.Println("configuration is valid")
+		os.Exit(0)
+	}
+	outputs, previous, err := setupOutputs(fs, fl, opt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	errs = append(errs, resolveConfig(fs, fl, opt, wd)...)
+	runDebugCommands(fs, fl, cs, opt, errs)
+
+	ctx, cancel, interrupted := interruptContext(fl.timeout)
+	defer cancel()
+	outs, err := openOutputs(outputs)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	var pw *progressWriter
+	if fl.progress {
+		jsonOutput := false
+		for _, spec := range outputs {
+			if spec.path == "" && (spec.format == "json" || spec.format == "jsonl") {
+				jsonOutput = true
+			}
+		}
+		pw = newProgressWriter(os.Stderr, jsonOutput)
+		opt.Progress = pw.Event
+	}
+	checkers := cs
+	if len(errs) > 0 {
+		// Don't run checks with a broken configuration, but still
+		// load the packages to report errors in them.
+		checkers = nil
+		opt.Cache = nil
+	}
+	var res *lintResult
+	if fl.apiCheck != "" && len(errs) == 0 {
+		res, err = checkAPI(ctx, fl.apiCheck, fs.Args(), opt)
+	} else if fl.shards > 0 && len(errs) == 0 {
+		res, err = lintShards(ctx, fs, cs, fl.shards, fs.Args(), opt)
+	} else {
+		res, err = lintPackages(ctx, checkers, fs.Args(), opt)
+	}
+	if pw != nil {
+		pw.Finish()
+	}
+	if err == context.DeadlineExceeded {
+		fmt.Fprintf(os.Stderr, "linting timed out after %s\n", fl.timeout)
+		os.Exit(1)
+	}
+	if err == ErrNoPackages {
+		fmt.Fprintf(os.Stderr, "%s: %v; use -allow-no-packages if this is expected\n", strings.Join(fs.Args(), " "), err)
+		os.Exit(1)
+	}
+	if err == context.Canceled {
+		// The run was interrupted; report what was found so far.
+		err = nil
+		if res == nil {
+			res = &lintResult{problems: make([][]lint.Problem, len(checkers))}
+		}
+	}
+	if err != nil {
+		errs = append(errs, err)
+		if res == nil {
+			res = &lintResult{}
+		}
+	}
+	if fl.shards == 0 {
+		// Shards report their own partial packages on stderr.
+		writePartial(os.Stderr, res.partial)
+	}
 
-	var ps []lint.Problem
-	for _, p := range pss {
+	ps := errorProblems(errs)
+	for _, p := range res.problems {
 		ps = append(ps, p...)
 	}
 
-	var f OutputFormatter
-	switch format {
-	case "text":
-		f = TextOutput{os.Stdout}
-	case "json":
-		f = JSONOutput{os.Stdout}
+	fopts := newFormatterOptions(fl, cs, opt, res, previous)
+	var groups []*problemGroup
+	if fl.groupBy != "" {
+		groups = groupProblems(cs, ps, fl.groupBy)
+	}
+	if err := writeOutputs(outs, fopts, ps, groups); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if fl.shards == 0 {
+		// Shards write their own internal errors to stderr.
+		writeInternalErrors(os.Stderr, ps)
+	}
+	if fl.reproPath != "" {
+		if err := writeRepro(context.Background(), fl.reproPath, reproCommand(fs), ps, fs.Args(), opt, fl.reproStrip); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "wrote reproduction bundle to %s\n", fl.reproPath)
+	}
+	select {
+	case sig := <-interrupted:
+		n := 0
+		for _, ps := range res.problems {
+			n += len(ps)
+		}
+		fmt.Fprintf(os.Stderr, "run interrupted (%s): reported %d %s found before the interruption, results are incomplete\n", sig, n, pluralize(n, "problem", "problems"))
+		os.Exit(interruptExitCode(sig))
 	default:
-		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", format)
-		os.Exit(2)
 	}
+	if fl.reportSuppressions {
+		writeSuppressions(os.Stderr, res.suppressions)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	if fl.apiCheck != "" {
+		for _, ps := range res.problems {
+			if len(ps) > 0 {
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	}
+	for i, ps := range res.problems {
+		if !confs[i].ExitNonZero {
+			continue
+		}
+		for _, p := range ps {
+			if p.Severity == "" || p.Severity == "error" {
+				os.Exit(1)
+			}
+		}
+	}
+}
 
-	for _, p := range ps {
-		f.Format(p)
+// interruptExitCode returns the exit status of runs interrupted by
+// sig, which, like in shells, is 128 plus the number of the signal.
+func interruptExitCode(sig os.Signal) int {
+	if sig, ok := sig.(syscall.Signal); ok {
+		return 128 + int(sig)
 	}
-	for i, p := range pss {
-		if len(p) != 0 && confs[i].ExitNonZero {
-			os.Exit(1)
+	return 130
+}
+
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
 		}
 	}
+	return false
+}
+
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 type Options struct {
@@ -247,64 +1190,592 @@ type Options struct {
 	Ignores       string
 	GoVersion     int
 	ReturnIgnored bool
+	// AnnotateVariants records in problems which build variants of a
+	// package they were found in, if not all. See
+	// lint.Linter.AnnotateVariants.
+	AnnotateVariants bool
+	// Partial allows linting packages that failed to type-check. Only
+	// checks that depend solely on syntax will be run on them.
+	Partial bool
+	// DocsURL is the base URL of the checks' documentation. See
+	// lint.Linter.DocsURL.
+	DocsURL string
+	// IgnoreFile is the path of a file containing additional ignore
+	// rules, in the format of .staticcheckignore files.
+	IgnoreFile string
+	// Checks selects the checks to run. See lint.EnabledChecks.
+	Checks []string
+	// Config is made available to checks. Its Checks field is not
+	// consulted, callers should merge it into Checks.
+	Config config.Config
+	// Cache, if set, stores the results of linting packages and is
+	// consulted before linting them again. It is not used when
+	// linting individual files.
+	Cache cache.Cache
+	// CacheKey is an additional input to cache keys, for settings
+	// that aren't part of Options, such as the configuration of
+	// checkers.
+	CacheKey string
+	// Profile is the name of the configuration file's profile to
+	// apply. See DiscoverProjectFiles.
+	Profile string
+	// Severity maps check patterns to severities, which are assigned
+	// to problems. See config.Profile.
+	Severity map[string]string
+	// Overlay maps the absolute paths of files to contents that
+	// replace those on disk, for example of unsaved editor buffers.
+	// Files that don't exist on disk are added to their directories.
+	// The cache isn't used when linting with an overlay.
+	Overlay map[string][]byte
+	// PackageSpec is the path of a file describing the packages to
+	// lint, in the format of the driver protocol of go/packages. If
+	// set, packages aren't loaded from GOPATH, and no packages may be
+	// named explicitly.
+	PackageSpec string
+	// Progress, if set, is called as packages are loaded and checks
+	// run, so that users of long runs can see that work is being
+	// done. Calls are serialized. Runs that are satisfied by the
+	// cache, and custom loaders, don't report loading.
+	Progress func(ProgressEvent)
+	// CodeOwners is the path of a CODEOWNERS file. If set, problems
+	// are annotated with the owners of their files. See
+	// lint.Problem.Owners.
+	CodeOwners string
+	// Exclude lists gitignore-like patterns, relative to the current
+	// directory, of files and directories to exclude from analysis.
+	// Packages whose directories, or all of whose files, are excluded
+	// aren't loaded, unless other packages depend on them. Problems in
+	// other excluded files are dropped. The exclusions of
+	// configuration files apply as well.
+	Exclude []string
+	// MatchFunc and MatchType, if set, restrict problems to those in
+	// functions, and in the declarations and methods of types, whose
+	// names match them. They are regular expressions that must match
+	// entire names, such as "Fn", "(*T).Method" and
+	// "example.com/pkg.T", except that receivers in parentheses are
+	// matched literally. The cache isn't used when they are set.
+	MatchFunc string
+	MatchType string
+	// AllowNoPackages makes linting patterns that match no packages
+	// succeed without problems, instead of failing with
+	// ErrNoPackages.
+	AllowNoPackages bool
+	// DebugLoader, if set, receives a log of how packages are loaded
+	// from GOPATH: the build context, the packages that each pattern
+	// matched, directories that were skipped and why, and packages
+	// that failed to type-check. The cache isn't used when it is set.
+	DebugLoader io.Writer
+	// Loader loads the packages to lint. If nil, packages are loaded
+	// from GOPATH, or from PackageSpec if it is set. Custom loaders
+	// disable the cache.
+	Loader Loader
+
+	// scoped is set by DiscoverProjectFiles and enables scoped
+	// configuration. configPath is the innermost configuration file
+	// that applies to the project directory, and flagChecks are the
+	// checks from before the configuration was applied.
+	scoped     bool
+	configPath string
+	flagChecks []string
+	// configExclusions are the exclusions of the configuration files
+	// that apply to the project directory.
+	configExclusions []exclusion
+}
+
+// DiscoverProjectFiles looks for a .staticcheckignore and the
+// staticcheck.conf files that apply to dir, and applies them to opt.
+// Checks enabled in the configuration, and in the profile named by
+// opt.Profile, are applied before opt.Checks.
+//
+// It also enables scoped configuration: packages in directories with
+// configuration files that don't apply to dir are linted with their
+// own configuration instead.
+func (opt *Options) DiscoverProjectFiles(dir string) error {
+	if path, ok := findIgnoreFile(dir); ok {
+		opt.IgnoreFile = path
+	}
+	opt.scoped = true
+	opt.flagChecks = opt.Checks
+	sc, err := config.LoadScoped(dir)
+	if err != nil {
+		return configError(err)
+	}
+	if len(sc.Files) == 0 {
+		if opt.Profile != "" {
+			return &ConfigError{Msg: fmt.Sprintf("profile %q was selected, but there is no %s", opt.Profile, config.ConfigName)}
+		}
+		return nil
+	}
+	opt.configPath = sc.Files[len(sc.Files)-1]
+	opt.Config = sc.Config
+	exs, err := configExclusions(sc.Files)
+	if err != nil {
+		return err
+	}
+	opt.configExclusions = exs
+	if err := validateMessages(opt.Config, opt.configPath); err != nil {
+		opt.Config.Messages = nil
+		return err
+	}
+	checks, err := opt.scopeChecks(sc)
+	if err != nil {
+		return err
+	}
+	if opt.Profile != "" {
+		p, _ := sc.Profile(opt.Profile)
+		opt.Severity = p.Severity
+	}
+	opt.Checks = checks
+	return nil
+}
+
+// scopeChecks returns the checks selected by a scoped configuration,
+// its profile and the -checks flag.
+func (opt *Options) scopeChecks(sc config.Scoped) ([]string, error) {
+	checks := append([]string(nil), sc.Checks...)
+	if opt.Profile != "" {
+		p, err := sc.Profile(opt.Profile)
+		if err != nil {
+			return nil, &ConfigError{Position: token.Position{Filename: sc.Files[len(sc.Files)-1]}, Msg: err.Error()}
+		}
+		checks = append(checks, p.Checks...)
+	}
+	return append(checks, opt.flagChecks...), nil
+}
+
+// configError turns errors in configuration files into ConfigErrors.
+func configError(err error) error {
+	if err, ok := err.(*config.Error); ok {
+		return &ConfigError{Position: token.Position{Filename: err.Path}, Msg: err.Msg}
+	}
+	return &ConfigError{Msg: err.Error()}
 }
 
+// ErrNoPackages is returned when loading packages from GOPATH if the
+// patterns match no packages at all, which usually indicates a typo
+// in an import path. See Options.AllowNoPackages.
+var ErrNoPackages = errors.New("the patterns matched no packages")
+
 func Lint(cs []lint.Checker, pkgs []string, opt *Options) ([][]lint.Problem, error) {
+	return LintContext(context.Background(), cs, pkgs, opt)
+}
+
+// LintContext is like Lint, but aborts and returns the context's
+// error if the context gets canceled. If that happens after linting
+// started, the problems found until then are returned together with
+// the error.
+func LintContext(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) ([][]lint.Problem, error) {
+	res, err := LintResult(ctx, cs, pkgs, opt)
+	if res == nil {
+		return nil, err
+	}
+	return res.Problems, err
+}
+
+// A Result is the result of linting packages with a set of checkers.
+type Result struct {
+	// Problems contains the problems of each checker, in the order
+	// of the checkers.
+	Problems [][]lint.Problem
+	// Partial lists the packages that failed to type-check, with
+	// Options.Partial, and were only checked syntactically.
+	Partial []PartialPackage
+}
+
+// LintResult is like LintContext, but also reports which packages
+// were only checked syntactically. A non-nil Result may be returned
+// together with an error, such as when the context gets canceled
+// after linting started.
+func LintResult(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) (*Result, error) {
+	res, err := lintPackages(ctx, cs, pkgs, opt)
+	if res == nil {
+		return nil, err
+	}
+	return &Result{Problems: res.problems, Partial: res.partial}, err
+}
+
+// A lintResult is the result of linting packages with a set of
+// checkers.
+type lintResult struct {
+	// problems contains the problems of each checker.
+	problems [][]lint.Problem
+	// packages are the import paths of the analyzed packages.
+	packages     []string
+	suppressions []suppression
+	// partial lists the packages that failed to type-check, with
+	// Options.Partial, and were only checked syntactically.
+	partial []PartialPackage
+	// canceled is set if the run was canceled while linting, in
+	// which case problems only contain those found until then.
+	canceled bool
+}
+
+// A PartialPackage is a package that failed to type-check.
+type PartialPackage struct {
+	// Path is the import path of the package.
+	Path string
+	// Err is the first error in the package, or in the dependency
+	// that caused it to fail.
+	Err error
+}
+
+// partialPackages returns the initial packages of lprog that failed
+// to type-check.
+func partialPackages(lprog *loader.Program) []PartialPackage {
+	var firstErr func(info *loader.PackageInfo, seen map[*types.Package]bool) error
+	firstErr = func(info *loader.PackageInfo, seen map[*types.Package]bool) error {
+		if len(info.Errors) > 0 {
+			return info.Errors[0]
+		}
+		seen[info.Pkg] = true
+		for _, imp := range info.Pkg.Imports() {
+			dep := lprog.AllPackages[imp]
+			if dep == nil || seen[imp] || dep.TransitivelyErrorFree {
+				continue
+			}
+			if err := firstErr(dep, seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var out []PartialPackage
+	for _, info := range lprog.InitialPackages() {
+		if !info.TransitivelyErrorFree {
+			out = append(out, PartialPackage{info.Pkg.Path(), firstErr(info, map[*types.Package]bool{})})
+		}
+	}
+	return out
+}
+
+// lintPackages is like Lint, but returns additional information
+// about the run. If ctx gets canceled after linting started, it
+// returns the problems found until then, together with the context's
+// error.
+func lintPackages(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) (*lintResult, error) {
 	if opt == nil {
 		opt = &Options{}
 	}
-	ignores, err := parseIgnore(opt.Ignores)
+	exs, err := opt.exclusions()
 	if err != nil {
 		return nil, err
 	}
-	paths := gotool.ImportPaths(pkgs)
-	goFiles, err := resolveRelative(paths, opt.Tags)
+	res, err := loadAndLint(ctx, cs, pkgs, opt)
+	if res == nil {
+		return nil, err
+	}
+	excludeProblems(exs, res.problems)
+	applySeverities(res.problems, opt)
+	applyMessages(res.problems, opt)
+	if err := applyOwners(res.problems, opt); err != nil {
+		return nil, err
+	}
+	return res, err
+}
+
+// Load loads the packages named by pkgs with the loader of opt, for
+// use with LintProgram.
+func Load(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	return opt.loader().Load(ctx, pkgs, opt)
+}
+
+// LintProgram is like LintContext, but lints an already loaded
+// program, which may have been loaded by means other than Load. conf
+// is the configuration the program was loaded with.
+func LintProgram(ctx context.Context, cs []lint.Checker, lprog *loader.Program, conf *loader.Config, opt *Options) ([][]lint.Problem, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	res, err := lintProgram(ctx, cs, lprog, conf, opt)
 	if err != nil {
 		return nil, err
 	}
-	ctx := build.Default
-	ctx.BuildTags = opt.Tags
-	hadError := false
+	applySeverities(res.problems, opt)
+	applyMessages(res.problems, opt)
+	if err := applyOwners(res.problems, opt); err != nil {
+		return nil, err
+	}
+	return res.problems, nil
+}
+
+// applySeverities assigns the severities configured in opt to
+// problems.
+func applySeverities(problems [][]lint.Problem, opt *Options) {
+	if len(opt.Severity) == 0 {
+		return
+	}
+	for _, ps := range problems {
+		for i := range ps {
+			ps[i].Severity = registry.Severity(opt.Severity, ps[i].Check)
+		}
+	}
+}
+
+// applyMessages renders templated messages with the templates
+// configured in opt, keeping the default messages of problems whose
+// templates can't be rendered.
+func applyMessages(problems [][]lint.Problem, opt *Options) {
+	if len(opt.Config.Messages) == 0 {
+		return
+	}
+	templates := map[string]*template.Template{}
+	for key, text := range opt.Config.Messages {
+		if tmpl, err := lint.ParseMessage(text); err == nil {
+			templates[key] = tmpl
+		}
+	}
+	for _, ps := range problems {
+		for i := range ps {
+			p := &ps[i]
+			if p.MessageID == "" {
+				continue
+			}
+			tmpl, ok := templates[p.Check+"."+p.MessageID]
+			if !ok {
+				tmpl, ok = templates[p.Check]
+			}
+			if !ok {
+				continue
+			}
+			if text, err := lint.RenderMessage(tmpl, p.Args); err == nil {
+				p.Text = text
+			}
+		}
+	}
+}
+
+// validateMessages reports templates in cfg that fail to parse.
+func validateMessages(cfg config.Config, path string) error {
+	var keys []string
+	for key := range cfg.Messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := lint.ParseMessage(cfg.Messages[key]); err != nil {
+			return &ConfigError{Position: token.Position{Filename: path}, Msg: fmt.Sprintf("invalid template of message %s: %s", key, err)}
+		}
+	}
+	return nil
+}
+
+func loadAndLint(ctx context.Context, cs []lint.Checker, pkgs []string, opt *Options) (*lintResult, error) {
+	if opt.PackageSpec == "" && opt.Loader == nil {
+		if groups := splitFileGroups(pkgs, opt); groups != nil {
+			return lintFileGroups(ctx, cs, groups, func(files []string) (*lintResult, error) {
+				return loadAndLint(ctx, cs, files, opt)
+			})
+		}
+	}
+	run := func() (*lintResult, error) {
+		lprog, conf, err := Load(ctx, pkgs, opt)
+		if err == errAllExcluded || (err == ErrNoPackages && opt.AllowNoPackages) {
+			return &lintResult{problems: make([][]lint.Problem, len(cs))}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return lintProgram(ctx, cs, lprog, conf, opt)
+	}
+	if opt.PackageSpec != "" || opt.Loader != nil {
+		// Cache keys are derived from packages in GOPATH.
+		return run()
+	}
+	if opt.Cache != nil && len(opt.Overlay) == 0 && opt.DebugLoader == nil && opt.MatchFunc == "" && opt.MatchType == "" {
+		paths := gotool.ImportPaths(pkgs)
+		bctx := buildContext(opt)
+		if goFiles, err := resolveRelative(paths, &bctx); err == nil && !goFiles && len(paths) > 0 {
+			exs, err := opt.exclusions()
+			if err != nil {
+				return nil, err
+			}
+			if paths = excludePackages(exs, paths, &bctx, opt.LintTests); len(paths) > 0 {
+				return cachedLint(ctx, cs, paths, opt, run)
+			}
+		}
+	}
+	return run()
+}
+
+// loadPackages loads the packages or files named by pkgs from GOPATH.
+func loadPackages(ctx context.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	bctx := buildContext(opt)
+	applyOverlay(&bctx, opt.Overlay)
+	return loadFrom(ctx, &bctx, pkgs, opt)
+}
+
+// loadFrom loads the packages or files named by pkgs, finding them
+// with bctx.
+func loadFrom(ctx context.Context, bctx *build.Context, pkgs []string, opt *Options) (*loader.Program, *loader.Config, error) {
+	var paths []string
+	if opt.DebugLoader != nil {
+		logBuildContext(opt.DebugLoader, bctx, opt)
+		paths = logPatterns(opt.DebugLoader, bctx, pkgs)
+	} else {
+		paths = gotool.ImportPaths(pkgs)
+	}
+	if len(paths) == 0 {
+		return nil, nil, ErrNoPackages
+	}
+	goFiles, err := resolveRelative(paths, bctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	exs, err := opt.exclusions()
+	if err != nil {
+		return nil, nil, err
+	}
+	n := len(paths)
+	if goFiles {
+		paths = excludeFiles(exs, paths)
+	} else {
+		paths = excludePackages(exs, paths, bctx, opt.LintTests)
+	}
+	if opt.DebugLoader != nil && len(paths) < n {
+		fmt.Fprintf(opt.DebugLoader, "loader: excluded %d of %d packages or files\n", n-len(paths), n)
+	}
+	if len(paths) == 0 {
+		return nil, nil, errAllExcluded
+	}
+	var (
+		mu   sync.Mutex
+		errs ErrorList
+	)
 	conf := &loader.Config{
-		Build:      &ctx,
-		ParserMode: parser.ParseComments,
-		ImportPkgs: map[string]bool{},
+		Build:       bctx,
+		ParserMode:  parser.ParseComments,
+		ImportPkgs:  map[string]bool{},
+		AllowErrors: opt.Partial,
 		TypeChecker: types.Config{
-			Sizes: types.SizesFor(ctx.Compiler, ctx.GOARCH),
+			Sizes: types.SizesFor(bctx.Compiler, bctx.GOARCH),
 			Error: func(err error) {
-				// Only print the first error found
-				if hadError {
-					return
-				}
-				hadError = true
-				fmt.Fprintln(os.Stderr, err)
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
 			},
 		},
 	}
+	opt.progressHook(conf)
 	if goFiles {
-		conf.CreateFromFilenames("adhoc", paths...)
+		groups := groupFiles(paths, bctx)
+		for _, g := range groups {
+			if g.dir != "" {
+				// The loader doesn't cgo-process ad hoc packages,
+				// so we have to load the entire directory.
+				conf.ImportPkgs[g.dir] = false
+				continue
+			}
+			path := "adhoc"
+			if len(groups) > 1 {
+				path = g.path()
+			}
+			conf.CreateFromFilenames(path, g.files...)
+		}
 	} else {
 		for _, path := range paths {
 			conf.ImportPkgs[path] = opt.LintTests
 		}
 	}
-	lprog, err := conf.Load()
+	lprog, err := load(ctx, conf)
+	if err != nil {
+		if err != ctx.Err() {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(errs) > 0 {
+				// Report all errors, not just the names of the
+				// packages that had them.
+				return nil, nil, errs
+			}
+		}
+		return nil, nil, err
+	}
+	if opt.DebugLoader != nil {
+		logLoaded(opt.DebugLoader, lprog)
+	}
+	return lprog, conf, nil
+}
+
+// lintProgram runs the checkers on an already loaded program. If ctx
+// gets canceled while checkers run, it returns the problems found
+// until then, together with the context's error.
+func lintProgram(ctx context.Context, cs []lint.Checker, lprog *loader.Program, conf *loader.Config, opt *Options) (*lintResult, error) {
+	ignores, err := parseIgnore(opt.Ignores)
+	if err != nil {
+		return nil, err
+	}
+	if opt.IgnoreFile != "" {
+		fileIgnores, err := parseIgnoreFile(opt.IgnoreFile)
+		if err != nil {
+			return nil, err
+		}
+		ignores = append(ignores, fileIgnores...)
+	}
+
+	symbols, err := newSymbolFilter(opt)
 	if err != nil {
 		return nil, err
 	}
 
-	var problems [][]lint.Problem
+	res := &lintResult{}
+	suppressions := newSuppressionSet()
+	var scopes *scopeResolver
+	if opt.scoped {
+		scopes = newScopeResolver(opt)
+	}
 	for _, c := range cs {
 		runner := &runner{
+			scopes:        scopes,
 			checker:       c,
 			tags:          opt.Tags,
 			ignores:       ignores,
 			version:       opt.GoVersion,
 			returnIgnored: opt.ReturnIgnored,
+			variants:      opt.AnnotateVariants,
+			docsURL:       opt.DocsURL,
+			checks:        opt.Checks,
+			config:        opt.Config,
+		}
+		if opt.Progress != nil {
+			name := c.Name()
+			runner.progress = func(done, total int) {
+				opt.Progress(ProgressEvent{Phase: "lint", Checker: name, Done: done, Total: total})
+			}
+		}
+		ps, ss, err := runner.lint(ctx, lprog, conf)
+		if err != nil {
+			if err != ctx.Err() {
+				return nil, err
+			}
+			// Keep the problems found before the run was canceled,
+			// and leave those of the remaining checkers empty.
+			res.problems = append(res.problems, ps)
+			res.problems = append(res.problems, make([][]lint.Problem, len(cs)-len(res.problems))...)
+			res.canceled = true
+			break
+		}
+		res.problems = append(res.problems, ps)
+		suppressions.add(ss)
+	}
+	res.suppressions = suppressions.list()
+	if symbols != nil {
+		ranges := symbols.ranges(lprog)
+		for i, ps := range res.problems {
+			res.problems[i] = symbols.filter(ranges, ps)
 		}
-		problems = append(problems, runner.lint(lprog, conf))
 	}
-	return problems, nil
+
+	for _, pkg := range lprog.InitialPackages() {
+		res.packages = append(res.packages, pkg.Pkg.Path())
+	}
+	sort.Strings(res.packages)
+	res.partial = partialPackages(lprog)
+	if res.canceled {
+		return res, ctx.Err()
+	}
+	return res, nil
 }
 
 func shortPath(path string) string {
@@ -324,7 +1795,10 @@ func relativePositionString(pos token.Position) string {
 		if s != "" {
 			s += ":"
 		}
-		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+		s += strconv.Itoa(pos.Line)
+		if pos.Column != 0 {
+			s += ":" + strconv.Itoa(pos.Column)
+		}
 	}
 	if s == "" {
 		s = "-"
@@ -339,12 +1813,42 @@ func ProcessArgs(name string, cs []CheckerConfig, args []string) {
 	ProcessFlagSet(cs, flags)
 }
 
-func (runner *runner) lint(lprog *loader.Program, conf *loader.Config) []lint.Problem {
+// load loads the program described by conf. The loader itself cannot
+// be interrupted; if ctx gets canceled, load returns immediately and
+// the result of the loader is discarded.
+func load(ctx context.Context, conf *loader.Config) (*loader.Program, error) {
+	type result struct {
+		lprog *loader.Program
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		lprog, err := conf.Load()
+		ch <- result{lprog, err}
+	}()
+	select {
+	case res := <-ch:
+		return res.lprog, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (runner *runner) lint(ctx context.Context, lprog *loader.Program, conf *loader.Config) ([]lint.Problem, []lint.Suppression, error) {
 	l := &lint.Linter{
-		Checker:       runner.checker,
-		Ignores:       runner.ignores,
-		GoVersion:     runner.version,
-		ReturnIgnored: runner.returnIgnored,
+		Checker:          runner.checker,
+		Ignores:          runner.ignores,
+		GoVersion:        runner.version,
+		ReturnIgnored:    runner.returnIgnored,
+		AnnotateVariants: runner.variants,
+		DocsURL:          runner.docsURL,
+		Checks:           runner.checks,
+		Config:           runner.config,
+		Progress:         runner.progress,
+	}
+	if runner.scopes != nil {
+		l.Scope = runner.scopes.scope
 	}
-	return l.Lint(lprog, conf)
+	ps, err := l.LintContext(ctx, lprog, conf)
+	return ps, l.Suppressions, err
 }
@@ -0,0 +1,418 @@
+package lintutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+// validateConfig validates the configuration of the project in dir
+// without loading or linting any packages: the configuration files
+// that apply to dir or to its subdirectories, the ignore file, the
+// //lint:ignore and //lint:file-ignore directives of Go files in dir
+// and its subdirectories, as well as the -checks and -ignore flags,
+// which are given as flagChecks and opt.Ignores.
+//
+// Check IDs are only validated against the checks of cs. Patterns
+// that refer to checkers other than those of cs, such as those of
+// other tools sharing the configuration, are assumed to be valid.
+func validateConfig(cs []lint.Checker, dir string, flagChecks []string, opt *Options) ErrorList {
+	v := &configValidator{cs: cs}
+	configs, goFiles := projectFiles(dir)
+	profileFound := false
+	for _, path := range configs {
+		if v.configFile(path, opt.Profile) {
+			profileFound = true
+		}
+	}
+	if opt.Profile != "" && !profileFound {
+		v.fail(token.Position{}, "profile %q was selected, but no %s defines it", opt.Profile, config.ConfigName)
+	}
+	if path, ok := findIgnoreFile(dir); ok {
+		v.ignoreFile(path)
+	}
+	for _, path := range goFiles {
+		v.directives(path)
+	}
+	v.checkList(nil, "-checks flag", flagChecks)
+	v.ignoreFlag(opt.Ignores)
+	return v.errs
+}
+
+// projectFiles returns the configuration files that apply to dir,
+// from the innermost to the outermost one, followed by those in its
+// subdirectories, and the Go files in dir and its subdirectories.
+// Like the go command, it skips testdata directories and directories
+// whose names start with a dot or an underscore.
+func projectFiles(dir string) (configs, goFiles []string) {
+	for d := dir; ; {
+		path, ok := config.Find(d)
+		if !ok {
+			break
+		}
+		configs = append(configs, path)
+		if cfg, err := config.Load(path); err == nil && cfg.Root {
+			break
+		}
+		parent := filepath.Dir(filepath.Dir(path))
+		if parent == filepath.Dir(path) {
+			break
+		}
+		d = parent
+	}
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := fi.Name()
+		if fi.IsDir() {
+			if path != dir && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case name == config.ConfigName && filepath.Dir(path) != dir:
+			configs = append(configs, path)
+		case strings.HasSuffix(name, ".go"):
+			goFiles = append(goFiles, path)
+		}
+		return nil
+	})
+	return configs, goFiles
+}
+
+// A configValidator collects the errors found while validating a
+// configuration.
+type configValidator struct {
+	cs   []lint.Checker
+	errs ErrorList
+	// scopes caches the effective configurations of directories.
+	scopes map[string]config.Config
+}
+
+// scope returns the effective configuration of dir. Errors in
+// configuration files are reported when validating the files.
+func (v *configValidator) scope(dir string) config.Config {
+	if cfg, ok := v.scopes[dir]; ok {
+		return cfg
+	}
+	sc, _ := config.LoadScoped(dir)
+	if v.scopes == nil {
+		v.scopes = map[string]config.Config{}
+	}
+	v.scopes[dir] = sc.Config
+	return sc.Config
+}
+
+func (v *configValidator) fail(pos token.Position, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ConfigError{Position: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+// configFile validates the configuration file at path. It reports
+// whether the file defines the profile named profile.
+func (v *configValidator) configFile(path, profile string) bool {
+	cfg, err := config.Load(path)
+	if err != nil {
+		v.errs = append(v.errs, configError(err))
+		return false
+	}
+	src, _ := ioutil.ReadFile(path)
+	f := configSource{path, src}
+
+	v.checkList(f.locator(""), "checks", cfg.Checks)
+	for _, typ := range cfg.ExhaustiveFields {
+		slash := strings.LastIndex(typ, "/")
+		dot := strings.LastIndex(typ, ".")
+		if dot <= slash || dot == len(typ)-1 {
+			v.fail(f.position("", typ), "malformed type %q in exhaustive_fields, must be an import path and a type name, as in \"example.com/pkg.T\"", typ)
+		}
+	}
+	v.packageList(f, "opaque_error_packages", cfg.OpaqueErrorPackages)
+	v.packageList(f, "insecure_tls_packages", cfg.InsecureTLSPackages)
+	for _, pattern := range cfg.Exclude {
+		if _, err := compileIgnorePattern(pattern); err != nil {
+			v.fail(f.position("", pattern), "invalid pattern %q in exclude: %v", pattern, err)
+		}
+	}
+
+	var keys []string
+	for key := range cfg.Messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pos := f.position("[messages]", key)
+		check := key
+		if i := strings.Index(key, "."); i != -1 {
+			check = key[:i]
+		}
+		if msg := v.checkPattern(check, false); msg != "" {
+			v.fail(pos, "message %s: %s", key, msg)
+		}
+		if _, err := lint.ParseMessage(cfg.Messages[key]); err != nil {
+			v.fail(pos, "invalid template of message %s: %s", key, err)
+		}
+	}
+
+	var names []string
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		section := "[profiles." + name + "]"
+		v.checkList(f.locator(section), fmt.Sprintf("checks of profile %q", name), p.Checks)
+		if p.Format != "" {
+			if _, err := parseOutputSpec(p.Format); err != nil {
+				v.fail(f.position(section, p.Format), "profile %q: %s", name, err)
+			}
+		}
+		var patterns []string
+		for pattern := range p.Severity {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			pos := f.position("[profiles."+name+".severity]", pattern)
+			if msg := v.checkPattern(pattern, false); msg != "" {
+				v.fail(pos, "severity of profile %q: %s", name, msg)
+			}
+			if sev := p.Severity[pattern]; !isOneOf(sev, config.Severities) {
+				v.fail(pos, "profile %q: invalid severity %q for %s, must be one of %s", name, sev, pattern, strings.Join(config.Severities, ", "))
+			}
+		}
+	}
+	_, ok := cfg.Profiles[profile]
+	return ok
+}
+
+// packageList validates the import paths of the option what in the
+// configuration file f.
+func (v *configValidator) packageList(f configSource, what string, paths []string) {
+	for _, path := range paths {
+		if path == "" || strings.Contains(strings.TrimSuffix(path, "/..."), "...") {
+			v.fail(f.position("", path), "malformed import path %q in %s, only a trailing \"/...\" may be used to include subpackages", path, what)
+		}
+	}
+}
+
+// ignoreFile validates the ignore file at path.
+func (v *configValidator) ignoreFile(path string) {
+	igs, err := parseIgnoreFile(path)
+	if err != nil {
+		if errs, ok := err.(ErrorList); ok {
+			v.errs = append(v.errs, errs...)
+		} else {
+			v.errs = append(v.errs, &ConfigError{Position: token.Position{Filename: path}, Msg: err.Error()})
+		}
+		return
+	}
+	for _, ig := range igs {
+		pi := ig.(*pathIgnore)
+		for _, c := range pi.checks {
+			if msg := v.checkPattern(c, false); msg != "" {
+				v.fail(token.Position{Filename: path, Line: pi.line}, "%s", msg)
+			}
+		}
+	}
+}
+
+// ignoreFlag validates the value of the -ignore flag.
+func (v *configValidator) ignoreFlag(s string) {
+	igs, err := parseIgnore(s)
+	if err != nil {
+		v.errs = append(v.errs, err.(ErrorList)...)
+		return
+	}
+	for _, ig := range igs {
+		gi := ig.(*lint.GlobIgnore)
+		if _, err := filepath.Match(gi.Pattern, ""); err != nil {
+			v.fail(token.Position{}, "-ignore flag: malformed pattern %q", gi.Pattern)
+		}
+		for _, c := range gi.Checks {
+			if msg := v.checkPattern(c, false); msg != "" {
+				v.fail(token.Position{}, "-ignore flag: %s", msg)
+			}
+		}
+	}
+}
+
+// directives validates the ignore directives of the Go file at path.
+// Files that fail to parse are validated as far as possible; syntax
+// errors are left to linting.
+func (v *configValidator) directives(path string) {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if f == nil {
+		return
+	}
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, "//lint:") {
+				continue
+			}
+			pos := fset.Position(c.Pos())
+			cmd, args := parseDirective(c.Text)
+			if cmd != "ignore" && cmd != "file-ignore" {
+				continue
+			}
+			if len(args) < 2 {
+				v.fail(pos, "malformed linter directive; missing the required reason field?")
+				continue
+			}
+			if msg := v.scope(filepath.Dir(path)).CheckIgnoreReason(strings.Join(args[1:], " ")); msg != "" {
+				v.fail(pos, "%s", msg)
+			}
+			for _, check := range strings.Split(args[0], ",") {
+				if msg := v.checkPattern(check, false); msg != "" {
+					v.fail(pos, "%s", msg)
+				}
+			}
+		}
+	}
+}
+
+// parseDirective splits a //lint: directive into its command and
+// arguments, like the linter does.
+func parseDirective(s string) (cmd string, args []string) {
+	fields := strings.Split(strings.TrimPrefix(s, "//lint:"), " ")
+	return fields[0], fields[1:]
+}
+
+// checkList validates a list of check patterns in the syntax of the
+// -checks flag, reporting malformed and unknown patterns, as well as
+// checks that are both enabled and disabled. locate returns the
+// positions of patterns; if it is nil, the list isn't in a file and
+// errors are attributed to what.
+func (v *configValidator) checkList(locate func(s string) token.Position, what string, list []string) {
+	fail := func(s, format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		if locate == nil {
+			v.fail(token.Position{}, "%s: %s", what, msg)
+			return
+		}
+		v.fail(locate(s), "%s", msg)
+	}
+	enabled := map[string]bool{}
+	for _, pat := range list {
+		if msg := v.checkPattern(pat, true); msg != "" {
+			fail(pat, "%s", msg)
+			continue
+		}
+		value := !strings.HasPrefix(pat, "-")
+		id := strings.TrimPrefix(pat, "-")
+		if id == "all" || id == "none" {
+			// Keywords reset the effect of earlier patterns.
+			enabled = map[string]bool{}
+			continue
+		}
+		if prev, ok := enabled[id]; ok && prev != value {
+			fail(pat, "conflicting settings: %s is both enabled and disabled in %s; the later setting wins", id, what)
+		}
+		enabled[id] = value
+	}
+}
+
+// checkPattern validates a check pattern, as used by ignore rules and
+// severities, or in lists of checks to enable, which may also disable
+// checks and use the keywords "all" and "none". It returns a
+// description of the problem, or the empty string if there is none.
+func (v *configValidator) checkPattern(pat string, list bool) string {
+	if list {
+		if pat == "all" || pat == "none" {
+			return ""
+		}
+		pat = strings.TrimPrefix(pat, "-")
+	}
+	if pat == "*" {
+		return ""
+	}
+	if _, err := filepath.Match(pat, ""); err != nil {
+		return fmt.Sprintf("malformed pattern %q", pat)
+	}
+	for _, c := range v.cs {
+		for id := range c.Funcs() {
+			if lint.MatchCheck(pat, id) {
+				return ""
+			}
+		}
+	}
+	wildcard := strings.ContainsAny(pat, `*?[\`)
+	if !wildcard {
+		if _, _, _, ok := lint.SplitCheckID(pat); !ok {
+			return fmt.Sprintf("%q is not a check ID", pat)
+		}
+	}
+	// Patterns that may refer to other checkers can't be validated.
+	i := strings.IndexAny(pat, "0123456789*?[\\")
+	if i <= 0 {
+		return ""
+	}
+	for _, c := range v.cs {
+		if pat[:i] != c.Prefix() {
+			continue
+		}
+		if wildcard {
+			return fmt.Sprintf("pattern %q matches no checks", pat)
+		}
+		return fmt.Sprintf("unknown check %q", pat)
+	}
+	return ""
+}
+
+// configSource is the source of a configuration file, which is
+// searched for the positions of settings.
+type configSource struct {
+	path string
+	src  []byte
+}
+
+// position returns the position of the first occurrence of s in the
+// section starting with the line section, or after it if s isn't in
+// the section, preferring quoted occurrences. If s can't be found,
+// the position only has a file name.
+func (f configSource) position(section, s string) token.Position {
+	pos := token.Position{Filename: f.path}
+	start, end := 0, len(f.src)
+	if section != "" {
+		i := bytes.Index(f.src, []byte("\n"+section+"\n"))
+		if i == -1 && bytes.HasPrefix(f.src, []byte(section+"\n")) {
+			i = 0
+		}
+		if i != -1 {
+			start = i
+			if j := bytes.Index(f.src[start+1:], []byte("\n[")); j != -1 {
+				end = start + 1 + j
+			}
+		}
+	}
+	for _, region := range [][2]int{{start, end}, {end, len(f.src)}} {
+		for _, needle := range []string{strconv.Quote(s), "'" + s + "'", s} {
+			if i := bytes.Index(f.src[region[0]:region[1]], []byte(needle)); i != -1 {
+				pos.Line = 1 + bytes.Count(f.src[:region[0]+i], []byte("\n"))
+				return pos
+			}
+		}
+	}
+	return pos
+}
+
+// locator returns a function that returns the positions of strings in
+// section, for use with checkList.
+func (f configSource) locator(section string) func(s string) token.Position {
+	return func(s string) token.Position {
+		return f.position(section, s)
+	}
+}
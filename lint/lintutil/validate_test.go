@@ -0,0 +1,183 @@
+package lintutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+func TestCheckPattern(t *testing.T) {
+	v := &configValidator{cs: []lint.Checker{fileChecker{}}}
+	tests := []struct {
+		pat  string
+		list bool
+		want string
+	}{
+		{"F1000", false, ""},
+		{"F*", false, ""},
+		{"F100?", false, ""},
+		{"*", false, ""},
+		{"F9999", false, `unknown check "F9999"`},
+		{"F9*", false, `pattern "F9*" matches no checks`},
+		{"F[", false, `malformed pattern "F["`},
+		{"foo", false, `"foo" is not a check ID`},
+		// Only patterns whose prefix is exactly that of a checker
+		// are validated; others may refer to other checkers.
+		{"FX1000", false, ""},
+		{"SA9999", false, ""},
+		{"acme/F9999", false, ""},
+		// Lists of checks may disable checks and use keywords.
+		{"-F1000", true, ""},
+		{"-F9999", true, `unknown check "F9999"`},
+		{"all", true, ""},
+		{"none", true, ""},
+		{"none", false, `"none" is not a check ID`},
+	}
+	for _, tt := range tests {
+		if got := v.checkPattern(tt.pat, tt.list); got != tt.want {
+			t.Errorf("checkPattern(%q, %t) = %q, want %q", tt.pat, tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestConfigSourcePosition(t *testing.T) {
+	f := configSource{"staticcheck.conf", []byte(`checks = ["F1000", 'F2000']
+
+[messages]
+F1000 = "checked"
+
+[profiles.ci]
+checks = ["F1000"]
+F2000 = "unquoted"
+
+[profiles.ci.severity]
+"F1000" = "error"
+`)}
+	tests := []struct {
+		section string
+		s       string
+		line    int
+	}{
+		{"", "F1000", 1},
+		{"", "F2000", 1},
+		{"[messages]", "F1000", 4},
+		{"[profiles.ci]", "F1000", 7},
+		// Occurrences in the section are preferred to quoted ones
+		// elsewhere.
+		{"[profiles.ci]", "F2000", 8},
+		{"[profiles.ci.severity]", "F1000", 11},
+		{"[profiles.missing]", "F2000", 1},
+		{"", "F3000", 0},
+	}
+	for _, tt := range tests {
+		pos := f.position(tt.section, tt.s)
+		if pos.Filename != f.path || pos.Line != tt.line {
+			t.Errorf("position(%q, %q) = %s, want line %d", tt.section, tt.s, pos, tt.line)
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		config.ConfigName: `root = true
+checks = [
+	"all",
+	"-F1000",
+	"F1000",
+	"F9999",
+	"SA9999",
+]
+exclude = ['a\']
+
+[messages]
+F2000 = "x"
+
+[profiles.ci]
+checks = ["F9*", "F1000"]
+
+[profiles.ci.severity]
+F1000 = "fatal"
+`,
+		// Keywords reset earlier settings, so this list has no
+		// conflicts.
+		"sub/" + config.ConfigName: `checks = ["-F1000", "none", "F1000"]
+`,
+		IgnoreFileName: `gen/** F1000
+*.pb.go F9999,SA1000
+`,
+		"a.go": `package a
+
+//lint:ignore F1000 reason
+var x int
+
+//lint:ignore F9999 reason
+var y int
+
+//lint:ignore F1000
+var z int
+`,
+		"testdata/b.go": `package b
+
+//lint:ignore F9999 testdata isn't validated
+var x int
+`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := filepath.Join(dir, config.ConfigName)
+	ignore := filepath.Join(dir, IgnoreFileName)
+	src := filepath.Join(dir, "a.go")
+	want := []string{
+		conf + ":5: conflicting settings: F1000 is both enabled and disabled in checks; the later setting wins",
+		conf + `:6: unknown check "F9999"`,
+		conf + `:9: invalid pattern "a\\" in exclude`,
+		conf + `:12: message F2000: unknown check "F2000"`,
+		conf + `:15: pattern "F9*" matches no checks`,
+		conf + `:18: profile "ci": invalid severity "fatal" for F1000`,
+		ignore + `:2: unknown check "F9999"`,
+		src + `:6:1: unknown check "F9999"`,
+		src + ":9:1: malformed linter directive",
+		"-checks flag: conflicting settings: F1000 is both enabled and disabled in -checks flag",
+		`-ignore flag: unknown check "F9999"`,
+	}
+	opt := &Options{Ignores: "*.go:F1000 gen/*:F9999"}
+	errs := validateConfig([]lint.Checker{fileChecker{}}, dir, []string{"F1000", "-F1000"}, opt)
+	if len(errs) != len(want) {
+		t.Errorf("got %d errors, want %d:\n%v", len(errs), len(want), errs)
+	}
+	for i, err := range errs {
+		if i < len(want) && !strings.HasPrefix(err.Error(), want[i]) {
+			t.Errorf("got error %q, want one starting with %q", err, want[i])
+		}
+	}
+
+	opt.Profile = "missing"
+	errs = validateConfig([]lint.Checker{fileChecker{}}, filepath.Join(dir, "sub"), nil, opt)
+	found := false
+	for _, err := range errs {
+		if strings.Contains(err.Error(), `profile "missing" was selected`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("got errors %v, want one about the missing profile", errs)
+	}
+}
@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"text/template"
+)
+
+// Checks may report problems with templated messages, which consist of
+// a message ID, a default template and structured arguments. Users can
+// replace the templates, for example to adapt the phrasing to their
+// team or to link to internal documentation, without changing checks.
+// Templates use the syntax of text/template, with the arguments as
+// data, as in "{{.name}} is unused", and the functions in
+// MessageFuncs.
+
+// Args are the arguments of a templated message. Values are formatted
+// with fmt.Sprint when the problem is reported.
+type Args map[string]interface{}
+
+// MessageFuncs are the functions available to message templates, which
+// format arguments consistently.
+var MessageFuncs = template.FuncMap{
+	// quote formats a string as a Go string literal.
+	"quote": strconv.Quote,
+	// code formats an identifier or expression as code, in Markdown
+	// syntax.
+	"code": func(s string) string { return "`" + s + "`" },
+}
+
+// ParseMessage parses a message template.
+func ParseMessage(text string) (*template.Template, error) {
+	return template.New("message").Funcs(MessageFuncs).Option("missingkey=error").Parse(text)
+}
+
+// RenderMessage renders the message template tmpl with args.
+func RenderMessage(tmpl *template.Template, args map[string]string) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// defaultMessages caches the parsed default templates of checks.
+var defaultMessages = struct {
+	mu sync.Mutex
+	m  map[string]*template.Template
+}{m: map[string]*template.Template{}}
+
+// Reportf reports a problem with a templated message. The message id
+// identifies the message among those of the check, and text is its
+// default template. See Problem.MessageID.
+func (j *Job) Reportf(n Positioner, id, text string, args Args) *Problem {
+	defaultMessages.mu.Lock()
+	tmpl, ok := defaultMessages.m[text]
+	if !ok {
+		var err error
+		tmpl, err = ParseMessage(text)
+		if err != nil {
+			defaultMessages.mu.Unlock()
+			panic(fmt.Sprintf("invalid template of message %s of %s: %s", id, j.check, err))
+		}
+		defaultMessages.m[text] = tmpl
+	}
+	defaultMessages.mu.Unlock()
+
+	strs := map[string]string{}
+	for k, v := range args {
+		strs[k] = fmt.Sprint(v)
+	}
+	msg, err := RenderMessage(tmpl, strs)
+	if err != nil {
+		panic(fmt.Sprintf("invalid arguments of message %s of %s: %s", id, j.check, err))
+	}
+	p := j.Errorf(n, "%s", msg)
+	p.MessageID = id
+	p.Args = strs
+	return p
+}
@@ -0,0 +1,123 @@
+// Package registry describes the checks that checkers provide, for
+// editors, language servers and other drivers that want to list
+// checks, their default enablement and their severities without
+// depending on the command line machinery in lintutil.
+package registry // import "honnef.co/go/tools/lint/registry"
+
+import (
+	"sort"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+// A Check describes a single check of a checker.
+type Check struct {
+	// ID is the check's ID, such as "SA1000".
+	ID string
+	// Checker is the name of the checker that provides the check.
+	Checker string
+	// Title and Text are the check's documentation. Title is
+	// empty for undocumented checks.
+	Title string
+	Text  string
+	// Default reports whether the check is enabled when no checks
+	// are configured.
+	Default bool
+	// Enabled reports whether the check is enabled by the options
+	// that the checks were listed with.
+	Enabled bool
+	// Severity is the severity of the check's problems, one of
+	// config.Severities.
+	Severity string
+}
+
+// Options select the checks that are enabled and their severities.
+// The zero value describes the default configuration.
+type Options struct {
+	// Checks are applied in order to the default set of checks, in
+	// the syntax of lint.EnabledChecks.
+	Checks []string
+	// Severity maps check patterns to severities. Checks that match
+	// no pattern have the severity "error".
+	Severity map[string]string
+}
+
+// ConfigOptions returns the options that a configuration selects,
+// including the checks and severities of the named profile, if
+// profile isn't empty.
+func ConfigOptions(cfg config.Config, profile string) (Options, error) {
+	opt := Options{Checks: append([]string(nil), cfg.Checks...)}
+	if profile == "" {
+		return opt, nil
+	}
+	p, err := cfg.Profile(profile)
+	if err != nil {
+		return Options{}, err
+	}
+	opt.Checks = append(opt.Checks, p.Checks...)
+	opt.Severity = p.Severity
+	return opt, nil
+}
+
+// Checks returns the checks of cs, ordered by checker and ID.
+func Checks(cs []lint.Checker, opt Options) []Check {
+	var out []Check
+	for _, c := range cs {
+		var docs map[string]*lint.Documentation
+		if dc, ok := c.(lint.DocumentedChecker); ok {
+			docs = dc.Docs()
+		}
+		enabled := map[string]bool{}
+		for _, id := range lint.EnabledChecks(c, opt.Checks) {
+			enabled[id] = true
+		}
+
+		var ids []string
+		for id, fn := range c.Funcs() {
+			if fn != nil {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			check := Check{
+				ID:       id,
+				Checker:  c.Name(),
+				Default:  true,
+				Enabled:  enabled[id],
+				Severity: Severity(opt.Severity, id),
+			}
+			if doc := docs[id]; doc != nil {
+				check.Title = doc.Title
+				check.Text = doc.Text
+				check.Default = !doc.NonDefault
+			}
+			if check.Severity == "" {
+				check.Severity = "error"
+			}
+			out = append(out, check)
+		}
+	}
+	return out
+}
+
+// Severity returns the severity of check according to the most
+// specific matching pattern in severities, or the empty string if no
+// pattern matches. Exact matches take precedence over patterns with
+// wildcards, and longer patterns over shorter ones.
+func Severity(severities map[string]string, check string) string {
+	if sev, ok := severities[check]; ok {
+		return sev
+	}
+	var best, sev string
+	for pattern, s := range severities {
+		if !lint.MatchCheck(pattern, check) {
+			continue
+		}
+		if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, sev = pattern, s
+		}
+	}
+	return sev
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
+)
+
+type testChecker struct{}
+
+func (testChecker) Name() string            { return "test" }
+func (testChecker) Prefix() string          { return "TEST" }
+func (testChecker) Init(prog *lint.Program) {}
+
+func (testChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"TEST1000": func(*lint.Job) {},
+		"TEST1001": func(*lint.Job) {},
+		"TEST1002": nil,
+	}
+}
+
+func (testChecker) Docs() map[string]*lint.Documentation {
+	return map[string]*lint.Documentation{
+		"TEST1000": {Title: "First check"},
+		"TEST1001": {Title: "Second check", NonDefault: true},
+	}
+}
+
+func TestChecks(t *testing.T) {
+	cfg := config.Config{
+		Checks: []string{"-TEST1000"},
+		Profiles: map[string]config.Profile{
+			"ci": {
+				Checks:   []string{"TEST1001"},
+				Severity: map[string]string{"TEST*": "warning"},
+			},
+		},
+	}
+	opt, err := ConfigOptions(cfg, "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Checks([]lint.Checker{testChecker{}}, opt)
+	want := []Check{
+		{ID: "TEST1000", Checker: "test", Title: "First check", Default: true, Enabled: false, Severity: "warning"},
+		{ID: "TEST1001", Checker: "test", Title: "Second check", Default: false, Enabled: true, Severity: "warning"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %+v, want %+v", got[i], want[i])
+		}
+	}
+
+	if _, err := ConfigOptions(cfg, "release"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	severities := map[string]string{
+		"*":      "info",
+		"SA*":    "warning",
+		"SA1000": "error",
+	}
+	tests := []struct {
+		check, want string
+	}{
+		{"SA1000", "error"},
+		{"SA1001", "warning"},
+		{"ST1000", "info"},
+	}
+	for _, tt := range tests {
+		if got := Severity(severities, tt.check); got != tt.want {
+			t.Errorf("Severity(%q) = %q, want %q", tt.check, got, tt.want)
+		}
+	}
+	if got := Severity(nil, "SA1000"); got != "" {
+		t.Errorf("Severity with no patterns = %q, want empty string", got)
+	}
+}
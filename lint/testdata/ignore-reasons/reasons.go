@@ -0,0 +1,13 @@
+package pkg
+
+//lint:ignore TEST1000 too short
+func fn1() {}
+
+//lint:ignore TEST1000 long enough, but without a ticket
+func fn2() {}
+
+//lint:ignore TEST1000 tracked in PROJ-123
+func fn3() {}
+
+// MATCH:3 "the reason of the linter directive must be at least 10 characters long"
+// MATCH:6 "the reason of the linter directive must match"
@@ -0,0 +1,9 @@
+package pkg
+
+//lint:ignore TEST1000 the package doesn't type-check
+var fooBar = undefinedThing
+
+//lint:ignore TEST1000
+func fn() {}
+
+// MATCH:6 /malformed linter directive/
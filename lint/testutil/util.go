@@ -7,6 +7,7 @@
 package testutil // import "honnef.co/go/tools/lint/testutil"
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"go/parser"
@@ -20,14 +21,58 @@ import (
 	"strings"
 	"testing"
 
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/lint"
 
 	"golang.org/x/tools/go/loader"
 )
 
 var lintMatch = flag.String("lint.match", "", "restrict testdata matches to this pattern")
+var updateGolden = flag.Bool("lint.update-golden", false, "write the results of applying suggested fixes to .golden files")
 
+// TestAll runs c on the files in testdata/dir and compares the
+// problems it finds with the instructions in the files' comments:
+//
+//	// MATCH "text"       expects a problem on this line
+//	// MATCH:N /regexp/   expects a problem on line N
+//	// RELATED "text"     expects a related position on this line
+//	// RELATED:N "text"   expects a related position on line N
+//	// MATCH:go1.N "text" expects a problem only when targeting Go 1.N
+//	//                    or newer
+//
+// Qualifiers can be combined, as in MATCH:go1.N:LINE. Files are
+// linted for the Go version in their name, such as file_go18.go, or
+// that of the directory they are in, such as go1.22/file.go, or Go
+// 1.0. Version directories allow keeping the test files of checks
+// whose behavior depends on the targeted version, such as checks of
+// loop variables, side by side under the same file name. Files with
+// version guards are additionally linted for each
+// guarded version and the one before it; unguarded instructions must
+// hold for all of them.
+// Related positions must belong to a problem in the same set of
+// files. If a check suggests fixes for problems in file.go, the result
+// of applying the first fix of each problem must equal file.go.golden;
+// run the tests with -lint.update-golden to update golden files.
 func TestAll(t *testing.T, c lint.Checker, dir string) {
+	TestAllConfig(t, c, dir, config.Config{})
+}
+
+// TestAllConfig is like TestAll, but lints the files with the
+// configuration cfg, for testing checks that depend on configuration
+// options. Such tests are usually kept in a subdirectory of testdata,
+// which TestAll ignores, unless it is a version directory.
+func TestAllConfig(t *testing.T, c lint.Checker, dir string, cfg config.Config) {
+	testAll(t, c, dir, cfg, false)
+}
+
+// TestAllPartial is like TestAll, but allows type errors in the
+// files, like runs with -partial do. Files with type errors are
+// only subjected to syntactic checks.
+func TestAllPartial(t *testing.T, c lint.Checker, dir string) {
+	testAll(t, c, dir, config.Config{}, true)
+}
+
+func testAll(t *testing.T, c lint.Checker, dir string, cfg config.Config, partial bool) {
 	baseDir := filepath.Join("testdata", dir)
 	fis, err := ioutil.ReadDir(baseDir)
 	if err != nil {
@@ -41,37 +86,97 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 		t.Fatalf("Bad -lint.match value %q: %v", *lintMatch, err)
 	}
 
-	files := map[int][]os.FileInfo{}
+	// names are the slash-separated paths of the test files,
+	// relative to baseDir. dirVersions maps the names of files in
+	// version directories to the versions of their directories.
+	var names []string
+	dirVersions := map[string]int{}
 	for _, fi := range fis {
-		if !rx.MatchString(fi.Name()) {
+		if !fi.IsDir() {
+			if strings.HasSuffix(fi.Name(), ".go") {
+				names = append(names, fi.Name())
+			}
 			continue
 		}
-		if !strings.HasSuffix(fi.Name(), ".go") {
+		v, ok := versionDir(fi.Name())
+		if !ok {
 			continue
 		}
-		parts := strings.Split(fi.Name(), "_")
+		sub, err := ioutil.ReadDir(filepath.Join(baseDir, fi.Name()))
+		if err != nil {
+			t.Fatalf("ioutil.ReadDir: %v", err)
+		}
+		for _, sfi := range sub {
+			if sfi.IsDir() || !strings.HasSuffix(sfi.Name(), ".go") {
+				continue
+			}
+			name := fi.Name() + "/" + sfi.Name()
+			names = append(names, name)
+			dirVersions[name] = v
+		}
+	}
+
+	files := map[int][]string{}
+	instructions := map[string][]instruction{}
+	// goldenVersions are the versions for which fixes are compared
+	// with golden files, the newest a file is linted for.
+	goldenVersions := map[string]int{}
+	for _, name := range names {
+		if !rx.MatchString(name) {
+			continue
+		}
+		parts := strings.Split(path.Base(name), "_")
 		v := 0
 		if len(parts) > 1 && strings.HasPrefix(parts[len(parts)-1], "go1") {
+			if _, ok := dirVersions[name]; ok {
+				t.Fatalf("file name %q has a version, but is in a version directory", name)
+			}
 			var err error
 			s := parts[len(parts)-1][len("go1"):]
 			s = s[:len(s)-len(".go")]
 			v, err = strconv.Atoi(s)
 			if err != nil {
-				t.Fatalf("cannot process file name %q: %s", fi.Name(), err)
+				t.Fatalf("cannot process file name %q: %s", name, err)
+			}
+		}
+		if dv, ok := dirVersions[name]; ok {
+			v = dv
+		}
+		src, err := ioutil.ReadFile(filepath.Join(baseDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("Failed reading %s: %v", name, err)
+		}
+		ins := parseInstructions(t, name, src)
+		instructions[name] = ins
+		versions := map[int]bool{v: true}
+		for _, in := range ins {
+			for _, gv := range []int{in.MinVersion - 1, in.MinVersion} {
+				if in.MinVersion != 0 && gv >= v {
+					versions[gv] = true
+				}
+			}
+		}
+		for v := range versions {
+			files[v] = append(files[v], name)
+			if v > goldenVersions[name] {
+				goldenVersions[name] = v
 			}
 		}
-		files[v] = append(files[v], fi)
 	}
 
 	conf := &loader.Config{
 		ParserMode: parser.ParseComments,
 	}
+	if partial {
+		conf.AllowErrors = true
+		conf.TypeChecker.Error = func(error) {}
+	}
 	sources := map[string][]byte{}
-	for _, fi := range fis {
-		filename := path.Join(baseDir, fi.Name())
+	for _, name := range names {
+		filename := path.Join(baseDir, name)
 		src, err := ioutil.ReadFile(filename)
 		if err != nil {
-			t.Errorf("Failed reading %s: %v", fi.Name(), err)
+			t.Errorf("Failed reading %s: %v", name, err)
 			continue
 		}
 		f, err := conf.ParseFile(filename, src)
@@ -79,8 +184,8 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 			t.Errorf("error parsing %s: %s", filename, err)
 			continue
 		}
-		sources[fi.Name()] = src
-		conf.CreateFromFiles(fi.Name(), f)
+		sources[name] = src
+		conf.CreateFromFiles(name, f)
 	}
 
 	lprog, err := conf.Load()
@@ -88,20 +193,27 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 		t.Fatalf("error loading program: %s", err)
 	}
 
-	for version, fis := range files {
-		l := &lint.Linter{Checker: c, GoVersion: version}
+	for version, names := range files {
+		l := &lint.Linter{Checker: c, GoVersion: version, Checks: []string{"all"}, Config: cfg}
 
 		res := l.Lint(lprog, conf)
-		for _, fi := range fis {
-			name := fi.Name()
+		all := append([]lint.Problem(nil), res...)
+		for _, name := range names {
 			src := sources[name]
 
-			ins := parseInstructions(t, name, src)
-
-			for _, in := range ins {
+			for _, in := range instructions[name] {
+				if version < in.MinVersion {
+					continue
+				}
+				if in.Related {
+					if !hasRelated(all, baseDir, name, in) {
+						t.Errorf("Lint failed at %s:%d for Go 1.%d; no related position matching /%v/", name, in.Line, version, in.Match)
+					}
+					continue
+				}
 				ok := false
 				for i, p := range res {
-					if p.Position.Line != in.Line || filepath.Base(p.Position.Filename) != name {
+					if p.Position.Line != in.Line || testName(baseDir, p.Position.Filename) != name {
 						continue
 					}
 					if in.Match.MatchString(p.Text) {
@@ -109,21 +221,24 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 						copy(res[i:], res[i+1:])
 						res = res[:len(res)-1]
 
-						//t.Logf("/%v/ matched at %s:%d", in.Match, fi.Name(), in.Line)
+						//t.Logf("/%v/ matched at %s:%d", in.Match, name, in.Line)
 						ok = true
 						break
 					}
 				}
 				if !ok {
-					t.Errorf("Lint failed at %s:%d; /%v/ did not match", name, in.Line, in.Match)
+					t.Errorf("Lint failed at %s:%d for Go 1.%d; /%v/ did not match", name, in.Line, version, in.Match)
 				}
 			}
+			if version == goldenVersions[name] {
+				checkGolden(t, filepath.Join(baseDir, filepath.FromSlash(name)), src, all)
+			}
 		}
 		for _, p := range res {
-			name := filepath.Base(p.Position.Filename)
-			for _, fi := range fis {
-				if name == fi.Name() {
-					t.Errorf("Unexpected problem at %s: %v", p.Position, p.Text)
+			name := testName(baseDir, p.Position.Filename)
+			for _, n := range names {
+				if name == n {
+					t.Errorf("Unexpected problem at %s for Go 1.%d: %v", p.Position, version, p.Text)
 					break
 				}
 			}
@@ -131,10 +246,90 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 	}
 }
 
+// versionDir reports whether the directory name, such as go1.22,
+// holds test files for a version of Go, and returns its minor
+// version.
+func versionDir(name string) (int, bool) {
+	if !strings.HasPrefix(name, "go1.") {
+		return 0, false
+	}
+	v, err := strconv.Atoi(strings.TrimPrefix(name, "go1."))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// testName returns the name of the test file filename, relative to
+// baseDir, as used as the key of instructions.
+func testName(baseDir, filename string) string {
+	rel, err := filepath.Rel(baseDir, filename)
+	if err != nil {
+		return filepath.Base(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func hasRelated(ps []lint.Problem, baseDir, name string, in instruction) bool {
+	for _, p := range ps {
+		for _, r := range p.Related {
+			if r.Position.Line == in.Line && testName(baseDir, r.Position.Filename) == name && in.Match.MatchString(r.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkGolden applies the first suggested fix of all problems to the
+// file at path and compares the result with path.golden.
+func checkGolden(t *testing.T, path string, src []byte, ps []lint.Problem) {
+	var edits []lint.Edit
+	for _, p := range ps {
+		if len(p.Fixes) == 0 {
+			continue
+		}
+		for _, e := range p.Fixes[0].Edits {
+			if filepath.Clean(e.Position.Filename) == filepath.Clean(path) {
+				edits = append(edits, e)
+			}
+		}
+	}
+	golden := path + ".golden"
+	want, err := ioutil.ReadFile(golden)
+	if err != nil && !os.IsNotExist(err) {
+		t.Errorf("Failed reading %s: %v", golden, err)
+		return
+	}
+	if err != nil && len(edits) == 0 {
+		return
+	}
+	got, err := lint.ApplyEdits(src, edits)
+	if err != nil {
+		t.Errorf("Failed applying fixes to %s: %v", path, err)
+		return
+	}
+	if *updateGolden {
+		if err := ioutil.WriteFile(golden, got, 0666); err != nil {
+			t.Errorf("Failed writing %s: %v", golden, err)
+		}
+		return
+	}
+	if want == nil {
+		t.Errorf("Fixes were suggested for %s, but %s doesn't exist", path, golden)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Applying fixes to %s doesn't produce %s; got:\n%s", path, golden, got)
+	}
+}
+
 type instruction struct {
 	Line        int            // the line number this applies to
 	Match       *regexp.Regexp // what pattern to match
 	Replacement string         // what the suggested replacement line should be
+	Related     bool           // whether Match applies to a related position
+	MinVersion  int            // the minimum Go version this applies to
 }
 
 // parseInstructions parses instructions from the comments in a Go source file.
@@ -158,7 +353,13 @@ func parseInstructions(t *testing.T, filename string, src []byte) []instruction
 				ins = make([]instruction, 0)
 				continue
 			}
-			if !strings.Contains(line, "MATCH") {
+			var kind string
+			switch {
+			case strings.Contains(line, "MATCH"):
+				kind = "MATCH"
+			case strings.Contains(line, "RELATED"):
+				kind = "RELATED"
+			default:
 				continue
 			}
 			rx, err := extractPattern(line)
@@ -166,13 +367,24 @@ func parseInstructions(t *testing.T, filename string, src []byte) []instruction
 				t.Fatalf("At %v:%d: %v", filename, ln, err)
 			}
 			matchLine := ln
-			if i := strings.Index(line, "MATCH:"); i >= 0 {
-				// This is a match for a different line.
-				lns := strings.TrimPrefix(line[i:], "MATCH:")
-				lns = lns[:strings.Index(lns, " ")]
-				matchLine, err = strconv.Atoi(lns)
-				if err != nil {
-					t.Fatalf("Bad match line number %q at %v:%d: %v", lns, filename, ln, err)
+			minVersion := 0
+			if i := strings.Index(line, kind+":"); i >= 0 {
+				quals := strings.TrimPrefix(line[i:], kind+":")
+				quals = quals[:strings.Index(quals, " ")]
+				for _, q := range strings.Split(quals, ":") {
+					if strings.HasPrefix(q, "go1.") {
+						// This only applies to newer Go versions.
+						minVersion, err = strconv.Atoi(strings.TrimPrefix(q, "go1."))
+						if err != nil {
+							t.Fatalf("Bad Go version %q at %v:%d: %v", q, filename, ln, err)
+						}
+						continue
+					}
+					// This is a match for a different line.
+					matchLine, err = strconv.Atoi(q)
+					if err != nil {
+						t.Fatalf("Bad match line number %q at %v:%d: %v", q, filename, ln, err)
+					}
 				}
 			}
 			var repl string
@@ -183,6 +395,8 @@ func parseInstructions(t *testing.T, filename string, src []byte) []instruction
 				Line:        matchLine,
 				Match:       rx,
 				Replacement: repl,
+				Related:     kind == "RELATED",
+				MinVersion:  minVersion,
 			})
 		}
 	}
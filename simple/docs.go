@@ -0,0 +1,480 @@
+package simple
+
+import "honnef.co/go/tools/lint"
+
+// Docs documents the checks and categories of checks of this checker.
+var Docs = map[string]*lint.Documentation{
+	"S1": {
+		Title: "Code simplifications",
+	},
+	"S1000": {
+		Title: "Use plain channel send or receive",
+		Text: "`select` with a single case can be replaced with a simple send or\n" +
+			"receive.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"select {\n" +
+			"case x := <-ch:\n" +
+			"  fmt.Println(x)\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"x := <-ch\n" +
+			"fmt.Println(x)\n" +
+			"```\n" +
+			"\n" +
+			"An empty `select {}` blocks forever. This is flagged outside of the\n" +
+			"functions main and init, where blocking forever is usually\n" +
+			"intended, as goroutines that can never finish leak.\n",
+	},
+	"S1001": {
+		Title: "Replace with `copy()`",
+		Text: "Use `copy()` for copying elements from one slice to another.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"for i, x := range src {\n" +
+			"  dst[i] = x\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"copy(dst, src)\n" +
+			"```\n",
+	},
+	"S1002": {
+		Title: "Omit comparison with boolean constant",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if x == true {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"if x {}\n" +
+			"```\n",
+	},
+	"S1003": {
+		Title: "Replace with `strings.Contains`",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if strings.Index(x, y) != -1 {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"if strings.Contains(x, y) {}\n" +
+			"```\n",
+	},
+	"S1004": {
+		Title: "Replace with `bytes.Equal`",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if bytes.Compare(x, y) == 0 {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"if bytes.Equal(x, y) {}\n" +
+			"```\n",
+	},
+	"S1005": {
+		Title: "Drop unnecessary use of the blank identifier",
+		Text: "In many cases, assigning to the blank identifier is unnecessary.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"for _ = range s {}\n" +
+			"x, _ = someMap[key]\n" +
+			"_ = <-ch\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"for range s{}\n" +
+			"x = someMap[key]\n" +
+			"<-ch\n" +
+			"```\n",
+	},
+	"S1006": {
+		Title: "Replace with `for { ... }`",
+		Text:  "For infinite loops, using `for { ... }` is the most idiomatic choice.\n",
+	},
+	"S1007": {
+		Title: "Simplify regular expression by using raw string literal",
+		Text: "Raw string literals use `` ` `` instead of `\"` and do not support any escape\n" +
+			"sequences. This means that the backslash (`\\`) can be used freely,\n" +
+			"without the need of escaping.\n" +
+			"\n" +
+			"Since regular expressions have their own escape sequences, raw strings\n" +
+			"can improve their readability.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"regexp.Compile(\"\\\\A(\\\\w+) profile: total \\\\d+\\\\n\\\\z\")\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"regexp.Compile(`\\A(\\w+) profile: total \\d+\\n\\z`)\n" +
+			"```\n",
+	},
+	"S1008": {
+		Title: "Simplify returning boolean expression",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if <expr> {\n" +
+			"  return true\n" +
+			"}\n" +
+			"return false\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"return <expr>\n" +
+			"```\n",
+	},
+	"S1009": {
+		Title: "Omit redundant nil check on slices",
+		Text: "The `len` function is defined for all slices, even nil ones, which\n" +
+			"have a length of zero. It is not necessary to check if a slice is not\n" +
+			"nil before checking that its length is not zero.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if x != nil && len(x) != 0 {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"if len(x) != 0 {}\n" +
+			"```\n",
+	},
+	"S1010": {
+		Title: "Omit default slice index",
+		Text: "When slicing, the second index defaults to the length of the value,\n" +
+			"making `s[n:len(s)]` and `s[n:]` equivalent.\n",
+	},
+	"S1011": {
+		Title: "Use a single append to concatenate two slices",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"for _, e := range y {\n" +
+			"  x = append(x, e)\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"x = append(x, y...)\n" +
+			"```\n",
+	},
+	"S1012": {
+		Title: "Replace with `time.Since(x)`",
+		Text: "The `time.Since` helper has the same effect as using\n" +
+			"`time.Now().Sub(x)` but is easier to read.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"time.Now().Sub(x)\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"time.Since(x)\n" +
+			"```\n",
+	},
+	"S1016": {
+		Title: "Use a type conversion",
+		Text: "Two struct types with identical fields can be converted between each\n" +
+			"other. In older versions of Go, the fields had to have identical\n" +
+			"struct tags. Since Go 1.8, however, struct tags are ignored during\n" +
+			"conversions. It is thus not necessary to manually copy every field\n" +
+			"individually.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"var x T1\n" +
+			"y := T2{\n" +
+			"  Field1: x.Field1,\n" +
+			"  Field2: x.Field2,\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"var x T1\n" +
+			"y := T2(x)\n" +
+			"```\n",
+	},
+	"S1017": {
+		Title: "Replace with `strings.TrimPrefix`",
+		Text: "Instead of using `strings.HasPrefix` and manual slicing, use the\n" +
+			"`strings.TrimPrefix` function. If the string doesn't start with the\n" +
+			"prefix, the original string will be returned. Using\n" +
+			"`strings.TrimPrefix` reduces complexity, and avoids common bugs, such\n" +
+			"as off-by-one mistakes.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if strings.HasPrefix(str, prefix) {\n" +
+			"  str = str[len(prefix):]\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"str = strings.TrimPrefix(str, prefix)\n" +
+			"```\n",
+	},
+	"S1018": {
+		Title: "Replace with `copy()`",
+		Text: "`copy()` permits using the same source and destination slice, even\n" +
+			"with overlapping ranges. This makes it ideal for sliding elements in a\n" +
+			"slice.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"for i := 0; i < n; i++ {\n" +
+			"  bs[i] = bs[offset+i]\n" +
+			"}\n" +
+			"\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"copy(bs[:n], bs[offset:])\n" +
+			"```\n",
+	},
+	"S1019": {
+		Title: "Simplify `make` call",
+		Text: "The `make` function has default values for the length and capacity\n" +
+			"arguments. For channels and maps, the length defaults to zero.\n" +
+			"Additionally, for slices the capacity defaults to the length.\n",
+	},
+	"S1020": {
+		Title: "Omit redundant nil check in type assertion",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if _, ok := i.(T); ok && i != nil {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"if _, ok := i.(T); ok {}\n" +
+			"```\n",
+	},
+	"S1021": {
+		Title: "Merge variable declaration and assignment",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"var x uint\n" +
+			"x = 1\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"var x uint = 1\n" +
+			"```\n",
+	},
+	"S1023": {
+		Title: "Omit redundant control flow",
+		Text: "Functions that have no return value do not need a `return` statement\n" +
+			"as the final statement of the function.\n" +
+			"\n" +
+			"Switches in Go do not have automatic fallthrough, unlike languages\n" +
+			"like C. It is not necessary to have a `break` statement as the final\n" +
+			"statement in a `case` block.\n",
+	},
+	"S1024": {
+		Title: "Replace with `time.Until(x)`",
+		Text: "The `time.Until` helper has the same effect as using\n" +
+			"`x.Sub(time.Now())` but is easier to read.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"x.Sub(time.Now())\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"time.Until(x)\n" +
+			"```\n",
+	},
+	"S1025": {
+		Title: "Don't use `fmt.Sprintf(\"%s\", x)` unnecessarily",
+		Text: "In many instances, there are easier and more efficient ways of getting\n" +
+			"a value's string representation. Whenever a value's underlying type is\n" +
+			"a string already, or the type has a `String` method, they should be\n" +
+			"used directly.\n" +
+			"\n" +
+			"Given the following shared definitions\n" +
+			"\n" +
+			"```\n" +
+			"type T1 string\n" +
+			"type T2 int\n" +
+			"\n" +
+			"func (T2) String() string { return \"Hello, world\" }\n" +
+			"\n" +
+			"var x string\n" +
+			"var y T1\n" +
+			"var z T2\n" +
+			"```\n" +
+			"\n" +
+			"we can simplify the following\n" +
+			"\n" +
+			"```\n" +
+			"fmt.Sprintf(\"%s\", x)\n" +
+			"fmt.Sprintf(\"%s\", y)\n" +
+			"fmt.Sprintf(\"%s\", z)\n" +
+			"```\n" +
+			"\n" +
+			"to\n" +
+			"\n" +
+			"```\n" +
+			"x\n" +
+			"string(y)\n" +
+			"z.String()\n" +
+			"```\n",
+	},
+	"S1028": {
+		Title: "Replace with `fmt.Errorf`",
+		Text: "**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"errors.New(fmt.Sprintf(...))\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"fmt.Errorf(...)\n" +
+			"```\n",
+	},
+	"S1029": {
+		Title: "Range over the string",
+		Text: "Ranging over a string will yield byte offsets and runes. If the offset\n" +
+			"isn't used, this is functionally equivalent to converting the string\n" +
+			"to a slice of runes and ranging over that. Ranging directly over the\n" +
+			"string will be more performant, however, as it avoids allocating a new\n" +
+			"slice, the size of which depends on the length of the string.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"for _, r := range []rune(s) {}\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"for _, r := range s {}\n" +
+			"```\n",
+	},
+	"S1030": {
+		Title: "Use `bytes.Buffer.String` or `bytes.Buffer.Bytes`",
+		Text: "`bytes.Buffer` has both a `String` and a `Bytes` method. It is never\n" +
+			"necessary to use `string(buf.Bytes())` or `[]byte(buf.String())` –\n" +
+			"simply use the other method.\n",
+	},
+	"S1031": {
+		Title: "Omit redundant nil check around loop",
+		Text: "You can use `range` on nil slices and maps, the loop will simply never\n" +
+			"execute. This makes an additional nil check around the loop\n" +
+			"unnecessary.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"if s != nil {\n" +
+			"  for _, x := range s {\n" +
+			"    ...\n" +
+			"  }\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"for _, x := range s {\n" +
+			"  ...\n" +
+			"}\n" +
+			"```\n",
+	},
+	"S1032": {
+		Title: "Replace with `sort.Ints(x)`, `sort.Float64s(x)`, `sort.Strings(x)`",
+		Text: "The `sort.Ints`, `sort.Float64s` and `sort.Strings` functions are\n" +
+			"easier to read than `sort.Sort(sort.IntSlice(x))`,\n" +
+			"`sort.Sort(sort.Float64Slice(x))` and\n" +
+			"`sort.Sort(sort.StringSlice(x))`.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"sort.Sort(sort.StringSlice(x))\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"sort.Strings(x)\n" +
+			"```\n",
+	},
+	"S1033": {
+		Title: "Replace with `time.Now().UnixMilli()`",
+		Text: "Since Go 1.17, the `UnixMilli` and `UnixMicro` methods of\n" +
+			"`time.Time` return the time in milliseconds and microseconds, which\n" +
+			"is easier to read than dividing the result of `UnixNano`.\n" +
+			"\n" +
+			"**Before:**\n" +
+			"\n" +
+			"```\n" +
+			"time.Now().UnixNano() / 1e6\n" +
+			"```\n" +
+			"\n" +
+			"**After:**\n" +
+			"\n" +
+			"```\n" +
+			"time.Now().UnixMilli()\n" +
+			"```\n",
+	},
+}
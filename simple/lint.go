@@ -30,6 +30,8 @@ func NewChecker() *Checker {
 func (*Checker) Name() string   { return "gosimple" }
 func (*Checker) Prefix() string { return "S" }
 
+func (*Checker) Docs() map[string]*lint.Documentation { return Docs }
+
 func (c *Checker) Init(prog *lint.Program) {}
 
 func (c *Checker) Funcs() map[string]lint.Func {
@@ -67,6 +69,7 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"S1030": c.LintBytesBufferConversions,
 		"S1031": c.LintNilCheckAroundRange,
 		"S1032": c.LintSortHelpers,
+		"S1033": c.LintTimeUnixMilli,
 	}
 }
 
@@ -89,11 +92,122 @@ func (c *Checker) LintSingleCaseSelect(j *lint.Job) {
 		if !ok {
 			return false
 		}
-		return len(v.Body.List) == 1
+		return len(v.Body.List) == 1 && v.Body.List[0].(*ast.CommClause).Comm != nil
+	}
+
+	// breaks reports whether any of stmts contains a break statement
+	// that refers to the innermost select.
+	breaks := func(stmts []ast.Stmt) bool {
+		found := false
+		fn := func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				return false
+			case *ast.BranchStmt:
+				if node.Tok == token.BREAK && node.Label == nil {
+					found = true
+				}
+			}
+			return !found
+		}
+		for _, stmt := range stmts {
+			ast.Inspect(stmt, fn)
+		}
+		return found
+	}
+	// conflicts reports whether moving the variables declared in
+	// clause to the enclosing block would clash with other
+	// variables, either in the enclosing block or in the statements
+	// that follow sel.
+	conflicts := func(clause *ast.CommClause, rest []ast.Stmt) bool {
+		scope := j.Program.Info.Scopes[clause]
+		if scope == nil {
+			return true
+		}
+		parent := scope.Parent()
+		for _, name := range scope.Names() {
+			if parent.Lookup(name) != nil {
+				return true
+			}
+			_, outer := parent.LookupParent(name, token.NoPos)
+			if outer == nil {
+				continue
+			}
+			used := false
+			for _, stmt := range rest {
+				ast.Inspect(stmt, func(node ast.Node) bool {
+					if ident, ok := node.(*ast.Ident); ok && ObjectOf(j, ident) == outer {
+						used = true
+					}
+					return !used
+				})
+			}
+			if used {
+				return true
+			}
+		}
+		return false
+	}
+	// rewrite returns the edits that replace the single-case select
+	// sel with its channel operation, followed by the body of the
+	// case, or nil if that can't be done safely. Comments are
+	// preserved.
+	rewrite := func(f *ast.File, sel *ast.SelectStmt, rest []ast.Stmt) []lint.Edit {
+		clause := sel.Body.List[0].(*ast.CommClause)
+		comm := Render(j, clause.Comm)
+		if breaks(clause.Body) || conflicts(clause, rest) {
+			return nil
+		}
+		tf := j.Program.SSA.Fset.File(sel.Pos())
+		lbrace, caseLine, colon, rbrace := tf.Line(sel.Body.Lbrace), tf.Line(clause.Case), tf.Line(clause.Colon), tf.Line(sel.Body.Rbrace)
+		if len(clause.Body) == 0 && lbrace == rbrace {
+			for _, cg := range f.Comments {
+				if cg.Pos() > sel.Pos() && cg.End() < sel.End() {
+					return nil
+				}
+			}
+			return []lint.Edit{j.Replace(sel, comm)}
+		}
+		if caseLine == lbrace || colon == rbrace {
+			// Not formatted by gofmt.
+			return nil
+		}
+		if len(clause.Body) > 0 && (tf.Line(clause.Body[0].Pos()) == colon || tf.Line(clause.Body[len(clause.Body)-1].End()) == rbrace) {
+			return nil
+		}
+		if spansLines(f, tf, clause.Colon, sel.Body.Rbrace) {
+			return nil
+		}
+		edits := []lint.Edit{
+			j.ReplaceRange(sel.Pos(), sel.Body.Lbrace+1, comm),
+			j.ReplaceRange(tf.LineStart(caseLine)-1, clause.Colon+1, ""),
+		}
+		edits = append(edits, unindent(j, tf, colon+1, rbrace-1)...)
+		edits = append(edits, j.ReplaceRange(tf.LineStart(rbrace)-1, sel.Body.Rbrace+1, ""))
+		return edits
 	}
 
 	seen := map[ast.Node]struct{}{}
+	// rest maps statements to the statements that follow them in
+	// their blocks. Labeled statements have no entries, as their
+	// labels can't be preserved.
+	rest := map[ast.Stmt][]ast.Stmt{}
+	var f *ast.File
+	var blocking bool
 	fn := func(node ast.Node) bool {
+		var list []ast.Stmt
+		switch v := node.(type) {
+		case *ast.BlockStmt:
+			list = v.List
+		case *ast.CaseClause:
+			list = v.Body
+		case *ast.CommClause:
+			list = v.Body
+		}
+		for i, stmt := range list {
+			rest[stmt] = list[i+1:]
+		}
+
 		switch v := node.(type) {
 		case *ast.ForStmt:
 			if len(v.Body.List) != 1 {
@@ -112,17 +226,73 @@ func (c *Checker) LintSingleCaseSelect(j *lint.Job) {
 			if _, ok := seen[v]; ok {
 				return true
 			}
+			if len(v.Body.List) == 0 && !blocking {
+				j.Errorf(node, "select {} blocks forever; unless the goroutine should never finish, wait for a channel or context instead")
+				return true
+			}
 			if !isSingleSelect(v) {
 				return true
 			}
-			j.Errorf(node, "should use a simple channel send/receive instead of select with a single case")
+			p := j.Errorf(node, "should use a simple channel send/receive instead of select with a single case")
+			if after, ok := rest[v]; ok {
+				if edits := rewrite(f, v, after); edits != nil {
+					j.AddFix(p, "use a plain channel operation", edits...)
+				}
+			}
 			return true
 		}
 		return true
 	}
-	for _, f := range c.filterGenerated(j.Program.Files) {
-		ast.Inspect(f, fn)
+	for _, f = range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			// Blocking forever is intended in main and init,
+			// for example in programs that only serve requests.
+			blocking = false
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Recv == nil {
+				blocking = fd.Name.Name == "init" || (fd.Name.Name == "main" && f.Name.Name == "main")
+			}
+			ast.Inspect(decl, fn)
+		}
+	}
+}
+
+// spansLines reports whether the source between pos and end contains
+// a string literal or comment that spans multiple lines, whose
+// contents mustn't be reindented.
+func spansLines(f *ast.File, tf *token.File, pos, end token.Pos) bool {
+	found := false
+	ast.Inspect(f, func(node ast.Node) bool {
+		if node == nil || node.End() < pos || node.Pos() > end || found {
+			return false
+		}
+		if lit, ok := node.(*ast.BasicLit); ok && lit.Kind == token.STRING && tf.Line(lit.Pos()) != tf.Line(lit.End()) {
+			found = true
+		}
+		return true
+	})
+	for _, cg := range f.Comments {
+		for _, cmt := range cg.List {
+			if cmt.End() >= pos && cmt.Pos() <= end && tf.Line(cmt.Pos()) != tf.Line(cmt.End()) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// unindent returns edits that remove one level of indentation from
+// the lines first to last, for fixes that move code out of a block.
+func unindent(j *lint.Job, tf *token.File, first, last int) []lint.Edit {
+	var edits []lint.Edit
+	for l := first; l <= last; l++ {
+		start := tf.LineStart(l)
+		if tf.LineStart(l+1)-start <= 1 {
+			// empty line
+			continue
+		}
+		edits = append(edits, j.ReplaceRange(start, start+1, ""))
 	}
+	return edits
 }
 
 func (c *Checker) LintLoopCopy(j *lint.Job) {
@@ -653,7 +823,8 @@ func (c *Checker) LintRedundantNilCheckWithLen(j *lint.Job) {
 		default:
 			return true
 		}
-		j.Errorf(expr, "should omit nil check; len() for %s is defined as zero", nilType)
+		p := j.Errorf(expr, "should omit nil check; len() for %s is defined as zero", nilType)
+		j.AddFix(p, "remove nil check", j.ReplaceRange(expr.Pos(), y.Pos(), ""))
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -804,7 +975,8 @@ func (c *Checker) LintTimeSince(j *lint.Job) {
 		if sel.Sel.Name != "Sub" {
 			return true
 		}
-		j.Errorf(call, "should use time.Since instead of time.Now().Sub")
+		p := j.Errorf(call, "should use time.Since instead of time.Now().Sub")
+		j.AddFix(p, "use time.Since", j.Replace(call, timeQualifier(j, sel.X)+"Since("+RenderArgs(j, call.Args)+")"))
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -812,6 +984,13 @@ func (c *Checker) LintTimeSince(j *lint.Job) {
 	}
 }
 
+// timeQualifier returns the qualifier, such as "time.", with which
+// the call to time.Now refers to the time package. Fixes reuse it,
+// as the import may be renamed.
+func timeQualifier(j *lint.Job, now ast.Expr) string {
+	return Render(j, now.(*ast.CallExpr).Fun.(*ast.SelectorExpr).X) + "."
+}
+
 func (c *Checker) LintTimeUntil(j *lint.Job) {
 	if !IsGoVersion(j, 8) {
 		return
@@ -827,7 +1006,55 @@ func (c *Checker) LintTimeUntil(j *lint.Job) {
 		if !IsCallToAST(j, call.Args[0], "time.Now") {
 			return true
 		}
-		j.Errorf(call, "should use time.Until instead of t.Sub(time.Now())")
+		p := j.Errorf(call, "should use time.Until instead of t.Sub(time.Now())")
+		t := call.Fun.(*ast.SelectorExpr).X
+		j.AddFix(p, "use time.Until", j.Replace(call, timeQualifier(j, call.Args[0])+"Until("+Render(j, t)+")"))
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintTimeUnixMilli(j *lint.Job) {
+	if !IsGoVersion(j, 17) {
+		return
+	}
+	fn := func(node ast.Node) bool {
+		expr, ok := node.(*ast.BinaryExpr)
+		if !ok || expr.Op != token.QUO {
+			return true
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok || !IsCallToAST(j, call, "(time.Time).UnixNano") {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		// Only the current time is flagged: for times before 1970,
+		// UnixMilli rounds down, while the division rounds towards
+		// zero, and UnixNano overflows for times far from 1970.
+		if !ok || !IsCallToAST(j, sel.X, "time.Now") {
+			return true
+		}
+		tv := j.Program.Info.Types[expr.Y]
+		if tv.Value == nil {
+			return true
+		}
+		d, ok := constant.Int64Val(constant.ToInt(tv.Value))
+		if !ok {
+			return true
+		}
+		var method string
+		switch d {
+		case 1e3:
+			method = "UnixMicro"
+		case 1e6:
+			method = "UnixMilli"
+		default:
+			return true
+		}
+		p := j.Errorf(expr, "should use time.Now().%s() instead of dividing time.Now().UnixNano()", method)
+		j.AddFix(p, "use "+method, j.Replace(expr, Render(j, sel.X)+"."+method+"()"))
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -1613,18 +1840,51 @@ func (c *Checker) LintRangeStringRunes(j *lint.Job) {
 }
 
 func (c *Checker) LintNilCheckAroundRange(j *lint.Job) {
+	// unwrap returns the edits that replace ifstmt with the loop it
+	// contains, or nil if the code isn't formatted as expected.
+	unwrap := func(f *ast.File, ifstmt *ast.IfStmt, loop *ast.RangeStmt) []lint.Edit {
+		tf := j.Program.SSA.Fset.File(ifstmt.Pos())
+		ifLine, rbrace := tf.Line(ifstmt.Pos()), tf.Line(ifstmt.Body.Rbrace)
+		if tf.Line(loop.Pos()) == ifLine || tf.Line(loop.End()) == rbrace {
+			return nil
+		}
+		if spansLines(f, tf, ifstmt.Pos(), ifstmt.End()) {
+			return nil
+		}
+		// Comments on the line of the if statement are kept on a
+		// line of their own.
+		header := j.ReplaceRange(tf.LineStart(ifLine), tf.LineStart(ifLine+1), "")
+		for _, cg := range f.Comments {
+			if cg.Pos() > ifstmt.Body.Lbrace && tf.Line(cg.Pos()) == ifLine {
+				header = j.ReplaceRange(ifstmt.Pos(), cg.Pos(), "")
+				break
+			}
+		}
+		edits := []lint.Edit{header}
+		edits = append(edits, unindent(j, tf, ifLine+1, rbrace-1)...)
+		edits = append(edits, j.ReplaceRange(tf.LineStart(rbrace)-1, ifstmt.Body.Rbrace+1, ""))
+		return edits
+	}
+
+	// elseIfs are if statements in else branches, which can't be
+	// removed.
+	elseIfs := map[*ast.IfStmt]bool{}
+	var f *ast.File
 	fn := func(node ast.Node) bool {
 		ifstmt, ok := node.(*ast.IfStmt)
 		if !ok {
 			return true
 		}
+		if elif, ok := ifstmt.Else.(*ast.IfStmt); ok {
+			elseIfs[elif] = true
+		}
 
 		cond, ok := ifstmt.Cond.(*ast.BinaryExpr)
 		if !ok {
 			return true
 		}
 
-		if cond.Op != token.NEQ || !IsNil(j, cond.Y) || len(ifstmt.Body.List) != 1 {
+		if cond.Op != token.NEQ || !IsNil(j, cond.Y) || len(ifstmt.Body.List) != 1 || ifstmt.Else != nil {
 			return true
 		}
 
@@ -1645,11 +1905,16 @@ func (c *Checker) LintNilCheckAroundRange(j *lint.Job) {
 		}
 		switch j.Program.Info.TypeOf(rangeXIdent).(type) {
 		case *types.Slice, *types.Map:
-			j.Errorf(node, "unnecessary nil check around range")
+			p := j.Errorf(node, "unnecessary nil check around range")
+			if ifstmt.Init == nil && !elseIfs[ifstmt] {
+				if edits := unwrap(f, ifstmt, loop); edits != nil {
+					j.AddFix(p, "remove nil check", edits...)
+				}
+			}
 		}
 		return true
 	}
-	for _, f := range c.filterGenerated(j.Program.Files) {
+	for _, f = range c.filterGenerated(j.Program.Files) {
 		ast.Inspect(f, fn)
 	}
 }
@@ -38,4 +38,21 @@ func main() {
 			fmt.Println(v)
 		}
 	}
+
+	// the nil check matters if there's an else branch
+	if str != nil {
+		for _, s := range str {
+			s = s + "E"
+		}
+	} else {
+		fmt.Println("nil")
+	}
+
+	if len(str) > 0 {
+		fmt.Println()
+	} else if str != nil { // MATCH /unnecessary nil check around range/
+		for _, s := range str {
+			s = s + "F"
+		}
+	}
 }
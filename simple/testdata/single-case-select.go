@@ -26,3 +26,45 @@ outer:
 		}
 	}
 }
+
+func fn2(ch chan int) int {
+	select { // MATCH /should use a simple channel send/
+	case v := <-ch:
+		// use v
+		v++
+
+		return v
+	}
+}
+
+func fn3(ch chan int, v int) int {
+	select { // MATCH /should use a simple channel send/
+	case v := <-ch:
+		return v
+	}
+}
+
+func fn4(ch chan int) {
+	for i := 0; i < 10; i++ {
+		select { // MATCH /should use a simple channel send/
+		case <-ch:
+			if i > 5 {
+				break
+			}
+			println(i)
+		}
+		println()
+	}
+}
+
+func fn5(ch chan int) {
+	select {
+	default:
+	}
+	select { // MATCH "select {} blocks forever"
+	}
+}
+
+func init() {
+	select {}
+}
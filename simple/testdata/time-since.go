@@ -1,9 +1,13 @@
 package pkg
 
-import "time"
+import (
+	"time"
+	t "time"
+)
 
 func fn() {
 	t1 := time.Now()
 	_ = time.Now().Sub(t1) // MATCH "time.Since"
 	_ = time.Date(0, 0, 0, 0, 0, 0, 0, nil).Sub(t1)
+	_ = t.Now().Sub(t1) // MATCH "time.Since"
 }
@@ -0,0 +1,18 @@
+package pkg
+
+import (
+	"time"
+	t2 "time"
+)
+
+func fn(t time.Time) {
+	_ = time.Now().UnixNano() / 1e6                     // MATCH:go1.17 "should use time.Now().UnixMilli() instead of dividing time.Now().UnixNano()"
+	_ = time.Now().UnixNano() / 1000                    // MATCH:go1.17 "should use time.Now().UnixMicro()"
+	_ = time.Now().UnixNano() / int64(time.Millisecond) // MATCH:go1.17 "UnixMilli"
+	_ = t2.Now().UnixNano() / 1000000                   // MATCH:go1.17 "UnixMilli"
+	_ = time.Now().UnixNano() / 1e9
+	_ = t.UnixNano() / 1e6
+	_ = time.Now().UnixNano() * 1e6
+	d := int64(1e6)
+	_ = time.Now().UnixNano() / d
+}
@@ -0,0 +1,12 @@
+package pkg
+
+import (
+	"time"
+	t2 "time"
+)
+
+func fn(t time.Time) {
+	t.Sub(time.Now()) // MATCH:go1.8 "time.Until"
+	t.Sub(t)
+	_ = t.Add(time.Hour).Sub(t2.Now()) // MATCH:go1.8 "time.Until"
+}
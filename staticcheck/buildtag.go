@@ -1,9 +1,18 @@
 package staticcheck
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
+	"honnef.co/go/tools/lint"
 	. "honnef.co/go/tools/lint/lintdsl"
 )
 
@@ -19,3 +28,597 @@ func buildTags(f *ast.File) [][]string {
 	}
 	return out
 }
+
+// goosList and goarchList are the values of GOOS and GOARCH that
+// build constraints may refer to.
+var (
+	goosList = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd",
+		"illumos", "ios", "js", "linux", "nacl", "netbsd", "openbsd",
+		"plan9", "solaris", "wasip1", "windows", "zos",
+	}
+	goarchList = []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be",
+		"loong64", "mips", "mipsle", "mips64", "mips64le", "mips64p32",
+		"mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+		"s390", "s390x", "sparc", "sparc64", "wasm",
+	}
+	// unixList are the operating systems that satisfy the unix
+	// build tag.
+	unixList = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd",
+		"illumos", "ios", "linux", "netbsd", "openbsd", "solaris",
+	}
+	// noAMD64GOOS are the operating systems of goosList that
+	// don't support amd64.
+	noAMD64GOOS = []string{"aix", "hurd", "js", "nacl", "wasip1", "zos"}
+	// impliedGOOS maps operating systems to the ones whose build
+	// tags they also satisfy.
+	impliedGOOS = map[string]string{
+		"android": "linux",
+		"illumos": "solaris",
+		"ios":     "darwin",
+	}
+	// otherBuildTags are build tags that the go tool sets, or that
+	// are commonly used to exclude files.
+	otherBuildTags = []string{
+		"asan", "cgo", "gc", "gccgo", "ignore", "msan", "purego", "race", "unix",
+	}
+)
+
+var goVersionTagRx = regexp.MustCompile(`^go1\.[0-9]+$`)
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownBuildTag(tag string) bool {
+	return contains(goosList, tag) || contains(goarchList, tag) ||
+		contains(otherBuildTags, tag) || goVersionTagRx.MatchString(tag)
+}
+
+// A constraint is a boolean expression of build tags.
+type constraint interface {
+	eval(tag func(string) bool) bool
+	tags(fn func(string))
+}
+
+type (
+	tagConstraint string
+	notConstraint struct{ x constraint }
+	andConstraint struct{ x, y constraint }
+	orConstraint  struct{ x, y constraint }
+)
+
+func (c tagConstraint) eval(tag func(string) bool) bool { return tag(string(c)) }
+func (c notConstraint) eval(tag func(string) bool) bool { return !c.x.eval(tag) }
+func (c andConstraint) eval(tag func(string) bool) bool { return c.x.eval(tag) && c.y.eval(tag) }
+func (c orConstraint) eval(tag func(string) bool) bool  { return c.x.eval(tag) || c.y.eval(tag) }
+
+func (c tagConstraint) tags(fn func(string)) { fn(string(c)) }
+func (c notConstraint) tags(fn func(string)) { c.x.tags(fn) }
+func (c andConstraint) tags(fn func(string)) { c.x.tags(fn); c.y.tags(fn) }
+func (c orConstraint) tags(fn func(string))  { c.x.tags(fn); c.y.tags(fn) }
+
+func isTagChar(r byte) bool {
+	return r == '_' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+func isTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTagChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseGoBuild parses the expression of a //go:build line.
+func parseGoBuild(expr string) (constraint, error) {
+	var toks []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '!':
+			toks = append(toks, expr[i:i+1])
+			i++
+		case strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			toks = append(toks, expr[i:i+2])
+			i += 2
+		case isTagChar(c):
+			j := i
+			for j < len(expr) && isTagChar(expr[j]) {
+				j++
+			}
+			toks = append(toks, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+
+	var parseOr, parseAnd, parseNot func() (constraint, error)
+	parseOr = func() (constraint, error) {
+		x, err := parseAnd()
+		for err == nil && len(toks) > 0 && toks[0] == "||" {
+			toks = toks[1:]
+			var y constraint
+			y, err = parseAnd()
+			x = orConstraint{x, y}
+		}
+		return x, err
+	}
+	parseAnd = func() (constraint, error) {
+		x, err := parseNot()
+		for err == nil && len(toks) > 0 && toks[0] == "&&" {
+			toks = toks[1:]
+			var y constraint
+			y, err = parseNot()
+			x = andConstraint{x, y}
+		}
+		return x, err
+	}
+	parseNot = func() (constraint, error) {
+		if len(toks) == 0 {
+			return nil, errors.New("unexpected end of expression")
+		}
+		tok := toks[0]
+		toks = toks[1:]
+		switch {
+		case tok == "!":
+			x, err := parseNot()
+			return notConstraint{x}, err
+		case tok == "(":
+			x, err := parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if len(toks) == 0 || toks[0] != ")" {
+				return nil, errors.New("missing )")
+			}
+			toks = toks[1:]
+			return x, nil
+		case isTag(tok):
+			return tagConstraint(tok), nil
+		default:
+			return nil, fmt.Errorf("unexpected %s", tok)
+		}
+	}
+
+	x, err := parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) > 0 {
+		return nil, fmt.Errorf("unexpected %s", toks[0])
+	}
+	return x, nil
+}
+
+// parsePlusBuild parses the fields of // +build lines. All lines
+// must be satisfied, a line is satisfied if any of its fields is, and
+// a field is satisfied if all of its comma-separated terms are.
+func parsePlusBuild(lines [][]string) (constraint, error) {
+	var x constraint
+	for _, fields := range lines {
+		var line constraint
+		for _, field := range fields {
+			var and constraint
+			for _, term := range strings.Split(field, ",") {
+				tag := strings.TrimPrefix(term, "!")
+				if !isTag(tag) {
+					return nil, fmt.Errorf("invalid term %q", term)
+				}
+				var t constraint = tagConstraint(tag)
+				if tag != term {
+					t = notConstraint{t}
+				}
+				if and == nil {
+					and = t
+				} else {
+					and = andConstraint{and, t}
+				}
+			}
+			if line == nil {
+				line = and
+			} else {
+				line = orConstraint{line, and}
+			}
+		}
+		if line == nil {
+			continue
+		}
+		if x == nil {
+			x = line
+		} else {
+			x = andConstraint{x, line}
+		}
+	}
+	return x, nil
+}
+
+// maxConstraintTags limits the number of free tags for which
+// constraints are evaluated exhaustively.
+const maxConstraintTags = 12
+
+func constraintTags(cs ...constraint) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range cs {
+		c.tags(func(tag string) {
+			if !seen[tag] {
+				seen[tag] = true
+				out = append(out, tag)
+			}
+		})
+	}
+	sort.Strings(out)
+	return out
+}
+
+// constraintsEqual reports whether x and y are satisfied by the same
+// sets of tags. It returns true if there are too many tags to tell.
+func constraintsEqual(x, y constraint) bool {
+	tags := constraintTags(x, y)
+	if len(tags) > maxConstraintTags {
+		return true
+	}
+	set := map[string]bool{}
+	tag := func(t string) bool { return set[t] }
+	for bits := 0; bits < 1<<uint(len(tags)); bits++ {
+		for i, t := range tags {
+			set[t] = bits&(1<<uint(i)) != 0
+		}
+		if x.eval(tag) != y.eval(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSatisfiable reports whether some build configuration satisfies
+// c, taking into account that only one GOOS and one GOARCH can be
+// set at a time. It returns true if there are too many tags to tell.
+func isSatisfiable(c constraint) bool {
+	// Besides the values that c refers to, the empty string stands
+	// for all other operating systems and architectures.
+	gooses, goarches := []string{""}, []string{""}
+	var free []string
+	for _, t := range constraintTags(c) {
+		switch {
+		case contains(goosList, t):
+			gooses = append(gooses, t)
+		case contains(goarchList, t):
+			goarches = append(goarches, t)
+		default:
+			free = append(free, t)
+		}
+	}
+	if len(free) > maxConstraintTags {
+		return true
+	}
+	set := map[string]bool{}
+	for _, goos := range gooses {
+		for _, goarch := range goarches {
+			for bits := 0; bits < 1<<uint(len(free)); bits++ {
+				for i, t := range free {
+					set[t] = bits&(1<<uint(i)) != 0
+				}
+				ok := c.eval(func(t string) bool {
+					switch {
+					case contains(goosList, t):
+						return t == goos || impliedGOOS[goos] == t
+					case contains(goarchList, t):
+						return t == goarch
+					case t == "unix" && goos != "":
+						return contains(unixList, goos)
+					default:
+						return set[t]
+					}
+				})
+				if ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// fileConstraint is the build constraint of a file.
+type fileConstraint struct {
+	name string
+	// goBuild is the first //go:build line, if any, and plusBuild
+	// are the // +build lines.
+	goBuild   *ast.Comment
+	plusBuild []*ast.Comment
+	// expr is the parsed constraint, preferring the //go:build
+	// line. It is nil if the file has no constraint or it is
+	// invalid.
+	expr constraint
+	// problems are syntax errors and other problems with the
+	// lines.
+	problems []string
+	// pos is the position at which problems are reported.
+	pos token.Pos
+}
+
+// plusBuildText returns the constraint of a // +build line.
+func plusBuildText(cm *ast.Comment) string {
+	text := strings.TrimSpace(strings.TrimPrefix(cm.Text, "//"))
+	return strings.TrimSpace(strings.TrimPrefix(text, "+build"))
+}
+
+func newFileConstraint(name string, f *ast.File) *fileConstraint {
+	fc := &fileConstraint{name: name, pos: f.Package}
+	cutoff := f.Package
+	if f.Doc != nil {
+		cutoff = f.Doc.Pos()
+	}
+	for _, cg := range f.Comments {
+		if cg.Pos() >= cutoff {
+			break
+		}
+		for _, cm := range cg.List {
+			switch {
+			case strings.HasPrefix(cm.Text, "//go:build ") || cm.Text == "//go:build":
+				if fc.goBuild != nil {
+					fc.problems = append(fc.problems, "multiple //go:build lines")
+					continue
+				}
+				fc.goBuild = cm
+			case strings.HasPrefix(cm.Text, "// +build ") || strings.HasPrefix(cm.Text, "//+build "):
+				fc.plusBuild = append(fc.plusBuild, cm)
+			}
+		}
+	}
+	if fc.goBuild != nil {
+		fc.pos = fc.goBuild.Pos()
+	} else if len(fc.plusBuild) > 0 {
+		fc.pos = fc.plusBuild[0].Pos()
+	}
+
+	var goBuild, plusBuild constraint
+	var text string
+	if fc.goBuild != nil {
+		var err error
+		text = strings.TrimSpace(strings.TrimPrefix(fc.goBuild.Text, "//go:build"))
+		goBuild, err = parseGoBuild(text)
+		if err != nil {
+			fc.problems = append(fc.problems, fmt.Sprintf("invalid //go:build line: %s", err))
+		}
+	}
+	if len(fc.plusBuild) > 0 {
+		var lines [][]string
+		for _, cm := range fc.plusBuild {
+			lines = append(lines, strings.Fields(plusBuildText(cm)))
+		}
+		var err error
+		plusBuild, err = parsePlusBuild(lines)
+		if err != nil {
+			fc.problems = append(fc.problems, fmt.Sprintf("invalid // +build line: %s", err))
+		}
+	}
+	switch {
+	case goBuild != nil && plusBuild != nil:
+		if !constraintsEqual(goBuild, plusBuild) {
+			fc.problems = append(fc.problems, "the //go:build line and the // +build lines don't match; Go 1.17 and later only use the //go:build line")
+		}
+		fc.expr = goBuild
+	case goBuild != nil:
+		fc.expr = goBuild
+	case plusBuild != nil && fc.goBuild == nil:
+		var lines []string
+		for _, cm := range fc.plusBuild {
+			lines = append(lines, plusBuildText(cm))
+		}
+		text = strings.Join(lines, "; ")
+		fc.expr = plusBuild
+	}
+	if fc.expr != nil && !isSatisfiable(fc.expr) {
+		fc.problems = append(fc.problems, fmt.Sprintf("the build constraint %q can never be satisfied, so the file is never built", text))
+	}
+	return fc
+}
+
+// osArchSuffix returns the GOOS and GOARCH that the name of a file
+// restricts it to, as in x_linux.go, x_amd64.go or x_linux_amd64.go.
+func osArchSuffix(name string) (goos, goarch string) {
+	base := strings.TrimSuffix(filepath.Base(name), ".go")
+	parts := strings.Split(base, "_")[1:]
+	if n := len(parts); n > 0 && parts[n-1] == "test" {
+		parts = parts[:n-1]
+	}
+	n := len(parts)
+	switch {
+	case n >= 2 && contains(goosList, parts[n-2]) && contains(goarchList, parts[n-1]):
+		return parts[n-2], parts[n-1]
+	case n >= 1 && contains(goosList, parts[n-1]):
+		return parts[n-1], ""
+	case n >= 1 && contains(goarchList, parts[n-1]):
+		return "", parts[n-1]
+	}
+	return "", ""
+}
+
+// matches reports whether the file may be built for goos and
+// goarch, going by its name and its build constraint. Other tags are
+// looked up in ctx.
+func (fc *fileConstraint) matches(ctx *build.Context, goos, goarch string) bool {
+	isGOOS := func(t string) bool {
+		return t == goos || impliedGOOS[goos] == t
+	}
+	if os, arch := osArchSuffix(fc.name); (os != "" && !isGOOS(os)) || (arch != "" && arch != goarch) {
+		return false
+	}
+	if fc.expr == nil {
+		return true
+	}
+	return fc.expr.eval(func(t string) bool {
+		switch {
+		case contains(goosList, t):
+			return isGOOS(t)
+		case contains(goarchList, t):
+			return t == goarch
+		case t == "unix":
+			return contains(unixList, goos)
+		case t == "cgo":
+			return ctx.CgoEnabled
+		case t == "gc" || t == "gccgo":
+			return t == ctx.Compiler
+		default:
+			return contains(ctx.BuildTags, t) || contains(ctx.ReleaseTags, t)
+		}
+	})
+}
+
+func (c *Checker) CheckBuildConstraints(j *lint.Job) {
+	type pkgFiles struct {
+		// pos is where problems in ignored files are reported.
+		pos     token.Pos
+		loaded  []*fileConstraint
+		ignored []*fileConstraint
+	}
+	abs := func(name string) string {
+		if path, err := filepath.Abs(name); err == nil {
+			return path
+		}
+		return name
+	}
+	// seen records the files that have been collected. Test
+	// variants of packages share their directories and ignored
+	// files.
+	seen := map[string]bool{}
+	for _, f := range j.Program.Files {
+		seen[abs(j.Program.Prog.Fset.File(f.Pos()).Name())] = true
+	}
+	var pkgs []*pkgFiles
+	for _, pkg := range j.Program.Packages {
+		files := c.filterGenerated(pkg.Info.Files)
+		if len(files) == 0 {
+			continue
+		}
+		pf := &pkgFiles{pos: files[0].Name.Pos()}
+		for _, f := range files {
+			name := filepath.Base(j.Program.DisplayPosition(f.Pos()).Filename)
+			pf.loaded = append(pf.loaded, newFileConstraint(name, f))
+		}
+		if bp := pkg.BuildPkg; bp != nil {
+			for _, name := range bp.IgnoredGoFiles {
+				path := abs(filepath.Join(bp.Dir, name))
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+				f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+				if err != nil || (IsGenerated(f) && !c.CheckGenerated) {
+					continue
+				}
+				pf.ignored = append(pf.ignored, newFileConstraint(name, f))
+			}
+		}
+		pkgs = append(pkgs, pf)
+	}
+
+	// uses counts the files that use each tag.
+	uses := map[string]int{}
+	for _, pf := range pkgs {
+		for _, fc := range append(pf.loaded, pf.ignored...) {
+			if fc.expr == nil {
+				continue
+			}
+			for _, tag := range constraintTags(fc.expr) {
+				uses[tag]++
+			}
+		}
+	}
+	// suggestion returns the tag that tag, which only a single file
+	// uses, is likely a misspelling of, if any.
+	suggestion := func(tag string) string {
+		if uses[tag] != 1 || isKnownBuildTag(tag) {
+			return ""
+		}
+		maxDist := 2
+		if len(tag) <= 4 {
+			maxDist = 1
+		}
+		var candidates []string
+		candidates = append(candidates, goosList...)
+		candidates = append(candidates, goarchList...)
+		candidates = append(candidates, otherBuildTags...)
+		for t, n := range uses {
+			if n > 1 {
+				candidates = append(candidates, t)
+			}
+		}
+		sort.Strings(candidates)
+		best, bestDist := "", maxDist+1
+		for _, cand := range candidates {
+			if d := editDistance(tag, cand); d < bestDist {
+				best, bestDist = cand, d
+			}
+		}
+		return best
+	}
+	typos := func(fc *fileConstraint) []string {
+		if fc.expr == nil {
+			return nil
+		}
+		var out []string
+		for _, tag := range constraintTags(fc.expr) {
+			if s := suggestion(tag); s != "" {
+				out = append(out, fmt.Sprintf("the build tag %q isn't used by any other file; did you mean %q?", tag, s))
+			}
+		}
+		return out
+	}
+
+	for _, pf := range pkgs {
+		for _, fc := range pf.loaded {
+			for _, msg := range append(fc.problems, typos(fc)...) {
+				j.Errorf(posNode(fc.pos), "%s", msg)
+			}
+		}
+		for _, fc := range pf.ignored {
+			for _, msg := range append(fc.problems, typos(fc)...) {
+				j.Errorf(posNode(pf.pos), "%s: %s", fc.name, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// largeContextValue is the size in bytes above which structs stored
+// in contexts by value are flagged.
+const largeContextValue = 128
+
+// keyInitialisms are initialisms that names derived from context keys
+// spell in upper case, as the stylecheck naming check expects.
+var keyInitialisms = map[string]bool{
+	"API":  true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"UID":  true,
+	"URL":  true,
+	"UUID": true,
+}
+
+// contextKeyName returns the name of an unexported type that can
+// replace the string key, such as requestIDKey for "request-id", or
+// false if no valid name can be derived from the key.
+func contextKeyName(key string) (string, bool) {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", false
+	}
+	var name string
+	for i, word := range words {
+		r := []rune(word)
+		if i == 0 {
+			if !unicode.IsLetter(r[0]) || r[0] > unicode.MaxASCII {
+				return "", false
+			}
+			r[0] = unicode.ToLower(r[0])
+		} else if keyInitialisms[strings.ToUpper(word)] {
+			r = []rune(strings.ToUpper(word))
+		} else {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		name += string(r)
+	}
+	return name + "Key", true
+}
+
+func (c *Checker) CheckContextKeyType(j *lint.Job) {
+	// loopVars are the variables declared by loops, which, before
+	// Go 1.22, are shared by all iterations.
+	loopVars := map[types.Object]bool{}
+	if !IsGoVersion(j, 22) {
+		for _, f := range j.Program.Files {
+			ast.Inspect(f, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.RangeStmt:
+					if node.Tok != token.DEFINE {
+						return true
+					}
+					for _, x := range []ast.Expr{node.Key, node.Value} {
+						if id, ok := x.(*ast.Ident); ok {
+							loopVars[ObjectOf(j, id)] = true
+						}
+					}
+				case *ast.ForStmt:
+					if init, ok := node.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+						for _, x := range init.Lhs {
+							if id, ok := x.(*ast.Ident); ok {
+								loopVars[ObjectOf(j, id)] = true
+							}
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	type stringKey struct {
+		pkg *types.Package
+		key string
+	}
+	// uses are the arguments of calls to WithValue and Value with
+	// constant string keys, which suggested fixes have to replace
+	// together.
+	uses := map[stringKey][]ast.Expr{}
+	// decls are the top-level declarations that contain the first
+	// call to WithValue with each key, after which fixes declare the
+	// key type.
+	decls := map[stringKey]ast.Decl{}
+	type badKey struct {
+		call *ast.CallExpr
+		key  stringKey
+		ok   bool
+	}
+	var bad []badKey
+
+	for _, f := range j.Program.Files {
+		pkg := j.NodePackage(f).Pkg
+		for _, decl := range f.Decls {
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				isValue := IsCallToAST(j, call, "(context.Context).Value")
+				if !isValue && !IsCallToAST(j, call, "context.WithValue") {
+					return true
+				}
+				key := call.Args[0]
+				if !isValue {
+					key = call.Args[1]
+				}
+				var sk stringKey
+				T, isBasic := TypeOf(j, key).(*types.Basic)
+				tv := j.Program.Info.Types[key]
+				hasKey := isBasic && tv.Value != nil && tv.Value.Kind() == constant.String
+				if hasKey {
+					sk = stringKey{pkg, constant.StringVal(tv.Value)}
+					uses[sk] = append(uses[sk], key)
+				}
+				if isValue {
+					return true
+				}
+				if isBasic && T.Kind() != types.UntypedNil {
+					if hasKey {
+						if _, ok := decls[sk]; !ok {
+							decls[sk] = decl
+						}
+					}
+					bad = append(bad, badKey{call, sk, hasKey})
+				}
+				checkContextValue(j, call.Args[2], loopVars)
+				return true
+			})
+		}
+	}
+
+	// fixed records the keys whose fixes have been suggested. A fix
+	// replaces all uses of its key, so only the first call with each
+	// key gets one.
+	fixed := map[stringKey]bool{}
+	for _, b := range bad {
+		key := b.call.Args[1]
+		p := j.Errorf(key, "should not use built-in type %s as key for value; define your own type to avoid collisions", types.TypeString(TypeOf(j, key), nil))
+		if !b.ok || fixed[b.key] {
+			continue
+		}
+		fixed[b.key] = true
+		name, ok := contextKeyName(b.key.key)
+		if !ok {
+			continue
+		}
+		if _, obj := b.key.pkg.Scope().LookupParent(name, token.NoPos); obj != nil {
+			continue
+		}
+		var edits []lint.Edit
+		for _, use := range uses[b.key] {
+			edits = append(edits, j.Replace(use, name+"{}"))
+		}
+		decl := decls[b.key]
+		edits = append(edits, j.ReplaceRange(decl.End(), decl.End(), "\n\ntype "+name+" struct{}"))
+		j.AddFix(p, "use an unexported key type", edits...)
+	}
+}
+
+// checkContextValue flags values stored in contexts that are large
+// structs, which are copied every time they are retrieved, and
+// pointers to loop variables, which are shared by all iterations.
+func checkContextValue(j *lint.Job, val ast.Expr, loopVars map[types.Object]bool) {
+	if unary, ok := val.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		if id, ok := unary.X.(*ast.Ident); ok && loopVars[ObjectOf(j, id)] {
+			j.Errorf(val, "storing a pointer to the loop variable %s in a context; all iterations of the loop share the variable, so the context sees the values of later iterations", id.Name)
+		}
+		return
+	}
+	T := TypeOf(j, val)
+	if _, ok := T.Underlying().(*types.Struct); !ok {
+		return
+	}
+	if size := sizes(j.Program).Sizeof(T); size > largeContextValue {
+		j.Errorf(val, "storing a struct of %d bytes in a context copies it every time the value is retrieved; consider storing a pointer instead", size)
+	}
+}
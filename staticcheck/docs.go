@@ -0,0 +1,991 @@
+package staticcheck
+
+import "honnef.co/go/tools/lint"
+
+// Docs documents the checks and categories of checks of this checker.
+var Docs = map[string]*lint.Documentation{
+	"SA1": {
+		Title: "Various misuses of the standard library",
+	},
+	"SA1000": {
+		Title: "Invalid regular expression",
+	},
+	"SA1001": {
+		Title: "Invalid template",
+		Text: "Constant templates passed to `Parse` are checked for syntax errors.\n" +
+			"\n" +
+			"If a template is created with `template.New(...).Parse(...)` and\n" +
+			"all of the data passed to its `Execute` method is of a known type,\n" +
+			"references to fields and methods that don't exist on any of these\n" +
+			"types are flagged as well.\n",
+	},
+	"SA1002": {
+		Title: "Invalid format in `time.Parse`",
+	},
+	"SA1003": {
+		Title: "Unsupported argument to functions in `encoding/binary`",
+		Text: "`binary.Read` and `binary.Write` only support values of fixed\n" +
+			"size, such as sized integers and arrays and structs made up of\n" +
+			"them. Values containing `int`, `uint`, strings, maps or slices are\n" +
+			"rejected at runtime. When the offending value is nested inside a\n" +
+			"struct, the path to the field is reported.\n",
+	},
+	"SA1004": {
+		Title: "Suspiciously small untyped constant in `time.Sleep`",
+	},
+	"SA1005": {
+		Title: "Invalid first argument to `exec.Command`",
+		Text: "`os/exec` runs programs directly (using variants of the\n" +
+			"[fork](https://en.wikipedia.org/wiki/Fork_(system_call)) and\n" +
+			"[exec](https://en.wikipedia.org/wiki/Exec_(system_call)) system calls\n" +
+			"on Unix systems). This shouldn't be confused with running a command in\n" +
+			"a shell. The shell will allow for features such as input redirection,\n" +
+			"pipes, and general scripting. The\n" +
+			"shell is also responsible for splitting the user's input into a\n" +
+			"program name and its arguments. For example, the equivalent to `ls /\n" +
+			"/tmp` would be `exec.Command(\"ls\", \"/\", \"/tmp\")`.\n" +
+			"\n" +
+			"If you want to run a command in a shell, consider using something like\n" +
+			"the following – but be aware that not all systems, particularly\n" +
+			"Windows, will have a `/bin/sh` program:\n" +
+			"\n" +
+			"```\n" +
+			"exec.Command(\"/bin/sh\", \"-c\", \"ls | grep Awesome\")\n" +
+			"```\n",
+	},
+	"SA1006": {
+		Title: "Printf with dynamic first argument and no further arguments",
+		Text: "Using `fmt.Printf` with a dynamic first argument can lead to\n" +
+			"unexpected output. The first argument is a format string, where\n" +
+			"certain character combinations have special meaning. If, for example,\n" +
+			"a user were to enter a string such as `Interest rate: 5%` and you\n" +
+			"printed it with `fmt.Printf(s)`, it would lead to the following\n" +
+			"output: `Interest rate: 5%!(NOVERB)`.\n" +
+			"\n" +
+			"Similarly, forming the first parameyer via string concatenation with\n" +
+			"user input should be avoided for the same reason. When printing user\n" +
+			"input, either use a variant of `fmt.Print`, or use the `%s` Printf\n" +
+			"verb and pass the string as an argument.\n",
+	},
+	"SA1007": {
+		Title: "Invalid URL in `net/url.Parse`",
+	},
+	"SA1008": {
+		Title: "Non-canonical key in `http.Header` map",
+	},
+	"SA1010": {
+		Title: "`(*regexp.Regexp).FindAll` called with `n == 0`, which will always return zero results",
+	},
+	"SA1011": {
+		Title: "Various methods in the `strings` package expect valid UTF-8, but invalid input is provided",
+	},
+	"SA1012": {
+		Title: "A nil `context.Context` is being passed to a function, consider using `context.TODO` instead",
+	},
+	"SA1013": {
+		Title: "`io.Seeker.Seek` is being called with the `whence` constant as the first argument, but it should be the second",
+	},
+	"SA1014": {
+		Title: "Non-pointer value passed to `Unmarshal` or `Decode`",
+		Text: "Functions that unmarshal data, such as `json.Unmarshal`, store it\n" +
+			"in the value that their argument points to. Passing a value that\n" +
+			"isn't a pointer, or a nil pointer or interface, makes them return\n" +
+			"an error. This check covers the decoders of the standard library,\n" +
+			"of common YAML and TOML packages, and the functions listed in the\n" +
+			"`unmarshal_functions` option of the configuration file, whose last\n" +
+			"parameter must be an empty interface and which must return an\n" +
+			"error.\n",
+	},
+	"SA1015": {
+		Title: "Using `time.Tick` in a way that will leak. Consider using `time.NewTicker`, and only use `time.Tick` in tests, commands and endless functions",
+	},
+	"SA1016": {
+		Title: "Trapping a signal that cannot be trapped",
+	},
+	"SA1017": {
+		Title: "Channels used with `signal.Notify` should be buffered",
+	},
+	"SA1018": {
+		Title: "`strings.Replace` called with `n == 0`, which does nothing",
+	},
+	"SA1019": {
+		Title: "Using a deprecated function, variable, constant or field",
+	},
+	"SA1020": {
+		Title: "Using an invalid `host:port` pair with a `net.Listen`-related function",
+	},
+	"SA1021": {
+		Title: "Using `bytes.Equal` to compare two `net.IP`",
+		Text: "A `net.IP` stores an IPv4 or IPv6 address as a slice of bytes. The\n" +
+			"length of the slice for an IPv4 address, however, can be either 4 or\n" +
+			"16 bytes long, using different ways of representing IPv4 addresses. In\n" +
+			"order to correctly compare two `net.IP`s, the `net.IP.Equal` method\n" +
+			"should be used, as it takes both representations into account.\n",
+	},
+	"SA1023": {
+		Title: "Modifying the buffer in an `io.Writer` implementation",
+	},
+	"SA1024": {
+		Title: "A string cutset contains duplicate characters, suggesting `TrimPrefix` or `TrimSuffix` should be used instead of `TrimLeft` or `TrimRight`",
+	},
+	"SA1025": {
+		Title: "Calling WriteHeader more than once, or writing to a ResponseWriter after http.Error",
+	},
+	"SA1026": {
+		Title: "Using an identifier from syscall or golang.org/x/sys that isn't available on all platforms the file is built for",
+	},
+	"SA1027": {
+		Title: "Invalid combination of flags passed to os.OpenFile",
+	},
+	"SA1028": {
+		Title: "Cancel function of a context isn't called",
+		Text: "The cancel functions returned by `context.WithCancel`,\n" +
+			"`context.WithTimeout` and `context.WithDeadline` release the\n" +
+			"resources associated with the derived context. Discarding them, or\n" +
+			"returning without calling them, leaks the context until its parent\n" +
+			"is canceled.\n" +
+			"\n" +
+			"A deferred call counts as a call on all paths that execute the\n" +
+			"defer statement. Cancel functions that are stored, passed to other\n" +
+			"functions or returned are assumed to be called elsewhere.\n",
+	},
+	"SA1029": {
+		Title: "Using `reflect.DeepEqual` on errors, protocol buffers or types with an `Equal` method",
+		Text: "`reflect.DeepEqual` compares values by their internal structure.\n" +
+			"Errors should be compared with `errors.Is` (or `==` before Go 1.13),\n" +
+			"which also matches wrapped errors. Generated protocol buffer messages\n" +
+			"contain internal state and should be compared with `proto.Equal`.\n" +
+			"Types with an `Equal` method, such as `time.Time`, define their own\n" +
+			"notion of equality that `reflect.DeepEqual` doesn't respect.\n",
+	},
+	"SA1030": {
+		Title: "Using the `path` package on operating system paths",
+		Text: "The `path` package only handles slash-separated paths, such as\n" +
+			"those in URLs. Paths of files, such as those returned by\n" +
+			"`os.Getwd` or passed in flags named like `-dir` or `-path`, should\n" +
+			"be manipulated with `path/filepath`, which uses the separator of\n" +
+			"the operating system.\n",
+	},
+	"SA1031": {
+		Title: "Comparing `time.Time` values with `==`",
+		Text: "A `time.Time` holds a location and, for times obtained from\n" +
+			"`time.Now`, a monotonic clock reading. Both are compared by `==`,\n" +
+			"so two values describing the same instant may compare unequal. The\n" +
+			"`Equal` method should be used instead. For the same reason,\n" +
+			"`time.Time` makes for an unreliable map key.\n",
+	},
+	"SA1032": {
+		Title: "Misuse of `exec.Cmd`",
+		Text: "An `exec.Cmd` can't be reused once it has been started, and its\n" +
+			"methods have to be called in the right order. This check flags:\n" +
+			"\n" +
+			"- starting a command a second time, with any of Start, Run, Output\n" +
+			"  and CombinedOutput\n" +
+			"- calling Wait after Run, Output or CombinedOutput, which already\n" +
+			"  wait for the command to exit, or calling Wait twice\n" +
+			"- setting Stdin, Stdout or Stderr after the command was started\n" +
+			"- reading from the pipes returned by StdoutPipe and StderrPipe\n" +
+			"  after waiting for the command, which closes the pipes\n",
+	},
+	"SA1033": {
+		Title: "Using `os.IsNotExist` and related functions on wrapped errors",
+		Text: "`os.IsNotExist`, `os.IsExist` and `os.IsPermission` predate error\n" +
+			"wrapping and don't unwrap errors, so they return false for errors\n" +
+			"that wrap matching ones, such as those returned by `fmt.Errorf`\n" +
+			"with `%w`. Errors that may be wrapped should be inspected with\n" +
+			"`errors.Is(err, fs.ErrNotExist)` instead, or `os.ErrNotExist`\n" +
+			"before Go 1.16.\n",
+	},
+	"SA1034": {
+		Title: "Context value with a key of a built-in type, or an unsuitable value",
+		Text: "Keys of context values should be of unexported types defined by\n" +
+			"the packages that use them. Keys of built-in types, such as\n" +
+			"strings, collide with the keys of other packages that happen to\n" +
+			"use the same value. For constant string keys, the suggested fix\n" +
+			"declares an empty struct type for the key and uses it in the\n" +
+			"package's calls to WithValue and Value with that key.\n" +
+			"\n" +
+			"Structs larger than 128 bytes stored by value are copied every\n" +
+			"time they are retrieved, and, before Go 1.22, pointers to loop\n" +
+			"variables point to a variable that all iterations share; both\n" +
+			"are flagged as well.\n",
+	},
+	"SA2": {
+		Title: "Concurrency issues",
+	},
+	"SA2000": {
+		Title: "`sync.WaitGroup.Add` called inside the goroutine, leading to a race condition",
+	},
+	"SA2001": {
+		Title: "Empty critical section, did you mean to `defer` the unlock?",
+	},
+	"SA2002": {
+		Title: "Called `testing.T.FailNow` or `SkipNow` in a goroutine, which isn't allowed",
+	},
+	"SA2003": {
+		Title: "Deferred `Lock` right after locking, likely meant to defer `Unlock` instead",
+	},
+	"SA2004": {
+		Title: "Using the request's context in a goroutine that outlives the HTTP handler",
+	},
+	"SA2005": {
+		Title: "Calling `sync.Once.Do` with different functions",
+		Text: "A `sync.Once` runs the function of the first call to Do only; the\n" +
+			"functions of all later calls are ignored. Calling Do on the same\n" +
+			"Once with different functions in different places suggests that\n" +
+			"each was expected to run.\n",
+	},
+	"SA2006": {
+		Title: "64-bit atomic operations on unaligned fields",
+		Text: "On 32-bit platforms such as 386 and ARM, 64-bit atomic operations\n" +
+			"require their operands to be 64-bit aligned, and panic otherwise.\n" +
+			"Only the first word of an allocated struct, variable or slice is\n" +
+			"guaranteed to be aligned. Fields that are accessed atomically should\n" +
+			"be placed at the start of their structs.\n" +
+			"\n" +
+			"The layout of structs is computed for the targeted architecture, or\n" +
+			"for 386 if the targeted architecture is a 64-bit one.\n",
+	},
+	"SA2007": {
+		Title: "Mutex held across blocking operations",
+		Text: "Holding a mutex while doing network I/O, querying a database,\n" +
+			"sleeping or waiting on a channel makes all other goroutines that\n" +
+			"need the mutex wait for the blocking operation too. Under load,\n" +
+			"this serializes requests, and a single slow operation can stall\n" +
+			"a whole server. Mutexes should be released before blocking, for\n" +
+			"example by copying the state that is needed out of the critical\n" +
+			"section.\n" +
+			"\n" +
+			"Calls of the net, net/http and database/sql packages that do I/O,\n" +
+			"time.Sleep, and channel operations that may block are considered\n" +
+			"blocking. Sends on channels that the function made with a\n" +
+			"constant, nonzero capacity, such as one that a single result is\n" +
+			"sent on, are not. Mutexes are considered held from a call to Lock or\n" +
+			"RLock until the next statement of the same block that may unlock\n" +
+			"them; deferred unlocks hold them until the end of the block.\n",
+		NonDefault: true,
+	},
+	"SA3": {
+		Title: "Testing issues",
+	},
+	"SA3000": {
+		Title: "`TestMain` doesn't call `os.Exit`, hiding test failures",
+	},
+	"SA3001": {
+		Title: "Assigning to `b.N` in benchmarks distorts the results",
+	},
+	"SA3002": {
+		Title: "Fuzz target that hides failures",
+		Text: "Fuzz targets must only use the `*testing.T` they are passed; calling\n" +
+			"methods of the `*testing.F`, such as `f.Fatal`, inside of them panics\n" +
+			"instead of reporting the failing input. Fuzz targets that always\n" +
+			"call `t.Skip` never test anything, and deferred functions that\n" +
+			"discard the value of `recover` hide crashes from the fuzzer.\n\n" +
+			"This check applies to Go 1.18 and later.\n",
+	},
+	"SA3003": {
+		Title: "Fuzz target doesn't use one of its parameters",
+		Text: "The fuzzer generates values for all parameters of a fuzz target.\n" +
+			"Values of parameters that the target never uses are wasted effort\n" +
+			"and enlarge the corpus.\n\n" +
+			"This check applies to Go 1.18 and later.\n",
+	},
+	"SA3004": {
+		Title: "Fuzz test without a seed corpus",
+		Text: "Without `-fuzz`, `go test` runs fuzz targets only with the inputs of\n" +
+			"the seed corpus, which consists of the values passed to `f.Add` and\n" +
+			"the files in `testdata/fuzz/FuzzXxx`. Fuzz tests without a seed\n" +
+			"corpus aren't exercised by ordinary test runs, and the fuzzer has\n" +
+			"to start from scratch.\n\n" +
+			"This check applies to Go 1.18 and later.\n",
+	},
+	"SA4": {
+		Title: "Code that isn't really doing anything",
+	},
+	"SA4000": {
+		Title: "Boolean expression has identical expressions on both sides",
+	},
+	"SA4001": {
+		Title: "`&*x` gets simplified to `x`, it does not copy `x`",
+	},
+	"SA4002": {
+		Title: "Comparing strings with known different sizes has predictable results",
+	},
+	"SA4003": {
+		Title: "Comparing unsigned values against negative values is pointless",
+	},
+	"SA4004": {
+		Title: "The loop exits unconditionally after one iteration",
+	},
+	"SA4005": {
+		Title: "Field assignment that will never be observed. Did you mean to use a pointer receiver?",
+	},
+	"SA4006": {
+		Title: "A value assigned to a variable is never read before being overwritten. Forgotten error check or dead code?",
+	},
+	"SA4008": {
+		Title: "The variable in the loop condition never changes, are you incrementing the wrong variable?",
+	},
+	"SA4009": {
+		Title: "A function argument is overwritten before its first use",
+	},
+	"SA4010": {
+		Title: "The result of `append` will never be observed anywhere",
+	},
+	"SA4011": {
+		Title: "Break statement with no effect. Did you mean to break out of an outer loop?",
+	},
+	"SA4012": {
+		Title: "Comparing a value against NaN even though no value is equal to NaN",
+	},
+	"SA4013": {
+		Title: "Negating a boolean twice (`!!b`) is the same as writing `b`. This is either redundant, or a typo.",
+	},
+	"SA4014": {
+		Title: "An if/else if chain has repeated conditions and no side-effects; if the condition didn't match the first time, it won't match the second time, either",
+	},
+	"SA4015": {
+		Title: "Calling functions like `math.Ceil` on floats converted from integers doesn't do anything useful",
+	},
+	"SA4016": {
+		Title: "Certain bitwise operations, such as `x ^ 0`, do not do anything useful",
+	},
+	"SA4017": {
+		Title: "A pure function's return value is discarded, making the call pointless",
+	},
+	"SA4018": {
+		Title: "Self-assignment of variables",
+	},
+	"SA4019": {
+		Title: "Multiple, identical build constraints in the same file",
+	},
+	"SA4020": {
+		Title: "Modifying a copy of a map entry without writing it back",
+		Text: "Map entries aren't addressable, so reading a struct from a map\n" +
+			"yields a copy. Modifying the fields of that copy has no effect on\n" +
+			"the map unless the copy is stored in the map again.\n",
+	},
+	"SA4021": {
+		Title: "Comparing errors against newly created errors",
+		Text: "Every call to errors.New or fmt.Errorf returns a distinct error, even\n" +
+			"if the messages are identical. Comparing an error against a newly\n" +
+			"created one, such as in err == errors.New(\"EOF\"), or against an\n" +
+			"error that is created anew in every call of a function, is\n" +
+			"therefore never true. Sentinel errors should be declared at\n" +
+			"package level.\n",
+	},
+	"SA4022": {
+		Title: "Unchecked error due to a shadowed err variable",
+		Text: "When an error variable is redeclared in an inner scope, typically\n" +
+			"with `:=`, errors assigned to it in that scope aren't visible to\n" +
+			"checks of the outer variable after the scope ends:\n" +
+			"\n" +
+			"    var err error\n" +
+			"    if cond {\n" +
+			"        v, err := fn1()\n" +
+			"        if err != nil {\n" +
+			"            return err\n" +
+			"        }\n" +
+			"        err = fn2(v)\n" +
+			"    }\n" +
+			"    if err != nil {\n" +
+			"        return err\n" +
+			"    }\n" +
+			"\n" +
+			"The error returned by fn2 is never checked, even though the code\n" +
+			"appears to check it. Assign to the outer variable with `=` instead,\n" +
+			"or check the error in the inner scope.\n",
+	},
+	"SA4023": {
+		Title: "Invalid, inconsistent or unsatisfiable build constraints",
+		Text: "Files whose build constraints can't be satisfied silently drop out\n" +
+			"of all builds. This check reports `//go:build` and `// +build` lines\n" +
+			"with syntax errors, `//go:build` lines that don't match the\n" +
+			"`// +build` lines of the same file, constraints that can never be\n" +
+			"satisfied, such as `linux && windows`, and tags that no other file\n" +
+			"uses and that are similar to known or commonly used tags, which\n" +
+			"are likely misspelled.\n" +
+			"\n" +
+			"Files that are excluded from the build are reported at the package\n" +
+			"clause of one of the package's other files.\n",
+	},
+	"SA4024": {
+		Title: "Discarding the result of a function whose result must be used",
+		Text: "Some functions have no useful effect other than their results,\n" +
+			"such as `context.WithValue`, or methods of builders that return\n" +
+			"modified copies. Functions can be marked as such with a\n" +
+			"`//lint:must-use` comment in their documentation, which may be\n" +
+			"followed by an explanation, or with the\n" +
+			"`must_use_functions` option of the configuration file, which\n" +
+			"lists functions such as `(*example.com/project/query.Builder).Where`.\n" +
+			"\n" +
+			"Unlike errcheck, this check applies to results of any type.\n" +
+			"Assigning results to the blank identifier explicitly discards\n" +
+			"them and isn't reported.\n",
+	},
+	"SA5": {
+		Title: "Correctness issues",
+	},
+	"SA5000": {
+		Title: "Assignment to nil map",
+	},
+	"SA5001": {
+		Title: "Defering `Close` before checking for a possible error",
+	},
+	"SA5002": {
+		Title: "The empty `for` loop (`for {}`) spins and can block the scheduler",
+	},
+	"SA5003": {
+		Title: "Defers in infinite loops will never execute",
+	},
+	"SA5004": {
+		Title: "`for { select { ...` with an empty default branch spins",
+	},
+	"SA5005": {
+		Title: "The finalizer references the finalized object, preventing garbage collection",
+		Text: "A finalizer is a function associated with an object that runs when the\n" +
+			"garbage collector is ready to collect said object, that is when the\n" +
+			"object is no longer referenced by anything.\n" +
+			"\n" +
+			"If the finalizer references the object, however, it will always remain\n" +
+			"as the final reference to that object, preventing the garbage\n" +
+			"collector from collecting the object. The finalizer will never run,\n" +
+			"and the object will never be collected, leading to a memory leak. That\n" +
+			"is why the finalizer should instead use its first argument to operate\n" +
+			"on the object. That way, the number of references can temporarily go\n" +
+			"to zero before the object is being passed to the finalizer.\n",
+	},
+	"SA5006": {
+		Title: "Slice index out of bounds",
+	},
+	"SA5007": {
+		Title: "Infinite recursive call",
+		Text: "A function that calls itself recursively needs to have an exit\n" +
+			"condition. Otherwise it will recurse forever, until the system runs\n" +
+			"out of memory.\n" +
+			"\n" +
+			"This issue can be caused by simple bugs such as forgetting adding an\n" +
+			"exit condition. It can also happen \"on purpose\". Some languages have\n" +
+			"[tail call optimization](https://en.wikipedia.org/wiki/Tail_call)\n" +
+			"which makes certain infinite recursive calls safe to use. Go, however,\n" +
+			"does not implement TCO, and as such a loop should be used instead.\n",
+	},
+	"SA5008": {
+		Title: "Interface value with an uncomparable dynamic type used as map key or in comparison",
+		Text: "Values of interface type can be used as map keys and be compared\n" +
+			"with `==`, as long as their dynamic types are comparable. Slices,\n" +
+			"maps, functions and structs containing them are not, and storing\n" +
+			"them in an interface and using that as a map key causes a runtime\n" +
+			"panic.\n",
+	},
+	"SA5009": {
+		Title: "Invalid Printf call",
+		Text: "Printf-style calls are checked for explicit argument indexes that\n" +
+			"refer to non-existent arguments, for `*` widths and precisions that\n" +
+			"aren't provided by integers, and for functions and channels being\n" +
+			"formatted, which prints their addresses.\n",
+	},
+	"SA5010": {
+		Title: "Sending to or receiving from a nil channel",
+		Text: "Sending to or receiving from a nil channel blocks forever. This\n" +
+			"check flags channel operations on variables that were never\n" +
+			"initialized with make, or that were set to nil, on all paths\n" +
+			"leading to the operation. Nil channels in select statements are a\n" +
+			"common way of disabling cases and aren't flagged.\n",
+	},
+	"SA5011": {
+		Title: "Encoding values with `encoding/json` or `encoding/gob` that can't round-trip",
+		Text: "`encoding/json` returns errors for channels, functions and complex\n" +
+			"numbers, and for maps whose keys aren't strings, integers or\n" +
+			"types implementing `encoding.TextMarshaler`. Structs without\n" +
+			"exported fields are silently encoded as empty objects, and\n" +
+			"nothing is decoded into them. `encoding/gob` rejects channels,\n" +
+			"functions and structs without exported fields. This check flags\n" +
+			"the values passed to encoders and decoders whose types contain\n" +
+			"such parts, naming the path of the offending field. Types that\n" +
+			"encode themselves, such as by implementing `json.Marshaler`, are\n" +
+			"not inspected.\n",
+	},
+	"SA5012": {
+		Title: "Sending to or receiving from a channel that may be nil",
+		Text: "Like SA5010, but flags channel operations on variables that\n" +
+			"are nil on only some of the paths leading to the operation,\n" +
+			"unless the operation is guarded by a comparison with nil. Whether\n" +
+			"these paths can actually be taken isn't known; a channel that is\n" +
+			"only made if a condition holds, and only used if the same\n" +
+			"condition holds, is flagged as well.\n",
+		NonDefault: true,
+	},
+	"SA6": {
+		Title: "Performance issues",
+	},
+	"SA6000": {
+		Title: "Using `regexp.Match` or related in a loop, should use `regexp.Compile`",
+	},
+	"SA6001": {
+		Title: "Missing an optimization opportunity when indexing maps by byte slices",
+		Text: "Map keys must be comparable, which precludes the use of []byte. This\n" +
+			"usually leads to using string keys and converting []bytes to\n" +
+			"strings.\n" +
+			"\n" +
+			"Normally, a conversion of []byte to string needs to copy the data and\n" +
+			"causes allocations. The compiler, however, recognizes `m[string(b)]`\n" +
+			"and uses the data of `b` directly, without copying it, because it\n" +
+			"knows that the data can't change during the map lookup. This leads\n" +
+			"to the counter-intuitive situation that\n" +
+			"\n" +
+			"```\n" +
+			"k := string(b)\n" +
+			"println(m[k])\n" +
+			"println(m[k])\n" +
+			"```\n" +
+			"\n" +
+			"will be less efficient than\n" +
+			"\n" +
+			"```\n" +
+			"println(m[string(b)])\n" +
+			"println(m[string(b)])\n" +
+			"```\n" +
+			"\n" +
+			"because the first version needs to copy and allocate, while the second\n" +
+			"one does not.\n" +
+			"\n" +
+			"For some history on this optimization, check out commit\n" +
+			"[f5f5a8b6209f84961687d993b93ea0d397f5d5bf](https://github.com/golang/go/commit/f5f5a8b6209f84961687d993b93ea0d397f5d5bf).\n",
+	},
+	"SA6002": {
+		Title: "Storing non-pointer values in `sync.Pool` allocates memory",
+		Text: "A `sync.Pool` is used to avoid unnecessary allocations and reduce the\n" +
+			"amount of work the garbage collector has to do.\n" +
+			"\n" +
+			"When passing a value that is not a pointer\n" +
+			"to a function that accepts an interface, the value\n" +
+			"needs to be placed on the heap, which means an additional allocation.\n" +
+			"Slices are a common thing to put in `sync.Pool`s, and they're structs\n" +
+			"with 3 fields (length, capacity, and a pointer to an array). In order to avoid\n" +
+			"the extra allocation, one should store a pointer to the slice instead.\n" +
+			"Values that fit in a single byte, such as booleans, and zero-sized\n" +
+			"values don't require allocations and aren't flagged.\n" +
+			"\n" +
+			"See the\n" +
+			"[comments on a Go CL](https://go-review.googlesource.com/#/c/24371/)\n" +
+			"that discuss this problem.\n",
+	},
+	"SA6003": {
+		Title: "Converting a string to a slice of runes before ranging over it",
+		Text: "You may want to loop over the runes in a string. Instead of converting\n" +
+			"the string to a slice of runes and looping over that, you can loop\n" +
+			"over the string itself. That is,\n" +
+			"\n" +
+			"```\n" +
+			"for _, r := range s {}\n" +
+			"```\n" +
+			"\n" +
+			"and\n" +
+			"\n" +
+			"```\n" +
+			"for _, r := range []rune(s) {}\n" +
+			"```\n" +
+			"\n" +
+			"will yield the same values. The first version, however, will be faster\n" +
+			"and avoid unnecessary memory allocations.\n" +
+			"\n" +
+			"Do note that if you are interested in the indices, ranging over a\n" +
+			"string and over a slice of runes will yield different indices. The\n" +
+			"first one yields byte offsets, while the second one yields indices in\n" +
+			"the slice of runes.\n",
+	},
+	"SA6004": {
+		Title: "Regular expression does not contain any meta characters",
+		Text: "Regular expressions that do not contain any meta characters (things\n" +
+			"like `\\d`) are just regular strings. Using the `regexp` with such\n" +
+			"expressions is unnecessarily complex and slow. Functions from the\n" +
+			"`bytes` and `strings` packages should be used instead.\n",
+	},
+	"SA6005": {
+		Title: "Storing the address of a loop variable beyond the iteration",
+		Text: "Taking the address of a loop variable and appending it to a slice,\n" +
+			"sending it on a channel or assigning it to a variable declared\n" +
+			"outside of the loop keeps the variable alive beyond the iteration.\n" +
+			"\n" +
+			"Before Go 1.22, all iterations share the same variable, so all\n" +
+			"stored pointers point to the value of the last iteration. Since Go\n" +
+			"1.22, every iteration has its own variable, which has to be\n" +
+			"allocated on the heap in every iteration. In both cases, storing\n" +
+			"values instead of pointers, or allocating a slice of values once\n" +
+			"and storing pointers to its elements, is usually better.\n",
+		NonDefault: true,
+	},
+	"SA6006": {
+		Title: "Converting a loop-invariant value to an interface inside a loop",
+		Text: "Converting a value to an interface, for example by passing it to a\n" +
+			"function such as `fmt.Println`, allocates unless the value is a\n" +
+			"pointer, a constant or very small. Values that don't change in the\n" +
+			"loop can be converted to an interface once before the loop instead\n" +
+			"of in every iteration. Conversions in return statements and calls\n" +
+			"to panic, which end the loop, aren't flagged.\n",
+		NonDefault: true,
+	},
+	"SA6007": {
+		Title: "Converting a loop-invariant string to a byte slice inside a loop",
+		Text: "Converting a string to a byte slice copies the string. If the\n" +
+			"string doesn't change in the loop, the conversion can be done once\n" +
+			"before the loop, as long as the loop doesn't modify the slice.\n" +
+			"Conversions in return statements and calls to panic, which end\n" +
+			"the loop, aren't flagged.\n",
+		NonDefault: true,
+	},
+	"SA6008": {
+		Title: "Inefficient construction of errors",
+		Text: "Calling `fmt.Errorf` with a message that contains no formatting\n" +
+			"directives parses the message for nothing; `errors.New` creates the\n" +
+			"same error more cheaply.\n" +
+			"\n" +
+			"Errors with constant messages that are constructed in every\n" +
+			"iteration of a loop allocate every time. Declaring them once, as\n" +
+			"package-level variables, avoids this, and lets callers compare\n" +
+			"errors to them. Errors that are returned right away, ending the\n" +
+			"loop, aren't flagged.\n",
+		NonDefault: true,
+	},
+	"SA7": {
+		Title: "Security issues",
+	},
+	"SA7000": {
+		Title: "Seeding math/rand with a predictable value",
+		Text: "Before Go 1.20, the global source of math/rand is seeded with 1\n" +
+			"unless rand.Seed is called. Seeding it with a constant, or with\n" +
+			"the current time in seconds, as in `rand.Seed(time.Now().Unix())`,\n" +
+			"makes the numbers it generates the same in every run, or easy to\n" +
+			"guess. Numbers that must not be guessed should be generated with\n" +
+			"crypto/rand instead.\n",
+		NonDefault: true,
+	},
+	"SA7001": {
+		Title: "Generating secrets with math/rand",
+		Text: "The bytes generated by math/rand.Read are predictable, and mustn't\n" +
+			"be used for keys, tokens, nonces and other secrets; crypto/rand.Read\n" +
+			"should be used instead. Calls to math/rand.Read are flagged if the\n" +
+			"name of the buffer or of the enclosing function, such as `key` or\n" +
+			"`newSessionToken`, suggests a secret, or if the buffer is passed to\n" +
+			"a function of a cryptographic package, such as aes.NewCipher.\n",
+	},
+	"SA7002": {
+		Title: "Weak TLS configuration",
+		Text: "tls.Config literals are flagged if they set InsecureSkipVerify,\n" +
+			"which disables the verification of certificates, outside of\n" +
+			"tests, if they set MinVersion to a version older than TLS 1.2, or\n" +
+			"if they list cipher suites that use RC4 or 3DES, or CBC mode with\n" +
+			"SHA-256.\n" +
+			"\n" +
+			"Packages that need weak configurations, for example to talk to\n" +
+			"legacy servers, can be listed in the `insecure_tls_packages`\n" +
+			"option of the configuration file. A trailing `/...` includes\n" +
+			"subpackages.\n",
+	},
+	"SA7003": {
+		Title: "Building SQL queries by concatenating strings",
+		Text: "Queries passed to the methods of database/sql that are built by\n" +
+			"concatenating strings that aren't constant, as in\n" +
+			"`db.Query(\"SELECT * FROM users WHERE name = '\" + name + \"'\")`,\n" +
+			"allow SQL injection. Values should be passed as arguments of a\n" +
+			"parameterized query instead, as in\n" +
+			"`db.Query(\"SELECT * FROM users WHERE name = ?\", name)`.\n" +
+			"\n" +
+			"Identifiers, such as table names, can't be parameters. Functions\n" +
+			"that safely quote them can be listed, by their full names such as\n" +
+			"`github.com/lib/pq.QuoteIdentifier`, in the `sql_quote_functions`\n" +
+			"option of the configuration file; their results may be\n" +
+			"concatenated into queries.\n",
+	},
+	"SA7004": {
+		Title: "Hard-coded credentials",
+		Text: "Passwords, API keys, tokens and other secrets stored in source\n" +
+			"code are exposed to everyone who can read the code, and can't be\n" +
+			"rotated without changing it. String literals are flagged if they\n" +
+			"are assigned to or compared with variables and fields whose names\n" +
+			"suggest secrets, such as `password`, `apiKey` or `clientSecret`.\n" +
+			"Long, random-looking literals are also flagged if they are passed\n" +
+			"as such parameters, or to functions whose names suggest\n" +
+			"authentication, such as `SetBasicAuth`. Tokens only count as\n" +
+			"secrets if they are qualified, as in `authToken`, and values that\n" +
+			"look like the names of environment variables, such as\n" +
+			"`DB_PASSWORD`, are skipped. Test files are skipped as well.\n" +
+			"\n" +
+			"Additional words that suggest secrets can be listed in the\n" +
+			"`secret_names` option of the configuration file. Values that\n" +
+			"aren't secrets, such as well-known credentials of local\n" +
+			"development servers, can be marked with a `//lint:ignore SA7004`\n" +
+			"directive that explains why.\n",
+		NonDefault: true,
+	},
+	"SA7005": {
+		Title: "Error message leaks memory addresses or secrets",
+		Text: "Formatting values with `%v` or `%s` in `fmt.Errorf` puts their\n" +
+			"contents into error messages, which often end up in logs or in\n" +
+			"responses to users. For pointers to values other than structs,\n" +
+			"arrays, slices and maps, fmt prints the memory address, which is\n" +
+			"useless to readers and reveals the layout of memory to attackers.\n" +
+			"For structs, fmt prints all fields, including those that hold\n" +
+			"passwords, tokens and other secrets. Fields are considered secret\n" +
+			"if their names, such as `Password` or `apiToken`, suggest it;\n" +
+			"additional words can be listed in the `secret_names` option of the\n" +
+			"configuration file. Values that implement `error`, `fmt.Stringer`\n" +
+			"or `fmt.Formatter` control their own formatting and aren't flagged.\n",
+		NonDefault: true,
+	},
+	"SA9": {
+		Title: "Dubious code constructs that have a high probability of being wrong",
+	},
+	"SA9001": {
+		Title: "`defer`s in `for range` loops may not run when you expect them to",
+	},
+	"SA9002": {
+		Title: "Using a non-octal `os.FileMode`  that looks like it was meant to be in octal.",
+	},
+	"SA9003": {
+		Title: "Empty body in an if or else branch",
+	},
+	"SA9004": {
+		Title: "Only the first constant has an explicit type",
+		Text: "In a constant declaration such as the following:\n" +
+			"\n" +
+			"```\n" +
+			"const (\n" +
+			"\tFirst byte = 1\n" +
+			"    Second     = 2\n" +
+			")\n" +
+			"```\n" +
+			"\n" +
+			"the constant `Second` does **not** have the same type as the constant\n" +
+			"`First`. This construct shouldn't be confused with\n" +
+			"\n" +
+			"```\n" +
+			"const (\n" +
+			"\tFirst byte = iota\n" +
+			"    Second\n" +
+			")\n" +
+			"```\n" +
+			"\n" +
+			"where `First` and `Second` do indeed have the same type. The type is\n" +
+			"only passed on when no explicit value is assigned to the constant.\n" +
+			"\n" +
+			"When declaring enumerations with explicit values it is therefore\n" +
+			"important not to write\n" +
+			"\n" +
+			"```\n" +
+			"const (\n" +
+			"      EnumFirst EnumType = 1\n" +
+			"      EnumSecond         = 2\n" +
+			"      EnumThird          = 3\n" +
+			")\n" +
+			"```\n" +
+			"\n" +
+			"This discrepancy in types can cause various confusing behaviors and\n" +
+			"bugs.\n" +
+			"\n" +
+			"#### Wrong type in variable declarations\n" +
+			"\n" +
+			"The most obvious issue with such incorrect enumerations expresses\n" +
+			"itself as a compile error:\n" +
+			"\n" +
+			"```\n" +
+			"package pkg\n" +
+			"\n" +
+			"const (\n" +
+			"\tEnumFirst  uint8 = 1\n" +
+			"\tEnumSecond       = 2\n" +
+			")\n" +
+			"\n" +
+			"func fn(useFirst bool) {\n" +
+			"\tx := EnumSecond\n" +
+			"\tif useFirst {\n" +
+			"\t\tx = EnumFirst\n" +
+			"\t}\n" +
+			"}\n" +
+			"\n" +
+			"```\n" +
+			"\n" +
+			"fails to compile with\n" +
+			"\n" +
+			"```\n" +
+			"./const.go:11:5: cannot use EnumFirst (type uint8) as type int in assignment\n" +
+			"```\n" +
+			"\n" +
+			"#### Losing method sets\n" +
+			"\n" +
+			"A more subtle issue occurs with types that have methods and optional\n" +
+			"interfaces. Consider the following:\n" +
+			"\n" +
+			"```\n" +
+			"package main\n" +
+			"\n" +
+			"import \"fmt\"\n" +
+			"\n" +
+			"type Enum int\n" +
+			"\n" +
+			"func (e Enum) String() string {\n" +
+			"\treturn \"an enum\"\n" +
+			"}\n" +
+			"\n" +
+			"const (\n" +
+			"\tEnumFirst  Enum = 1\n" +
+			"\tEnumSecond      = 2\n" +
+			")\n" +
+			"\n" +
+			"func main() {\n" +
+			"\tfmt.Println(EnumFirst)\n" +
+			"\tfmt.Println(EnumSecond)\n" +
+			"}\n" +
+			"```\n" +
+			"\n" +
+			"This code will output\n" +
+			"\n" +
+			"```\n" +
+			"an enum\n" +
+			"2\n" +
+			"```\n" +
+			"\n" +
+			"as EnumSecond has no explicit type, and thus defaults to `int`.\n",
+	},
+	"SA9005": {
+		Title: "Comparing structs that contain floating-point fields",
+		Text: "Comparing structs with `==` compares all of their fields. For\n" +
+			"floating-point fields, this means that two structs containing NaN\n" +
+			"in the same field will never be equal, not even when compared to\n" +
+			"themselves.\n",
+	},
+	"SA9006": {
+		Title: "Switch over an enum type doesn't handle all of its values",
+		Text: "A named integer or string type with constants of that type\n" +
+			"declared in its package is treated as an enum. Switch statements\n" +
+			"over such a type that have no default case must handle all of the\n" +
+			"constants.\n" +
+			"\n" +
+			"Setting the `exhaustive` option of the configuration file to\n" +
+			"`\"annotated\"` limits the check to types whose declaration is\n" +
+			"documented with a `//lint:enum` comment.\n",
+		NonDefault: true,
+	},
+	"SA9007": {
+		Title: "Composite literal doesn't set all fields of a struct that requires it",
+		Text: "Some structs, such as those holding options, should have all of\n" +
+			"their fields set explicitly, so that adding a field forces all\n" +
+			"places that create the struct to be revisited. Such structs are\n" +
+			"marked by documenting their declaration with a\n" +
+			"`//lint:exhaustive-fields` comment, or by listing them, as\n" +
+			"`importpath.Name`, in the `exhaustive_fields` option of the\n" +
+			"configuration file. Empty composite literals are allowed.\n",
+		NonDefault: true,
+	},
+	"SA9008": {
+		Title: "Building file paths or URLs with fmt.Sprintf",
+		Text: "Joining path elements with fmt.Sprintf and \"/\", as in\n" +
+			"`fmt.Sprintf(\"%s/%s\", dir, name)`, produces the wrong separator on\n" +
+			"Windows and doesn't clean the result; filepath.Join should be used\n" +
+			"for file paths, and path.Join for slash-separated paths. Strings\n" +
+			"interpolated into the path or query of a URL aren't escaped;\n" +
+			"url.PathEscape, url.Values or, since Go 1.19, url.JoinPath should be\n" +
+			"used instead.\n",
+		NonDefault: true,
+	},
+	"SA9009": {
+		Title: "Converting an integer to a string",
+		Text: "Converting an integer to a string, as in `string(i)`, yields the\n" +
+			"UTF-8 encoding of the rune with that value, not the decimal\n" +
+			"representation of the number, which strconv.Itoa or fmt.Sprint\n" +
+			"produce. Conversions that are meant to produce runes should convert\n" +
+			"to rune first, as in `string(rune(i))`. Conversions of bytes and\n" +
+			"runes aren't flagged.\n" +
+			"\n" +
+			"When targeting versions of Go older than 1.15, only conversions\n" +
+			"whose results are concatenated with strings or passed to functions\n" +
+			"of the fmt package are flagged.\n",
+	},
+	"SA9010": {
+		Title: "Error formatted with fmt.Errorf doesn't follow the project's wrapping policy",
+		Text: "Since Go 1.13, fmt.Errorf wraps errors formatted with the `%w`\n" +
+			"verb, so that callers can inspect them with errors.Is and\n" +
+			"errors.As. Wrapping makes the wrapped errors part of a package's\n" +
+			"API, which projects may want to require or to prevent.\n" +
+			"\n" +
+			"Setting the `error_wrapping` option of the configuration file to\n" +
+			"`\"wrap\"` flags errors formatted with `%v` or `%s` instead of being\n" +
+			"wrapped. Packages listed in the `opaque_error_packages` option,\n" +
+			"such as those at the boundary of an API, must not wrap errors\n" +
+			"instead; in them, uses of `%w` are flagged. A trailing `/...`\n" +
+			"includes subpackages.\n",
+		NonDefault: true,
+	},
+	"SA9011": {
+		Title: "Init function does I/O or panics on errors",
+		Text: "Init functions run whenever a package is imported, including by\n" +
+			"tests and by tools that only need a small part of the package.\n" +
+			"Work that reads files, accesses the network or a database, runs\n" +
+			"commands or modifies the environment of the process makes such\n" +
+			"packages slow to start and hard to test, and init functions that\n" +
+			"panic or exit on errors crash every program that imports them.\n" +
+			"Such work is better done lazily, or in a setup function that\n" +
+			"returns an error.\n" +
+			"\n" +
+			"Calls to Must functions, such as regexp.MustCompile, are flagged\n" +
+			"unless all of their arguments are constants. template.Must is\n" +
+			"allowed. Additional functions that init functions may call can be\n" +
+			"listed in the `init_allowed_functions` option of the configuration\n" +
+			"file; a trailing `*` matches any name with that prefix. Test files\n" +
+			"are skipped.\n",
+		NonDefault: true,
+	},
+	"SA9012": {
+		Title: "Package-level variable of mutable type is modified after initialization",
+		Text: "Package-level maps, slices, pointers and structs with exported\n" +
+			"fields that are modified outside of init functions are global\n" +
+			"state: the behavior of the package depends on what other code has\n" +
+			"done before, tests can't run in parallel, and dependencies are\n" +
+			"hidden. Projects that prefer passing dependencies explicitly can\n" +
+			"use this check to find such variables. Writes in init functions\n" +
+			"and in test files are allowed.\n" +
+			"\n" +
+			"Variables that are meant to be modified, such as registries of\n" +
+			"plugins, can be listed, as `importpath.name`, in the\n" +
+			"`mutable_globals` option of the configuration file; a trailing `*`\n" +
+			"matches any name with that prefix.\n",
+		NonDefault: true,
+	},
+	"SA9013": {
+		Title: "`//go:generate` directive with malformed quoting or an undeclared command",
+		Text: "Code generation is only reproducible if the generators are\n" +
+			"dependencies of the module, in the versions that go.mod records.\n" +
+			"This check reports `//go:generate` directives that run commands\n" +
+			"which neither a `tool` directive in go.mod nor an import in\n" +
+			"tools.go provide, `go run` of packages that go.mod doesn't require,\n" +
+			"and `go tool` of tools that go.mod doesn't declare. `go run` with\n" +
+			"an explicit version, local scripts and common system commands are\n" +
+			"allowed. Directives with malformed quoting, which go generate\n" +
+			"rejects, are reported as well.\n" +
+			"\n" +
+			"Only the tools.go files in the module root and its tools\n" +
+			"directory are considered. Files outside of modules are only\n" +
+			"checked for malformed quoting.\n",
+		NonDefault: true,
+	},
+	"SA9014": {
+		Title: "Import violates the import rules of the configuration",
+		Text: "Projects can restrict which packages may be imported with the\n" +
+			"`import_rules` option of the configuration file, for example to\n" +
+			"phase out deprecated libraries, to keep dependencies with\n" +
+			"unsuitable licenses out, or to confine `unsafe` to a few\n" +
+			"packages. Each rule forbids the packages listed in `forbid`, or\n" +
+			"all packages not listed in `allow`, in all packages except those\n" +
+			"listed in `except`:\n" +
+			"\n" +
+			"    [[import_rules]]\n" +
+			"    forbid = [\"unsafe\"]\n" +
+			"    except = [\"example.com/project/internal/fastpath/...\"]\n" +
+			"    reason = \"unsafe code needs a security review\"\n" +
+			"\n" +
+			"Imports are matched by the packages they resolve to, so vendored\n" +
+			"copies of forbidden packages are reported as well.\n",
+		NonDefault: true,
+	},
+}
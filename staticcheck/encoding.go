@@ -0,0 +1,317 @@
+package staticcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+	"honnef.co/go/tools/ssa"
+)
+
+// encodingFuncs maps functions of encoding/json and encoding/gob to
+// the index of the argument they encode or decode into.
+var encodingFuncs = map[string]int{
+	"encoding/json.Marshal":           0,
+	"encoding/json.MarshalIndent":     0,
+	"encoding/json.Unmarshal":         1,
+	"(*encoding/json.Encoder).Encode": 0,
+	"(*encoding/json.Decoder).Decode": 0,
+	"(*encoding/gob.Encoder).Encode":  0,
+	"(*encoding/gob.Decoder).Decode":  0,
+}
+
+// An encoding describes the types that an encoding package can't
+// handle.
+type encoding struct {
+	// custom are the methods with which types encode or decode
+	// themselves.
+	custom []string
+	json   bool
+	// qf qualifies the names of types in messages.
+	qf types.Qualifier
+}
+
+var (
+	jsonEncoding = encoding{custom: []string{"MarshalJSON", "UnmarshalJSON", "MarshalText", "UnmarshalText"}, json: true}
+	gobEncoding  = encoding{custom: []string{"GobEncode", "GobDecode", "MarshalBinary", "UnmarshalBinary"}}
+)
+
+// isCustom reports whether T encodes or decodes itself.
+func (enc encoding) isCustom(T types.Type) bool {
+	if _, ok := T.(*types.Pointer); !ok {
+		T = types.NewPointer(T)
+	}
+	ms := types.NewMethodSet(T)
+	for _, name := range enc.custom {
+		if ms.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the name that the encoding uses for field, and
+// whether the encoding ignores it.
+func (enc encoding) fieldName(field *types.Var, tag string) (string, bool) {
+	if enc.json && reflect.StructTag(tag).Get("json") == "-" {
+		return "", true
+	}
+	if !field.Exported() && !(enc.json && field.Anonymous()) {
+		// encoding/json promotes the exported fields of embedded
+		// structs, even if the struct's type isn't exported.
+		return "", true
+	}
+	if !enc.json {
+		switch field.Type().Underlying().(type) {
+		case *types.Chan, *types.Signature:
+			// gob treats such fields like unexported ones.
+			return "", true
+		}
+	}
+	return field.Name(), false
+}
+
+// unencodable returns a description of the first part of T, found at
+// path, that enc can't handle, or the empty string.
+func (enc encoding) unencodable(T types.Type, path string, seen map[types.Type]bool) string {
+	where := func() string {
+		if path == "" {
+			return "the value"
+		}
+		return "field " + strings.TrimPrefix(path, ".")
+	}
+	if seen[T] {
+		return ""
+	}
+	seen[T] = true
+	if enc.isCustom(T) {
+		return ""
+	}
+	switch U := T.Underlying().(type) {
+	case *types.Basic:
+		if enc.json && U.Info()&types.IsComplex != 0 {
+			return fmt.Sprintf("%s has the unsupported type %s", where(), types.TypeString(T, enc.qf))
+		}
+	case *types.Chan, *types.Signature:
+		return fmt.Sprintf("%s has the unsupported type %s", where(), types.TypeString(T, enc.qf))
+	case *types.Pointer:
+		return enc.unencodable(U.Elem(), path, seen)
+	case *types.Slice:
+		return enc.unencodable(U.Elem(), path+"[]", seen)
+	case *types.Array:
+		return enc.unencodable(U.Elem(), path+"[]", seen)
+	case *types.Map:
+		if enc.json && !enc.isCustom(U.Key()) {
+			basic, ok := U.Key().Underlying().(*types.Basic)
+			if !ok || basic.Info()&(types.IsString|types.IsInteger) == 0 {
+				return fmt.Sprintf("%s has map keys of the unsupported type %s", where(), types.TypeString(U.Key(), enc.qf))
+			}
+		}
+		return enc.unencodable(U.Elem(), path+"[]", seen)
+	case *types.Struct:
+		if U.NumFields() == 0 {
+			return ""
+		}
+		visible, msg := enc.fields(U, path, seen)
+		if msg != "" {
+			return msg
+		}
+		if !visible {
+			if enc.json {
+				return fmt.Sprintf("%s of type %s has no exported fields, so nothing is encoded or decoded", where(), types.TypeString(T, enc.qf))
+			}
+			return fmt.Sprintf("%s of type %s has no exported fields, which gob rejects", where(), types.TypeString(T, enc.qf))
+		}
+	}
+	return ""
+}
+
+// fields checks the fields of the struct T, found at path. It
+// returns whether the encoding handles any of them, and a
+// description of the first field that it can't handle.
+func (enc encoding) fields(T *types.Struct, path string, seen map[types.Type]bool) (bool, string) {
+	visible := false
+	for i := 0; i < T.NumFields(); i++ {
+		field := T.Field(i)
+		name, ignored := enc.fieldName(field, T.Tag(i))
+		if ignored {
+			continue
+		}
+		if enc.json && field.Anonymous() && !enc.isCustom(field.Type()) {
+			if embedded, ok := Dereference(field.Type()).Underlying().(*types.Struct); ok {
+				// The fields of embedded structs are promoted.
+				v, msg := enc.fields(embedded, path+"."+name, seen)
+				if msg != "" {
+					return false, msg
+				}
+				visible = visible || v
+				continue
+			}
+			if !field.Exported() {
+				continue
+			}
+		}
+		visible = true
+		if msg := enc.unencodable(field.Type(), path+"."+name, seen); msg != "" {
+			return false, msg
+		}
+	}
+	return visible, ""
+}
+
+func (c *Checker) CheckUnencodableType(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		obj, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+		if !ok {
+			return true
+		}
+		idx, ok := encodingFuncs[obj.FullName()]
+		if !ok || idx >= len(call.Args) {
+			return true
+		}
+		enc := jsonEncoding
+		if obj.Pkg().Path() == "encoding/gob" {
+			enc = gobEncoding
+		}
+		enc.qf = nameQualifier(j.NodePackage(call).Pkg)
+		arg := call.Args[idx]
+		T := TypeOf(j, arg)
+		if T == nil {
+			return true
+		}
+		if _, ok := T.Underlying().(*types.Interface); ok {
+			return true
+		}
+		msg := enc.unencodable(T, "", map[types.Type]bool{})
+		if msg == "" {
+			return true
+		}
+		name := Render(j, call.Fun)
+		j.Errorf(arg, "%s can't handle %s: %s", name, Render(j, arg), msg)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// unmarshalFuncs maps functions that unmarshal data to the index of
+// the argument they unmarshal into.
+var unmarshalFuncs = map[string]int{
+	"encoding/xml.Unmarshal":                       1,
+	"(*encoding/xml.Decoder).Decode":               0,
+	"(*encoding/xml.Decoder).DecodeElement":        0,
+	"encoding/json.Unmarshal":                      1,
+	"(*encoding/json.Decoder).Decode":              0,
+	"(*encoding/gob.Decoder).Decode":               0,
+	"encoding/asn1.Unmarshal":                      1,
+	"encoding/asn1.UnmarshalWithParams":            1,
+	"gopkg.in/yaml.v2.Unmarshal":                   1,
+	"gopkg.in/yaml.v2.UnmarshalStrict":             1,
+	"(*gopkg.in/yaml.v2.Decoder).Decode":           0,
+	"gopkg.in/yaml.v3.Unmarshal":                   1,
+	"(*gopkg.in/yaml.v3.Decoder).Decode":           0,
+	"sigs.k8s.io/yaml.Unmarshal":                   1,
+	"github.com/BurntSushi/toml.Unmarshal":         1,
+	"github.com/BurntSushi/toml.Decode":            1,
+	"github.com/BurntSushi/toml.DecodeFile":        1,
+	"(*github.com/BurntSushi/toml.Decoder).Decode": 0,
+	"github.com/mitchellh/mapstructure.Decode":     1,
+}
+
+// matchesFunction reports whether the function called name matches
+// any of patterns. A trailing "*" in a pattern matches any suffix.
+func matchesFunction(name string, patterns []string) bool {
+	for _, pat := range patterns {
+		if pat == name || (strings.HasSuffix(pat, "*") && strings.HasPrefix(name, strings.TrimSuffix(pat, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnmarshalLike reports whether fn's last parameter is an empty
+// interface and its last result an error, like that of
+// json.Unmarshal.
+func isUnmarshalLike(fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+	params, results := sig.Params(), sig.Results()
+	if params.Len() == 0 || results.Len() == 0 {
+		return false
+	}
+	iface, ok := params.At(params.Len() - 1).Type().Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() != 0 {
+		return false
+	}
+	return types.Identical(results.At(results.Len()-1).Type(), types.Universe.Lookup("error").Type())
+}
+
+func (c *Checker) CheckUnmarshalPointer(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, b := range ssafn.Blocks {
+			for _, ins := range b.Instrs {
+				site, ok := ins.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				callee := site.Common().StaticCallee()
+				if callee == nil {
+					continue
+				}
+				obj, ok := callee.Object().(*types.Func)
+				if !ok {
+					continue
+				}
+				idx, ok := unmarshalFuncs[obj.FullName()]
+				if !ok {
+					pkg := j.NodePackage(site)
+					if pkg == nil || !matchesFunction(obj.FullName(), pkg.Config.UnmarshalFunctions) || !isUnmarshalLike(obj) {
+						continue
+					}
+					idx = obj.Type().(*types.Signature).Params().Len() - 1
+				}
+				args := site.Common().Args
+				if callee.Signature.Recv() != nil {
+					args = args[1:]
+				}
+				if idx >= len(args) {
+					continue
+				}
+				name := obj.Name()
+				if callee.Signature.Recv() == nil {
+					name = obj.Pkg().Name() + "." + name
+				}
+				arg := args[idx]
+				if k, ok := arg.(*ssa.Const); ok && k.IsNil() {
+					j.Errorf(site, "%s expects to unmarshal into a pointer, but the provided value is nil", name)
+					continue
+				}
+				mi, ok := arg.(*ssa.MakeInterface)
+				if !ok {
+					continue
+				}
+				if _, ok := mi.X.Type().Underlying().(*types.Pointer); !ok {
+					if _, ok := mi.X.Type().Underlying().(*types.Interface); !ok {
+						j.Errorf(site, "%s expects to unmarshal into a pointer, but the provided value is not a pointer", name)
+					}
+					continue
+				}
+				if k, ok := mi.X.(*ssa.Const); ok && k.IsNil() {
+					j.Errorf(site, "%s expects to unmarshal into a pointer, but the provided pointer is nil", name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// fuzzTargets calls fn for the fuzz targets in f, that is the
+// function literals passed to testing.F.Fuzz.
+func fuzzTargets(j *lint.Job, f *ast.File, fn func(call *ast.CallExpr, target *ast.FuncLit)) {
+	ast.Inspect(f, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !IsCallToAST(j, call, "(*testing.F).Fuzz") {
+			return true
+		}
+		if lit, ok := call.Args[0].(*ast.FuncLit); ok {
+			fn(call, lit)
+		}
+		return true
+	})
+}
+
+func (c *Checker) CheckFuzzTargetFailures(j *lint.Job) {
+	if !IsGoVersion(j, 18) {
+		return
+	}
+	isRecover := func(expr ast.Expr) bool {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		b, ok := ObjectOf(j, id).(*types.Builtin)
+		return ok && b.Name() == "recover"
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		fuzzTargets(j, f, func(_ *ast.CallExpr, target *ast.FuncLit) {
+			for _, stmt := range target.Body.List {
+				expr, ok := stmt.(*ast.ExprStmt)
+				if !ok {
+					continue
+				}
+				call, ok := expr.X.(*ast.CallExpr)
+				if !ok {
+					continue
+				}
+				for _, name := range []string{"Skip", "Skipf", "SkipNow"} {
+					if IsCallToAST(j, call, "(*testing.common)."+name) {
+						j.Errorf(call, "the fuzz target unconditionally calls %s, so it never tests any input", name)
+					}
+				}
+			}
+
+			ast.Inspect(target.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.CallExpr:
+					sel, ok := node.Fun.(*ast.SelectorExpr)
+					if !ok || !hasType(j, sel.X, "*testing.F") {
+						return true
+					}
+					j.Errorf(node, "the fuzz target calls %s, but only the methods of the *testing.T passed to the fuzz target may be used inside of it", Render(j, node.Fun))
+				case *ast.DeferStmt:
+					lit, ok := node.Call.Fun.(*ast.FuncLit)
+					if !ok {
+						return true
+					}
+					for _, stmt := range lit.Body.List {
+						var discarded bool
+						switch stmt := stmt.(type) {
+						case *ast.ExprStmt:
+							discarded = isRecover(stmt.X)
+						case *ast.AssignStmt:
+							discarded = len(stmt.Rhs) == 1 && isRecover(stmt.Rhs[0]) && IsBlank(stmt.Lhs[0])
+						}
+						if discarded {
+							j.Errorf(stmt, "the fuzz target discards the value of recover, which hides crashes from the fuzzer")
+						}
+					}
+				}
+				return true
+			})
+		})
+	}
+}
+
+func (c *Checker) CheckFuzzTargetParams(j *lint.Job) {
+	if !IsGoVersion(j, 18) {
+		return
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		fuzzTargets(j, f, func(_ *ast.CallExpr, target *ast.FuncLit) {
+			used := map[types.Object]bool{}
+			ast.Inspect(target.Body, func(node ast.Node) bool {
+				if id, ok := node.(*ast.Ident); ok {
+					used[ObjectOf(j, id)] = true
+				}
+				return true
+			})
+			i := 0
+			for _, field := range target.Type.Params.List {
+				for _, name := range field.Names {
+					i++
+					if i == 1 || name.Name == "_" || used[ObjectOf(j, name)] {
+						// The first parameter is the *testing.T.
+						continue
+					}
+					j.Errorf(name, "the fuzz target never uses the parameter %s, so fuzzing it is wasted effort; remove it, or use it", name.Name)
+				}
+			}
+		})
+	}
+}
+
+// hasFuzzCorpus reports whether the fuzz test named name, which is
+// declared in the file filename, has a seed corpus in testdata.
+func hasFuzzCorpus(filename, name string) bool {
+	entries, err := filepath.Glob(filepath.Join(filepath.Dir(filename), "testdata", "fuzz", name, "*"))
+	return err == nil && len(entries) > 0
+}
+
+func (c *Checker) CheckFuzzSeedCorpus(j *lint.Job) {
+	if !IsGoVersion(j, 18) {
+		return
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || !strings.HasPrefix(fn.Name.Name, "Fuzz") {
+				continue
+			}
+			params := fn.Type.Params.List
+			if len(params) != 1 || len(params[0].Names) != 1 || !hasType(j, params[0].Type, "*testing.F") {
+				continue
+			}
+			fparam := ObjectOf(j, params[0].Names[0])
+			fuzzes, seeded := false, false
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				switch {
+				case IsCallToAST(j, call, "(*testing.F).Fuzz"):
+					fuzzes = true
+				case IsCallToAST(j, call, "(*testing.F).Add"):
+					seeded = true
+				default:
+					for _, arg := range call.Args {
+						if id, ok := arg.(*ast.Ident); ok && ObjectOf(j, id) == fparam {
+							// Helpers may add to the corpus.
+							seeded = true
+						}
+					}
+				}
+				return true
+			})
+			if !fuzzes || seeded {
+				continue
+			}
+			if hasFuzzCorpus(j.Program.DisplayPosition(fn.Pos()).Filename, fn.Name.Name) {
+				continue
+			}
+			j.Errorf(fn.Name, "%s has no seed corpus; add inputs with %s.Add or to testdata/fuzz/%s, so that tests run without -fuzz exercise the target", fn.Name.Name, params[0].Names[0].Name, fn.Name.Name)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package staticcheck
+
+import (
+	"bufio"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"honnef.co/go/tools/lint"
+)
+
+// generateCommands are commands that //go:generate directives may
+// use without declaring them as dependencies, because they are part
+// of Go or of most systems.
+var generateCommands = map[string]bool{
+	"go": true, "gofmt": true,
+	"awk": true, "bash": true, "cat": true, "cp": true, "echo": true,
+	"false": true, "mkdir": true, "mv": true, "rm": true, "sed": true,
+	"sh": true, "touch": true, "true": true,
+}
+
+// goTools are the commands that go tool runs without declaring them
+// as tools.
+var goTools = map[string]bool{
+	"addr2line": true, "asm": true, "buildid": true, "cgo": true,
+	"compile": true, "covdata": true, "cover": true, "doc": true,
+	"fix": true, "link": true, "nm": true, "objdump": true, "pack": true,
+	"pprof": true, "test2json": true, "trace": true, "vet": true,
+}
+
+// splitGenerate splits the arguments of a //go:generate directive
+// into words, like go generate does. Words are separated by spaces
+// and tabs, and double-quoted words use Go syntax.
+func splitGenerate(line string) ([]string, error) {
+	var words []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return words, nil
+		}
+		if line[0] != '"' {
+			i := strings.IndexAny(line, " \t")
+			if i == -1 {
+				i = len(line)
+			}
+			words = append(words, line[:i])
+			line = line[i:]
+			continue
+		}
+		i := 1
+		for ; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(line) {
+			return nil, errors.New("unterminated quoted string")
+		}
+		word, err := strconv.Unquote(line[:i+1])
+		if err != nil {
+			return nil, errors.New("invalid quoted string " + line[:i+1])
+		}
+		words = append(words, word)
+		line = line[i+1:]
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			return nil, errors.New("quoted string " + strings.Fields(line)[0] + " isn't followed by a space")
+		}
+	}
+}
+
+// A generateModule describes the dependencies that a module declares
+// for its //go:generate directives.
+type generateModule struct {
+	path     string
+	requires []string
+	// tools are the packages of tool directives in go.mod, and
+	// imports are the packages imported by tools.go files.
+	tools   []string
+	imports []string
+}
+
+var majorVersionRx = regexp.MustCompile(`^v[0-9]+$`)
+
+// commandName returns the name of the binary that go install builds
+// for the package path.
+func commandName(pkg string) string {
+	name := path.Base(pkg)
+	if majorVersionRx.MatchString(name) && path.Dir(pkg) != "." {
+		name = path.Base(path.Dir(pkg))
+	}
+	return name
+}
+
+// hasCommand reports whether one of the packages builds the command
+// name.
+func hasCommand(pkgs []string, name string) bool {
+	for _, pkg := range pkgs {
+		if pkg == name || commandName(pkg) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// providesPackage reports whether the package pkg is part of the
+// module, the standard library, or a dependency of the module.
+func (m *generateModule) providesPackage(pkg string) bool {
+	if !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".") {
+		return true
+	}
+	for _, mod := range append([]string{m.path}, m.requires...) {
+		if pkg == mod || strings.HasPrefix(pkg, mod+"/") {
+			return true
+		}
+	}
+	for _, imp := range m.imports {
+		if pkg == imp {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGoMod extracts the module path, the required modules and the
+// tools from the contents of a go.mod file.
+func parseGoMod(data []byte) *generateModule {
+	m := &generateModule{}
+	block := ""
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		arg := strings.Trim(fields[1], `"`)
+		switch fields[0] {
+		case "module":
+			m.path = arg
+		case "require":
+			m.requires = append(m.requires, arg)
+		case "tool":
+			m.tools = append(m.tools, arg)
+		}
+	}
+	return m
+}
+
+// generateModules finds and caches the modules that directories
+// belong to.
+type generateModules struct {
+	mu   sync.Mutex
+	dirs map[string]*generateModule
+}
+
+// get returns the module that the directory dir belongs to, or nil if
+// it doesn't belong to a module.
+func (gm *generateModules) get(dir string) *generateModule {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if gm.dirs == nil {
+		gm.dirs = map[string]*generateModule{}
+	}
+
+	var visited []string
+	var m *generateModule
+	for {
+		if cached, ok := gm.dirs[dir]; ok {
+			m = cached
+			break
+		}
+		visited = append(visited, dir)
+		if data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			m = parseGoMod(data)
+			m.imports = toolsImports(dir)
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	for _, dir := range visited {
+		gm.dirs[dir] = m
+	}
+	return m
+}
+
+// toolsImports returns the packages that the tools.go file of the
+// module in dir imports.
+func toolsImports(dir string) []string {
+	var out []string
+	for _, name := range []string{"tools.go", filepath.Join("tools", "tools.go")} {
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+func (c *Checker) CheckGenerateDirectives(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		var mod *generateModule
+		if name := j.Program.Prog.Fset.File(f.Pos()).Name(); name != "" {
+			if dir, err := filepath.Abs(filepath.Dir(name)); err == nil {
+				mod = c.modules.get(dir)
+			}
+		}
+		// aliases are the commands defined with -command.
+		aliases := map[string]bool{}
+		for _, cg := range f.Comments {
+			for _, cm := range cg.List {
+				if !strings.HasPrefix(cm.Text, "//go:generate ") {
+					continue
+				}
+				words, err := splitGenerate(strings.TrimPrefix(cm.Text, "//go:generate "))
+				if err != nil {
+					j.Errorf(cm, "malformed //go:generate directive: %s", err)
+					continue
+				}
+				if len(words) >= 3 && words[0] == "-command" {
+					aliases[words[1]] = true
+					words = words[2:]
+				}
+				if len(words) == 0 || mod == nil {
+					continue
+				}
+				checkGenerateCommand(j, cm, mod, aliases, words)
+			}
+		}
+	}
+}
+
+func checkGenerateCommand(j *lint.Job, cm *ast.Comment, mod *generateModule, aliases map[string]bool, words []string) {
+	cmd := words[0]
+	if strings.ContainsAny(cmd, "$/\\") || aliases[cmd] {
+		// Environment variables, scripts in the repository and
+		// aliases, which are checked where they are defined.
+		return
+	}
+	if cmd != "go" {
+		if !generateCommands[cmd] && !hasCommand(mod.tools, cmd) && !hasCommand(mod.imports, cmd) {
+			j.Errorf(cm, "command %s isn't declared as a tool in go.mod or imported in tools.go, so code generation depends on what is installed; declare it, or use go run with a version", cmd)
+		}
+		return
+	}
+
+	if len(words) < 2 {
+		return
+	}
+	var arg string
+	for _, w := range words[2:] {
+		if !strings.HasPrefix(w, "-") {
+			arg = w
+			break
+		}
+	}
+	if arg == "" {
+		return
+	}
+	switch words[1] {
+	case "run":
+		if strings.Contains(arg, "@") || strings.HasPrefix(arg, ".") || strings.HasSuffix(arg, ".go") || filepath.IsAbs(arg) {
+			return
+		}
+		if !mod.providesPackage(arg) {
+			j.Errorf(cm, "go run %s refers to a package that go.mod doesn't require; add it to tools.go, or use go run %s@version", arg, arg)
+		}
+	case "tool":
+		if !goTools[arg] && !hasCommand(mod.tools, arg) {
+			j.Errorf(cm, "go tool %s refers to a tool that go.mod doesn't declare; add it with go get -tool", arg)
+		}
+	}
+}
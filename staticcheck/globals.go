@@ -0,0 +1,126 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// isMutableType reports whether values of type T can be modified in
+// place by code that can access them: maps, slices, pointers and
+// structs with exported fields.
+func isMutableType(T types.Type) bool {
+	switch T := T.Underlying().(type) {
+	case *types.Map, *types.Slice, *types.Pointer:
+		return true
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			if T.Field(i).Exported() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writtenVar returns the package-level variable that is modified by
+// assigning to lhs, if any, such as v in v.f[i] = x.
+func writtenVar(j *lint.Job, lhs ast.Expr) *types.Var {
+	for {
+		switch expr := lhs.(type) {
+		case *ast.ParenExpr:
+			lhs = expr.X
+		case *ast.StarExpr:
+			lhs = expr.X
+		case *ast.IndexExpr:
+			lhs = expr.X
+		case *ast.SelectorExpr:
+			if id, ok := expr.X.(*ast.Ident); ok {
+				if _, ok := ObjectOf(j, id).(*types.PkgName); ok {
+					lhs = expr.Sel
+					continue
+				}
+			}
+			lhs = expr.X
+		case *ast.Ident:
+			v, ok := ObjectOf(j, expr).(*types.Var)
+			if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+				return nil
+			}
+			return v
+		default:
+			return nil
+		}
+	}
+}
+
+func (c *Checker) CheckGlobalMutableState(j *lint.Job) {
+	writes := map[*types.Var][]ast.Node{}
+	record := func(lhs ast.Expr, node ast.Node) {
+		v := writtenVar(j, lhs)
+		if v == nil || !isMutableType(v.Type()) {
+			return
+		}
+		writes[v] = append(writes[v], node)
+	}
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				record(lhs, node)
+			}
+		case *ast.IncDecStmt:
+			record(node.X, node)
+		case *ast.CallExpr:
+			if id, ok := node.Fun.(*ast.Ident); ok && len(node.Args) > 0 {
+				if b, ok := ObjectOf(j, id).(*types.Builtin); ok && b.Name() == "delete" {
+					record(node.Args[0], node)
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if IsInTest(j, f) {
+			// Tests commonly replace package-level variables.
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			if fn.Recv == nil && fn.Name.Name == "init" {
+				// Writes in init functions are part of initializing
+				// the variables, unless they happen in closures
+				// that run later.
+				ast.Inspect(fn.Body, func(node ast.Node) bool {
+					if lit, ok := node.(*ast.FuncLit); ok {
+						ast.Inspect(lit.Body, inspect)
+						return false
+					}
+					return true
+				})
+				continue
+			}
+			ast.Inspect(fn.Body, inspect)
+		}
+	}
+
+	for v, nodes := range writes {
+		pkg := j.NodePackage(v)
+		if pkg == nil || IsInTest(j, v) || v.Name() == "_" {
+			continue
+		}
+		if matchesFunction(v.Pkg().Path()+"."+v.Name(), pkg.Config.MutableGlobals) {
+			continue
+		}
+		p := j.Errorf(v, "package-level variable %s of mutable type %s is modified outside of init, which makes the package's behavior depend on hidden state; consider passing it to the code that needs it instead", v.Name(), types.TypeString(v.Type(), types.RelativeTo(v.Pkg())))
+		for _, node := range nodes {
+			j.AddRelated(p, node, "%s is modified here", v.Name())
+		}
+	}
+}
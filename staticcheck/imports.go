@@ -0,0 +1,75 @@
+package staticcheck
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// matchesImport reports whether the import path is matched by
+// patterns of import rules, which are package patterns or "std" for
+// the standard library.
+func matchesImport(path string, patterns []string) bool {
+	for _, pat := range patterns {
+		if pat == "std" && !strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			return true
+		}
+	}
+	return matchesPackage(path, patterns)
+}
+
+// unvendoredPath returns the import path of the package path without
+// the prefix of vendor directories.
+func unvendoredPath(path string) string {
+	if i := strings.LastIndex(path, "/vendor/"); i != -1 {
+		return path[i+len("/vendor/"):]
+	}
+	return strings.TrimPrefix(path, "vendor/")
+}
+
+func (c *Checker) CheckImportRules(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pkg := j.NodePackage(f)
+		if pkg == nil || len(pkg.Config.ImportRules) == 0 {
+			continue
+		}
+		importer := strings.TrimSuffix(pkg.Pkg.Path(), "_test")
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" {
+				continue
+			}
+			// Match the package that the import resolves to, so
+			// that vendored copies don't evade the rules.
+			obj := j.Program.Info.Implicits[spec]
+			if spec.Name != nil {
+				obj = j.Program.Info.Defs[spec.Name]
+			}
+			if pn, ok := obj.(*types.PkgName); ok {
+				path = unvendoredPath(pn.Imported().Path())
+			}
+			for _, rule := range pkg.Config.ImportRules {
+				if matchesPackage(importer, rule.Except) {
+					continue
+				}
+				var verb string
+				switch {
+				case matchesImport(path, rule.Forbid):
+					verb = "is forbidden"
+				case len(rule.Allow) > 0 && !matchesImport(path, rule.Allow):
+					verb = "isn't allowed"
+				default:
+					continue
+				}
+				if rule.Reason != "" {
+					j.Errorf(spec, "importing %s %s by the configuration: %s", path, verb, rule.Reason)
+				} else {
+					j.Errorf(spec, "importing %s %s by the configuration", path, verb)
+				}
+				break
+			}
+		}
+	}
+}
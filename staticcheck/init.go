@@ -0,0 +1,161 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// initWork describes the work that calling fn does, if it is work
+// that doesn't belong in init functions.
+func initWork(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return ""
+	}
+	name := fn.Name()
+	var recv string
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		if T, ok := Dereference(sig.Recv().Type()).(*types.Named); ok {
+			recv = T.Obj().Name()
+		}
+	}
+	switch fn.Pkg().Path() {
+	case "os":
+		if recv != "" {
+			break
+		}
+		switch name {
+		case "Open", "OpenFile", "Create", "ReadFile", "WriteFile", "ReadDir",
+			"Mkdir", "MkdirAll", "MkdirTemp", "CreateTemp", "Remove", "RemoveAll",
+			"Rename", "Stat", "Lstat", "Chmod", "Chown", "Symlink", "Link", "Truncate":
+			return "does file I/O"
+		case "Setenv", "Unsetenv", "Clearenv", "Chdir":
+			return "modifies the environment of the process"
+		case "Exit":
+			return "exits the program"
+		}
+	case "io/ioutil":
+		switch name {
+		case "ReadFile", "WriteFile", "ReadDir", "TempFile", "TempDir":
+			return "does file I/O"
+		}
+	case "os/exec":
+		if recv == "Cmd" {
+			switch name {
+			case "Run", "Start", "Output", "CombinedOutput":
+				return "runs a command"
+			}
+		}
+	case "net":
+		if strings.HasPrefix(name, "Dial") || strings.HasPrefix(name, "Listen") || strings.HasPrefix(name, "Lookup") {
+			return "accesses the network"
+		}
+	case "net/http":
+		switch recv {
+		case "", "Client", "Server":
+			switch name {
+			case "Get", "Head", "Post", "PostForm", "Do",
+				"ListenAndServe", "ListenAndServeTLS", "Serve", "ServeTLS":
+				return "accesses the network"
+			}
+		}
+	case "database/sql":
+		switch recv {
+		case "DB":
+			switch name {
+			case "Ping", "PingContext", "Exec", "ExecContext", "Query", "QueryContext", "QueryRow", "QueryRowContext", "Begin", "BeginTx":
+				return "accesses a database"
+			}
+		}
+	case "flag":
+		if recv == "" && name == "Parse" {
+			return "parses the command line, also when the package is imported by tests and other programs"
+		}
+	case "log":
+		if strings.HasPrefix(name, "Fatal") {
+			return "exits the program"
+		}
+		if strings.HasPrefix(name, "Panic") {
+			return "panics"
+		}
+	}
+	return ""
+}
+
+// initAllowedFunctions are functions that init functions may call,
+// even though they panic on errors.
+var initAllowedFunctions = []string{
+	"text/template.Must",
+	"html/template.Must",
+}
+
+func (c *Checker) CheckHeavyInit(j *lint.Job) {
+	errorType := types.Universe.Lookup("error").Type()
+	checkInit := func(pkg *lint.Pkg, fn *ast.FuncDecl) {
+		allowed := append(append([]string(nil), initAllowedFunctions...), pkg.Config.InitAllowedFunctions...)
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				// Closures usually run later, not during
+				// initialization.
+				return false
+			case *ast.CallExpr:
+				var id *ast.Ident
+				switch fun := node.Fun.(type) {
+				case *ast.Ident:
+					id = fun
+				case *ast.SelectorExpr:
+					id = fun.Sel
+				default:
+					return true
+				}
+				switch obj := ObjectOf(j, id).(type) {
+				case *types.Builtin:
+					if obj.Name() == "panic" && len(node.Args) == 1 && types.Implements(TypeOf(j, node.Args[0]), errorType.Underlying().(*types.Interface)) {
+						j.Errorf(node, "init function panics on error, which makes the package hard to test and crashes every program that imports it; consider returning the error from an explicit setup function")
+					}
+				case *types.Func:
+					if matchesFunction(obj.FullName(), allowed) {
+						return true
+					}
+					if desc := initWork(obj); desc != "" {
+						j.Errorf(node, "init function calls %s, which %s; consider doing this lazily or in an explicit setup function, so that importing the package has no hidden costs", obj.FullName(), desc)
+						return true
+					}
+					if strings.HasPrefix(obj.Name(), "Must") && !constantArgs(j, node) {
+						j.Errorf(node, "init function calls %s, which panics on error; consider returning the error from an explicit setup function", obj.FullName())
+					}
+				}
+			}
+			return true
+		})
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if IsInTest(j, f) {
+			continue
+		}
+		pkg := j.NodePackage(f)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != "init" || fn.Body == nil {
+				continue
+			}
+			checkInit(pkg, fn)
+		}
+	}
+}
+
+// constantArgs reports whether all arguments of call are constants,
+// such as the pattern of regexp.MustCompile, which can't fail
+// unexpectedly at run time.
+func constantArgs(j *lint.Job, call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if tv, ok := j.Program.Info.Types[arg]; !ok || tv.Value == nil {
+			return false
+		}
+	}
+	return true
+}
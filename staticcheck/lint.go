@@ -9,8 +9,10 @@ import (
 	"go/types"
 	htmltemplate "html/template"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"regexp/syntax"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -57,14 +59,6 @@ func uniqueCutset(call *Call) {
 	}
 }
 
-func unmarshalPointer(name string, arg int) CallCheck {
-	return func(call *Call) {
-		if !Pointer(call.Args[arg].Value) {
-			call.Args[arg].Invalid(fmt.Sprintf("%s expects to unmarshal into a pointer, but the provided value is not a pointer", name))
-		}
-	}
-}
-
 func pointlessIntMath(call *Call) {
 	if ConvertedFromInt(call.Args[0].Value) {
 		call.Invalid(fmt.Sprintf("calling %s on a converted integer is pointless", CallName(call.Instr.Common())))
@@ -100,10 +94,17 @@ var (
 
 	checkEncodingBinaryRules = map[string]CallCheck{
 		"encoding/binary.Write": func(call *Call) {
+			checkBinaryMarshal(call, call.Args[2], "binary.Write")
+		},
+		"encoding/binary.Read": func(call *Call) {
 			arg := call.Args[2]
-			if !CanBinaryMarshal(call.Job, arg.Value) {
-				arg.Invalid(fmt.Sprintf("value of type %s cannot be used with binary.Write", arg.Value.Value.Type()))
+			switch arg.Value.Value.Type().Underlying().(type) {
+			case *types.Pointer, *types.Slice, *types.Interface:
+			default:
+				arg.Invalid(fmt.Sprintf("binary.Read needs a pointer or a slice to read into, not a value of type %s", arg.Value.Value.Type()))
+				return
 			}
+			checkBinaryMarshal(call, arg, "binary.Read")
 		},
 	}
 
@@ -121,9 +122,18 @@ var (
 		"(*sync.Pool).Put": func(call *Call) {
 			arg := call.Args[0]
 			typ := arg.Value.Value.Type()
-			if !IsPointerLike(typ) {
-				arg.Invalid("argument should be pointer-like to avoid allocations")
+			if IsPointerLike(typ) {
+				return
+			}
+			if _, ok := typ.Underlying().(*types.Signature); ok {
+				return
 			}
+			if size := sizes(call.Job.Program).Sizeof(typ); size <= 1 {
+				// The runtime doesn't allocate for zero-sized
+				// values and single bytes.
+				return
+			}
+			arg.Invalid(fmt.Sprintf("argument should be pointer-like to avoid allocations: every Put of a %s allocates", types.TypeString(typ, types.RelativeTo(call.Parent.Pkg.Pkg))))
 		},
 	}
 
@@ -153,14 +163,6 @@ var (
 		"strings.TrimRight": uniqueCutset,
 	}
 
-	checkUnmarshalPointerRules = map[string]CallCheck{
-		"encoding/xml.Unmarshal":                unmarshalPointer("xml.Unmarshal", 1),
-		"(*encoding/xml.Decoder).Decode":        unmarshalPointer("Decode", 0),
-		"(*encoding/xml.Decoder).DecodeElement": unmarshalPointer("DecodeElement", 0),
-		"encoding/json.Unmarshal":               unmarshalPointer("json.Unmarshal", 1),
-		"(*encoding/json.Decoder).Decode":       unmarshalPointer("Decode", 0),
-	}
-
 	checkUnbufferedSignalChanRules = map[string]CallCheck{
 		"os/signal.Notify": func(call *Call) {
 			arg := call.Args[0]
@@ -207,6 +209,8 @@ type Checker struct {
 	CheckGenerated bool
 	funcDescs      *functions.Descriptions
 	deprecatedObjs map[types.Object]string
+	platforms      platformSymbols
+	modules        generateModules
 }
 
 func NewChecker() *Checker {
@@ -216,6 +220,8 @@ func NewChecker() *Checker {
 func (*Checker) Name() string   { return "staticcheck" }
 func (*Checker) Prefix() string { return "SA" }
 
+func (*Checker) Docs() map[string]*lint.Documentation { return Docs }
+
 func (c *Checker) Funcs() map[string]lint.Func {
 	return map[string]lint.Func{
 		"SA1000": c.callChecker(checkRegexpRules),
@@ -232,7 +238,7 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA1011": c.callChecker(checkUTF8CutsetRules),
 		"SA1012": c.CheckNilContext,
 		"SA1013": c.CheckSeeker,
-		"SA1014": c.callChecker(checkUnmarshalPointerRules),
+		"SA1014": c.CheckUnmarshalPointer,
 		"SA1015": c.CheckLeakyTimeTick,
 		"SA1016": c.CheckUntrappableSignal,
 		"SA1017": c.callChecker(checkUnbufferedSignalChanRules),
@@ -243,14 +249,31 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA1022": nil,
 		"SA1023": c.CheckWriterBufferModified,
 		"SA1024": c.callChecker(checkUniqueCutsetRules),
+		"SA1025": c.CheckResponseWriterMisuse,
+		"SA1026": c.CheckPlatformSpecificSymbols,
+		"SA1027": c.CheckOpenFileFlags,
+		"SA1028": c.CheckLostCancel,
+		"SA1029": c.CheckDeepEqualMisuse,
+		"SA1030": c.CheckPathOnOSPaths,
+		"SA1031": c.CheckTimeEquality,
+		"SA1032": c.CheckExecCmdMisuse,
+		"SA1033": c.CheckErrorPredicateOnWrapped,
+		"SA1034": c.CheckContextKeyType,
 
 		"SA2000": c.CheckWaitgroupAdd,
 		"SA2001": c.CheckEmptyCriticalSection,
 		"SA2002": c.CheckConcurrentTesting,
 		"SA2003": c.CheckDeferLock,
+		"SA2004": c.CheckRequestContextInGoroutine,
+		"SA2005": c.CheckOnceWithDifferentFuncs,
+		"SA2006": c.CheckAtomicAlignment,
+		"SA2007": c.CheckLockAcrossBlocking,
 
 		"SA3000": c.CheckTestMainExit,
 		"SA3001": c.CheckBenchmarkN,
+		"SA3002": c.CheckFuzzTargetFailures,
+		"SA3003": c.CheckFuzzTargetParams,
+		"SA3004": c.CheckFuzzSeedCorpus,
 
 		"SA4000": c.CheckLhsRhsIdentical,
 		"SA4001": c.CheckIneffectiveCopy,
@@ -273,6 +296,11 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA4017": c.CheckPureFunctions,
 		"SA4018": c.CheckSelfAssignment,
 		"SA4019": c.CheckDuplicateBuildConstraints,
+		"SA4020": c.CheckLostMapEntryUpdate,
+		"SA4021": c.CheckCompareFreshError,
+		"SA4022": c.CheckShadowedErr,
+		"SA4023": c.CheckBuildConstraints,
+		"SA4024": c.CheckMustUseResults,
 
 		"SA5000": c.CheckNilMaps,
 		"SA5001": c.CheckEarlyDefer,
@@ -282,18 +310,44 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA5005": c.CheckCyclicFinalizer,
 		// "SA5006": c.CheckSliceOutOfBounds,
 		"SA5007": c.CheckInfiniteRecursion,
+		"SA5008": c.CheckUncomparableInterfaceKeys,
+		"SA5009": c.CheckPrintf,
+		"SA5010": c.CheckNilChannelOperation,
+		"SA5011": c.CheckUnencodableType,
+		"SA5012": c.CheckMaybeNilChannelOperation,
 
 		"SA6000": c.callChecker(checkRegexpMatchLoopRules),
 		"SA6001": c.CheckMapBytesKey,
 		"SA6002": c.callChecker(checkSyncPoolValueRules),
 		"SA6003": c.CheckRangeStringRunes,
 		"SA6004": c.CheckSillyRegexp,
+		"SA6005": c.CheckLoopVarAddressStored,
+		"SA6006": c.CheckLoopInterfaceConversion,
+		"SA6007": c.CheckLoopStringToBytes,
+		"SA6008": c.CheckErrorConstruction,
+
+		"SA7000": c.CheckMathRandSeed,
+		"SA7001": c.CheckMathRandSecrets,
+		"SA7002": c.CheckWeakTLSConfig,
+		"SA7003": c.callChecker(checkSQLConcatRules),
+		"SA7004": c.CheckHardcodedSecrets,
+		"SA7005": c.CheckErrorFormatLeaks,
 
 		"SA9000": nil,
 		"SA9001": c.CheckDubiousDeferInChannelRangeLoop,
 		"SA9002": c.CheckNonOctalFileMode,
 		"SA9003": c.CheckEmptyBranch,
 		"SA9004": c.CheckMissingEnumTypesInDeclaration,
+		"SA9005": c.CheckFloatStructComparison,
+		"SA9006": c.CheckExhaustiveSwitch,
+		"SA9007": c.CheckExhaustiveFields,
+		"SA9008": c.CheckSprintfPaths,
+		"SA9009": c.CheckStringIntConversion,
+		"SA9010": c.CheckErrorWrapping,
+		"SA9011": c.CheckHeavyInit,
+		"SA9012": c.CheckGlobalMutableState,
+		"SA9013": c.CheckGenerateDirectives,
+		"SA9014": c.CheckImportRules,
 	}
 }
 
@@ -396,6 +450,13 @@ func (c *Checker) findDeprecated(prog *lint.Program) {
 	}
 }
 
+// ProgramChecks returns the checks whose results in a package depend
+// on the other packages being linted. SA4023 detects misspelled build
+// tags by comparing them with the tags used by all linted files.
+func (c *Checker) ProgramChecks() []string {
+	return []string{"SA4023"}
+}
+
 func (c *Checker) Init(prog *lint.Program) {
 	wg := &sync.WaitGroup{}
 	wg.Add(2)
@@ -519,6 +580,9 @@ func (c *Checker) CheckUntrappableSignal(j *lint.Job) {
 }
 
 func (c *Checker) CheckTemplate(j *lint.Job) {
+	// lits maps the positions of calls to Parse to their template
+	// arguments, for use by checkTemplateFields.
+	lits := map[token.Pos]ast.Expr{}
 	fn := func(node ast.Node) bool {
 		call, ok := node.(*ast.CallExpr)
 		if !ok {
@@ -545,6 +609,7 @@ func (c *Checker) CheckTemplate(j *lint.Job) {
 		if !ok {
 			return true
 		}
+		lits[call.Lparen] = call.Args[0]
 		var err error
 		switch kind {
 		case "text":
@@ -563,6 +628,7 @@ func (c *Checker) CheckTemplate(j *lint.Job) {
 	for _, f := range j.Program.Files {
 		ast.Inspect(f, fn)
 	}
+	c.checkTemplateFields(j, lits)
 }
 
 func (c *Checker) CheckTimeSleepConstant(j *lint.Job) {
@@ -1257,72 +1323,84 @@ func (c *Checker) CheckBenchmarkN(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckUnreadVariableValues(j *lint.Job) {
-	for _, ssafn := range j.Program.InitialFunctions {
-		if IsExample(ssafn) {
-			continue
-		}
-		node := ssafn.Syntax()
-		if node == nil {
-			continue
+// unreadAssignments calls fn for each identifier that is assigned a
+// value that is never read in the function ssafn.
+func unreadAssignments(ssafn *ssa.Function, fn func(lhs *ast.Ident)) {
+	node := ssafn.Syntax()
+	if node == nil {
+		return
+	}
+
+	ast.Inspect(node, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok {
+			return true
 		}
+		if len(assign.Lhs) > 1 && len(assign.Rhs) == 1 {
+			// Either a function call with multiple return values,
+			// or a comma-ok assignment
 
-		ast.Inspect(node, func(node ast.Node) bool {
-			assign, ok := node.(*ast.AssignStmt)
-			if !ok {
+			val, _ := ssafn.ValueForExpr(assign.Rhs[0])
+			if val == nil {
 				return true
 			}
-			if len(assign.Lhs) > 1 && len(assign.Rhs) == 1 {
-				// Either a function call with multiple return values,
-				// or a comma-ok assignment
-
-				val, _ := ssafn.ValueForExpr(assign.Rhs[0])
-				if val == nil {
-					return true
-				}
-				refs := val.Referrers()
-				if refs == nil {
-					return true
-				}
-				for _, ref := range *refs {
-					ex, ok := ref.(*ssa.Extract)
-					if !ok {
-						continue
-					}
-					exrefs := ex.Referrers()
-					if exrefs == nil {
-						continue
-					}
-					if len(FilterDebug(*exrefs)) == 0 {
-						lhs := assign.Lhs[ex.Index]
-						if ident, ok := lhs.(*ast.Ident); !ok || ok && ident.Name == "_" {
-							continue
-						}
-						j.Errorf(lhs, "this value of %s is never used", lhs)
-					}
-				}
+			refs := val.Referrers()
+			if refs == nil {
 				return true
 			}
-			for i, lhs := range assign.Lhs {
-				rhs := assign.Rhs[i]
-				if ident, ok := lhs.(*ast.Ident); !ok || ok && ident.Name == "_" {
+			for _, ref := range *refs {
+				ex, ok := ref.(*ssa.Extract)
+				if !ok {
 					continue
 				}
-				val, _ := ssafn.ValueForExpr(rhs)
-				if val == nil {
+				exrefs := ex.Referrers()
+				if exrefs == nil {
 					continue
 				}
-
-				refs := val.Referrers()
-				if refs == nil {
-					// TODO investigate why refs can be nil
-					return true
-				}
-				if len(FilterDebug(*refs)) == 0 {
-					j.Errorf(lhs, "this value of %s is never used", lhs)
+				if len(FilterDebug(*exrefs)) == 0 {
+					lhs := assign.Lhs[ex.Index]
+					if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+						fn(ident)
+					}
 				}
 			}
 			return true
+		}
+		for i, lhs := range assign.Lhs {
+			rhs := assign.Rhs[i]
+			ident, ok := lhs.(*ast.Ident)
+			if !ok || ident.Name == "_" {
+				continue
+			}
+			val, _ := ssafn.ValueForExpr(rhs)
+			if val == nil {
+				continue
+			}
+
+			refs := val.Referrers()
+			if refs == nil {
+				// TODO investigate why refs can be nil
+				return true
+			}
+			if len(FilterDebug(*refs)) == 0 {
+				fn(ident)
+			}
+		}
+		return true
+	})
+}
+
+func (c *Checker) CheckUnreadVariableValues(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		if IsExample(ssafn) {
+			continue
+		}
+		unreadAssignments(ssafn, func(lhs *ast.Ident) {
+			if _, _, ok := shadowedErr(j, ssafn.Syntax(), lhs); ok {
+				// Flagged by CheckShadowedErr
+				return
+			}
+			j.Reportf(lhs, "unused-value", "this value of {{.name}} is never used", lint.Args{"name": lhs.Name})
 		})
 	}
 }
@@ -2788,3 +2866,2180 @@ func (c *Checker) CheckMissingEnumTypesInDeclaration(j *lint.Job) {
 		ast.Inspect(f, fn)
 	}
 }
+
+// walkInstructionsAfter calls fn for every instruction that may be
+// executed after ins, in the order of a breadth-first traversal of
+// the control flow graph. Every instruction is visited at most once,
+// and ins itself is never visited. Walking stops early if fn returns
+// false.
+func walkInstructionsAfter(ins ssa.Instruction, fn func(ssa.Instruction) bool) {
+	block := ins.Block()
+	found := false
+	for _, ins2 := range block.Instrs {
+		if ins2 == ins {
+			found = true
+			continue
+		}
+		if found && !fn(ins2) {
+			return
+		}
+	}
+
+	seen := map[*ssa.BasicBlock]bool{}
+	queue := append([]*ssa.BasicBlock(nil), block.Succs...)
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		for _, ins2 := range b.Instrs {
+			if ins2 == ins {
+				continue
+			}
+			if !fn(ins2) {
+				return
+			}
+		}
+		queue = append(queue, b.Succs...)
+	}
+}
+
+func (c *Checker) CheckResponseWriterMisuse(j *lint.Job) {
+	// describe returns a short description of how ins writes to the
+	// response, or the empty string if it doesn't write to w.
+	describe := func(ins ssa.Instruction, w ssa.Value) string {
+		call, ok := ins.(ssa.CallInstruction)
+		if !ok {
+			return ""
+		}
+		if _, ok := ins.(*ssa.Call); !ok {
+			// go and defer statements don't run in order
+			return ""
+		}
+		common := call.Common()
+		if common.IsInvoke() {
+			if common.Value != w {
+				return ""
+			}
+			switch common.Method.Name() {
+			case "WriteHeader", "Write":
+				return common.Method.Name()
+			}
+			return ""
+		}
+		if IsCallTo(common, "net/http.Error") && common.Args[0] == w {
+			return "http.Error"
+		}
+		return ""
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		var writers []ssa.Value
+		for _, param := range ssafn.Params {
+			if IsType(param.Type(), "net/http.ResponseWriter") {
+				writers = append(writers, param)
+			}
+		}
+		for _, fv := range ssafn.FreeVars {
+			if IsType(fv.Type(), "net/http.ResponseWriter") {
+				writers = append(writers, fv)
+			}
+		}
+		for _, w := range writers {
+			for _, block := range ssafn.Blocks {
+				for _, ins := range block.Instrs {
+					first := describe(ins, w)
+					if first == "" {
+						continue
+					}
+					walkInstructionsAfter(ins, func(ins2 ssa.Instruction) bool {
+						second := describe(ins2, w)
+						switch {
+						case second == "WriteHeader":
+							j.Errorf(ins2, "superfluous call to WriteHeader, the response header may have already been written by a call to %s", first)
+							return false
+						case second != "" && first == "http.Error":
+							j.Errorf(ins2, "%s may write to the response after http.Error has already written an error response; missing return?", second)
+							return false
+						}
+						return true
+					})
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckRequestContextInGoroutine(j *lint.Job) {
+	// deref looks through the loads of variables captured by
+	// closures.
+	deref := func(v ssa.Value) ssa.Value {
+		if load, ok := v.(*ssa.UnOp); ok && load.Op == token.MUL {
+			return load.X
+		}
+		return v
+	}
+	// storedValues returns the values stored in v if it is a
+	// variable captured by a closure, or v itself otherwise.
+	storedValues := func(v ssa.Value) []ssa.Value {
+		alloc, ok := v.(*ssa.Alloc)
+		if !ok {
+			return []ssa.Value{v}
+		}
+		var out []ssa.Value
+		for _, ref := range *alloc.Referrers() {
+			if store, ok := ref.(*ssa.Store); ok && store.Addr == alloc {
+				out = append(out, store.Val)
+			}
+		}
+		return out
+	}
+	isContextCall := func(v ssa.Value, req ssa.Value) bool {
+		call, ok := v.(*ssa.Call)
+		if !ok {
+			return false
+		}
+		return IsCallTo(call.Common(), "(*net/http.Request).Context") &&
+			deref(call.Common().Args[0]) == req
+	}
+	// usesContext reports whether the function calls the Context
+	// method on req.
+	usesContext := func(fn *ssa.Function, req ssa.Value) bool {
+		for _, block := range fn.Blocks {
+			for _, ins := range block.Instrs {
+				if v, ok := ins.(ssa.Value); ok && isContextCall(v, req) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// waits reports whether the handler may wait for the goroutine
+	// to finish after starting it, in which case the context is
+	// still valid.
+	waits := func(ins ssa.Instruction) bool {
+		ret := false
+		walkInstructionsAfter(ins, func(ins2 ssa.Instruction) bool {
+			switch ins2 := ins2.(type) {
+			case *ssa.UnOp:
+				if ins2.Op == token.ARROW {
+					ret = true
+				}
+			case *ssa.Select:
+				ret = true
+			case *ssa.Call:
+				if IsCallTo(ins2.Common(), "(*sync.WaitGroup).Wait") {
+					ret = true
+				}
+			}
+			return !ret
+		})
+		return ret
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		params := ssafn.Params
+		if ssafn.Signature.Recv() != nil {
+			params = params[1:]
+		}
+		if len(params) != 2 ||
+			!IsType(params[0].Type(), "net/http.ResponseWriter") ||
+			!IsType(params[1].Type(), "*net/http.Request") {
+			continue
+		}
+		req := params[1]
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				g, ok := ins.(*ssa.Go)
+				if !ok {
+					continue
+				}
+				captures := false
+				for _, arg := range g.Call.Args {
+					if isContextCall(arg, req) {
+						captures = true
+					}
+				}
+				if mc, ok := g.Call.Value.(*ssa.MakeClosure); ok {
+					fn := mc.Fn.(*ssa.Function)
+					for i, binding := range mc.Bindings {
+						for _, v := range storedValues(binding) {
+							if isContextCall(v, req) ||
+								(v == req && usesContext(fn, fn.FreeVars[i])) {
+								captures = true
+							}
+						}
+					}
+				}
+				if !captures || waits(g) {
+					continue
+				}
+				j.Errorf(g, "the request's context is canceled when the handler returns, but it is used by a goroutine that may outlive the handler")
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckPlatformSpecificSymbols(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		name := j.Program.DisplayPosition(f.Pos()).Filename
+		fc := newFileConstraint(name, f)
+		if !hasExplicitConstraints(fc) {
+			// Files without build constraints are usually part of
+			// packages that are only meant for a subset of
+			// platforms. Only check files whose author explicitly
+			// specified the platforms to build for.
+			continue
+		}
+		gooses := matchingGOOS(j.Program.Build, fc)
+		if len(gooses) < 2 {
+			continue
+		}
+		srcDir := filepath.Dir(name)
+		fn := func(node ast.Node) bool {
+			sel, ok := node.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			ident, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			pkg, ok := ObjectOf(j, ident).(*types.PkgName)
+			if !ok || !isPlatformSpecificPackage(pkg.Imported().Path()) {
+				return true
+			}
+			var missing []string
+			for _, goos := range gooses {
+				syms := c.platforms.get(j.Program.Build, pkg.Imported().Path(), srcDir, goos)
+				if !syms[sel.Sel.Name] {
+					missing = append(missing, goos)
+				}
+			}
+			if len(missing) > 0 {
+				j.Errorf(sel, "%s is not available on %s, but the file's build constraints allow building it for %s",
+					Render(j, sel), strings.Join(missing, ", "), pluralize(len(missing), "this platform", "these platforms"))
+			}
+			return true
+		}
+		ast.Inspect(f, fn)
+	}
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+func (c *Checker) CheckOpenFileFlags(j *lint.Job) {
+	// flagNames returns the names of the flags that are ORed
+	// together in expr, or false if expr isn't made up exclusively of
+	// named flags.
+	var flagNames func(expr ast.Expr, out map[string]bool) bool
+	flagNames = func(expr ast.Expr, out map[string]bool) bool {
+		switch expr := expr.(type) {
+		case *ast.ParenExpr:
+			return flagNames(expr.X, out)
+		case *ast.BinaryExpr:
+			if expr.Op != token.OR {
+				return false
+			}
+			return flagNames(expr.X, out) && flagNames(expr.Y, out)
+		case *ast.Ident, *ast.SelectorExpr:
+			var obj types.Object
+			if sel, ok := expr.(*ast.SelectorExpr); ok {
+				obj = ObjectOf(j, sel.Sel)
+			} else {
+				obj = ObjectOf(j, expr.(*ast.Ident))
+			}
+			if obj == nil || obj.Pkg() == nil {
+				return false
+			}
+			switch obj.Pkg().Path() {
+			case "os", "syscall":
+			default:
+				return false
+			}
+			name := obj.Name()
+			if !strings.HasPrefix(name, "O_") {
+				return false
+			}
+			if name == "O_CREAT" {
+				name = "O_CREATE"
+			}
+			out[name] = true
+			return true
+		default:
+			return false
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !IsCallToAST(j, call, "os.OpenFile") {
+			return true
+		}
+		flags := map[string]bool{}
+		if !flagNames(call.Args[1], flags) {
+			return true
+		}
+		writes := flags["O_WRONLY"] || flags["O_RDWR"]
+		switch {
+		case flags["O_WRONLY"] && flags["O_RDWR"]:
+			j.Errorf(call.Args[1], "O_WRONLY and O_RDWR are mutually exclusive")
+		case flags["O_EXCL"] && !flags["O_CREATE"]:
+			j.Errorf(call.Args[1], "O_EXCL has no defined meaning without O_CREATE")
+		case !writes && flags["O_TRUNC"]:
+			j.Errorf(call.Args[1], "the file is opened read-only, but O_TRUNC requires write access")
+		case !writes && flags["O_APPEND"]:
+			j.Errorf(call.Args[1], "the file is opened read-only, appending to it with O_APPEND requires O_WRONLY or O_RDWR")
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// floatField returns the path to the first floating-point field in
+// T. Fields behind pointers aren't considered, as they are compared
+// by address.
+func floatField(T types.Type) (string, bool) {
+	switch T := T.Underlying().(type) {
+	case *types.Basic:
+		return "", T.Info()&(types.IsFloat|types.IsComplex) != 0
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			field := T.Field(i)
+			if path, ok := floatField(field.Type()); ok {
+				if path == "" || path[0] == '[' {
+					return field.Name() + path, true
+				}
+				return field.Name() + "." + path, true
+			}
+		}
+	case *types.Array:
+		if path, ok := floatField(T.Elem()); ok {
+			if path == "" || path[0] == '[' {
+				return "[i]" + path, true
+			}
+			return "[i]." + path, true
+		}
+	}
+	return "", false
+}
+
+func (c *Checker) CheckFloatStructComparison(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		expr, ok := node.(*ast.BinaryExpr)
+		if !ok || (expr.Op != token.EQL && expr.Op != token.NEQ) {
+			return true
+		}
+		T := TypeOf(j, expr.X)
+		if _, ok := T.Underlying().(*types.Struct); !ok {
+			return true
+		}
+		path, ok := floatField(T)
+		if !ok {
+			return true
+		}
+		j.Errorf(expr, "comparing values of type %s with %s also compares the floating-point field %s, and NaN is never equal to itself; consider comparing the fields explicitly",
+			types.TypeString(T, types.RelativeTo(j.NodePackage(expr).Pkg)), expr.Op, path)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// uncomparableDynamicType returns the type of v's dynamic value, if v
+// is an interface value that was created from a type that doesn't
+// support ==.
+func uncomparableDynamicType(v ssa.Value) (types.Type, bool) {
+	mi, ok := v.(*ssa.MakeInterface)
+	if !ok {
+		return nil, false
+	}
+	T := mi.X.Type()
+	return T, !types.Comparable(T)
+}
+
+func (c *Checker) CheckUncomparableInterfaceKeys(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				var key ssa.Value
+				switch ins := ins.(type) {
+				case *ssa.MapUpdate:
+					key = ins.Key
+				case *ssa.Lookup:
+					if _, ok := ins.X.Type().Underlying().(*types.Map); !ok {
+						continue
+					}
+					key = ins.Index
+				case *ssa.BinOp:
+					if ins.Op != token.EQL && ins.Op != token.NEQ {
+						continue
+					}
+					tx, okx := uncomparableDynamicType(ins.X)
+					ty, oky := uncomparableDynamicType(ins.Y)
+					// Comparing interface values only panics if both
+					// dynamic types are identical.
+					if okx && oky && types.Identical(tx, ty) {
+						j.Errorf(ins, "comparing interface values holding values of type %s panics at runtime, because the type isn't comparable",
+							types.TypeString(tx, types.RelativeTo(ssafn.Pkg.Pkg)))
+					}
+					continue
+				default:
+					continue
+				}
+				if T, ok := uncomparableDynamicType(key); ok {
+					j.Errorf(ins, "using a value of type %s as a map key panics at runtime, because the type isn't comparable",
+						types.TypeString(T, types.RelativeTo(ssafn.Pkg.Pkg)))
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckLostCancel(j *lint.Job) {
+	// cancelValue returns the cancel function returned by call, or
+	// nil if it isn't used at all.
+	cancelValue := func(call *ssa.Call) ssa.Value {
+		for _, ref := range *call.Referrers() {
+			if ex, ok := ref.(*ssa.Extract); ok && ex.Index == 1 {
+				return ex
+			}
+		}
+		return nil
+	}
+	// cancelUses returns the instructions that call the cancel
+	// function v, directly or via defer or go. It returns false if v
+	// is used in any other way, such as by being stored, passed to a
+	// function or returned, in which case it may be called elsewhere.
+	var cancelUses func(v ssa.Value, seen map[ssa.Value]bool, out []ssa.Instruction) ([]ssa.Instruction, bool)
+	cancelUses = func(v ssa.Value, seen map[ssa.Value]bool, out []ssa.Instruction) ([]ssa.Instruction, bool) {
+		if seen[v] {
+			return out, true
+		}
+		seen[v] = true
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef, *ssa.BlankStore:
+			case *ssa.Phi:
+				var ok bool
+				out, ok = cancelUses(ref, seen, out)
+				if !ok {
+					return nil, false
+				}
+			case ssa.CallInstruction:
+				common := ref.Common()
+				if common.IsInvoke() || common.Value != v {
+					return nil, false
+				}
+				for _, arg := range common.Args {
+					if arg == v {
+						return nil, false
+					}
+				}
+				out = append(out, ref)
+			default:
+				return nil, false
+			}
+		}
+		return out, true
+	}
+	// unguardedReturn returns a return instruction that may be
+	// reached from ins without passing through any of uses, or nil if
+	// there is none. A deferred call counts as a call on all paths
+	// that pass through the defer statement.
+	unguardedReturn := func(ins ssa.Instruction, uses []ssa.Instruction) *ssa.Return {
+		used := map[*ssa.BasicBlock]bool{}
+		for _, use := range uses {
+			used[use.Block()] = true
+		}
+		block := ins.Block()
+		if used[block] {
+			after := false
+			for _, ins2 := range block.Instrs {
+				if ins2 == ins {
+					after = true
+					continue
+				}
+				if !after {
+					continue
+				}
+				for _, use := range uses {
+					if use == ins2 {
+						return nil
+					}
+				}
+			}
+		}
+		if ret, ok := block.Instrs[len(block.Instrs)-1].(*ssa.Return); ok {
+			return ret
+		}
+
+		seen := map[*ssa.BasicBlock]bool{block: true}
+		queue := append([]*ssa.BasicBlock(nil), block.Succs...)
+		for len(queue) > 0 {
+			b := queue[0]
+			queue = queue[1:]
+			if seen[b] || used[b] {
+				continue
+			}
+			seen[b] = true
+			if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+				return ret
+			}
+			queue = append(queue, b.Succs...)
+		}
+		return nil
+	}
+	// returnPos returns the position of ret, or of the end of the
+	// function for implicit returns.
+	returnPos := func(fn *ssa.Function, ret *ssa.Return) token.Pos {
+		if ret.Pos().IsValid() {
+			return ret.Pos()
+		}
+		switch syntax := fn.Syntax().(type) {
+		case *ast.FuncDecl:
+			return syntax.Body.Rbrace
+		case *ast.FuncLit:
+			return syntax.Body.Rbrace
+		}
+		return token.NoPos
+	}
+
+	// discarded records the positions of calls whose second result
+	// is explicitly discarded. SSA doesn't distinguish these from
+	// results assigned to variables that are never used.
+	discarded := map[token.Pos]bool{}
+	fn := func(node ast.Node) bool {
+		var lhs []ast.Expr
+		var rhs []ast.Expr
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			lhs, rhs = node.Lhs, node.Rhs
+		case *ast.ValueSpec:
+			for _, name := range node.Names {
+				lhs = append(lhs, name)
+			}
+			rhs = node.Values
+		case *ast.ExprStmt:
+			if call, ok := node.X.(*ast.CallExpr); ok {
+				discarded[call.Lparen] = true
+			}
+			return true
+		default:
+			return true
+		}
+		if len(lhs) != 2 || len(rhs) != 1 {
+			return true
+		}
+		call, ok := rhs[0].(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := lhs[1].(*ast.Ident); ok && ident.Name == "_" {
+			discarded[call.Lparen] = true
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				name := CallName(call.Common())
+				switch name {
+				case "context.WithCancel", "context.WithTimeout", "context.WithDeadline":
+				default:
+					continue
+				}
+				cancel := cancelValue(call)
+				if cancel == nil || discarded[call.Pos()] {
+					j.Errorf(call, "the cancel function returned by %s is discarded; the context and its resources won't be released until the parent context is canceled", name)
+					continue
+				}
+				uses, ok := cancelUses(cancel, map[ssa.Value]bool{}, nil)
+				if !ok {
+					continue
+				}
+				if len(uses) == 0 {
+					j.Errorf(call, "the cancel function returned by %s is never called; the context and its resources won't be released until the parent context is canceled", name)
+					continue
+				}
+				ret := unguardedReturn(call, uses)
+				if ret == nil {
+					continue
+				}
+				p := j.Errorf(call, "the cancel function returned by %s is not called on all paths", name)
+				if pos := returnPos(ssafn, ret); pos.IsValid() {
+					j.AddRelated(p, posNode(pos), "this return statement may be reached without calling cancel")
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckDeepEqualMisuse(j *lint.Job) {
+	errIface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	// method returns the method called name in the method set of T.
+	method := func(T types.Type, name string) (*types.Signature, bool) {
+		sel := types.NewMethodSet(T).Lookup(nil, name)
+		if sel == nil {
+			return nil, false
+		}
+		return sel.Type().(*types.Signature), true
+	}
+	isProto := func(T types.Type) bool {
+		sig, ok := method(T, "ProtoMessage")
+		return ok && sig.Params().Len() == 0 && sig.Results().Len() == 0
+	}
+	// hasEqual reports whether T has a method Equal(T) bool.
+	hasEqual := func(T types.Type) bool {
+		sig, ok := method(T, "Equal")
+		if !ok || sig.Params().Len() != 1 || sig.Results().Len() != 1 {
+			return false
+		}
+		return types.Identical(sig.Params().At(0).Type(), T) &&
+			types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool])
+	}
+
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !IsCallToAST(j, call, "reflect.DeepEqual") || len(call.Args) != 2 {
+			return true
+		}
+		var T types.Type
+		for _, arg := range call.Args {
+			if !IsNil(j, arg) {
+				T = TypeOf(j, arg)
+				break
+			}
+		}
+		if T == nil {
+			return true
+		}
+		qf := types.RelativeTo(j.NodePackage(call).Pkg)
+		switch {
+		case isProto(T):
+			j.Errorf(call, "reflect.DeepEqual compares the internal state of protocol buffer messages; use proto.Equal to compare values of type %s",
+				types.TypeString(T, qf))
+		case hasEqual(T):
+			j.Errorf(call, "type %s has an Equal method, which should be used instead of reflect.DeepEqual",
+				types.TypeString(T, qf))
+		case types.Implements(T, errIface):
+			if IsGoVersion(j, 13) {
+				j.Errorf(call, "reflect.DeepEqual compares the internal structure of errors; use errors.Is instead")
+			} else {
+				j.Errorf(call, "reflect.DeepEqual compares the internal structure of errors; compare them with == instead")
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckLostMapEntryUpdate(j *lint.Job) {
+	// fromMap reports whether v is a value read from a map, by
+	// indexing it or ranging over it.
+	fromMap := func(v ssa.Value) bool {
+		if ex, ok := v.(*ssa.Extract); ok {
+			switch tuple := ex.Tuple.(type) {
+			case *ssa.Lookup:
+				v = tuple
+			case *ssa.Next:
+				return !tuple.IsString && ex.Index == 2
+			}
+		}
+		lookup, ok := v.(*ssa.Lookup)
+		if !ok {
+			return false
+		}
+		_, ok = lookup.X.Type().Underlying().(*types.Map)
+		return ok
+	}
+	// fieldAddrs collects the addresses of fields of the struct at
+	// addr, including those of nested structs.
+	var fieldAddrs func(addr ssa.Value, out map[ssa.Value]bool)
+	fieldAddrs = func(addr ssa.Value, out map[ssa.Value]bool) {
+		for _, ref := range *addr.Referrers() {
+			if field, ok := ref.(*ssa.FieldAddr); ok {
+				out[field] = true
+				fieldAddrs(field, out)
+			}
+		}
+	}
+	// feedsFields reports whether v is only used to compute values
+	// stored in fields, as in v.A++.
+	var feedsFields func(v ssa.Value, fields map[ssa.Value]bool) bool
+	feedsFields = func(v ssa.Value, fields map[ssa.Value]bool) bool {
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef:
+			case *ssa.Store:
+				if !fields[ref.Addr] || ref.Val != v {
+					return false
+				}
+			case *ssa.BinOp:
+				if !feedsFields(ref, fields) {
+					return false
+				}
+			case *ssa.Convert:
+				if !feedsFields(ref, fields) {
+					return false
+				}
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	// lostUpdate returns a store to a field of the local variable
+	// alloc, if the variable holds a map entry and is only ever
+	// written to.
+	lostUpdate := func(alloc *ssa.Alloc) *ssa.Store {
+		fields := map[ssa.Value]bool{}
+		fieldAddrs(alloc, fields)
+		var loaded bool
+		for _, ref := range *alloc.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef, *ssa.FieldAddr:
+			case *ssa.Store:
+				if ref.Addr != alloc {
+					// The address of the variable escapes.
+					return nil
+				}
+				if fromMap(ref.Val) {
+					loaded = true
+				}
+			default:
+				// The variable is read, or its address escapes.
+				return nil
+			}
+		}
+		if !loaded {
+			return nil
+		}
+		var first *ssa.Store
+		for field := range fields {
+			for _, ref := range *field.Referrers() {
+				switch ref := ref.(type) {
+				case *ssa.DebugRef, *ssa.FieldAddr:
+				case *ssa.Store:
+					if ref.Addr != field {
+						return nil
+					}
+					if first == nil || ref.Pos() < first.Pos() {
+						first = ref
+					}
+				case *ssa.UnOp:
+					if ref.Op != token.MUL || !feedsFields(ref, fields) {
+						return nil
+					}
+				default:
+					return nil
+				}
+			}
+		}
+		return first
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				alloc, ok := ins.(*ssa.Alloc)
+				if !ok || alloc.Heap {
+					continue
+				}
+				if _, ok := alloc.Type().(*types.Pointer).Elem().Underlying().(*types.Struct); !ok {
+					continue
+				}
+				if store := lostUpdate(alloc); store != nil {
+					j.Errorf(store, "this modifies a copy of a map entry, which is never written back to the map or used otherwise")
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckPathOnOSPaths(j *lint.Job) {
+	// sources are functions whose first result is a path in the
+	// format of the operating system.
+	sources := map[string]bool{
+		"os.Getwd":          true,
+		"os.UserHomeDir":    true,
+		"os.UserCacheDir":   true,
+		"os.UserConfigDir":  true,
+		"os.Executable":     true,
+		"os.TempDir":        true,
+		"io/ioutil.TempDir": true,
+
+		"path/filepath.Abs":          true,
+		"path/filepath.Clean":        true,
+		"path/filepath.Dir":          true,
+		"path/filepath.EvalSymlinks": true,
+		"path/filepath.FromSlash":    true,
+		"path/filepath.Join":         true,
+		"path/filepath.Rel":          true,
+	}
+	sinks := map[string]bool{
+		"path.Base":  true,
+		"path.Clean": true,
+		"path.Dir":   true,
+		"path.Ext":   true,
+		"path.IsAbs": true,
+		"path.Join":  true,
+		"path.Split": true,
+	}
+	isPathFlag := func(v ssa.Value) (string, bool) {
+		k, ok := v.(*ssa.Const)
+		if !ok || k.Value == nil || k.Value.Kind() != constant.String {
+			return "", false
+		}
+		name := constant.StringVal(k.Value)
+		lower := strings.ToLower(name)
+		return name, strings.HasSuffix(lower, "path") || strings.HasSuffix(lower, "dir")
+	}
+	flagArgs := func(call *ssa.CallCommon) []ssa.Value {
+		if call.Signature().Recv() != nil {
+			// Skip the receiver of FlagSet methods.
+			return call.Args[1:]
+		}
+		return call.Args
+	}
+
+	// flagPtrs are pointers to string flags whose names suggest that
+	// they hold paths. flagGlobals are global variables holding such
+	// pointers.
+	flagPtrs := map[ssa.Value]string{}
+	flagGlobals := map[*ssa.Global]string{}
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				switch CallName(call.Common()) {
+				case "flag.String", "(*flag.FlagSet).String":
+					name, ok := isPathFlag(flagArgs(call.Common())[0])
+					if !ok {
+						continue
+					}
+					src := fmt.Sprintf("the flag %q", name)
+					flagPtrs[call] = src
+					for _, ref := range *call.Referrers() {
+						if store, ok := ref.(*ssa.Store); ok {
+							if g, ok := store.Addr.(*ssa.Global); ok {
+								flagGlobals[g] = src
+							}
+						}
+					}
+				case "flag.StringVar", "(*flag.FlagSet).StringVar":
+					args := flagArgs(call.Common())
+					name, ok := isPathFlag(args[1])
+					if !ok {
+						continue
+					}
+					flagPtrs[args[0]] = fmt.Sprintf("the flag %q", name)
+				}
+			}
+		}
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		// tainted maps values that hold OS paths to a description of
+		// their source.
+		tainted := map[ssa.Value]string{}
+		taint := func(v ssa.Value, src string) bool {
+			if _, ok := tainted[v]; ok || src == "" {
+				return false
+			}
+			tainted[v] = src
+			return true
+		}
+		// variadicTaint returns the source of the first tainted value
+		// stored in the slice holding the variadic arguments of a
+		// call.
+		variadicTaint := func(arg ssa.Value) (string, bool) {
+			sl, ok := arg.(*ssa.Slice)
+			if !ok {
+				return "", false
+			}
+			alloc, ok := sl.X.(*ssa.Alloc)
+			if !ok {
+				return "", false
+			}
+			for _, ref := range *alloc.Referrers() {
+				addr, ok := ref.(*ssa.IndexAddr)
+				if !ok {
+					continue
+				}
+				for _, ref := range *addr.Referrers() {
+					if store, ok := ref.(*ssa.Store); ok {
+						if src, ok := tainted[store.Val]; ok {
+							return src, true
+						}
+					}
+				}
+			}
+			return "", false
+		}
+		for changed := true; changed; {
+			changed = false
+			for _, block := range ssafn.Blocks {
+				for _, ins := range block.Instrs {
+					switch ins := ins.(type) {
+					case *ssa.Call:
+						name := CallName(ins.Common())
+						if _, ok := ins.Type().(*types.Tuple); !ok && sources[name] {
+							changed = taint(ins, name) || changed
+						}
+					case *ssa.Extract:
+						if ins.Index != 0 {
+							continue
+						}
+						if call, ok := ins.Tuple.(*ssa.Call); ok {
+							if name := CallName(call.Common()); sources[name] {
+								changed = taint(ins, name) || changed
+							}
+						}
+					case *ssa.UnOp:
+						if ins.Op != token.MUL {
+							continue
+						}
+						if src, ok := flagPtrs[ins.X]; ok {
+							changed = taint(ins, src) || changed
+						}
+						if load, ok := ins.X.(*ssa.UnOp); ok && load.Op == token.MUL {
+							if g, ok := load.X.(*ssa.Global); ok {
+								changed = taint(ins, flagGlobals[g]) || changed
+							}
+						}
+					case *ssa.BinOp:
+						if ins.Op != token.ADD {
+							continue
+						}
+						if src, ok := tainted[ins.X]; ok {
+							changed = taint(ins, src) || changed
+						} else if src, ok := tainted[ins.Y]; ok {
+							changed = taint(ins, src) || changed
+						}
+					case *ssa.Phi:
+						for _, edge := range ins.Edges {
+							if src, ok := tainted[edge]; ok {
+								changed = taint(ins, src) || changed
+								break
+							}
+						}
+					case *ssa.ChangeType:
+						changed = taint(ins, tainted[ins.X]) || changed
+					}
+				}
+			}
+		}
+
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				name := CallName(call.Common())
+				if !sinks[name] {
+					continue
+				}
+				for _, arg := range call.Common().Args {
+					src, ok := tainted[arg]
+					if !ok {
+						src, ok = variadicTaint(arg)
+					}
+					if ok {
+						fn := strings.TrimPrefix(name, "path.")
+						j.Errorf(call, "%s operates on slash-separated paths, but this argument comes from %s, which is an operating system path; use filepath.%s instead", name, src, fn)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckTimeEquality(j *lint.Job) {
+	// operand renders expr for use as the receiver of a method call.
+	operand := func(expr ast.Expr) string {
+		switch expr.(type) {
+		case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.CompositeLit, *ast.ParenExpr:
+			return Render(j, expr)
+		default:
+			return "(" + Render(j, expr) + ")"
+		}
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BinaryExpr:
+			if node.Op != token.EQL && node.Op != token.NEQ {
+				return true
+			}
+			if !IsOfType(j, node.X, "time.Time") || !IsOfType(j, node.Y, "time.Time") {
+				return true
+			}
+			fix := operand(node.X) + ".Equal(" + Render(j, node.Y) + ")"
+			if node.Op == token.NEQ {
+				fix = "!" + fix
+			}
+			p := j.Errorf(node, "comparing time.Time values with %s also compares their locations and monotonic clock readings; use the Equal method instead", node.Op)
+			j.AddFix(p, "use Equal", j.Replace(node, fix))
+		case *ast.MapType:
+			if IsOfType(j, node.Key, "time.Time") {
+				j.Errorf(node.Key, "using time.Time as a map key compares locations and monotonic clock readings; consider using the result of the UnixNano method instead")
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// nameQualifier qualifies types from packages other than pkg with
+// the name of their package.
+func nameQualifier(pkg *types.Package) types.Qualifier {
+	return func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+}
+
+type constsByPos []*types.Const
+
+func (s constsByPos) Len() int           { return len(s) }
+func (s constsByPos) Less(i, j int) bool { return s[i].Pos() < s[j].Pos() }
+func (s constsByPos) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// enumMembers returns the constants of type T declared in the same
+// package as T, in the order of their declaration. Unexported
+// constants are only returned if from is T's package.
+func enumMembers(T *types.Named, from *types.Package) []*types.Const {
+	pkg := T.Obj().Pkg()
+	if pkg == nil {
+		return nil
+	}
+	var out []*types.Const
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		k, ok := scope.Lookup(name).(*types.Const)
+		if !ok || name == "_" || !types.Identical(k.Type(), T) {
+			continue
+		}
+		if pkg != from && !k.Exported() {
+			continue
+		}
+		out = append(out, k)
+	}
+	sort.Sort(constsByPos(out))
+	return out
+}
+
+// hasTypeDirective reports whether the declaration of the type obj is
+// documented with the comment //lint:<directive>.
+func hasTypeDirective(j *lint.Job, obj *types.TypeName, directive string) bool {
+	info := j.Program.Prog.AllPackages[obj.Pkg()]
+	if info == nil {
+		return false
+	}
+	hasDirective := func(doc *ast.CommentGroup) bool {
+		if doc == nil {
+			return false
+		}
+		for _, c := range doc.List {
+			if strings.TrimSpace(c.Text) == "//lint:"+directive {
+				return true
+			}
+		}
+		return false
+	}
+	for _, f := range info.Files {
+		if f.Pos() > obj.Pos() || obj.Pos() > f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if spec.Name.Pos() != obj.Pos() {
+					continue
+				}
+				return hasDirective(spec.Doc) || (!gen.Lparen.IsValid() && hasDirective(gen.Doc))
+			}
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckExhaustiveSwitch(j *lint.Job) {
+	annotated := map[*types.TypeName]bool{}
+	fn := func(node ast.Node) bool {
+		sw, ok := node.(*ast.SwitchStmt)
+		if !ok || sw.Tag == nil {
+			return true
+		}
+		T, ok := TypeOf(j, sw.Tag).(*types.Named)
+		if !ok {
+			return true
+		}
+		basic, ok := T.Underlying().(*types.Basic)
+		if !ok || basic.Info()&(types.IsInteger|types.IsString) == 0 {
+			return true
+		}
+		pkg := j.NodePackage(sw)
+		members := enumMembers(T, pkg.Pkg)
+		if len(members) == 0 {
+			return true
+		}
+		if pkg.Config.Exhaustive == "annotated" {
+			v, ok := annotated[T.Obj()]
+			if !ok {
+				v = hasTypeDirective(j, T.Obj(), "enum")
+				annotated[T.Obj()] = v
+			}
+			if !v {
+				return true
+			}
+		}
+
+		covered := map[string]bool{}
+		for _, stmt := range sw.Body.List {
+			clause := stmt.(*ast.CaseClause)
+			if clause.List == nil {
+				// Switches with a default case are exhaustive.
+				return true
+			}
+			for _, expr := range clause.List {
+				tv := j.Program.Info.Types[expr]
+				if tv.Value == nil {
+					// We can't reason about non-constant cases.
+					return true
+				}
+				covered[tv.Value.ExactString()] = true
+			}
+		}
+		var missing []string
+		for _, k := range members {
+			key := k.Val().ExactString()
+			if covered[key] {
+				continue
+			}
+			// Only report one of several constants with the same
+			// value.
+			covered[key] = true
+			missing = append(missing, k.Name())
+		}
+		if len(missing) == 0 {
+			return true
+		}
+		list := strings.Join(missing, ", ")
+		if len(missing) > 5 {
+			list = fmt.Sprintf("%s and %d more", strings.Join(missing[:5], ", "), len(missing)-5)
+		}
+		j.Errorf(sw, "switch on %s is missing %s %s and has no default case",
+			types.TypeString(T, nameQualifier(pkg.Pkg)), pluralize(len(missing), "case", "cases"), list)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckExhaustiveFields(j *lint.Job) {
+	marked := map[*types.TypeName]bool{}
+	fn := func(node ast.Node) bool {
+		lit, ok := node.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) == 0 {
+			// Empty literals explicitly ask for the zero value.
+			return true
+		}
+		T, ok := TypeOf(j, lit).(*types.Named)
+		if !ok || T.Obj().Pkg() == nil {
+			return true
+		}
+		s, ok := T.Underlying().(*types.Struct)
+		if !ok {
+			return true
+		}
+		if _, ok := lit.Elts[0].(*ast.KeyValueExpr); !ok {
+			// Unkeyed literals have to set all fields.
+			return true
+		}
+		pkg := j.NodePackage(lit)
+		v, ok := marked[T.Obj()]
+		if !ok {
+			v = hasTypeDirective(j, T.Obj(), "exhaustive-fields")
+			name := T.Obj().Pkg().Path() + "." + T.Obj().Name()
+			for _, typ := range pkg.Config.ExhaustiveFields {
+				if typ == name {
+					v = true
+				}
+			}
+			marked[T.Obj()] = v
+		}
+		if !v {
+			return true
+		}
+
+		set := map[string]bool{}
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if ident, ok := kv.Key.(*ast.Ident); ok {
+					set[ident.Name] = true
+				}
+			}
+		}
+		var missing []string
+		for i := 0; i < s.NumFields(); i++ {
+			field := s.Field(i)
+			if field.Name() == "_" || set[field.Name()] {
+				continue
+			}
+			if T.Obj().Pkg() != pkg.Pkg && !field.Exported() {
+				continue
+			}
+			missing = append(missing, field.Name())
+		}
+		if len(missing) > 0 {
+			j.Errorf(lit, "composite literal of %s doesn't set %s %s",
+				types.TypeString(T, nameQualifier(pkg.Pkg)), pluralize(len(missing), "field", "fields"), strings.Join(missing, ", "))
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckCompareFreshError(j *lint.Job) {
+	// fresh returns the name of the function that created the error
+	// v, if v is a new error that is only used in comparisons. As
+	// long as the error doesn't escape, no other value can be equal
+	// to it.
+	fresh := func(v ssa.Value) string {
+		call, ok := v.(*ssa.Call)
+		if !ok {
+			return ""
+		}
+		name := CallName(call.Common())
+		if name != "errors.New" && name != "fmt.Errorf" {
+			return ""
+		}
+		for _, ref := range *call.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.DebugRef:
+			case *ssa.BinOp:
+				if ref.Op != token.EQL && ref.Op != token.NEQ {
+					return ""
+				}
+			default:
+				// The error escapes, for example by being
+				// returned, and may be compared against itself.
+				return ""
+			}
+		}
+		return name
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				binop, ok := ins.(*ssa.BinOp)
+				if !ok || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+					continue
+				}
+				name := fresh(binop.X)
+				if name == "" {
+					name = fresh(binop.Y)
+				}
+				if name == "" {
+					continue
+				}
+				result := "false"
+				if binop.Op == token.NEQ {
+					result = "true"
+				}
+				j.Errorf(binop, "comparison with an error created by %s is always %s, because each call creates a new error; compare with an error declared at package level", name, result)
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckNilChannelOperation(j *lint.Job) {
+	c.checkNilChannelOperation(j, false)
+}
+
+func (c *Checker) CheckMaybeNilChannelOperation(j *lint.Job) {
+	c.checkNilChannelOperation(j, true)
+}
+
+// checkNilChannelOperation flags channel operations on channels that
+// are always nil, or, if maybe is set, on channels that are nil on
+// some paths leading to the operation. Whether these paths can be
+// taken at all isn't known.
+func (c *Checker) checkNilChannelOperation(j *lint.Job, maybe bool) {
+	isNil := func(v ssa.Value) bool {
+		k, ok := v.(*ssa.Const)
+		return ok && k.Value == nil
+	}
+	// nils returns the nil constants that v may be, looking through
+	// phis, and whether v is always nil.
+	var nils func(v ssa.Value, seen map[ssa.Value]bool) ([]*ssa.Const, bool)
+	nils = func(v ssa.Value, seen map[ssa.Value]bool) ([]*ssa.Const, bool) {
+		if seen[v] {
+			return nil, true
+		}
+		seen[v] = true
+		switch v := v.(type) {
+		case *ssa.Const:
+			if v.Value == nil {
+				return []*ssa.Const{v}, true
+			}
+		case *ssa.Phi:
+			var out []*ssa.Const
+			always := true
+			for _, edge := range v.Edges {
+				ks, all := nils(edge, seen)
+				out = append(out, ks...)
+				always = always && all
+			}
+			return out, always
+		}
+		return nil, false
+	}
+	// guarded reports whether block is only reached if v isn't nil,
+	// because a dominating branch compares v against nil.
+	guarded := func(v ssa.Value, block *ssa.BasicBlock) bool {
+		for dom := block.Idom(); dom != nil; dom = dom.Idom() {
+			if len(dom.Instrs) == 0 {
+				continue
+			}
+			br, ok := dom.Instrs[len(dom.Instrs)-1].(*ssa.If)
+			if !ok {
+				continue
+			}
+			cmp, ok := br.Cond.(*ssa.BinOp)
+			if !ok || (cmp.X != v && cmp.Y != v) || (!isNil(cmp.X) && !isNil(cmp.Y)) {
+				continue
+			}
+			notNil := dom.Succs[0]
+			switch cmp.Op {
+			case token.EQL:
+				notNil = dom.Succs[1]
+			case token.NEQ:
+			default:
+				continue
+			}
+			if notNil.Dominates(block) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		// assigned maps nil constants to the declarations and
+		// assignments of variables that they are the values of.
+		assigned := map[*ssa.Const]*ssa.DebugRef{}
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				ref, ok := ins.(*ssa.DebugRef)
+				if !ok {
+					continue
+				}
+				if k, ok := ref.X.(*ssa.Const); ok && k.Value == nil {
+					if _, ok := assigned[k]; !ok {
+						assigned[k] = ref
+					}
+				}
+			}
+		}
+
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				var ch ssa.Value
+				var op string
+				switch ins := ins.(type) {
+				case *ssa.Send:
+					ch, op = ins.Chan, "send to"
+				case *ssa.UnOp:
+					if ins.Op != token.ARROW {
+						continue
+					}
+					ch, op = ins.X, "receive from"
+				default:
+					continue
+				}
+				ks, always := nils(ch, map[ssa.Value]bool{})
+				if len(ks) == 0 {
+					continue
+				}
+				if always == maybe {
+					continue
+				}
+				var p *lint.Problem
+				if always {
+					p = j.Errorf(ins, "%s nil channel blocks forever", op)
+				} else {
+					if guarded(ch, block) {
+						continue
+					}
+					p = j.Errorf(ins, "%s channel that is nil on some paths, which blocks forever", op)
+				}
+				for _, k := range ks {
+					if ref, ok := assigned[k]; ok {
+						j.AddRelated(p, ref, "channel is nil here")
+					}
+				}
+			}
+		}
+	}
+}
+
+// importName returns the name under which f imports path, or the
+// empty string.
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != path {
+			continue
+		}
+		if imp.Name == nil {
+			return path
+		}
+		if imp.Name.Name == "_" || imp.Name.Name == "." {
+			return ""
+		}
+		return imp.Name.Name
+	}
+	return ""
+}
+
+func (c *Checker) CheckStringIntConversion(j *lint.Job) {
+	// decimal returns an expression formatting x, of type T, as a
+	// decimal number, using packages that f already imports.
+	decimal := func(f *ast.File, x string, T types.Type) string {
+		if name := importName(f, "strconv"); name != "" {
+			// convert converts x to the basic type kind, unless it
+			// already is of that type.
+			convert := func(kind types.BasicKind) string {
+				if types.Identical(T, types.Typ[kind]) || T == types.Typ[types.UntypedInt] {
+					return x
+				}
+				return types.Typ[kind].Name() + "(" + x + ")"
+			}
+			switch {
+			case T.Underlying().(*types.Basic).Kind() == types.Int || T == types.Typ[types.UntypedInt]:
+				return name + ".Itoa(" + convert(types.Int) + ")"
+			case T.Underlying().(*types.Basic).Info()&types.IsUnsigned != 0:
+				return name + ".FormatUint(" + convert(types.Uint64) + ", 10)"
+			default:
+				return name + ".FormatInt(" + convert(types.Int64) + ", 10)"
+			}
+		}
+		if name := importName(f, "fmt"); name != "" {
+			return name + ".Sprint(" + x + ")"
+		}
+		return ""
+	}
+	isString := func(T types.Type) bool {
+		b, ok := T.Underlying().(*types.Basic)
+		return ok && b.Info()&types.IsString != 0
+	}
+
+	// numeric records conversions whose results are concatenated
+	// with strings or formatted, where a decimal number was most
+	// likely intended.
+	numeric := map[ast.Expr]bool{}
+	var f *ast.File
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BinaryExpr:
+			if node.Op == token.ADD && isString(TypeOf(j, node)) {
+				numeric[node.X] = true
+				numeric[node.Y] = true
+			}
+			return true
+		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok {
+				if fn, ok := ObjectOf(j, sel.Sel).(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" {
+					for _, arg := range node.Args {
+						numeric[arg] = true
+					}
+				}
+			}
+		default:
+			return true
+		}
+
+		call := node.(*ast.CallExpr)
+		if len(call.Args) != 1 {
+			return true
+		}
+		if tv, ok := j.Program.Info.Types[call.Fun]; !ok || !tv.IsType() || !isString(tv.Type) {
+			return true
+		}
+		T, ok := TypeOf(j, call.Args[0]).Underlying().(*types.Basic)
+		if !ok || T.Info()&types.IsInteger == 0 {
+			return true
+		}
+		switch T.Kind() {
+		case types.Uint8, types.Int32, types.UntypedRune:
+			// Conversions of bytes and runes are intended.
+			return true
+		}
+		if j.Program.GoVersion < 15 && !numeric[call] {
+			// Only go vet in Go 1.15 started flagging all such
+			// conversions; before, only flag those that most
+			// likely meant to produce decimal numbers.
+			return true
+		}
+
+		p := j.Errorf(call, "conversion from %s to string yields a string of one rune, not a decimal number", TypeOf(j, call.Args[0]))
+		x := Render(j, call.Args[0])
+		if d := decimal(f, x, TypeOf(j, call.Args[0])); d != "" {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "string" && ObjectOf(j, ident) == types.Universe.Lookup("string") {
+				j.AddFix(p, "format as decimal number", j.Replace(call, d))
+			} else {
+				j.AddFix(p, "format as decimal number", j.Replace(call.Args[0], d))
+			}
+		}
+		j.AddFix(p, "convert to rune explicitly", j.Replace(call.Args[0], "rune("+x+")"))
+		return true
+	}
+	for _, f = range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// shadowedErr reports whether ident refers to an error variable that
+// shadows an error variable of an enclosing scope of the function
+// body, where the outer variable is compared against nil after the
+// inner one goes out of scope. It returns the shadowing variable and
+// the comparison.
+func shadowedErr(j *lint.Job, body ast.Node, ident *ast.Ident) (*types.Var, *ast.BinaryExpr, bool) {
+	errType := types.Universe.Lookup("error").Type()
+	inner, ok := ObjectOf(j, ident).(*types.Var)
+	if !ok || !types.Identical(inner.Type(), errType) || inner.Parent() == nil || inner.Parent().Parent() == nil {
+		return nil, nil, false
+	}
+	scope := inner.Parent()
+	_, obj := scope.Parent().LookupParent(inner.Name(), inner.Pos())
+	outer, ok := obj.(*types.Var)
+	if !ok || !types.Identical(outer.Type(), errType) || outer.Pos() < body.Pos() || outer.Pos() >= body.End() {
+		return nil, nil, false
+	}
+
+	var check *ast.BinaryExpr
+	ast.Inspect(body, func(node ast.Node) bool {
+		if check != nil {
+			return false
+		}
+		binop, ok := node.(*ast.BinaryExpr)
+		if !ok || binop.Pos() < scope.End() || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+			return true
+		}
+		for _, pair := range [][2]ast.Expr{{binop.X, binop.Y}, {binop.Y, binop.X}} {
+			if ident, ok := pair[0].(*ast.Ident); ok && ObjectOf(j, ident) == outer && IsNil(j, pair[1]) {
+				check = binop
+			}
+		}
+		return true
+	})
+	return inner, check, check != nil
+}
+
+func (c *Checker) CheckShadowedErr(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		unreadAssignments(ssafn, func(lhs *ast.Ident) {
+			inner, check, ok := shadowedErr(j, ssafn.Syntax(), lhs)
+			if !ok {
+				return
+			}
+			p := j.Reportf(lhs, "unchecked", "this error is never checked: {{.name}} shadows the {{.name}} that is checked later", lint.Args{"name": lhs.Name})
+			j.AddRelated(p, inner, "%s is redeclared here, shadowing the outer %s", lhs.Name, lhs.Name)
+			j.AddRelated(p, check, "this checks the outer %s, not the shadowing one", lhs.Name)
+		})
+	}
+}
+
+func (c *Checker) CheckExecCmdMisuse(j *lint.Job) {
+	// precedes reports whether a is executed before b on all paths
+	// to b.
+	precedes := func(a, b ssa.Instruction) bool {
+		if a.Block() != b.Block() {
+			return a.Block().Dominates(b.Block())
+		}
+		for _, ins := range a.Block().Instrs {
+			switch ins {
+			case a:
+				return true
+			case b:
+				return false
+			}
+		}
+		return false
+	}
+	// waiting are the methods that wait for the command to exit.
+	waiting := map[string]bool{
+		"(*os/exec.Cmd).Run":            true,
+		"(*os/exec.Cmd).Output":         true,
+		"(*os/exec.Cmd).CombinedOutput": true,
+		"(*os/exec.Cmd).Wait":           true,
+	}
+	shortName := func(call *ssa.Call) string {
+		return CallName(call.Common())[len("(*os/exec.Cmd)."):]
+	}
+	fieldName := func(fa *ssa.FieldAddr) string {
+		return fa.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct).Field(fa.Field).Name()
+	}
+	// pipeUses returns the instructions that use the pipe returned by
+	// call, looking through conversions to other interfaces.
+	pipeUses := func(call *ssa.Call) []ssa.Instruction {
+		var uses []ssa.Instruction
+		var fn func(v ssa.Value)
+		fn = func(v ssa.Value) {
+			if v.Referrers() == nil {
+				return
+			}
+			for _, ref := range FilterDebug(*v.Referrers()) {
+				switch ref := ref.(type) {
+				case *ssa.MakeInterface:
+					fn(ref)
+				case *ssa.ChangeInterface:
+					fn(ref)
+				default:
+					uses = append(uses, ref)
+				}
+			}
+		}
+		for _, ref := range *call.Referrers() {
+			if ex, ok := ref.(*ssa.Extract); ok && ex.Index == 0 {
+				fn(ex)
+			}
+		}
+		return uses
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		// starts and waits map commands to the calls that start them
+		// and wait for them to exit; Run and friends do both.
+		starts := map[ssa.Value][]*ssa.Call{}
+		waits := map[ssa.Value][]*ssa.Call{}
+		pipes := map[ssa.Value][]*ssa.Call{}
+		stores := map[ssa.Value][]*ssa.Store{}
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				switch ins := ins.(type) {
+				case *ssa.Call:
+					name := CallName(ins.Common())
+					if !strings.HasPrefix(name, "(*os/exec.Cmd).") {
+						continue
+					}
+					cmd := ins.Common().Args[0]
+					switch name {
+					case "(*os/exec.Cmd).Start", "(*os/exec.Cmd).Run", "(*os/exec.Cmd).Output", "(*os/exec.Cmd).CombinedOutput":
+						starts[cmd] = append(starts[cmd], ins)
+					case "(*os/exec.Cmd).StdoutPipe", "(*os/exec.Cmd).StderrPipe":
+						pipes[cmd] = append(pipes[cmd], ins)
+					}
+					if waiting[name] {
+						waits[cmd] = append(waits[cmd], ins)
+					}
+				case *ssa.Store:
+					fa, ok := ins.Addr.(*ssa.FieldAddr)
+					if !ok || !IsType(fa.X.Type(), "*os/exec.Cmd") {
+						continue
+					}
+					switch fieldName(fa) {
+					case "Stdin", "Stdout", "Stderr":
+						stores[fa.X] = append(stores[fa.X], ins)
+					}
+				}
+			}
+		}
+
+		for cmd, calls := range starts {
+		startLoop:
+			for _, call := range calls {
+				for _, prev := range starts[cmd] {
+					if prev != call && precedes(prev, call) {
+						p := j.Errorf(call, "exec.Cmd can't be reused: the command was already started by %s", shortName(prev))
+						j.AddRelated(p, prev, "command started here")
+						continue startLoop
+					}
+				}
+			}
+		}
+		for cmd, calls := range waits {
+		waitLoop:
+			for _, call := range calls {
+				if shortName(call) != "Wait" {
+					continue
+				}
+				for _, prev := range waits[cmd] {
+					if prev != call && precedes(prev, call) {
+						p := j.Errorf(call, "Wait called after %s, which already waited for the command to exit", shortName(prev))
+						j.AddRelated(p, prev, "waited here")
+						continue waitLoop
+					}
+				}
+			}
+		}
+		for cmd, cmdStores := range stores {
+		storeLoop:
+			for _, store := range cmdStores {
+				for _, start := range starts[cmd] {
+					if precedes(start, store) {
+						p := j.Errorf(store, "setting %s after the command was started has no effect", fieldName(store.Addr.(*ssa.FieldAddr)))
+						j.AddRelated(p, start, "command started here")
+						continue storeLoop
+					}
+				}
+			}
+		}
+		for cmd, calls := range pipes {
+			for _, call := range calls {
+			useLoop:
+				for _, use := range pipeUses(call) {
+					for _, wait := range waits[cmd] {
+						if precedes(wait, use) {
+							p := j.Errorf(use, "reading from the pipe returned by %s after %s, which closes the pipe", shortName(call), shortName(wait))
+							j.AddRelated(p, wait, "pipe closed here")
+							continue useLoop
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// targetArch returns the architecture that prog was loaded for.
+func targetArch(prog *lint.Program) string {
+	if prog.Build != nil {
+		return prog.Build.GOARCH
+	}
+	return runtime.GOARCH
+}
+
+// sizes returns the sizes of types for the architecture that prog
+// was loaded for.
+func sizes(prog *lint.Program) types.Sizes {
+	if s := types.SizesFor("gc", targetArch(prog)); s != nil {
+		return s
+	}
+	return types.SizesFor("gc", "amd64")
+}
+
+func (c *Checker) CheckOnceWithDifferentFuncs(j *lint.Job) {
+	// A use is a call of Do on a sync.Once.
+	type use struct {
+		call *ssa.Call
+		fn   *ssa.Function
+	}
+	// once returns the variable or field that holds the sync.Once
+	// that v points to.
+	once := func(v ssa.Value) types.Object {
+		switch v := v.(type) {
+		case *ssa.Global:
+			return v.Object()
+		case *ssa.FieldAddr:
+			return v.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct).Field(v.Field)
+		}
+		return nil
+	}
+
+	uses := map[types.Object][]use{}
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok || !IsCallTo(call.Common(), "(*sync.Once).Do") {
+					continue
+				}
+				obj := once(call.Common().Args[0])
+				fn := unwrapFunction(call.Common().Args[1])
+				if obj == nil || fn == nil {
+					continue
+				}
+				uses[obj] = append(uses[obj], use{call, fn})
+			}
+		}
+	}
+
+	for obj, uses := range uses {
+		first := uses[0]
+		for _, u := range uses[1:] {
+			if u.call.Pos() < first.call.Pos() {
+				first = u
+			}
+		}
+		for _, u := range uses {
+			if u.fn == first.fn {
+				continue
+			}
+			p := j.Errorf(u.call, "%s is used with different functions; only the function of the first call to Do runs", obj.Name())
+			j.AddRelated(p, first.call, "%s is used with a different function here", obj.Name())
+		}
+	}
+}
+
+func (c *Checker) CheckAtomicAlignment(j *lint.Job) {
+	fns := map[string]bool{
+		"sync/atomic.AddInt64":             true,
+		"sync/atomic.AddUint64":            true,
+		"sync/atomic.LoadInt64":            true,
+		"sync/atomic.LoadUint64":           true,
+		"sync/atomic.StoreInt64":           true,
+		"sync/atomic.StoreUint64":          true,
+		"sync/atomic.SwapInt64":            true,
+		"sync/atomic.SwapUint64":           true,
+		"sync/atomic.CompareAndSwapInt64":  true,
+		"sync/atomic.CompareAndSwapUint64": true,
+	}
+	sizes := sizes(j.Program)
+	arch := targetArch(j.Program)
+	if sizes.Sizeof(types.Typ[types.Uintptr]) == 8 {
+		// The program is correct on the targeted architecture, but
+		// the first word of allocated structs is the only 64-bit
+		// word that is guaranteed to be aligned on all platforms.
+		sizes = types.SizesFor("gc", "386")
+		arch = "32-bit platforms"
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok || !fns[CallName(call.Common())] {
+					continue
+				}
+				field, ok := call.Common().Args[0].(*ssa.FieldAddr)
+				if !ok {
+					continue
+				}
+				// Add up the offsets of nested fields. The struct
+				// that contains them is assumed to be aligned.
+				var offset int64
+				for v := ssa.Value(field); ; {
+					fa, ok := v.(*ssa.FieldAddr)
+					if !ok {
+						break
+					}
+					T := fa.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct)
+					var fields []*types.Var
+					for i := 0; i < T.NumFields(); i++ {
+						fields = append(fields, T.Field(i))
+					}
+					offset += sizes.Offsetsof(fields)[fa.Field]
+					v = fa.X
+				}
+				if offset%8 == 0 {
+					continue
+				}
+				name := field.X.Type().Underlying().(*types.Pointer).Elem().Underlying().(*types.Struct).Field(field.Field).Name()
+				j.Errorf(call, "64-bit atomic operation on field %s, which isn't 64-bit aligned on %s (offset %d); move it to the start of the struct", name, arch, offset)
+			}
+		}
+	}
+}
+
+// matchesPackage reports whether the package path is listed in
+// patterns, such as the opaque_error_packages option, in which a
+// trailing "/..." includes subpackages.
+func matchesPackage(path string, patterns []string) bool {
+	for _, pat := range patterns {
+		if pat == path {
+			return true
+		}
+		if strings.HasSuffix(pat, "/...") {
+			prefix := strings.TrimSuffix(pat, "/...")
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBlockingCall reports whether calls to fn are known to block for
+// potentially long times, such as by doing network I/O or sleeping.
+func isBlockingCall(fn *types.Func) bool {
+	if fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Name()
+	var recv string
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		if T, ok := Dereference(sig.Recv().Type()).(*types.Named); ok {
+			recv = T.Obj().Name()
+		}
+	}
+	hasPrefix := func(prefixes ...string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	switch fn.Pkg().Path() {
+	case "time":
+		return recv == "" && name == "Sleep"
+	case "net":
+		return hasPrefix("Dial", "Listen", "Lookup", "Accept", "Read", "Write")
+	case "net/http":
+		switch recv {
+		case "", "Client", "Server":
+			switch name {
+			case "Get", "Head", "Post", "PostForm", "Do",
+				"ListenAndServe", "ListenAndServeTLS", "Serve", "ServeTLS", "Shutdown":
+				return true
+			}
+		}
+	case "database/sql":
+		switch recv {
+		case "DB", "Tx", "Conn", "Stmt", "Rows", "Row":
+			return hasPrefix("Query", "Exec", "Ping", "Begin", "Prepare", "Commit", "Rollback", "Conn", "Next", "Scan")
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckLockAcrossBlocking(j *lint.Job) {
+	// mutexCall returns the mutex and the name of the method if call
+	// is a call to a method of sync.Mutex or sync.RWMutex.
+	mutexCall := func(call *ast.CallExpr) (ast.Expr, string, bool) {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil, "", false
+		}
+		fn, ok := ObjectOf(j, sel.Sel).(*types.Func)
+		if !ok {
+			return nil, "", false
+		}
+		switch fn.FullName() {
+		case "(*sync.Mutex).Lock", "(*sync.Mutex).Unlock",
+			"(*sync.RWMutex).Lock", "(*sync.RWMutex).Unlock",
+			"(*sync.RWMutex).RLock", "(*sync.RWMutex).RUnlock":
+			return sel.X, fn.Name(), true
+		}
+		return nil, "", false
+	}
+	unlockOf := map[string]string{"Lock": "Unlock", "RLock": "RUnlock"}
+
+	// buffered records the local variables that are only ever
+	// assigned channels made with a constant, nonzero capacity, such
+	// as one that a single result is sent on. Sends on them aren't
+	// considered to block.
+	buffered := map[types.Object]bool{}
+	isBufferedMake := func(expr ast.Expr) bool {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		if fn, ok := ObjectOf(j, id).(*types.Builtin); !ok || fn.Name() != "make" {
+			return false
+		}
+		n, ok := ExprToInt(j, call.Args[1])
+		return ok && n > 0
+	}
+	assign := func(lhs, rhs []ast.Expr) {
+		for i, x := range lhs {
+			id, ok := x.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj, ok := ObjectOf(j, id).(*types.Var)
+			if !ok || obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() {
+				continue
+			}
+			if len(lhs) == len(rhs) && isBufferedMake(rhs[i]) {
+				if _, ok := buffered[obj]; !ok {
+					buffered[obj] = true
+				}
+			} else {
+				buffered[obj] = false
+			}
+		}
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				assign(node.Lhs, node.Rhs)
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(node.Names))
+				for i, name := range node.Names {
+					lhs[i] = name
+				}
+				assign(lhs, node.Values)
+			case *ast.UnaryExpr:
+				// The channel may be replaced through a pointer.
+				if id, ok := node.X.(*ast.Ident); ok && node.Op == token.AND {
+					if obj := ObjectOf(j, id); obj != nil {
+						buffered[obj] = false
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// checkList checks the statements of a block for mutexes that
+	// are locked and then held across blocking operations.
+	checkList := func(stmts []ast.Stmt) {
+		for i, stmt := range stmts {
+			expr, ok := stmt.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			call, ok := expr.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			mu, method, ok := mutexCall(call)
+			if !ok || unlockOf[method] == "" {
+				continue
+			}
+			name := Render(j, mu)
+			isUnlock := func(call *ast.CallExpr) bool {
+				x, m, ok := mutexCall(call)
+				return ok && m == unlockOf[method] && Render(j, x) == name
+			}
+
+			type blocking struct {
+				node ast.Node
+				desc string
+			}
+			var ops []blocking
+			var visit func(node ast.Node) bool
+			visit = func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.FuncLit, *ast.GoStmt, *ast.DeferStmt:
+					// Deferred calls run after the scanned
+					// statements, usually after a deferred unlock.
+					return false
+				case *ast.SendStmt:
+					if id, ok := node.Chan.(*ast.Ident); ok && buffered[ObjectOf(j, id)] {
+						return true
+					}
+					ops = append(ops, blocking{node, "channel send"})
+				case *ast.UnaryExpr:
+					if node.Op == token.ARROW {
+						ops = append(ops, blocking{node, "channel receive"})
+					}
+				case *ast.RangeStmt:
+					if _, ok := TypeOf(j, node.X).Underlying().(*types.Chan); ok {
+						ops = append(ops, blocking{node, "range over a channel"})
+					}
+				case *ast.SelectStmt:
+					hasDefault := false
+					for _, clause := range node.Body.List {
+						if clause.(*ast.CommClause).Comm == nil {
+							hasDefault = true
+						}
+					}
+					if !hasDefault {
+						ops = append(ops, blocking{node, "select"})
+					}
+					// The channel operations of the cases are part
+					// of the select; only their bodies are scanned.
+					for _, clause := range node.Body.List {
+						for _, stmt := range clause.(*ast.CommClause).Body {
+							ast.Inspect(stmt, visit)
+						}
+					}
+					return false
+				case *ast.CallExpr:
+					var id *ast.Ident
+					switch fun := node.Fun.(type) {
+					case *ast.Ident:
+						id = fun
+					case *ast.SelectorExpr:
+						id = fun.Sel
+					default:
+						return true
+					}
+					if fn, ok := ObjectOf(j, id).(*types.Func); ok && isBlockingCall(fn) {
+						ops = append(ops, blocking{node, "call to " + fn.FullName()})
+					}
+				}
+				return true
+			}
+			for _, stmt := range stmts[i+1:] {
+				// Statements that unlock the mutex, even if only on
+				// some paths, end the scan, unless the unlock is
+				// deferred.
+				unlocked := false
+				ast.Inspect(stmt, func(node ast.Node) bool {
+					switch node := node.(type) {
+					case *ast.FuncLit, *ast.GoStmt, *ast.DeferStmt:
+						return false
+					case *ast.CallExpr:
+						if isUnlock(node) {
+							unlocked = true
+						}
+					}
+					return !unlocked
+				})
+				if unlocked {
+					break
+				}
+				ast.Inspect(stmt, visit)
+			}
+			if len(ops) == 0 {
+				continue
+			}
+			p := j.Errorf(stmt, "%s is held across blocking operations, which makes other goroutines that need it wait for them; consider releasing it before blocking", name)
+			for _, op := range ops {
+				j.AddRelated(p, op.node, "blocking %s while %s is held", op.desc, name)
+			}
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			checkList(node.List)
+		case *ast.CaseClause:
+			checkList(node.Body)
+		case *ast.CommClause:
+			checkList(node.Body)
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
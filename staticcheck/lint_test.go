@@ -3,6 +3,7 @@ package staticcheck
 import (
 	"testing"
 
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/lint"
 	"honnef.co/go/tools/lint/lintutil"
 	"honnef.co/go/tools/lint/testutil"
@@ -13,6 +14,119 @@ func TestAll(t *testing.T) {
 	testutil.TestAll(t, c, "")
 }
 
+func TestErrorWrapping(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		ErrorWrapping:       "wrap",
+		OpaqueErrorPackages: []string{"opaque_go113.go"},
+	}
+	testutil.TestAllConfig(t, c, "CheckErrorWrapping", cfg)
+}
+
+func TestWeakTLSConfig(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		InsecureTLSPackages: []string{"legacy.go"},
+	}
+	testutil.TestAllConfig(t, c, "CheckWeakTLSConfig", cfg)
+}
+
+func TestSQLConcat(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		SQLQuoteFunctions: []string{"quote.go.QuoteIdentifier"},
+	}
+	testutil.TestAllConfig(t, c, "CheckSQLConcat", cfg)
+}
+
+func TestHardcodedSecrets(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		SecretNames: []string{"pin"},
+	}
+	testutil.TestAllConfig(t, c, "CheckHardcodedSecrets", cfg)
+}
+
+func TestUnmarshalPointer(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		UnmarshalFunctions: []string{"codec.go.Unmarshal*", "(*codec.go.Decoder).Decode", "codec.go.Load"},
+	}
+	testutil.TestAllConfig(t, c, "CheckUnmarshalPointer", cfg)
+}
+
+func TestHeavyInit(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		InitAllowedFunctions: []string{"setup.go.MustLoad"},
+	}
+	testutil.TestAllConfig(t, c, "CheckHeavyInit", cfg)
+}
+
+func TestGlobalMutableState(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		MutableGlobals: []string{"registry.go.drivers"},
+	}
+	testutil.TestAllConfig(t, c, "CheckGlobalMutableState", cfg)
+}
+
+func TestMustUseResults(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		MustUseFunctions: []string{"(*query.go.Builder).Wh*"},
+	}
+	testutil.TestAllConfig(t, c, "CheckMustUseResults", cfg)
+}
+
+func TestImportRules(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		ImportRules: []config.ImportRule{
+			{Forbid: []string{"unsafe"}, Except: []string{"fast.go"}, Reason: "unsafe code needs a review"},
+			{Forbid: []string{"net/rpc/..."}},
+			{Allow: []string{"fmt", "net/...", "unsafe"}, Except: []string{"fast.go"}},
+		},
+	}
+	testutil.TestAllConfig(t, c, "CheckImportRules", cfg)
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	c := NewChecker()
+	testutil.TestAll(t, c, "CheckGenerateDirectives")
+}
+
+func TestContextKeyTypeFixes(t *testing.T) {
+	opt := &lintutil.Options{
+		Checks: []string{"none", "SA1034"},
+		Loader: lintutil.MemoryLoader{Files: map[string]string{
+			"example.com/a/a.go": `package a
+
+import "context"
+
+func fn(ctx context.Context) {
+	ctx = context.WithValue(ctx, "user", 1)
+	ctx = context.WithValue(ctx, "user", 2)
+	_ = ctx.Value("user")
+}
+`,
+		}},
+	}
+	res, err := lintutil.Lint([]lint.Checker{NewChecker()}, []string{"example.com/a"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := res[0]
+	if len(ps) != 2 {
+		t.Fatalf("got problems %v, want two", ps)
+	}
+	// The fix replaces all uses of the key and declares its type
+	// once, so it must only be suggested once.
+	if len(ps[0].Fixes) != 1 || len(ps[0].Fixes[0].Edits) != 4 || len(ps[1].Fixes) != 0 {
+		t.Errorf("got fixes %+v and %+v, want one fix with four edits on the first problem", ps[0].Fixes, ps[1].Fixes)
+	}
+}
+
 func BenchmarkStdlib(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		c := NewChecker()
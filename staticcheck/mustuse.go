@@ -0,0 +1,107 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// mustUseFuncs are functions whose results must be used, because
+// calling them has no useful effect otherwise. Discarded cancel
+// functions of contexts are reported by CheckLostCancel.
+var mustUseFuncs = map[string]bool{
+	"context.WithValue":               true,
+	"(*bytes.Buffer).String":          true,
+	"(*bytes.Buffer).Bytes":           true,
+	"(*strings.Builder).String":       true,
+	"(*net/url.URL).String":           true,
+	"(*net/http.Request).WithContext": true,
+}
+
+// hasFuncDirective reports whether the declaration of the function
+// obj is documented with the comment //lint:<directive>, which may be
+// followed by a space and an explanation.
+func hasFuncDirective(j *lint.Job, obj *types.Func, directive string) bool {
+	info := j.Program.Prog.AllPackages[obj.Pkg()]
+	if info == nil {
+		return false
+	}
+	for _, f := range info.Files {
+		if f.Pos() > obj.Pos() || obj.Pos() > f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Pos() != obj.Pos() || fn.Doc == nil {
+				continue
+			}
+			for _, c := range fn.Doc.List {
+				text := strings.TrimSpace(c.Text)
+				if text == "//lint:"+directive || strings.HasPrefix(text, "//lint:"+directive+" ") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckMustUseResults(j *lint.Job) {
+	// annotated caches whether functions are documented with
+	// //lint:must-use.
+	annotated := map[*types.Func]bool{}
+	mustUse := func(pkg *lint.Pkg, fn *types.Func) bool {
+		if fn.Pkg() == nil {
+			return false
+		}
+		if mustUseFuncs[fn.FullName()] {
+			return true
+		}
+		if pkg != nil && matchesFunction(fn.FullName(), pkg.Config.MustUseFunctions) {
+			return true
+		}
+		v, ok := annotated[fn]
+		if !ok {
+			v = hasFuncDirective(j, fn, "must-use")
+			annotated[fn] = v
+		}
+		return v
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pkg := j.NodePackage(f)
+		ast.Inspect(f, func(node ast.Node) bool {
+			stmt, ok := node.(*ast.ExprStmt)
+			if !ok {
+				return true
+			}
+			call, ok := stmt.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var id *ast.Ident
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				id = fun
+			case *ast.SelectorExpr:
+				id = fun.Sel
+			default:
+				return true
+			}
+			fn, ok := ObjectOf(j, id).(*types.Func)
+			if !ok || fn.Type().(*types.Signature).Results().Len() == 0 || !mustUse(pkg, fn) {
+				return true
+			}
+			if ssafn := j.Program.SSA.FuncValue(fn); ssafn != nil {
+				if desc := c.funcDescs.Get(ssafn); desc.Pure && !desc.Stub {
+					// Reported by the check for pure functions.
+					return true
+				}
+			}
+			j.Errorf(call, "the result of %s must be used", Render(j, call.Fun))
+			return true
+		})
+	}
+}
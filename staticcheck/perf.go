@@ -0,0 +1,354 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// exitsLoop reports whether node is a return statement or a call to
+// panic, which end the loops that contain them.
+func exitsLoop(j *lint.Job, node ast.Node) bool {
+	switch node := node.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.CallExpr:
+		if id, ok := node.Fun.(*ast.Ident); ok {
+			if b, ok := ObjectOf(j, id).(*types.Builtin); ok && b.Name() == "panic" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inspectLoops calls fn for all nodes in root, with their parents
+// and the innermost loop whose body contains the node, if any. Loops
+// outside of function literals that contain the node don't count, as
+// function literals don't necessarily run once per iteration, and
+// neither do loops that the node ends, as it runs at most once.
+func inspectLoops(j *lint.Job, root ast.Node, fn func(node, parent ast.Node, loop ast.Stmt)) {
+	var stack []ast.Node
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		var loop ast.Stmt
+	search:
+		for i := len(stack) - 1; i >= 0; i-- {
+			var body *ast.BlockStmt
+			switch s := stack[i].(type) {
+			case *ast.FuncLit:
+				break search
+			case *ast.ReturnStmt, *ast.CallExpr:
+				if exitsLoop(j, s) {
+					break search
+				}
+				continue
+			case *ast.ForStmt:
+				body = s.Body
+			case *ast.RangeStmt:
+				body = s.Body
+			default:
+				continue
+			}
+			if node.Pos() >= body.Pos() && node.End() <= body.End() {
+				loop = stack[i].(ast.Stmt)
+				break
+			}
+		}
+		var parent ast.Node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		fn(node, parent, loop)
+		stack = append(stack, node)
+		return true
+	})
+}
+
+// loopVars returns the variables declared by loop, which are
+// assigned in every iteration.
+func loopVars(j *lint.Job, loop ast.Stmt) []types.Object {
+	var ids []ast.Expr
+	switch loop := loop.(type) {
+	case *ast.RangeStmt:
+		if loop.Tok == token.DEFINE {
+			ids = []ast.Expr{loop.Key, loop.Value}
+		}
+	case *ast.ForStmt:
+		if init, ok := loop.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+			ids = init.Lhs
+		}
+	}
+	var objs []types.Object
+	for _, id := range ids {
+		if id, ok := id.(*ast.Ident); ok && ObjectOf(j, id) != nil {
+			objs = append(objs, ObjectOf(j, id))
+		}
+	}
+	return objs
+}
+
+// isLoopInvariant reports whether expr has the same value in all
+// iterations of loop: constants, and local variables declared
+// outside of the loop that the loop doesn't assign to or take the
+// address of.
+func isLoopInvariant(j *lint.Job, expr ast.Expr, loop ast.Stmt) bool {
+	if tv, ok := j.Program.Info.Types[expr]; ok && tv.Value != nil {
+		return true
+	}
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v, ok := ObjectOf(j, id).(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() == v.Pkg().Scope() {
+		return false
+	}
+	if v.Pos() >= loop.Pos() && v.Pos() < loop.End() {
+		return false
+	}
+	modified := false
+	isVar := func(x ast.Expr) bool {
+		id, ok := x.(*ast.Ident)
+		return ok && ObjectOf(j, id) == v
+	}
+	ast.Inspect(loop, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				if isVar(lhs) {
+					modified = true
+				}
+			}
+		case *ast.RangeStmt:
+			if isVar(node.Key) || isVar(node.Value) {
+				modified = true
+			}
+		case *ast.IncDecStmt:
+			if isVar(node.X) {
+				modified = true
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.AND && isVar(node.X) {
+				modified = true
+			}
+		}
+		return !modified
+	})
+	return !modified
+}
+
+func (c *Checker) CheckLoopVarAddressStored(j *lint.Job) {
+	// stored reports whether the value of expr, which is in loop,
+	// outlives the current iteration.
+	stored := func(node ast.Node, loop ast.Stmt, expr ast.Expr) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if id, ok := node.Fun.(*ast.Ident); ok {
+				if b, ok := ObjectOf(j, id).(*types.Builtin); ok && b.Name() == "append" {
+					for _, arg := range node.Args[1:] {
+						if arg == expr {
+							return true
+						}
+					}
+				}
+			}
+		case *ast.SendStmt:
+			return node.Value == expr
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return false
+			}
+			for i, rhs := range node.Rhs {
+				if rhs != expr {
+					continue
+				}
+				switch lhs := node.Lhs[i].(type) {
+				case *ast.IndexExpr, *ast.SelectorExpr, *ast.StarExpr:
+					return true
+				case *ast.Ident:
+					obj := ObjectOf(j, lhs)
+					return node.Tok != token.DEFINE && obj != nil && (obj.Pos() < loop.Pos() || obj.Pos() >= loop.End())
+				}
+			}
+		}
+		return false
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		inspectLoops(j, f, func(node, parent ast.Node, loop ast.Stmt) {
+			unary, ok := node.(*ast.UnaryExpr)
+			if !ok || unary.Op != token.AND || loop == nil || parent == nil {
+				return
+			}
+			id, ok := unary.X.(*ast.Ident)
+			if !ok {
+				return
+			}
+			isLoopVar := false
+			for _, obj := range loopVars(j, loop) {
+				if obj == ObjectOf(j, id) {
+					isLoopVar = true
+				}
+			}
+			if !isLoopVar || !stored(parent, loop, unary) {
+				return
+			}
+			if IsGoVersion(j, 22) {
+				j.Errorf(unary, "the address of the loop variable %s is stored beyond the iteration, which makes %s escape to the heap and allocates in every iteration; consider storing values, or allocating all of them at once", id.Name, id.Name)
+			} else {
+				j.Errorf(unary, "the address of the loop variable %s is stored beyond the iteration, but all iterations share %s, so all stored pointers point to the value of the last iteration; copy the value to a new variable first", id.Name, id.Name)
+			}
+		})
+	}
+}
+
+// isPointerShaped reports whether values of type T are stored
+// directly in interfaces, so that converting them to interfaces
+// doesn't allocate.
+func isPointerShaped(T types.Type) bool {
+	switch T := T.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Chan, *types.Signature:
+		return true
+	case *types.Basic:
+		switch T.Kind() {
+		case types.UnsafePointer, types.Bool, types.Int8, types.Uint8:
+			// Booleans and single bytes are stored in static
+			// memory by the runtime.
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckLoopInterfaceConversion(j *lint.Job) {
+	check := func(arg ast.Expr, param types.Type, loop ast.Stmt) {
+		if _, ok := param.Underlying().(*types.Interface); !ok {
+			return
+		}
+		T := TypeOf(j, arg)
+		if _, ok := T.Underlying().(*types.Interface); ok || isPointerShaped(T) {
+			return
+		}
+		if b, ok := T.(*types.Basic); ok && b.Info()&types.IsUntyped != 0 {
+			// Untyped nil
+			return
+		}
+		if tv := j.Program.Info.Types[arg]; tv.Value != nil || sizes(j.Program).Sizeof(T) == 0 {
+			// Constants and zero-sized values are converted
+			// without allocating.
+			return
+		}
+		if !isLoopInvariant(j, arg, loop) {
+			return
+		}
+		j.Errorf(arg, "%s is converted to an interface in every iteration of the loop, which may allocate each time; consider converting it once before the loop", Render(j, arg))
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		inspectLoops(j, f, func(node, _ ast.Node, loop ast.Stmt) {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || loop == nil {
+				return
+			}
+			if tv := j.Program.Info.Types[call.Fun]; tv.IsType() {
+				if len(call.Args) == 1 {
+					check(call.Args[0], tv.Type, loop)
+				}
+				return
+			}
+			sig, ok := TypeOf(j, call.Fun).(*types.Signature)
+			if !ok || call.Ellipsis.IsValid() {
+				return
+			}
+			if id, ok := call.Fun.(*ast.Ident); ok {
+				if _, ok := ObjectOf(j, id).(*types.Builtin); ok {
+					return
+				}
+			}
+			params := sig.Params()
+			for i, arg := range call.Args {
+				var param types.Type
+				switch {
+				case sig.Variadic() && i >= params.Len()-1:
+					param = params.At(params.Len() - 1).Type().(*types.Slice).Elem()
+				case i < params.Len():
+					param = params.At(i).Type()
+				default:
+					continue
+				}
+				check(arg, param, loop)
+			}
+		})
+	}
+}
+
+func (c *Checker) CheckLoopStringToBytes(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		inspectLoops(j, f, func(node, _ ast.Node, loop ast.Stmt) {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || loop == nil || len(call.Args) != 1 {
+				return
+			}
+			tv := j.Program.Info.Types[call.Fun]
+			if !tv.IsType() {
+				return
+			}
+			slice, ok := tv.Type.Underlying().(*types.Slice)
+			if !ok {
+				return
+			}
+			if elem, ok := slice.Elem().Underlying().(*types.Basic); !ok || elem.Kind() != types.Byte {
+				return
+			}
+			arg := call.Args[0]
+			if basic, ok := TypeOf(j, arg).Underlying().(*types.Basic); !ok || basic.Info()&types.IsString == 0 {
+				return
+			}
+			if !isLoopInvariant(j, arg, loop) {
+				return
+			}
+			j.Errorf(call, "converting the loop-invariant string %s to a byte slice in every iteration of the loop allocates each time; unless the loop modifies the slice, convert it once before the loop", Render(j, arg))
+		})
+	}
+}
+
+func (c *Checker) CheckErrorConstruction(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		inspectLoops(j, f, func(node, _ ast.Node, loop ast.Stmt) {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return
+			}
+			isErrorf := IsCallToAST(j, call, "fmt.Errorf")
+			if !isErrorf && !IsCallToAST(j, call, "errors.New") {
+				return
+			}
+			tv := j.Program.Info.Types[call.Args[0]]
+			if tv.Value == nil || tv.Value.Kind() != constant.String {
+				return
+			}
+			if isErrorf && strings.Contains(constant.StringVal(tv.Value), "%") {
+				return
+			}
+			var p *lint.Problem
+			switch {
+			case loop != nil:
+				p = j.Errorf(call, "error with a constant message is constructed in every iteration of the loop; consider declaring a package-level error variable instead")
+			case isErrorf:
+				p = j.Errorf(call, "fmt.Errorf is called without formatting directives or arguments; should use errors.New instead")
+			default:
+				return
+			}
+			if name := importName(f, "errors"); isErrorf && name != "" {
+				j.AddFix(p, "use errors.New", j.Replace(call.Fun, name+".New"))
+			}
+		})
+	}
+}
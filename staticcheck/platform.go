@@ -0,0 +1,119 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// platformGOOS are the operating systems that
+// CheckPlatformSpecificSymbols considers: those that support amd64,
+// the architecture that packages are built for, except for those that
+// imply other ones, such as android implying linux.
+var platformGOOS = func() []string {
+	var out []string
+	for _, goos := range goosList {
+		if _, ok := impliedGOOS[goos]; ok || contains(noAMD64GOOS, goos) {
+			continue
+		}
+		out = append(out, goos)
+	}
+	return out
+}()
+
+func isPlatformSpecificPackage(path string) bool {
+	if path == "syscall" {
+		return true
+	}
+	// Account for vendored copies of x/sys
+	for _, suffix := range []string{"golang.org/x/sys/unix", "golang.org/x/sys/windows", "golang.org/x/sys/plan9"} {
+		if path == suffix || strings.HasSuffix(path, "/vendor/"+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExplicitConstraints reports whether a file restricts the
+// platforms it can be built for, via its name, a //go:build line or
+// // +build lines.
+func hasExplicitConstraints(fc *fileConstraint) bool {
+	goos, _ := osArchSuffix(fc.name)
+	return goos != "" || fc.goBuild != nil || len(fc.plusBuild) > 0
+}
+
+// matchingGOOS returns the operating systems that the file may be
+// built for.
+func matchingGOOS(ctx *build.Context, fc *fileConstraint) []string {
+	var out []string
+	for _, goos := range platformGOOS {
+		if fc.matches(ctx, goos, "amd64") {
+			out = append(out, goos)
+		}
+	}
+	return out
+}
+
+// platformSymbols records which package-level identifiers a package
+// declares when built for a specific operating system.
+type platformSymbols struct {
+	mu sync.Mutex
+	m  map[string]map[string]bool
+}
+
+func (ps *platformSymbols) get(ctx *build.Context, path, srcDir, goos string) map[string]bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	key := path + " " + goos
+	if syms, ok := ps.m[key]; ok {
+		return syms
+	}
+	if ps.m == nil {
+		ps.m = map[string]map[string]bool{}
+	}
+
+	syms := map[string]bool{}
+	ps.m[key] = syms
+	ctx2 := *ctx
+	ctx2.GOOS = goos
+	ctx2.GOARCH = "amd64"
+	bp, err := ctx2.Import(path, srcDir, 0)
+	if err != nil {
+		// Most likely, the package doesn't exist on this platform
+		return syms
+	}
+	fset := token.NewFileSet()
+	var files []string
+	files = append(files, bp.GoFiles...)
+	files = append(files, bp.CgoFiles...)
+	for _, name := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					syms[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						syms[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							syms[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return syms
+}
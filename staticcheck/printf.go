@@ -0,0 +1,460 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+	"honnef.co/go/tools/ssa"
+)
+
+// printfFuncs maps printf-style functions to the index of their
+// format argument.
+var printfFuncs = map[string]int{
+	"fmt.Errorf":               0,
+	"fmt.Fprintf":              1,
+	"fmt.Printf":               0,
+	"fmt.Sprintf":              0,
+	"log.Fatalf":               0,
+	"log.Panicf":               0,
+	"log.Printf":               0,
+	"(*log.Logger).Fatalf":     0,
+	"(*log.Logger).Panicf":     0,
+	"(*log.Logger).Printf":     0,
+	"(*testing.common).Errorf": 0,
+	"(*testing.common).Fatalf": 0,
+	"(*testing.common).Logf":   0,
+	"(*testing.common).Skipf":  0,
+}
+
+// A printfArg is a use of an argument by a formatting directive,
+// either by the verb itself or by a star width or precision.
+type printfArg struct {
+	// index is the 0-based index of the argument, relative to the
+	// first argument following the format string.
+	index int
+	// star is true if the argument provides a width or precision.
+	star bool
+}
+
+// A printfVerb is a single formatting directive, such as %[2]*d.
+type printfVerb struct {
+	// offset is the byte offset of the directive in the format
+	// string.
+	offset int
+	// text is the directive's text.
+	text string
+	verb rune
+	args []printfArg
+	// err describes a malformed directive.
+	err string
+}
+
+// parsePrintf parses a printf format string, following the rules of
+// the fmt package for explicit argument indexes.
+func parsePrintf(f string) []printfVerb {
+	var verbs []printfVerb
+	argNum := 0
+	for i := 0; i < len(f); {
+		if f[i] != '%' {
+			i++
+			continue
+		}
+		v := printfVerb{offset: i}
+		j := i + 1
+
+		// parseIndex parses an explicit argument index at f[j:], if
+		// there is one.
+		parseIndex := func() bool {
+			if j >= len(f) || f[j] != '[' {
+				return true
+			}
+			end := strings.IndexByte(f[j:], ']')
+			if end == -1 {
+				v.err = "unterminated argument index"
+				j = len(f)
+				return false
+			}
+			n, err := strconv.Atoi(f[j+1 : j+end])
+			if err != nil || n < 1 {
+				v.err = "invalid argument index " + f[j:j+end+1]
+				j += end + 1
+				return false
+			}
+			argNum = n - 1
+			j += end + 1
+			return true
+		}
+		// parseNum parses a width or precision at f[j:].
+		parseNum := func() bool {
+			if !parseIndex() {
+				return false
+			}
+			if j < len(f) && f[j] == '*' {
+				v.args = append(v.args, printfArg{index: argNum, star: true})
+				argNum++
+				j++
+				return true
+			}
+			for j < len(f) && f[j] >= '0' && f[j] <= '9' {
+				j++
+			}
+			return true
+		}
+
+		for j < len(f) && strings.IndexByte("+-# 0", f[j]) != -1 {
+			j++
+		}
+		ok := parseNum()
+		if ok && j < len(f) && f[j] == '.' {
+			j++
+			ok = parseNum()
+		}
+		if ok {
+			ok = parseIndex()
+		}
+		if ok {
+			if j >= len(f) {
+				v.err = "missing verb"
+			} else {
+				r, size := utf8.DecodeRuneInString(f[j:])
+				v.verb = r
+				j += size
+				if r != '%' {
+					v.args = append(v.args, printfArg{index: argNum})
+					argNum++
+				}
+			}
+		}
+		v.text = f[i:j]
+		verbs = append(verbs, v)
+		i = j
+	}
+	return verbs
+}
+
+// formatPos returns the position of the byte at offset in the string
+// literal lit. If the literal contains escape sequences, the position
+// of the literal itself is returned.
+func formatPos(lit ast.Expr, offset int) token.Pos {
+	bl, ok := lit.(*ast.BasicLit)
+	if !ok || bl.Kind != token.STRING {
+		return lit.Pos()
+	}
+	if bl.Value[0] == '"' && strings.ContainsRune(bl.Value, '\\') {
+		return lit.Pos()
+	}
+	return bl.Pos() + 1 + token.Pos(offset)
+}
+
+type posNode token.Pos
+
+func (p posNode) Pos() token.Pos { return token.Pos(p) }
+
+func (c *Checker) CheckPrintf(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		obj, ok := ObjectOf(j, sel.Sel).(*types.Func)
+		if !ok {
+			return true
+		}
+		idx, ok := printfFuncs[obj.FullName()]
+		if !ok || len(call.Args) <= idx {
+			return true
+		}
+		tv := j.Program.Info.Types[call.Args[idx]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return true
+		}
+		format := constant.StringVal(tv.Value)
+		lit := call.Args[idx]
+		args := call.Args[idx+1:]
+
+		for _, v := range parsePrintf(format) {
+			pos := posNode(formatPos(lit, v.offset))
+			if v.err != "" {
+				j.Errorf(pos, "Printf format %s has %s", v.text, v.err)
+				continue
+			}
+			for _, arg := range v.args {
+				if arg.index >= len(args) {
+					j.Errorf(pos, "Printf format %s reads arg #%d, but call has only %d %s", v.text, arg.index+1, len(args), pluralize(len(args), "arg", "args"))
+					break
+				}
+				T := TypeOf(j, args[arg.index])
+				if T == nil {
+					continue
+				}
+				if arg.star {
+					if b, ok := T.Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+						j.Errorf(pos, "Printf format %s uses arg #%d of type %s as width or precision, but it must be an integer", v.text, arg.index+1, T)
+					}
+					continue
+				}
+				if v.verb == 'p' || v.verb == 'T' {
+					continue
+				}
+				switch T.Underlying().(type) {
+				case *types.Signature:
+					j.Errorf(pos, "Printf format %s has arg #%d of function type %s, which prints its address; did you mean to call it?", v.text, arg.index+1, T)
+				case *types.Chan:
+					j.Errorf(pos, "Printf format %s has arg #%d of channel type %s, which prints its address", v.text, arg.index+1, T)
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// isPathLike reports whether the format string f looks like a path:
+// it contains a slash and no characters other than those commonly
+// found in file names.
+func isPathLike(f string) bool {
+	if !strings.Contains(f, "/") {
+		return false
+	}
+	for _, r := range f {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("/._-~%[]", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Checker) CheckSprintfPaths(j *lint.Job) {
+	// stringArg reports whether the verb v formats a string
+	// argument, which may need escaping or separators.
+	stringArg := func(v printfVerb, args []ast.Expr) bool {
+		if (v.verb != 's' && v.verb != 'v') || len(v.args) != 1 || v.args[0].index >= len(args) {
+			return false
+		}
+		T := TypeOf(j, args[v.args[0].index])
+		if T == nil {
+			return false
+		}
+		b, ok := T.Underlying().(*types.Basic)
+		return ok && b.Info()&types.IsString != 0
+	}
+
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+			return true
+		}
+		if !IsCallToAST(j, call, "fmt.Sprintf") {
+			return true
+		}
+		tv := j.Program.Info.Types[call.Args[0]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return true
+		}
+		format := constant.StringVal(tv.Value)
+		args := call.Args[1:]
+		verbs := parsePrintf(format)
+		for _, v := range verbs {
+			if v.err != "" {
+				return true
+			}
+		}
+
+		if i := strings.Index(format, "://"); i > 0 && !strings.ContainsAny(format[:i], "%/") {
+			// Verbs in the scheme and host are left alone; those
+			// in the path and query need escaping.
+			rest := i + len("://")
+			path := strings.IndexAny(format[rest:], "/?")
+			if path == -1 {
+				return true
+			}
+			path += rest
+			query := strings.IndexByte(format, '?')
+			for _, v := range verbs {
+				if v.offset < path || !stringArg(v, args) {
+					continue
+				}
+				if query != -1 && v.offset > query {
+					j.Errorf(call, "fmt.Sprintf doesn't escape %s in the URL's query; use url.Values to build the query", v.text)
+				} else if j.Program.GoVersion >= 19 {
+					j.Errorf(call, "fmt.Sprintf doesn't escape %s in the URL's path; use url.JoinPath or url.PathEscape", v.text)
+				} else {
+					j.Errorf(call, "fmt.Sprintf doesn't escape %s in the URL's path; use url.PathEscape", v.text)
+				}
+				return true
+			}
+			return true
+		}
+
+		if !isPathLike(format) {
+			return true
+		}
+		for _, v := range verbs {
+			end := v.offset + len(v.text)
+			adjacent := (v.offset > 0 && format[v.offset-1] == '/') || (end < len(format) && format[end] == '/')
+			if adjacent && stringArg(v, args) {
+				j.Errorf(call, "should use filepath.Join to build file paths, or path.Join for slash-separated paths, instead of fmt.Sprintf")
+				return true
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckErrorWrapping(j *lint.Job) {
+	errIface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+			return true
+		}
+		if !IsCallToAST(j, call, "fmt.Errorf") {
+			return true
+		}
+		pkg := j.NodePackage(call)
+		opaque := matchesPackage(pkg.Pkg.Path(), pkg.Config.OpaqueErrorPackages)
+		wrap := pkg.Config.ErrorWrapping == "wrap" && IsGoVersion(j, 13)
+		if !opaque && !wrap {
+			return true
+		}
+		tv := j.Program.Info.Types[call.Args[0]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return true
+		}
+		args := call.Args[1:]
+		verbs := parsePrintf(constant.StringVal(tv.Value))
+		wrapped := false
+		for _, v := range verbs {
+			if v.err != "" {
+				return true
+			}
+			if v.verb == 'w' {
+				wrapped = true
+			}
+		}
+
+		for _, v := range verbs {
+			if len(v.args) == 0 {
+				continue
+			}
+			arg := v.args[len(v.args)-1]
+			if arg.star || arg.index >= len(args) {
+				continue
+			}
+			switch {
+			case opaque && v.verb == 'w':
+				j.Errorf(args[arg.index], "package %s must not expose wrapped errors; should format the error with %%v instead of %s", pkg.Pkg.Path(), v.text)
+			case !opaque && wrap && (v.verb == 'v' || v.verb == 's'):
+				if wrapped && !IsGoVersion(j, 20) {
+					// Before Go 1.20, fmt.Errorf only supports a
+					// single %w.
+					return true
+				}
+				T := TypeOf(j, args[arg.index])
+				if T == nil || !types.Implements(T, errIface) {
+					continue
+				}
+				j.Errorf(args[arg.index], "should wrap the error with %%w instead of formatting it with %s, so that callers can inspect it with errors.Is and errors.As", v.text)
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// errorPredicates map the functions of the os package that inspect
+// errors, but don't unwrap them, to the errors that errors.Is should
+// be compared to instead.
+var errorPredicates = map[string]string{
+	"os.IsNotExist":   "ErrNotExist",
+	"os.IsExist":      "ErrExist",
+	"os.IsPermission": "ErrPermission",
+}
+
+func (c *Checker) CheckErrorPredicateOnWrapped(j *lint.Job) {
+	if !IsGoVersion(j, 13) {
+		return
+	}
+	pkg := "fs"
+	if !IsGoVersion(j, 16) {
+		pkg = "os"
+	}
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, b := range ssafn.Blocks {
+			for _, ins := range b.Instrs {
+				call, ok := ins.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				name := CallName(call.Common())
+				sentinel, ok := errorPredicates[name]
+				if !ok || len(call.Common().Args) != 1 {
+					continue
+				}
+				wrap := wrappingCall(call.Common().Args[0], map[ssa.Value]bool{})
+				if wrap == nil {
+					continue
+				}
+				p := j.Errorf(call, "%s doesn't unwrap errors, but the error may wrap another one; use errors.Is(err, %s.%s) instead", name, pkg, sentinel)
+				j.AddRelated(p, wrap, "error wrapped here")
+			}
+		}
+	}
+}
+
+// wrappingCall returns a call that wraps an error, with fmt.Errorf
+// and %w or with errors.Join, whose result v may be.
+func wrappingCall(v ssa.Value, seen map[ssa.Value]bool) *ssa.Call {
+	if seen[v] {
+		return nil
+	}
+	seen[v] = true
+	switch v := v.(type) {
+	case *ssa.Call:
+		if IsCallTo(v.Common(), "errors.Join") {
+			return v
+		}
+		if !IsCallTo(v.Common(), "fmt.Errorf") || len(v.Call.Args) == 0 {
+			return nil
+		}
+		k, ok := v.Call.Args[0].(*ssa.Const)
+		if !ok || k.Value == nil || k.Value.Kind() != constant.String {
+			return nil
+		}
+		for _, verb := range parsePrintf(constant.StringVal(k.Value)) {
+			if verb.verb == 'w' {
+				return v
+			}
+		}
+	case *ssa.Phi:
+		for _, edge := range v.Edges {
+			if call := wrappingCall(edge, seen); call != nil {
+				return call
+			}
+		}
+	case *ssa.ChangeInterface:
+		return wrappingCall(v.X, seen)
+	}
+	return nil
+}
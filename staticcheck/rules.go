@@ -184,42 +184,62 @@ func ConvertedFromInt(v Value) bool {
 	return true
 }
 
-func validEncodingBinaryType(j *lint.Job, typ types.Type) bool {
-	typ = typ.Underlying()
-	switch typ := typ.(type) {
+// invalidEncodingBinaryField returns the path to the first field in
+// typ whose type can't be used with encoding/binary, and that field's
+// type. The path is empty if typ itself can't be used.
+func invalidEncodingBinaryField(j *lint.Job, typ types.Type) (string, types.Type, bool) {
+	switch utyp := typ.Underlying().(type) {
 	case *types.Basic:
-		switch typ.Kind() {
+		switch utyp.Kind() {
 		case types.Uint8, types.Uint16, types.Uint32, types.Uint64,
 			types.Int8, types.Int16, types.Int32, types.Int64,
 			types.Float32, types.Float64, types.Complex64, types.Complex128, types.Invalid:
-			return true
+			return "", nil, false
 		case types.Bool:
-			return IsGoVersion(j, 8)
+			if IsGoVersion(j, 8) {
+				return "", nil, false
+			}
 		}
-		return false
 	case *types.Struct:
-		n := typ.NumFields()
-		for i := 0; i < n; i++ {
-			if !validEncodingBinaryType(j, typ.Field(i).Type()) {
-				return false
+		for i := 0; i < utyp.NumFields(); i++ {
+			field := utyp.Field(i)
+			if path, T, ok := invalidEncodingBinaryField(j, field.Type()); ok {
+				if path == "" || path[0] == '[' {
+					return field.Name() + path, T, true
+				}
+				return field.Name() + "." + path, T, true
 			}
 		}
-		return true
+		return "", nil, false
 	case *types.Array:
-		return validEncodingBinaryType(j, typ.Elem())
+		if path, T, ok := invalidEncodingBinaryField(j, utyp.Elem()); ok {
+			if path == "" {
+				// The array itself can't be used, which is more
+				// useful to report than the path to its elements.
+				return "", typ, true
+			}
+			if path[0] == '[' {
+				return "[i]" + path, T, true
+			}
+			return "[i]." + path, T, true
+		}
+		return "", nil, false
 	case *types.Interface:
 		// we can't determine if it's a valid type or not
-		return true
+		return "", nil, false
 	}
-	return false
+	return "", typ, true
 }
 
-func CanBinaryMarshal(j *lint.Job, v Value) bool {
-	typ := v.Value.Type().Underlying()
-	if ttyp, ok := typ.(*types.Pointer); ok {
-		typ = ttyp.Elem().Underlying()
+// binaryMarshalField returns the path to the first field of v that
+// can't be used with encoding/binary, as well as its type. The path is
+// empty if v, or the elements of v if it is a slice, can't be used.
+func binaryMarshalField(j *lint.Job, v Value) (string, types.Type, bool) {
+	typ := v.Value.Type()
+	if ttyp, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ttyp.Elem()
 	}
-	if ttyp, ok := typ.(interface {
+	if ttyp, ok := typ.Underlying().(interface {
 		Elem() types.Type
 	}); ok {
 		if _, ok := ttyp.(*types.Pointer); !ok {
@@ -227,7 +247,27 @@ func CanBinaryMarshal(j *lint.Job, v Value) bool {
 		}
 	}
 
-	return validEncodingBinaryType(j, typ)
+	return invalidEncodingBinaryField(j, typ)
+}
+
+func CanBinaryMarshal(j *lint.Job, v Value) bool {
+	_, _, bad := binaryMarshalField(j, v)
+	return !bad
+}
+
+// checkBinaryMarshal flags arguments to the encoding/binary function
+// fn that contain values without a fixed size.
+func checkBinaryMarshal(call *Call, arg *Argument, fn string) {
+	path, T, bad := binaryMarshalField(call.Job, arg.Value)
+	if !bad {
+		return
+	}
+	typ := arg.Value.Value.Type()
+	if path == "" {
+		arg.Invalid(fmt.Sprintf("value of type %s cannot be used with %s", typ, fn))
+		return
+	}
+	arg.Invalid(fmt.Sprintf("value of type %s cannot be used with %s: field %s has type %s, which doesn't have a fixed size", typ, fn, path, T))
 }
 
 func RepeatZeroTimes(name string, arg int) CallCheck {
@@ -0,0 +1,716 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+	"honnef.co/go/tools/ssa"
+)
+
+// secretWords are the words that, in the names of variables and
+// functions, hint at values that must not be guessable.
+var secretWords = map[string]bool{
+	"key":      true,
+	"keys":     true,
+	"token":    true,
+	"tokens":   true,
+	"secret":   true,
+	"secrets":  true,
+	"nonce":    true,
+	"salt":     true,
+	"password": true,
+	"passwd":   true,
+	"iv":       true,
+	"session":  true,
+	"csrf":     true,
+	"otp":      true,
+}
+
+// descriptorWords are the words that, at the end of the names of
+// variables and fields, describe secrets rather than hold them, as in
+// passwordFile or tokenURL.
+var descriptorWords = map[string]bool{
+	"column":  true,
+	"env":     true,
+	"field":   true,
+	"file":    true,
+	"format":  true,
+	"header":  true,
+	"id":      true,
+	"label":   true,
+	"len":     true,
+	"length":  true,
+	"name":    true,
+	"param":   true,
+	"path":    true,
+	"pattern": true,
+	"prefix":  true,
+	"query":   true,
+	"suffix":  true,
+	"type":    true,
+	"url":     true,
+}
+
+// authWords are the words that, in the names of functions, hint at
+// authentication.
+var authWords = map[string]bool{
+	"auth":         true,
+	"authenticate": true,
+	"authorize":    true,
+	"credential":   true,
+	"credentials":  true,
+	"login":        true,
+	"oauth":        true,
+	"sign":         true,
+}
+
+// nameWords splits an identifier into its lower-cased words, at
+// underscores and changes of case, as in "newAPIKey" -> "new", "api",
+// "key".
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// isSecretName reports whether the identifier name contains one of
+// secretWords or of extra, the secret_names option.
+func isSecretName(name string, extra []string) bool {
+	for _, w := range nameWords(name) {
+		if secretWords[w] {
+			return true
+		}
+		for _, e := range extra {
+			if strings.ToLower(e) == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCryptoPackage(path string) bool {
+	return (strings.HasPrefix(path, "crypto/") && path != "crypto/rand") ||
+		strings.HasPrefix(path, "golang.org/x/crypto/")
+}
+
+// bufferObject returns the object of the variable or field that the
+// buffer expression, such as b or b[:], refers to.
+func bufferObject(j *lint.Job, expr ast.Expr) types.Object {
+	for {
+		switch e := expr.(type) {
+		case *ast.SliceExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return j.Program.Info.ObjectOf(e)
+		case *ast.SelectorExpr:
+			return j.Program.Info.ObjectOf(e.Sel)
+		default:
+			return nil
+		}
+	}
+}
+
+// flowsIntoCrypto reports whether obj is passed to a function or
+// method of a cryptographic package somewhere in body.
+func flowsIntoCrypto(j *lint.Job, body ast.Node, obj types.Object) bool {
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var id *ast.Ident
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			id = fun
+		case *ast.SelectorExpr:
+			id = fun.Sel
+		default:
+			return true
+		}
+		fn, ok := j.Program.Info.ObjectOf(id).(*types.Func)
+		if !ok || fn.Pkg() == nil || !isCryptoPackage(fn.Pkg().Path()) {
+			return true
+		}
+		for _, arg := range call.Args {
+			if bufferObject(j, arg) == obj {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+func (c *Checker) CheckMathRandSeed(j *lint.Job) {
+	if IsGoVersion(j, 20) {
+		// Since Go 1.20, the global source is seeded randomly, and
+		// rand.Seed is deprecated.
+		return
+	}
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || !IsCallToAST(j, call, "math/rand.Seed") {
+			return true
+		}
+		arg := call.Args[0]
+		if conv, ok := arg.(*ast.CallExpr); ok && len(conv.Args) == 1 {
+			// Look through conversions, as in int64(x).
+			if tv, ok := j.Program.Info.Types[conv.Fun]; ok && tv.IsType() {
+				arg = conv.Args[0]
+			}
+		}
+		switch {
+		case j.Program.Info.Types[arg].Value != nil:
+			j.Errorf(call, "seeding math/rand with a constant makes its numbers the same in every run; use crypto/rand for numbers that must not be guessed")
+		case IsCallToAST(j, arg, "(time.Time).Unix"):
+			if sel, ok := arg.(*ast.CallExpr).Fun.(*ast.SelectorExpr); ok && IsCallToAST(j, sel.X, "time.Now") {
+				j.Errorf(call, "seeding math/rand with the current time in seconds makes its numbers easy to guess; use crypto/rand for numbers that must not be guessed")
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckMathRandSecrets(j *lint.Job) {
+	var body ast.Node
+	var fnName string
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !IsCallToAnyAST(j, call, "math/rand.Read", "(*math/rand.Rand).Read") {
+			return true
+		}
+		obj := bufferObject(j, call.Args[0])
+		extra := j.NodePackage(call).Config.SecretNames
+		switch {
+		case isSecretName(fnName, extra):
+		case obj != nil && isSecretName(obj.Name(), extra):
+		case obj != nil && flowsIntoCrypto(j, body, obj):
+		default:
+			return true
+		}
+		j.Errorf(call, "math/rand doesn't generate unpredictable bytes, which keys, tokens and other secrets require; should use crypto/rand.Read")
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			fdecl, ok := decl.(*ast.FuncDecl)
+			if !ok || fdecl.Body == nil {
+				continue
+			}
+			body, fnName = fdecl.Body, fdecl.Name.Name
+			ast.Inspect(fdecl.Body, fn)
+		}
+	}
+}
+
+// tls12 is the value of tls.VersionTLS12.
+const tls12 = 0x0303
+
+// isWeakCipherSuite reports whether the crypto/tls constant name is a
+// cipher suite that uses RC4 or 3DES, or CBC mode with SHA-256, which
+// is vulnerable to Lucky13.
+func isWeakCipherSuite(name string) bool {
+	return strings.Contains(name, "_RC4_") ||
+		strings.Contains(name, "_3DES_") ||
+		strings.HasSuffix(name, "_CBC_SHA256")
+}
+
+func (c *Checker) CheckWeakTLSConfig(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		lit, ok := node.(*ast.CompositeLit)
+		if !ok || !IsType(TypeOf(j, lit), "crypto/tls.Config") {
+			return true
+		}
+		pkg := j.NodePackage(lit)
+		if matchesPackage(pkg.Pkg.Path(), pkg.Config.InsecureTLSPackages) {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			switch key.Name {
+			case "InsecureSkipVerify":
+				if IsInTest(j, kv) {
+					continue
+				}
+				if v := j.Program.Info.Types[kv.Value].Value; v != nil && constant.BoolVal(v) {
+					j.Errorf(kv, "InsecureSkipVerify disables the verification of certificates, which allows man-in-the-middle attacks")
+				}
+			case "MinVersion":
+				v := j.Program.Info.Types[kv.Value].Value
+				if v == nil {
+					continue
+				}
+				if n, ok := constant.Int64Val(v); ok && n != 0 && n < tls12 {
+					j.Errorf(kv, "MinVersion allows versions of TLS older than 1.2, which have known weaknesses")
+				}
+			case "CipherSuites":
+				suites, ok := kv.Value.(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				for _, suite := range suites.Elts {
+					var id *ast.Ident
+					switch suite := suite.(type) {
+					case *ast.Ident:
+						id = suite
+					case *ast.SelectorExpr:
+						id = suite.Sel
+					default:
+						continue
+					}
+					obj, ok := j.Program.Info.ObjectOf(id).(*types.Const)
+					if !ok || obj.Pkg() == nil || obj.Pkg().Path() != "crypto/tls" || !isWeakCipherSuite(obj.Name()) {
+						continue
+					}
+					j.Errorf(suite, "cipher suite %s is insecure", obj.Name())
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+var checkSQLConcatRules = map[string]CallCheck{
+	"(*database/sql.DB).Exec":              sqlQuery(0),
+	"(*database/sql.DB).ExecContext":       sqlQuery(1),
+	"(*database/sql.DB).Prepare":           sqlQuery(0),
+	"(*database/sql.DB).PrepareContext":    sqlQuery(1),
+	"(*database/sql.DB).Query":             sqlQuery(0),
+	"(*database/sql.DB).QueryContext":      sqlQuery(1),
+	"(*database/sql.DB).QueryRow":          sqlQuery(0),
+	"(*database/sql.DB).QueryRowContext":   sqlQuery(1),
+	"(*database/sql.Tx).Exec":              sqlQuery(0),
+	"(*database/sql.Tx).ExecContext":       sqlQuery(1),
+	"(*database/sql.Tx).Prepare":           sqlQuery(0),
+	"(*database/sql.Tx).PrepareContext":    sqlQuery(1),
+	"(*database/sql.Tx).Query":             sqlQuery(0),
+	"(*database/sql.Tx).QueryContext":      sqlQuery(1),
+	"(*database/sql.Tx).QueryRow":          sqlQuery(0),
+	"(*database/sql.Tx).QueryRowContext":   sqlQuery(1),
+	"(*database/sql.Conn).ExecContext":     sqlQuery(1),
+	"(*database/sql.Conn).PrepareContext":  sqlQuery(1),
+	"(*database/sql.Conn).QueryContext":    sqlQuery(1),
+	"(*database/sql.Conn).QueryRowContext": sqlQuery(1),
+}
+
+// sqlQuery returns a CallCheck that flags queries, passed as the
+// argument with the given index, that are built by concatenating
+// strings that aren't constant. Strings returned by the functions
+// listed in the sql_quote_functions option are trusted.
+func sqlQuery(index int) CallCheck {
+	return func(call *Call) {
+		arg := call.Args[index]
+		v := arg.Value.Value
+		if !isConcat(v, map[ssa.Value]bool{}) {
+			return
+		}
+		pkg := call.Job.NodePackage(call.Instr)
+		if trustedSQL(v, pkg.Config.SQLQuoteFunctions, map[ssa.Value]bool{}) {
+			return
+		}
+		arg.Invalid("SQL query is built by concatenating strings, which allows SQL injection; should use a parameterized query and pass the values as arguments")
+	}
+}
+
+// isConcat reports whether the string v is the result of a
+// concatenation, possibly on only some paths.
+func isConcat(v ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		return false
+	}
+	seen[v] = true
+	switch v := v.(type) {
+	case *ssa.BinOp:
+		return v.Op == token.ADD
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if isConcat(e, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trustedSQL reports whether the string v is made up of constants
+// and of the results of the functions named by quoters only.
+func trustedSQL(v ssa.Value, quoters []string, seen map[ssa.Value]bool) bool {
+	if seen[v] {
+		// Values that are still being looked at, in cycles formed
+		// by loops, are trusted if all other values are.
+		return true
+	}
+	seen[v] = true
+	switch v := v.(type) {
+	case *ssa.Const:
+		return true
+	case *ssa.BinOp:
+		return v.Op == token.ADD && trustedSQL(v.X, quoters, seen) && trustedSQL(v.Y, quoters, seen)
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if !trustedSQL(e, quoters, seen) {
+				return false
+			}
+		}
+		return true
+	case *ssa.Call:
+		name := CallName(v.Common())
+		for _, q := range quoters {
+			if name == q {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenQualifiers are the words that, in names, make tokens
+// credentials, as in "authToken" and "apiToken".
+var tokenQualifiers = map[string]bool{
+	"access":  true,
+	"api":     true,
+	"auth":    true,
+	"bearer":  true,
+	"bot":     true,
+	"oauth":   true,
+	"refresh": true,
+}
+
+// isHardcodedSecretName reports whether values assigned to the
+// identifier name are likely secrets.
+func isHardcodedSecretName(name string, extra []string) bool {
+	words := nameWords(name)
+	if len(words) == 0 || descriptorWords[words[len(words)-1]] {
+		return false
+	}
+	if len(words) == 1 && (words[0] == "key" || words[0] == "keys") {
+		// A bare key is usually the key of a map or a header.
+		return false
+	}
+	if !isSecretName(name, extra) {
+		return false
+	}
+	// Tokens are also those of lexers and parsers, or the page
+	// tokens of APIs. Only qualified tokens are credentials.
+	qualified := false
+	for _, w := range words {
+		if w != "token" && w != "tokens" && isSecretName(w, extra) {
+			return true
+		}
+		if tokenQualifiers[w] {
+			qualified = true
+		}
+	}
+	return qualified
+}
+
+// isConstantName reports whether s looks like the name of a constant
+// or of an environment variable, such as "DB_PASSWORD" or "ADMIN".
+// Names with digits must contain underscores, as access key IDs
+// consist of upper case letters and digits as well.
+func isConstantName(s string) bool {
+	digits := false
+	for i, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+		case r == '_' && i > 0:
+		case r >= '0' && r <= '9' && i > 0:
+			digits = true
+		default:
+			return false
+		}
+	}
+	return !digits || strings.Contains(s, "_")
+}
+
+// looksLikeSecret reports whether the string literal s could be a
+// credential, rather than an empty value, a placeholder or a name.
+func looksLikeSecret(s string, extra []string) bool {
+	if len(s) < 4 || strings.ContainsAny(s, " \t\n<{%$") {
+		return false
+	}
+	if strings.Count(s, s[:1]) == len(s) {
+		// Masks such as "****" and "xxxx".
+		return false
+	}
+	if isConstantName(s) {
+		return false
+	}
+	// Values that are themselves names of secrets, such as the
+	// header "X-Auth-Token" or the key "password", aren't secrets.
+	return !isSecretName(s, extra)
+}
+
+// entropy returns the Shannon entropy of s, in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	for _, n := range counts {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(len(s))
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+func (c *Checker) CheckHardcodedSecrets(j *lint.Job) {
+	// literal returns the value of expr if it is a string literal.
+	literal := func(expr ast.Expr) (string, bool) {
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return "", false
+		}
+		return ExprToString(j, lit)
+	}
+	// name returns the name that expr refers to, such as a variable
+	// or field, or the value of a map key.
+	name := func(expr ast.Expr) string {
+		switch expr := expr.(type) {
+		case *ast.Ident:
+			return expr.Name
+		case *ast.SelectorExpr:
+			return expr.Sel.Name
+		case *ast.BasicLit:
+			s, _ := ExprToString(j, expr)
+			return s
+		}
+		return ""
+	}
+	var extra []string
+	check := func(lhs, rhs ast.Expr) {
+		s, ok := literal(rhs)
+		if !ok {
+			return
+		}
+		n := name(lhs)
+		if isHardcodedSecretName(n, extra) && looksLikeSecret(s, extra) {
+			j.Errorf(rhs, "%s is set to a hard-coded secret; secrets should be read from the environment, a file or a secret manager", n)
+		}
+	}
+
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ValueSpec:
+			for i, id := range node.Names {
+				if i < len(node.Values) {
+					check(id, node.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if len(node.Lhs) == len(node.Rhs) {
+				for i := range node.Lhs {
+					check(node.Lhs[i], node.Rhs[i])
+				}
+			}
+		case *ast.KeyValueExpr:
+			check(node.Key, node.Value)
+		case *ast.BinaryExpr:
+			if node.Op == token.EQL || node.Op == token.NEQ {
+				check(node.X, node.Y)
+				check(node.Y, node.X)
+			}
+		case *ast.CallExpr:
+			var id *ast.Ident
+			switch fun := node.Fun.(type) {
+			case *ast.Ident:
+				id = fun
+			case *ast.SelectorExpr:
+				id = fun.Sel
+			default:
+				return true
+			}
+			sig, ok := TypeOf(j, node.Fun).(*types.Signature)
+			if !ok {
+				return true
+			}
+			auth := false
+			for _, w := range nameWords(id.Name) {
+				if authWords[w] {
+					auth = true
+				}
+			}
+			for i, arg := range node.Args {
+				s, ok := literal(arg)
+				if !ok || !looksLikeSecret(s, extra) {
+					continue
+				}
+				if i < sig.Params().Len() && !(sig.Variadic() && i >= sig.Params().Len()-1) {
+					param := sig.Params().At(i).Name()
+					// Like arguments of authentication functions,
+					// only long, random strings are reported.
+					if isHardcodedSecretName(param, extra) && len(s) >= 16 && entropy(s) >= 3.5 {
+						j.Errorf(arg, "parameter %s of %s is passed a hard-coded secret; secrets should be read from the environment, a file or a secret manager", param, id.Name)
+						continue
+					}
+				}
+				if auth && len(s) >= 16 && entropy(s) >= 3.5 {
+					j.Errorf(arg, "%s is passed a string that looks like a hard-coded secret; secrets should be read from the environment, a file or a secret manager", id.Name)
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if IsInTest(j, f) {
+			continue
+		}
+		extra = j.NodePackage(f).Config.SecretNames
+		ast.Inspect(f, fn)
+	}
+}
+
+// secretField returns the path to a field of the struct type T whose
+// name suggests that it holds a secret, such as "Auth.Password", or
+// the empty string. Fields of nested structs are included, as fmt
+// prints them as well, but fields of pointed-to structs aren't.
+func secretField(T types.Type, extra []string, seen map[types.Type]bool) string {
+	s, ok := T.Underlying().(*types.Struct)
+	if !ok || seen[T] {
+		return ""
+	}
+	seen[T] = true
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if isHardcodedSecretName(field.Name(), extra) {
+			return field.Name()
+		}
+		if path := secretField(field.Type(), extra, seen); path != "" {
+			return field.Name() + "." + path
+		}
+	}
+	return ""
+}
+
+func (c *Checker) CheckErrorFormatLeaks(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+			return true
+		}
+		if !IsCallToAST(j, call, "fmt.Errorf") {
+			return true
+		}
+		tv := j.Program.Info.Types[call.Args[0]]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			return true
+		}
+		// Values that format themselves don't print their addresses
+		// or fields.
+		var formatters []*types.Interface
+		var fmtPkg *types.Package
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			fmtPkg = ObjectOf(j, fun.Sel).Pkg()
+		case *ast.Ident:
+			fmtPkg = ObjectOf(j, fun).Pkg()
+		}
+		for _, name := range []string{"Formatter", "Stringer", "GoStringer"} {
+			if obj := fmtPkg.Scope().Lookup(name); obj != nil {
+				formatters = append(formatters, obj.Type().Underlying().(*types.Interface))
+			}
+		}
+		formatters = append(formatters, types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+		formatsItself := func(T types.Type) bool {
+			for _, iface := range formatters {
+				if types.Implements(T, iface) {
+					return true
+				}
+			}
+			return false
+		}
+
+		extra := j.NodePackage(call).Config.SecretNames
+		args := call.Args[1:]
+		for _, v := range parsePrintf(constant.StringVal(tv.Value)) {
+			if v.err != "" || (v.verb != 'v' && v.verb != 's') || len(v.args) == 0 {
+				continue
+			}
+			arg := v.args[len(v.args)-1]
+			if arg.star || arg.index >= len(args) {
+				continue
+			}
+			T := TypeOf(j, args[arg.index])
+			if T == nil || formatsItself(T) {
+				continue
+			}
+			pos := posNode(formatPos(call.Args[0], v.offset))
+			if ptr, ok := T.Underlying().(*types.Pointer); ok {
+				// fmt prints the contents of pointers to structs,
+				// arrays, slices and maps, but the address of
+				// other pointers.
+				switch ptr.Elem().Underlying().(type) {
+				case *types.Struct, *types.Array, *types.Slice, *types.Map:
+					T = ptr.Elem()
+					if formatsItself(T) {
+						continue
+					}
+				default:
+					j.Errorf(pos, "error format %s prints the memory address of arg #%d of type %s, not its value; dereference it or format it some other way", v.text, arg.index+1, T)
+					continue
+				}
+			}
+			if path := secretField(T, extra, map[types.Type]bool{}); path != "" {
+				j.Errorf(pos, "error format %s prints all fields of arg #%d of type %s, including %s, which may hold a secret that would end up in logs", v.text, arg.index+1, T, path)
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
@@ -0,0 +1,253 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+	"honnef.co/go/tools/ssa"
+)
+
+// templateSource returns the call to Parse that created the template
+// v, if v can be traced back to a call of the form
+// template.New(...).Parse(const), possibly wrapped in template.Must
+// and stored in a global variable that is assigned exactly once.
+func templateSource(v ssa.Value, stores map[*ssa.Global][]ssa.Value) (*ssa.Call, bool) {
+	switch v := v.(type) {
+	case *ssa.UnOp:
+		g, ok := v.X.(*ssa.Global)
+		if !ok || v.Op != token.MUL || len(stores[g]) != 1 {
+			return nil, false
+		}
+		return templateSource(stores[g][0], stores)
+	case *ssa.Call:
+		if !IsCallTo(v.Common(), "text/template.Must") &&
+			!IsCallTo(v.Common(), "html/template.Must") {
+			return nil, false
+		}
+		return templateSource(v.Call.Args[0], stores)
+	case *ssa.Extract:
+		call, ok := v.Tuple.(*ssa.Call)
+		if !ok || v.Index != 0 {
+			return nil, false
+		}
+		if !IsCallTo(call.Common(), "(*text/template.Template).Parse") &&
+			!IsCallTo(call.Common(), "(*html/template.Template).Parse") {
+			return nil, false
+		}
+		recv, ok := call.Call.Args[0].(*ssa.Call)
+		if !ok || (!IsCallTo(recv.Common(), "text/template.New") &&
+			!IsCallTo(recv.Common(), "html/template.New")) {
+			return nil, false
+		}
+		if k, ok := call.Call.Args[1].(*ssa.Const); !ok || k.Value == nil || k.Value.Kind() != constant.String {
+			return nil, false
+		}
+		return call, true
+	}
+	return nil, false
+}
+
+// templateField resolves the chain of field and method names on T.
+// It returns the index of the first name that provably doesn't exist,
+// or -1 if all names exist or can't be resolved statically.
+func templateField(T types.Type, names []string) int {
+	for i, name := range names {
+		obj, _, _ := types.LookupFieldOrMethod(T, true, nil, name)
+		switch obj := obj.(type) {
+		case *types.Var:
+			T = obj.Type()
+		case *types.Func:
+			res := obj.Type().(*types.Signature).Results()
+			if res.Len() == 0 {
+				return -1
+			}
+			T = res.At(0).Type()
+		default:
+			// Only structs have a fixed set of fields. Maps and
+			// interfaces may contain anything.
+			if _, ok := Dereference(T).Underlying().(*types.Struct); ok {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// A templateRef is a reference to a field or method of the template's
+// data, such as .Name.First or $.Name.
+type templateRef struct {
+	offset int
+	// root is either "." or "$".
+	root  string
+	names []string
+}
+
+// templateRefs returns all references in tree that are resolved
+// relative to the data passed to Execute.
+func templateRefs(tree *parse.Tree) []templateRef {
+	var refs []templateRef
+	var walkPipe func(pipe *parse.PipeNode, dot bool)
+	var walk func(node parse.Node, dot bool)
+	walkPipe = func(pipe *parse.PipeNode, dot bool) {
+		if pipe == nil {
+			return
+		}
+		for _, cmd := range pipe.Cmds {
+			for _, arg := range cmd.Args {
+				switch arg := arg.(type) {
+				case *parse.FieldNode:
+					if dot {
+						refs = append(refs, templateRef{int(arg.Position()), ".", arg.Ident})
+					}
+				case *parse.VariableNode:
+					if len(arg.Ident) > 1 && arg.Ident[0] == "$" {
+						refs = append(refs, templateRef{int(arg.Position()), "$", arg.Ident[1:]})
+					}
+				case *parse.PipeNode:
+					walkPipe(arg, dot)
+				}
+			}
+		}
+	}
+	walk = func(node parse.Node, dot bool) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, n := range node.Nodes {
+				walk(n, dot)
+			}
+		case *parse.ActionNode:
+			walkPipe(node.Pipe, dot)
+		case *parse.TemplateNode:
+			walkPipe(node.Pipe, dot)
+		case *parse.IfNode:
+			walkPipe(node.Pipe, dot)
+			walk(node.List, dot)
+			walk(node.ElseList, dot)
+		case *parse.RangeNode:
+			// range and with change the meaning of dot in their
+			// bodies, but not in their else branches.
+			walkPipe(node.Pipe, dot)
+			walk(node.List, false)
+			walk(node.ElseList, dot)
+		case *parse.WithNode:
+			walkPipe(node.Pipe, dot)
+			walk(node.List, false)
+			walk(node.ElseList, dot)
+		}
+	}
+	walk(tree.Root, true)
+	return refs
+}
+
+// checkTemplateFields flags references to fields that don't exist on
+// the data passed to the template's Execute method, at all call sites
+// of Execute. lits maps the positions of calls to Parse to their
+// template arguments.
+func (c *Checker) checkTemplateFields(j *lint.Job, lits map[token.Pos]ast.Expr) {
+	stores := map[*ssa.Global][]ssa.Value{}
+	for _, fn := range j.Program.InitialFunctions {
+		for _, block := range fn.Blocks {
+			for _, ins := range block.Instrs {
+				if store, ok := ins.(*ssa.Store); ok {
+					if g, ok := store.Addr.(*ssa.Global); ok {
+						stores[g] = append(stores[g], store.Val)
+					}
+				}
+			}
+		}
+	}
+
+	type site struct {
+		call *ssa.Call
+		data types.Type
+	}
+	var sources []*ssa.Call
+	sites := map[*ssa.Call][]site{}
+	for _, fn := range j.Program.InitialFunctions {
+		for _, block := range fn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				if !IsCallTo(call.Common(), "(*text/template.Template).Execute") &&
+					!IsCallTo(call.Common(), "(*html/template.Template).Execute") {
+					continue
+				}
+				src, ok := templateSource(call.Call.Args[0], stores)
+				if !ok {
+					continue
+				}
+				if _, ok := sites[src]; !ok {
+					sources = append(sources, src)
+				}
+				var data types.Type
+				if mi, ok := call.Call.Args[2].(*ssa.MakeInterface); ok {
+					data = mi.X.Type()
+				}
+				sites[src] = append(sites[src], site{call, data})
+			}
+		}
+	}
+
+sourceLoop:
+	for _, src := range sources {
+		for _, s := range sites[src] {
+			if s.data == nil {
+				// We can only prove a field to be missing if we know
+				// the data at every call site.
+				continue sourceLoop
+			}
+		}
+		text := constant.StringVal(src.Call.Args[1].(*ssa.Const).Value)
+		tmpl, err := texttemplate.New("").Parse(text)
+		if err != nil || tmpl.Tree == nil {
+			// Syntax errors are reported by CheckTemplate. Other
+			// errors, such as the use of custom functions, prevent
+			// us from analyzing the template.
+			continue
+		}
+		for _, ref := range templateRefs(tmpl.Tree) {
+			missing := -1
+			for _, s := range sites[src] {
+				idx := templateField(s.data, ref.names)
+				if idx == -1 {
+					missing = -1
+					break
+				}
+				if missing == -1 || idx < missing {
+					missing = idx
+				}
+			}
+			if missing == -1 {
+				continue
+			}
+
+			var pos lint.Positioner = src
+			if lit, ok := lits[src.Pos()]; ok {
+				pos = posNode(formatPos(lit, ref.offset))
+			}
+			field := strings.Join(ref.names[:missing+1], ".")
+			if ref.root == "$" {
+				field = "$." + field
+			} else {
+				field = "." + field
+			}
+			p := j.Errorf(pos, "template refers to %s, which doesn't exist on %s", field, sites[src][0].data)
+			for _, s := range sites[src] {
+				j.AddRelated(p, s.call, "template is executed here with data of type %s", s.data)
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package pkg
+
+import "sync/atomic"
+
+type T1 struct {
+	n int64
+	b bool
+	m int64
+	k uint64
+}
+
+type T2 struct {
+	b  bool
+	t1 T1
+}
+
+func fn(t1 *T1, t2 *T2) {
+	atomic.AddInt64(&t1.n, 1)
+	atomic.AddInt64(&t1.m, 1)    // MATCH "64-bit atomic operation on field m, which isn't 64-bit aligned on 32-bit platforms (offset 12); move it to the start of the struct"
+	atomic.LoadUint64(&t1.k)     // MATCH "on field k, which isn't 64-bit aligned on 32-bit platforms (offset 20)"
+	atomic.AddInt64(&t2.t1.n, 1) // MATCH "on field n, which isn't 64-bit aligned on 32-bit platforms (offset 4)"
+	atomic.CompareAndSwapUint64(&t2.t1.k, 0, 1)
+	atomic.AddInt32(new(int32), 1)
+}
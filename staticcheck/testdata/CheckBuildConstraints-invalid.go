@@ -0,0 +1,7 @@
+//go:build linux &&
+// +build !!linux
+
+package pkg
+
+// MATCH:1 "invalid //go:build line: unexpected end of expression"
+// MATCH:1 /invalid // \+build line: invalid term .!!linux./
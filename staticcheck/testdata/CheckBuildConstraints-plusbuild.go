@@ -0,0 +1,5 @@
+// +build 386,amd64 android,!linux
+
+package pkg
+
+// MATCH:1 /the build constraint .386,amd64 android,!linux. can never be satisfied/
@@ -0,0 +1,6 @@
+//go:build (linx || integraton) && !tools && unix && !windows
+
+package pkg
+
+// MATCH:1 /the build tag .linx. isn't used by any other file; did you mean .linux./
+// MATCH:1 /the build tag .integraton. isn't used by any other file; did you mean .integration./
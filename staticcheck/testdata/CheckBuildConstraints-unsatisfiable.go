@@ -0,0 +1,8 @@
+//go:build (linux || android) && windows && integration
+// +build linux android
+// +build windows
+// +build integration
+
+package pkg
+
+// MATCH:1 /the build constraint .\(linux \|\| android\) && windows && integration. can never be satisfied/
@@ -0,0 +1,6 @@
+//go:build linux && integration
+// +build linux darwin
+
+package pkg
+
+// MATCH:1 "the //go:build line and the // +build lines don't match"
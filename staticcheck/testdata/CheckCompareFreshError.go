@@ -0,0 +1,44 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errSentinel = errors.New("sentinel")
+
+func fn1(err error) {
+	if err == errors.New("sentinel") { // MATCH "comparison with an error created by errors.New is always false"
+		println()
+	}
+	if err != fmt.Errorf("code %d", 1) { // MATCH "comparison with an error created by fmt.Errorf is always true"
+		println()
+	}
+	if err == errSentinel {
+		println()
+	}
+}
+
+func isNotFound(err error) bool {
+	errNotFound := errors.New("not found")
+	return err == errNotFound // MATCH "is always false"
+}
+
+func fn2(err error) {
+	switch err {
+	case errors.New("a"): // MATCH "is always false"
+	case errSentinel:
+	}
+}
+
+func fn3(fail bool) error {
+	errFailed := errors.New("failed")
+	var err error
+	if fail {
+		err = errFailed
+	}
+	if err == errFailed {
+		return err
+	}
+	return nil
+}
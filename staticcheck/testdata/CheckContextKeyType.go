@@ -0,0 +1,45 @@
+package pkg
+
+import "context"
+
+type key int
+
+type userKey struct{}
+
+type large struct {
+	buf [256]byte
+}
+
+type small struct {
+	id   int
+	name string
+}
+
+func fn1(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, "request-id", id) // MATCH "should not use built-in type string as key for value"
+	ctx = context.WithValue(ctx, key(0), id)
+	ctx = context.WithValue(ctx, userKey{}, id)
+	return context.WithValue(ctx, 1, id) // MATCH "should not use built-in type int as key for value"
+}
+
+func fn2(ctx context.Context) string {
+	ctx = context.WithValue(ctx, "request-id", "") // MATCH "should not use built-in type string as key for value"
+	id, _ := ctx.Value("request-id").(string)
+	return id
+}
+
+func fn3(ctx context.Context, l large, s small, users []string) {
+	ctx = context.WithValue(ctx, userKey{}, l) // MATCH "storing a struct of 256 bytes in a context"
+	ctx = context.WithValue(ctx, userKey{}, &l)
+	ctx = context.WithValue(ctx, userKey{}, s)
+	for _, user := range users {
+		ctx := context.WithValue(ctx, userKey{}, &user) // MATCH "storing a pointer to the loop variable user in a context"
+		_ = ctx
+	}
+	for i := 0; i < len(users); i++ {
+		user := users[i]
+		ctx := context.WithValue(ctx, userKey{}, &user)
+		_ = ctx
+	}
+	_ = ctx
+}
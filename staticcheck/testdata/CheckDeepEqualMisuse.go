@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"time"
+)
+
+type Message struct {
+	Name string
+}
+
+func (*Message) Reset()         {}
+func (*Message) String() string { return "" }
+func (*Message) ProtoMessage()  {}
+
+type MyError struct{}
+
+func (MyError) Error() string { return "" }
+
+type T struct{}
+
+func (T) Equal(int) bool { return false }
+
+func fn() {
+	var err error
+	var t1, t2 time.Time
+	var ip1, ip2 net.IP
+	var m1, m2 *Message
+	var x, y map[string]int
+
+	reflect.DeepEqual(err, errors.New(""))  // MATCH "compare them with == instead"
+	reflect.DeepEqual(nil, err)             // MATCH "compare them with == instead"
+	reflect.DeepEqual(MyError{}, MyError{}) // MATCH "compare them with == instead"
+	reflect.DeepEqual(t1, t2)               // MATCH "type time.Time has an Equal method"
+	reflect.DeepEqual(ip1, ip2)             // MATCH "type net.IP has an Equal method"
+	reflect.DeepEqual(m1, m2)               // MATCH "use proto.Equal to compare values of type *Message"
+	reflect.DeepEqual(x, y)
+	reflect.DeepEqual(T{}, T{})
+	reflect.DeepEqual(nil, nil)
+	reflect.DeepEqual(interface{}(err), nil)
+}
@@ -0,0 +1,11 @@
+package pkg
+
+import (
+	"errors"
+	"reflect"
+)
+
+func fn2() {
+	var err error
+	reflect.DeepEqual(err, errors.New("")) // MATCH:go1.13 "use errors.Is instead"
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+type Inner struct {
+	A int32
+	S string
+}
+
+type Outer struct {
+	X  uint16
+	In [2]Inner
+}
+
+type Header struct {
+	Magic [4]byte
+	Len   uint
+}
+
+type Names struct {
+	M map[string]int32
+}
+
+func fn2() {
+	var buf bytes.Buffer
+	var h Header
+	var o Outer
+	var n Names
+	var hs []Header
+	var x int32
+	var y int
+	var ok struct{ A, B int64 }
+
+	binary.Write(&buf, binary.BigEndian, h)  // MATCH "field Len has type uint, which doesn't have a fixed size"
+	binary.Write(&buf, binary.BigEndian, &o) // MATCH "field In[i].S has type string"
+	binary.Write(&buf, binary.BigEndian, n)  // MATCH "field M has type map[string]int32"
+	binary.Read(&buf, binary.BigEndian, &h)  // MATCH "cannot be used with binary.Read: field Len"
+	binary.Read(&buf, binary.BigEndian, hs)  // MATCH "cannot be used with binary.Read: field Len"
+	binary.Read(&buf, binary.BigEndian, x)   // MATCH "binary.Read needs a pointer or a slice"
+	binary.Read(&buf, binary.BigEndian, &y)  // MATCH "value of type *int cannot be used with binary.Read"
+	binary.Read(&buf, binary.BigEndian, &x)
+	binary.Read(&buf, binary.BigEndian, &ok)
+	binary.Write(&buf, binary.BigEndian, ok)
+}
@@ -0,0 +1,31 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func fn(xs []int) error {
+	_ = fmt.Errorf("something went wrong") // MATCH "should use errors.New"
+	_ = fmt.Errorf("%d went wrong", 1)
+	_ = fmt.Errorf("100%% wrong")
+	_ = errors.New("something went wrong")
+
+	var errs []error
+	for _, x := range xs {
+		errs = append(errs, errors.New("negative")) // MATCH "constructed in every iteration of the loop"
+		errs = append(errs, fmt.Errorf("negative")) // MATCH "constructed in every iteration of the loop"
+		errs = append(errs, fmt.Errorf("negative: %d", x))
+		if x > 10 {
+			return errors.New("too large")
+		}
+		if x > 20 {
+			panic(errors.New("much too large"))
+		}
+		go func() {
+			_ = errors.New("in closure")
+		}()
+	}
+	println(errs)
+	return nil
+}
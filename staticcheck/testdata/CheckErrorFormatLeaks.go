@@ -0,0 +1,40 @@
+package pkg
+
+import "fmt"
+
+type Credentials struct {
+	User     string
+	Password string
+}
+
+type Request struct {
+	URL  string
+	Auth Credentials
+}
+
+type Options struct {
+	Name         string
+	PasswordFile string
+	Creds        *Credentials
+}
+
+type Secret struct {
+	Token string
+}
+
+func (Secret) String() string { return "<secret>" }
+
+func fn(n *int, c Credentials, pc *Credentials, r Request, o Options, s Secret, ps *Secret, err error) {
+	_ = fmt.Errorf("bad value %v", n) // MATCH "prints the memory address of arg #1 of type *int"
+	_ = fmt.Errorf("bad value %d", n)
+	_ = fmt.Errorf("bad value %v", *n)
+	_ = fmt.Errorf("login failed: %v", c)   // MATCH "including Password"
+	_ = fmt.Errorf("login failed: %+v", pc) // MATCH "including Password"
+	_ = fmt.Errorf("request failed: %s", r) // MATCH "including Auth.Password"
+	_ = fmt.Errorf("login failed for %s", c.User)
+	_ = fmt.Errorf("bad options: %v", o)
+	_ = fmt.Errorf("bad secret: %v", s)
+	_ = fmt.Errorf("bad secret: %v", ps)
+	_ = fmt.Errorf("failed: %v", err)
+	_ = fmt.Errorf("bad value %p", n)
+}
@@ -0,0 +1,11 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+)
+
+func fn(err error) {
+	err = fmt.Errorf("opening config: %w", err)
+	_ = os.IsNotExist(err) // MATCH "use errors.Is(err, os.ErrNotExist) instead"
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+func open(name string) error {
+	_, err := os.Open(name)
+	return err
+}
+
+func fn(cond bool) {
+	err := open("foo")
+	_ = os.IsNotExist(err)
+
+	err = fmt.Errorf("opening config: %w", err)
+	_ = os.IsNotExist(err) // MATCH "os.IsNotExist doesn't unwrap errors, but the error may wrap another one; use errors.Is(err, fs.ErrNotExist) instead"
+	_ = errors.Is(err, os.ErrNotExist)
+
+	err2 := open("bar")
+	if cond {
+		err2 = fmt.Errorf("opening bar: %w", err2)
+	}
+	_ = os.IsPermission(err2) // MATCH "use errors.Is(err, fs.ErrPermission) instead"
+
+	err3 := fmt.Errorf("opening baz: %v", open("baz"))
+	_ = os.IsExist(err3)
+
+	err4 := errors.Join(open("a"), open("b"))
+	_ = os.IsExist(err4) // MATCH "use errors.Is(err, fs.ErrExist) instead"
+}
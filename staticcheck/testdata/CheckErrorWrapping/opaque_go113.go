@@ -0,0 +1,13 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+func fn() {
+	err := errors.New("")
+	_ = fmt.Errorf("foo: %w", err) // MATCH /must not expose wrapped errors; should format the error with %v instead of %w/
+	_ = fmt.Errorf("foo: %v", err)
+	_ = fmt.Errorf("foo: %s", err)
+}
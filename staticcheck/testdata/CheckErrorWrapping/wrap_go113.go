@@ -0,0 +1,33 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type myError struct{}
+
+func (*myError) Error() string { return "" }
+
+func fn() {
+	err := errors.New("")
+	var perr *os.PathError
+	var merr *myError
+	var s string
+
+	_ = fmt.Errorf("foo: %v", err)       // MATCH /should wrap the error with %w instead of formatting it with %v/
+	_ = fmt.Errorf("foo: %s", err)       // MATCH /should wrap the error with %w instead of formatting it with %s/
+	_ = fmt.Errorf("foo: %[1]v", err)    // MATCH /should wrap the error/
+	_ = fmt.Errorf("foo %s: %v", s, err) // MATCH /should wrap the error/
+	_ = fmt.Errorf("foo: %v", perr)      // MATCH /should wrap the error/
+	_ = fmt.Errorf("foo: %v", merr)      // MATCH /should wrap the error/
+	_ = fmt.Errorf("foo: %w", err)
+	_ = fmt.Errorf("foo: %q", err)
+	_ = fmt.Errorf("foo: %v", s)
+	_ = fmt.Errorf("foo: %d", 1)
+	_ = fmt.Errorf("foo: %*v", 1, err) // MATCH /should wrap the error/
+	_ = fmt.Errorf(s, err)
+	_ = fmt.Errorf("foo: %w, %v", err, err) // MATCH:go1.20 /should wrap the error/
+	_ = fmt.Sprintf("foo: %v", err)
+}
@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+func fn1() {
+	cmd := exec.Command("true")
+	cmd.Run()  // RELATED "waited here"
+	cmd.Wait() // MATCH "Wait called after Run, which already waited for the command to exit"
+}
+
+func fn2() {
+	cmd := exec.Command("true")
+	cmd.CombinedOutput() // RELATED "command started here"
+	cmd.Start()          // MATCH "exec.Cmd can't be reused: the command was already started by CombinedOutput"
+}
+
+func fn3() {
+	cmd := exec.Command("true")
+	cmd.Start()
+	cmd.Stdout = os.Stdout // MATCH "setting Stdout after the command was started has no effect"
+	cmd.Wait()
+}
+
+func fn4() {
+	cmd := exec.Command("true")
+	r, _ := cmd.StdoutPipe()
+	cmd.Start()
+	cmd.Wait()            // RELATED "pipe closed here"
+	io.Copy(os.Stdout, r) // MATCH "reading from the pipe returned by StdoutPipe after Wait, which closes the pipe"
+}
+
+func fn5() {
+	cmd := exec.Command("true")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	r, _ := cmd.StderrPipe()
+	cmd.Start()
+	r.Read(nil)
+	cmd.Wait()
+}
+
+func fn6(b bool) {
+	cmd := exec.Command("true")
+	if b {
+		cmd.Run()
+	} else {
+		cmd.Start()
+		cmd.Wait()
+	}
+}
+
+func fn7() {
+	cmd := exec.Command("true")
+	r, _ := cmd.StdoutPipe()
+	cmd.Start()
+	cmd.Wait()  // RELATED "pipe closed here"
+	r.Read(nil) // MATCH "reading from the pipe returned by StdoutPipe after Wait"
+}
+
+func fn8() {
+	cmd := exec.Command("true")
+	cmd.Start()
+	cmd.Wait()
+	cmd.Wait() // MATCH "Wait called after Wait"
+}
@@ -0,0 +1,33 @@
+package pkg
+
+import "net/http"
+
+//lint:exhaustive-fields
+type Options struct {
+	Name    string
+	Retries int
+	verbose bool
+	_       struct{}
+}
+
+type Other struct {
+	A, B int
+}
+
+type (
+	//lint:exhaustive-fields
+	Grouped struct {
+		X, Y int
+	}
+)
+
+func fn() {
+	_ = Options{Name: "foo"} // MATCH "doesn't set fields Retries, verbose"
+	_ = Options{Name: "foo", Retries: 1, verbose: true}
+	_ = &Options{Retries: 1, Name: "", verbose: false}
+	_ = Options{}
+	_ = []Options{{Name: "foo", Retries: 1}} // MATCH "doesn't set field verbose"
+	_ = Other{A: 1}
+	_ = Grouped{X: 1} // MATCH "composite literal of Grouped doesn't set field Y"
+	_ = http.Server{Addr: ""}
+}
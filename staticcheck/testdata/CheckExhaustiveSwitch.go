@@ -0,0 +1,59 @@
+package pkg
+
+import "go/token"
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+	Crimson = Red
+)
+
+type Kind string
+
+const (
+	KindA Kind = "a"
+	KindB Kind = "b"
+)
+
+type Plain int
+
+func fn(c Color, k Kind, p Plain, tok token.Token, x int) {
+	switch c { // MATCH "switch on Color is missing case Blue and has no default case"
+	case Red, Green:
+	}
+
+	switch c {
+	case Crimson, Green, Blue:
+	}
+
+	switch c {
+	case Red:
+	default:
+	}
+
+	switch k { // MATCH "missing cases KindA, KindB"
+	}
+
+	switch c {
+	case Color(x):
+	}
+
+	switch p {
+	case 1:
+	}
+
+	switch x {
+	case 1:
+	}
+
+	switch {
+	case c == Red:
+	}
+
+	switch tok { // MATCH /switch on token.Token is missing cases ILLEGAL, EOF, COMMENT, IDENT, INT and [0-9]+ more/
+	case token.ADD:
+	}
+}
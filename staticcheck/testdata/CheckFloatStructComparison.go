@@ -0,0 +1,39 @@
+package pkg
+
+type T1 struct {
+	A int
+	B float64
+}
+
+type T2 struct {
+	A int
+	B string
+}
+
+type T3 struct {
+	P *float64
+	N T1
+	C [2]complex64
+}
+
+type T4 struct {
+	X [3]float32
+}
+
+func fn() {
+	var a, b T1
+	_ = a == b // MATCH "also compares the floating-point field B"
+	_ = a != b // MATCH "also compares the floating-point field B"
+
+	var c, d T2
+	_ = c == d
+
+	var e, f T3
+	_ = e == f // MATCH "also compares the floating-point field N.B"
+
+	var g, h T4
+	_ = g == h // MATCH "also compares the floating-point field X[i]"
+
+	var i, k [2]float64
+	_ = i == k
+}
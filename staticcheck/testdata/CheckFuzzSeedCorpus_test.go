@@ -0,0 +1,10 @@
+package pkg
+
+import "testing"
+
+// Fuzzing requires Go 1.18.
+func FuzzNoCorpus(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte, unused int) {
+		f.Fatal()
+	})
+}
@@ -0,0 +1,30 @@
+package pkg
+
+//go:generate stringer -type=T
+//go:generate go run golang.org/x/tools/cmd/stringer -type=T
+//go:generate go run github.com/other/gen -out x.go
+//go:generate go run github.com/other/gen@v1.2.3 -out x.go
+//go:generate go run -mod=mod ./internal/gen
+//go:generate mockgen -source=generate.go
+//go:generate gen -v
+//go:generate go tool enumer -type=T
+//go:generate go tool stringer -type=T
+//go:generate go tool cover -h
+//go:generate sh -c "echo \"generated\" > x.txt"
+//go:generate echo "unterminated
+//go:generate echo "a"b
+//go:generate -command protogen protoc --go_out=.
+//go:generate protogen x.proto
+//go:generate -command ownrun go run example.com/gen/internal/run
+//go:generate ownrun -x
+//go:generate ./scripts/gen.sh
+//go:generate $GOROOT/bin/go vet
+
+type T int
+
+// MATCH:3 "command stringer isn't declared as a tool in go.mod or imported in tools.go"
+// MATCH:5 "go run github.com/other/gen refers to a package that go.mod doesn't require"
+// MATCH:11 "go tool stringer refers to a tool that go.mod doesn't declare"
+// MATCH:14 "malformed //go:generate directive: unterminated quoted string"
+// MATCH:15 /malformed //go:generate directive: quoted string b isn't followed by a space/
+// MATCH:16 "command protoc isn't declared"
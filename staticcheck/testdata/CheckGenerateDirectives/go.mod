@@ -0,0 +1,10 @@
+module example.com/gen
+
+go 1.24
+
+require (
+	golang.org/x/tools v0.1.0 // indirect
+	github.com/golang/mock v1.6.0
+)
+
+tool example.com/cmd/enumer
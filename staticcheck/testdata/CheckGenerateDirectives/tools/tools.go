@@ -0,0 +1,8 @@
+//go:build tools
+
+package tools
+
+import (
+	_ "github.com/golang/mock/mockgen"
+	_ "github.com/example/gen/v2"
+)
@@ -0,0 +1,57 @@
+package pkg
+
+import "sync"
+
+type Config struct {
+	Addr string
+}
+
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+var (
+	registry = map[string]int{} // MATCH "package-level variable registry of mutable type map[string]int is modified outside of init"
+	handlers []func()           // MATCH "package-level variable handlers of mutable type"
+	config   Config             // MATCH "package-level variable config of mutable type Config"
+	current  *Config            // MATCH "package-level variable current of mutable type *Config"
+	defaults = map[string]int{}
+	hits     counter
+	total    int
+)
+
+func init() {
+	defaults["a"] = 1
+	config.Addr = ":8080"
+	handlers = append(handlers, func() {
+		registry["b"]++ // RELATED "registry is modified here"
+	})
+}
+
+func Register(name string, fn func()) {
+	registry[name] = len(handlers)  // RELATED "registry is modified here"
+	handlers = append(handlers, fn) // RELATED "handlers is modified here"
+}
+
+func Unregister(name string) {
+	delete(registry, name) // RELATED "registry is modified here"
+}
+
+func SetAddr(addr string) {
+	config.Addr = addr     // RELATED "config is modified here"
+	(*current).Addr = addr // RELATED "current is modified here"
+}
+
+func Hit() {
+	hits.mu.Lock()
+	hits.n++
+	hits.mu.Unlock()
+	total++
+}
+
+func Lookup(name string) int {
+	registry := map[string]int{}
+	registry[name] = 1
+	return defaults[name] + registry[name]
+}
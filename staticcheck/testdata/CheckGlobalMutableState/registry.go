@@ -0,0 +1,14 @@
+package pkg
+
+var (
+	drivers = map[string]int{}
+	plugins = map[string]int{} // MATCH "package-level variable plugins of mutable type"
+)
+
+func RegisterDriver(name string) {
+	drivers[name] = len(drivers)
+}
+
+func RegisterPlugin(name string) {
+	plugins[name] = len(plugins)
+}
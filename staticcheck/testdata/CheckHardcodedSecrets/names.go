@@ -0,0 +1,6 @@
+package pkg
+
+var pin = "73914" // MATCH "pin is set to a hard-coded secret"
+
+//lint:ignore SA7004 the default password of the local development database
+var devPassword = "postgres"
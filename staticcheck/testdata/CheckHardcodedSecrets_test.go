@@ -0,0 +1,3 @@
+package pkg
+
+const testPassword = "hunter22"
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"flag"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+)
+
+var (
+	tmpl   *template.Template
+	rx     *regexp.Regexp
+	config []byte
+)
+
+func init() {
+	if _, err := os.Stat("/etc/pkg.conf"); err != nil { // MATCH "init function calls os.Stat, which does file I/O"
+		panic(err) // MATCH "init function panics on error"
+	}
+	os.Setenv("TZ", "UTC") // MATCH "init function calls os.Setenv, which modifies the environment"
+	flag.Parse()           // MATCH "init function calls flag.Parse, which parses the command line"
+}
+
+func init() {
+	resp, err := http.Get("https://example.com/config") // MATCH "init function calls net/http.Get, which accesses the network"
+	if err != nil {
+		log.Fatal(err) // MATCH "init function calls log.Fatal, which exits the program"
+	}
+	resp.Body.Close()
+}
+
+func init() {
+	b, err := ioutil.ReadFile(os.Getenv("PKG_CONFIG")) // MATCH "init function calls io/ioutil.ReadFile, which does file I/O"
+	if err != nil {
+		panic("no configuration")
+	}
+	config = b
+	rx = regexp.MustCompile(string(b)) // MATCH "init function calls regexp.MustCompile, which panics on error"
+}
+
+func init() {
+	tmpl = template.Must(template.New("").Parse(`{{.}}`))
+	rx = regexp.MustCompile(`^[a-z]+$`)
+	go func() {
+		http.ListenAndServe(":8080", nil)
+	}()
+}
+
+func setup() error {
+	_, err := os.Stat("/etc/pkg.conf")
+	return err
+}
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"os"
+	"regexp"
+)
+
+var (
+	rx     *regexp.Regexp
+	config []byte
+)
+
+func MustLoad(path string) []byte {
+	b, err := readFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func readFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func init() {
+	rx = regexp.MustCompile(os.Args[0]) // MATCH "init function calls regexp.MustCompile, which panics on error"
+	config = MustLoad(os.Args[0])
+}
@@ -0,0 +1,9 @@
+package pkg
+
+import (
+	"os"
+	"unsafe"
+)
+
+var _ = unsafe.Sizeof(0)
+var _ = os.Exit
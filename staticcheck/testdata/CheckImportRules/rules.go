@@ -0,0 +1,18 @@
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"net/rpc"         // MATCH "importing net/rpc is forbidden by the configuration"
+	"net/rpc/jsonrpc" // MATCH "importing net/rpc/jsonrpc is forbidden by the configuration"
+	_ "os"            // MATCH "importing os isn't allowed by the configuration"
+	str "strings"     // MATCH "importing strings isn't allowed by the configuration"
+	"unsafe"          // MATCH "importing unsafe is forbidden by the configuration: unsafe code needs a review"
+)
+
+var _ = fmt.Sprint
+var _ = http.Get
+var _ = rpc.Dial
+var _ = jsonrpc.Dial
+var _ = str.Repeat
+var _ = unsafe.Sizeof(0)
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"database/sql"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type T struct {
+	mu    sync.Mutex
+	rw    sync.RWMutex
+	db    *sql.DB
+	conn  net.Conn
+	cache map[string]string
+	ch    chan int
+	sync.Mutex
+}
+
+func (t *T) fn1() {
+	t.mu.Lock()             // MATCH "t.mu is held across blocking operations"
+	time.Sleep(time.Second) // RELATED "blocking call to time.Sleep while t.mu is held"
+	t.mu.Unlock()
+}
+
+func (t *T) fn2() {
+	t.mu.Lock() // MATCH "t.mu is held across blocking operations"
+	defer t.mu.Unlock()
+	t.cache["x"] = "y"
+	if _, err := t.db.Query("SELECT 1"); err != nil { // RELATED "blocking call to (*database/sql.DB).Query"
+		return
+	}
+	http.Get("https://example.com") // RELATED "blocking call to net/http.Get"
+}
+
+func (t *T) fn3() {
+	t.mu.Lock()
+	t.cache["x"] = "y"
+	t.mu.Unlock()
+	time.Sleep(time.Second)
+}
+
+func (t *T) fn4(b []byte) {
+	t.rw.RLock()    // MATCH "t.rw is held across blocking operations"
+	t.conn.Write(b) // RELATED "blocking call to (net.Conn).Write"
+	t.rw.RUnlock()
+
+	t.rw.RLock()
+	_ = t.cache["x"]
+	t.rw.RUnlock()
+	t.conn.Read(b)
+}
+
+func (t *T) fn5() {
+	t.Lock()  // MATCH "t is held across blocking operations"
+	t.ch <- 1 // RELATED "blocking channel send while t is held"
+	<-t.ch    // RELATED "blocking channel receive"
+	t.Unlock()
+}
+
+func (t *T) fn6() {
+	t.mu.Lock()
+	select {
+	case t.ch <- 1:
+	default:
+	}
+	go func() {
+		time.Sleep(time.Second)
+	}()
+	t.mu.Unlock()
+
+	t.mu.Lock() // MATCH "t.mu is held across blocking operations"
+	select {    // RELATED "blocking select"
+	case v := <-t.ch:
+		_ = v
+	case <-time.After(time.Second):
+	}
+	t.mu.Unlock()
+}
+
+func (t *T) fn7(ok bool) {
+	t.mu.Lock()
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	time.Sleep(time.Second)
+	t.mu.Unlock()
+}
+
+func (t *T) fn8() {
+	t.mu.Lock()           // MATCH "t.mu is held across blocking operations"
+	for v := range t.ch { // RELATED "blocking range over a channel"
+		_ = v
+	}
+	t.mu.Unlock()
+	var h http.Header
+	t.mu.Lock()
+	_ = h.Get("Content-Type")
+	_ = net.ParseIP("127.0.0.1")
+	t.mu.Unlock()
+}
+
+func (t *T) fn9(v int) {
+	ch := make(chan int, 1)
+	t.mu.Lock()
+	ch <- v
+	t.mu.Unlock()
+
+	const n = 4
+	var results = make(chan int, n)
+	t.mu.Lock()
+	results <- v
+	t.mu.Unlock()
+
+	unbuffered := make(chan int)
+	t.mu.Lock()     // MATCH "t.mu is held across blocking operations"
+	unbuffered <- v // RELATED "blocking channel send while t.mu is held"
+	t.mu.Unlock()
+
+	zero := make(chan int, 0)
+	t.mu.Lock() // MATCH "t.mu is held across blocking operations"
+	zero <- v   // RELATED "blocking channel send while t.mu is held"
+	t.mu.Unlock()
+
+	reassigned := make(chan int, 1)
+	if v > 0 {
+		reassigned = t.ch
+	}
+	t.mu.Lock()     // MATCH "t.mu is held across blocking operations"
+	reassigned <- v // RELATED "blocking channel send while t.mu is held"
+	t.mu.Unlock()
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import "fmt"
+
+type S struct{ a, b int }
+
+func fn(xs []int, s S, n int, p *S, e error) {
+	for _, x := range xs {
+		fmt.Println(s)     // MATCH "s is converted to an interface in every iteration"
+		fmt.Println(n, x)  // MATCH "n is converted to an interface in every iteration"
+		_ = interface{}(s) // MATCH "s is converted to an interface in every iteration"
+
+		fmt.Println(p)
+		fmt.Println(e)
+		fmt.Println(1)
+		fmt.Println(struct{}{})
+	}
+	for range xs {
+		n++
+		fmt.Println(n)
+	}
+	fmt.Println(s)
+}
+
+func fn2(xs []int, s S) error {
+	for _, x := range xs {
+		if x < 0 {
+			return fmt.Errorf("negative value in %v", s)
+		}
+		if x > 100 {
+			panic(fmt.Sprint("value too large in ", s))
+		}
+		fmt.Println(s) // MATCH "s is converted to an interface in every iteration"
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package pkg
+
+import "bytes"
+
+func fn(s string, ss []string) {
+	for range ss {
+		_ = []byte(s)       // MATCH "converting the loop-invariant string s to a byte slice"
+		_ = []byte("hello") // MATCH "converting the loop-invariant string "hello" to a byte slice"
+	}
+	for _, x := range ss {
+		_ = []byte(x)
+	}
+	for range ss {
+		s += "x"
+		_ = []byte(s)
+	}
+	_ = []byte(s)
+}
+
+func fn2(s string, bs [][]byte) []byte {
+	for _, b := range bs {
+		if len(b) == 0 {
+			return []byte(s)
+		}
+		if len(b) > 100 {
+			panic(bytes.NewBuffer([]byte(s)))
+		}
+		_ = append(b, []byte(s)...) // MATCH "converting the loop-invariant string s to a byte slice"
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package pkg
+
+type T struct{ p *int }
+
+func fn(xs []int, ch chan *int) {
+	var ptrs []*int
+	var last *int
+	var t T
+	m := map[int]*int{}
+	for _, x := range xs {
+		ptrs = append(ptrs, &x) // MATCH "all iterations share x"
+		ch <- &x                // MATCH "all iterations share x"
+		last = &x               // MATCH "all iterations share x"
+		t.p = &x                // MATCH "all iterations share x"
+		m[x] = &x               // MATCH "all iterations share x"
+
+		p := &x
+		println(*p)
+		y := x
+		ptrs = append(ptrs, &y)
+	}
+	for i := 0; i < len(xs); i++ {
+		ptrs = append(ptrs, &i) // MATCH "all iterations share i"
+	}
+	for i := range xs {
+		ptrs = append(ptrs, &xs[i])
+	}
+	for _, x := range xs {
+		go func() {
+			ptrs = append(ptrs, &x)
+		}()
+	}
+	println(ptrs, last, t.p, m)
+}
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+func fn1() {
+	ctx, _ := context.WithCancel(context.Background()) // MATCH "is discarded"
+	_ = ctx
+}
+
+func fn2() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = ctx
+}
+
+func fn3() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, cancel = context.WithTimeout(ctx, time.Second) // MATCH "is never called"
+	_ = ctx
+	_ = cancel
+}
+
+func fn4(b bool) error {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now()) // MATCH "not called on all paths"
+	if b {
+		return nil // RELATED "may be reached without calling cancel"
+	}
+	_ = ctx
+	cancel()
+	return nil
+}
+
+func fn5(b bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if b {
+		cancel()
+		return
+	}
+	_ = ctx
+	cancel()
+}
+
+func fn6() (context.Context, context.CancelFunc) {
+	return context.WithCancel(context.Background())
+}
+
+func fn7() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return ctx, cancel
+}
+
+type T struct {
+	cancel context.CancelFunc
+}
+
+func (t *T) fn8() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	return ctx
+}
+
+func fn9() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		_ = ctx
+	}()
+}
+
+func fn10() {
+	ctx, cancel := context.WithCancel(context.Background()) // MATCH "is never called"
+	_ = cancel
+	_ = ctx
+}
+
+func fn11() {
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		_ = ctx
+		cancel()
+	}
+}
+
+func fn12(b bool) {
+	ctx, cancel := context.WithCancel(context.Background()) // MATCH "not called on all paths"
+	_ = ctx
+	if b {
+		cancel()
+	}
+} // RELATED "may be reached"
+
+func fn13() {
+	context.WithCancel(context.Background()) // MATCH "is discarded"
+}
@@ -0,0 +1,68 @@
+package pkg
+
+type T struct {
+	A int
+	B struct {
+		C int
+	}
+}
+
+func fn1(m map[string]T) {
+	v := m["foo"]
+	v.A = 1 // MATCH "modifies a copy of a map entry"
+}
+
+func fn2(m map[string]T) {
+	v := m["foo"]
+	v.A = 1
+	m["foo"] = v
+}
+
+func fn3(m map[string]T) {
+	if v, ok := m["foo"]; ok {
+		v.B.C = 1 // MATCH "modifies a copy of a map entry"
+	}
+}
+
+func fn4(m map[string]T) {
+	for _, v := range m {
+		v.A++ // MATCH "modifies a copy of a map entry"
+	}
+}
+
+func fn5(m map[string]T) int {
+	v := m["foo"]
+	v.A = 1
+	return v.A
+}
+
+func fn6(m map[string]T) T {
+	v := m["foo"]
+	v.A = 1
+	return v
+}
+
+func fn7(m map[string]*T) {
+	v := m["foo"]
+	v.A = 1
+}
+
+func fn8(s []T) {
+	v := s[0]
+	v.A = 1
+}
+
+func fn9(m map[string]T) {
+	for k, v := range m {
+		v.A = 1
+		m[k] = v
+	}
+}
+
+func fn10(m map[string]T) {
+	v := m["foo"]
+	v.A = 1
+	fn11(&v)
+}
+
+func fn11(*T) {}
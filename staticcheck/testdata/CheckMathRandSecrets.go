@@ -0,0 +1,55 @@
+//lint:file-ignore SA1019 rand.Read is deprecated in newer versions of Go
+
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+)
+
+type config struct {
+	apiKey []byte
+	data   []byte
+}
+
+func fn1(r *rand.Rand) {
+	key := make([]byte, 32)
+	rand.Read(key) // MATCH "should use crypto/rand.Read"
+
+	var sessionID [16]byte
+	r.Read(sessionID[:]) // MATCH "should use crypto/rand.Read"
+
+	var cfg config
+	rand.Read(cfg.apiKey) // MATCH "should use crypto/rand.Read"
+	rand.Read(cfg.data)
+
+	b := make([]byte, 16)
+	rand.Read(b) // MATCH "should use crypto/rand.Read"
+	aes.NewCipher(b)
+
+	m := make([]byte, 16)
+	rand.Read(m) // MATCH "should use crypto/rand.Read"
+	hmac.New(sha256.New, m)
+
+	monkey := make([]byte, 16)
+	rand.Read(monkey)
+
+	noise := make([]byte, 16)
+	rand.Read(noise)
+	_ = hex.EncodeToString(noise)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b) // MATCH "should use crypto/rand.Read"
+	return hex.EncodeToString(b)
+}
+
+func keyboardLayout() []byte {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return b
+}
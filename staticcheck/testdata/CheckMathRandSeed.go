@@ -0,0 +1,18 @@
+package pkg
+
+import (
+	"math/rand"
+	"time"
+)
+
+func fn(seed int64) {
+	rand.Seed(1)                        // MATCH "seeding math/rand with a constant"
+	rand.Seed(int64(42))                // MATCH "seeding math/rand with a constant"
+	rand.Seed(time.Now().Unix())        // MATCH "seeding math/rand with the current time in seconds"
+	rand.Seed(int64(time.Now().Unix())) // MATCH "the current time in seconds"
+	rand.Seed(time.Now().UnixNano())
+	rand.Seed(seed)
+	t := time.Now()
+	rand.Seed(t.Unix())
+	rand.New(rand.NewSource(1))
+}
@@ -0,0 +1,7 @@
+package pkg
+
+import "math/rand"
+
+func fn2() {
+	rand.Seed(1)
+}
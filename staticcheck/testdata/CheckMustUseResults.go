@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+)
+
+type key struct{}
+
+var n int
+
+// next returns the next ID.
+//
+//lint:must-use
+func next() int {
+	n++
+	return n
+}
+
+// peek returns the next ID without consuming it.
+//
+//lint:must-use peeking has no other effect
+func peek() int {
+	return n + 1
+}
+
+// skip skips an ID.
+//
+//lint:must-user
+func skip() int {
+	n++
+	return n
+}
+
+func bump() int {
+	n++
+	return n
+}
+
+func fn(ctx context.Context, req *http.Request) {
+	context.WithValue(ctx, key{}, 1) // MATCH "the result of context.WithValue must be used"
+	req.WithContext(ctx)             // MATCH "the result of req.WithContext must be used"
+	next()                           // MATCH "the result of next must be used"
+	_ = next()
+	peek() // MATCH "the result of peek must be used"
+	skip()
+	_ = context.WithValue(ctx, key{}, 1)
+	bump()
+	go next()
+	ctx = context.WithValue(ctx, key{}, 1)
+	_ = ctx
+}
@@ -0,0 +1,23 @@
+package pkg
+
+type Builder struct{ where []string }
+
+func (b *Builder) Where(cond string) *Builder {
+	b.where = append(b.where, cond)
+	return &Builder{where: b.where}
+}
+
+func (b *Builder) Reset() *Builder {
+	b.where = nil
+	return b
+}
+
+func NewBuilder() *Builder { return &Builder{} }
+
+func fn() {
+	b := NewBuilder()
+	b.Where("a = 1") // MATCH "the result of b.Where must be used"
+	b.Reset()
+	b = b.Where("b = 2")
+	_ = b
+}
@@ -0,0 +1,68 @@
+package pkg
+
+func fn1() {
+	var ch chan int // RELATED "channel is nil here"
+	ch <- 1         // MATCH "send to nil channel blocks forever"
+}
+
+func fn2(b bool) int {
+	var ch chan int // RELATED "channel is nil here"
+	if b {
+		ch = make(chan int, 1)
+	}
+	return <-ch // MATCH "receive from channel that is nil on some paths"
+}
+
+func fn3(b bool) {
+	ch := make(chan int)
+	if b {
+		ch = nil // RELATED "channel is nil here"
+	}
+	ch <- 1 // MATCH "send to channel that is nil on some paths"
+}
+
+func fn4(b bool) int {
+	var ch chan int
+	if b {
+		ch = make(chan int, 1)
+	}
+	if ch != nil {
+		return <-ch
+	}
+	return 0
+}
+
+func fn5(b bool) int {
+	var ch chan int
+	if b {
+		ch = make(chan int, 1)
+	}
+	if ch == nil {
+		return 0
+	}
+	return <-ch
+}
+
+func fn6(done chan struct{}) int {
+	var ch chan int
+	select {
+	case v := <-ch:
+		return v
+	case <-done:
+		return 0
+	}
+}
+
+func fn7() {
+	ch := make(chan int, 1)
+	ch <- 1
+	<-ch
+}
+
+func fn8(b bool) {
+	var ch chan int // RELATED "channel is nil here"
+	if b {
+		ch = nil // RELATED "channel is nil here"
+	}
+	<-ch // MATCH "receive from nil channel blocks forever"
+}
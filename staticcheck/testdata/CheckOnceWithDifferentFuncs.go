@@ -0,0 +1,38 @@
+package pkg
+
+import "sync"
+
+var once sync.Once
+
+func setup()  {}
+func setup2() {}
+
+func fn1() {
+	once.Do(setup) // RELATED "once is used with a different function here"
+}
+
+func fn2() {
+	once.Do(setup)
+	once.Do(setup2) // MATCH "once is used with different functions; only the function of the first call to Do runs"
+}
+
+type T struct {
+	once sync.Once
+	v    int
+}
+
+func (t *T) init() { t.v = 1 }
+
+func (t *T) fn3() {
+	t.once.Do(t.init) // RELATED "once is used with a different function here"
+	t.once.Do(t.init)
+}
+
+func (t *T) fn4() {
+	t.once.Do(func() { t.v = 2 }) // MATCH "once is used with different functions"
+}
+
+var once2 sync.Once
+
+func fn6() { once2.Do(setup) }
+func fn7() { once2.Do(setup) }
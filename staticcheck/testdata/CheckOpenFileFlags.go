@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"os"
+	"syscall"
+)
+
+func fn() {
+	os.OpenFile("", os.O_RDONLY, 0)
+	os.OpenFile("", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	os.OpenFile("", os.O_RDWR|os.O_APPEND, 0)
+	os.OpenFile("", os.O_WRONLY|os.O_RDWR, 0)  // MATCH "O_WRONLY and O_RDWR are mutually exclusive"
+	os.OpenFile("", os.O_WRONLY|os.O_EXCL, 0)  // MATCH "O_EXCL has no defined meaning without O_CREATE"
+	os.OpenFile("", os.O_RDONLY|os.O_TRUNC, 0) // MATCH "O_TRUNC requires write access"
+	os.OpenFile("", os.O_APPEND, 0)            // MATCH "appending to it with O_APPEND"
+	os.OpenFile("", os.O_CREATE, 0644)
+	os.OpenFile("", os.O_RDONLY|os.O_CREATE, 0600)
+	os.OpenFile("", syscall.O_CREAT|syscall.O_RDWR, 0644)
+	os.OpenFile("", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+
+	flags := os.O_RDONLY
+	os.OpenFile("", flags|os.O_APPEND, 0)
+}
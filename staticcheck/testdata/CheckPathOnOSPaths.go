@@ -0,0 +1,37 @@
+package pkg
+
+import (
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var (
+	fOutDir = flag.String("out-dir", "", "")
+	fName   = flag.String("name", "", "")
+	fConfig string
+)
+
+func init() {
+	flag.StringVar(&fConfig, "configPath", "", "")
+}
+
+func fn1() {
+	wd, _ := os.Getwd()
+	_ = path.Join(wd, "foo")        // MATCH "comes from os.Getwd"
+	_ = path.Join("foo", wd+"/bar") // MATCH "use filepath.Join instead"
+	_ = path.Dir(*fOutDir)          // MATCH /comes from the flag "out-dir"/
+	_ = path.Base(fConfig)          // MATCH /comes from the flag "configPath"/
+	_ = path.Join(*fName, "foo")
+	_ = path.Join("a", "b")
+	_ = path.Dir(filepath.Join("a", "b")) // MATCH "comes from path/filepath.Join"
+	_ = path.Dir(filepath.ToSlash(wd))
+	_ = filepath.Join(wd, "foo")
+
+	home := os.TempDir()
+	if len(home) == 0 {
+		home = "/"
+	}
+	_ = path.Clean(home) // MATCH "comes from os.TempDir"
+}
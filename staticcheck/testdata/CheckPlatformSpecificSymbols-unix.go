@@ -0,0 +1,11 @@
+//go:build unix && !plan9
+
+package pkg
+
+import "syscall"
+
+func fn() {
+	_ = syscall.Getpid()
+	_ = syscall.O_DIRECT // MATCH "syscall.O_DIRECT is not available on darwin"
+	_ = syscall.Stdin
+}
@@ -0,0 +1,11 @@
+// +build linux darwin
+
+package pkg
+
+import "syscall"
+
+func fn() {
+	_ = syscall.O_RDONLY
+	_ = syscall.O_DIRECT // MATCH "syscall.O_DIRECT is not available on darwin"
+	_ = syscall.Getpid()
+}
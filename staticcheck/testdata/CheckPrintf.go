@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+func fn() {
+	var i int
+	var s string
+	var ch chan int
+	f := func() int { return 0 }
+
+	fmt.Printf("%d %s", i, s)
+	fmt.Printf("%[2]d %[1]s", s, i)
+	fmt.Printf("%[3]d", i, s) // MATCH "Printf format %[3]d reads arg #3, but call has only 2 args"
+	fmt.Printf("%d %d", i)    // MATCH "Printf format %d reads arg #2, but call has only 1 arg"
+	fmt.Printf("%*d", i, i)
+	fmt.Printf("%*d", s, i)    // MATCH "Printf format %*d uses arg #1 of type string as width or precision"
+	fmt.Printf("%.*f", 2, 1.0) // untyped constant
+	fmt.Printf("%[2]*[1]d", i, i)
+	fmt.Printf("%[0]d", i) // MATCH "Printf format %[0] has invalid argument index [0]"
+	fmt.Printf("%[1d", i)  // MATCH "Printf format %[1d has unterminated argument index"
+	fmt.Printf("%v", f)    // MATCH "Printf format %v has arg #1 of function type func() int, which prints its address; did you mean to call it?"
+	fmt.Printf("%v", f())
+	fmt.Printf("%p %T", f, f)
+	fmt.Printf("%v", ch) // MATCH "Printf format %v has arg #1 of channel type chan int"
+	fmt.Printf("100%%")
+	fmt.Printf("%")                    // MATCH "Printf format % has missing verb"
+	fmt.Fprintf(os.Stdout, "%s %s", s) // MATCH "reads arg #2"
+	fmt.Printf("%s", []interface{}{s}...)
+	log.Printf("%d")            // MATCH "reads arg #1, but call has only 0 args"
+	_ = fmt.Sprintf("%[2]v", s) // MATCH "reads arg #2"
+}
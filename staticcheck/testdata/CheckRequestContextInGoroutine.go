@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+func work(ctx context.Context) {}
+
+func fn1(w http.ResponseWriter, r *http.Request) {
+	go work(r.Context()) // MATCH "the request's context is canceled when the handler returns"
+}
+
+func fn2(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	go func() { // MATCH "the request's context is canceled when the handler returns"
+		work(ctx)
+	}()
+}
+
+func fn3(w http.ResponseWriter, r *http.Request) {
+	go func() { // MATCH "the request's context is canceled when the handler returns"
+		work(r.Context())
+	}()
+}
+
+func fn4(w http.ResponseWriter, r *http.Request) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		work(r.Context())
+	}()
+	wg.Wait()
+}
+
+func fn5(w http.ResponseWriter, r *http.Request) {
+	go work(context.Background())
+}
+
+func fn6(w http.ResponseWriter, r *http.Request) {
+	ch := make(chan struct{})
+	go func() {
+		work(r.Context())
+		close(ch)
+	}()
+	<-ch
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import "net/http"
+
+func fn1(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(200)
+	w.WriteHeader(500) // MATCH "superfluous call to WriteHeader"
+}
+
+func fn2(w http.ResponseWriter, r *http.Request) {
+	if r.URL == nil {
+		http.Error(w, "bad request", 400)
+	}
+	w.Write(nil) // MATCH "Write may write to the response after http.Error"
+}
+
+func fn3(w http.ResponseWriter, r *http.Request) {
+	if r.URL == nil {
+		http.Error(w, "bad request", 400)
+		return
+	}
+	w.WriteHeader(200)
+	w.Write(nil)
+}
+
+func fn4(w http.ResponseWriter, r *http.Request) {
+	w.Write(nil)
+	w.WriteHeader(200) // MATCH "superfluous call to WriteHeader, the response header may have already been written by a call to Write"
+}
+
+func fn5(w http.ResponseWriter, r *http.Request) {
+	if r.URL == nil {
+		w.WriteHeader(400)
+	} else {
+		w.WriteHeader(200)
+	}
+}
+
+func fn6(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "bad request", 400)
+	w.WriteHeader(500) // MATCH "superfluous call to WriteHeader"
+}
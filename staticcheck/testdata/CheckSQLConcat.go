@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+func fn(ctx context.Context, db *sql.DB, tx *sql.Tx, name string, id int, desc bool) {
+	db.Query("SELECT * FROM users WHERE name = '" + name + "'") // MATCH "SQL query is built by concatenating strings"
+	db.Query("SELECT * FROM users WHERE name = ?", name)
+	db.QueryRowContext(ctx, "SELECT * FROM users WHERE id = "+strconv.Itoa(id)) // MATCH "SQL query is built by concatenating strings"
+	tx.Exec("DELETE FROM " + name)                                              // MATCH "SQL query is built by concatenating strings"
+
+	const table = "users"
+	db.Exec("DELETE FROM " + table)
+
+	q := "SELECT * FROM users"
+	if desc {
+		q += " ORDER BY id DESC"
+	}
+	db.Query(q)
+
+	q2 := "SELECT * FROM users WHERE name = "
+	q2 += "'" + name + "'"
+	db.Query(q2) // MATCH "SQL query is built by concatenating strings"
+
+	q3 := "SELECT * FROM users WHERE id IN (?"
+	for i := 1; i < id; i++ {
+		q3 += ", ?"
+	}
+	q3 += ")"
+	db.Query(q3)
+
+	db.Query(name)
+}
@@ -0,0 +1,15 @@
+package pkg
+
+import (
+	"database/sql"
+	"strings"
+)
+
+func QuoteIdentifier(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+func fn(db *sql.DB, table, name string) {
+	db.Query("SELECT * FROM " + QuoteIdentifier(table))
+	db.Query("SELECT * FROM " + QuoteIdentifier(table) + " WHERE name = " + name) // MATCH "SQL query is built by concatenating strings"
+}
@@ -0,0 +1,79 @@
+package pkg
+
+func fn1() (int, error) { return 0, nil }
+func fn2(int) error     { return nil }
+
+func fn3(cond bool) error {
+	var err error
+	if cond {
+		v, err := fn1() // RELATED "err is redeclared here, shadowing the outer err"
+		if err != nil {
+			return err
+		}
+		err = fn2(v) // MATCH "this error is never checked: err shadows the err that is checked later"
+	}
+	if err != nil { // RELATED "this checks the outer err, not the shadowing one"
+		return err
+	}
+	return nil
+}
+
+func fn4(cond bool) (err error) {
+	if cond {
+		v, err := fn1()
+		if err != nil {
+			return err
+		}
+		err = fn2(v) // MATCH "this error is never checked"
+	}
+	if nil == err {
+		println()
+	}
+	return err
+}
+
+func fn5(cond bool) error {
+	var err error
+	if cond {
+		v, err := fn1()
+		if err != nil {
+			return err
+		}
+		err = fn2(v) // MATCH "this value of err is never used"
+	}
+	return err
+}
+
+func fn6(cond bool) error {
+	var err error
+	if cond {
+		v, err := fn1()
+		if err != nil {
+			return err
+		}
+		err = fn2(v)
+		if err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func fn7(cond bool) error {
+	var err error
+	if cond {
+		var v int
+		v, err = fn1()
+		if err != nil {
+			return err
+		}
+		err = fn2(v)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package pkg
+
+import "fmt"
+
+func fn(dir, name, user, q string, n int) {
+	_ = fmt.Sprintf("%s/%s", dir, name)                   // MATCH "should use filepath.Join"
+	_ = fmt.Sprintf("%s/config.json", dir)                // MATCH "should use filepath.Join"
+	_ = fmt.Sprintf("/var/lib/%s", name)                  // MATCH "should use filepath.Join"
+	_ = fmt.Sprintf("%s/%d", dir, n)                      // MATCH "should use filepath.Join"
+	_ = fmt.Sprintf("%d/%d", n, n)                        // numbers, such as fractions
+	_ = fmt.Sprintf("%s: %s/%s", dir, name, name)         // not a path
+	_ = fmt.Sprintf("%s.%s", dir, name)                   // no separator
+	_ = fmt.Sprintf("https://%s/", dir)                   // the host needs no escaping
+	_ = fmt.Sprintf("https://example.com/users/%s", user) // MATCH "doesn't escape %s in the URL's path; use url.PathEscape"
+	_ = fmt.Sprintf("https://example.com/search?q=%s", q) // MATCH "doesn't escape %s in the URL's query"
+	_ = fmt.Sprintf("https://example.com/users/%d", n)
+}
@@ -0,0 +1,7 @@
+package pkg
+
+import "fmt"
+
+func fn119(user string) {
+	_ = fmt.Sprintf("https://example.com/users/%s/posts", user) // MATCH "use url.JoinPath or url.PathEscape"
+}
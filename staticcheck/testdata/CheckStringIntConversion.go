@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type myString string
+type myInt int
+
+func fn1(i int, i64 int64, u uint, b byte, r rune, mi myInt) {
+	_ = strconv.Itoa(i)
+
+	_ = "id " + string(i)              // MATCH "conversion from int to string yields a string of one rune, not a decimal number"
+	_ = string(i64) + "s"              // MATCH "conversion from int64 to string"
+	_ = fmt.Sprintf("%s", string(u))   // MATCH "conversion from uint to string"
+	fmt.Println("value:", string(mi))  // MATCH /conversion from .*myInt to string/
+	_ = "id " + myString(i)            // MATCH "conversion from int to string"
+	_ = "x" + string(65)               // MATCH "conversion from untyped int to string"
+	_ = string(i)                      // MATCH:go1.15 "conversion from int to string"
+	var s myString = myString(i64 + 1) // MATCH:go1.15 "conversion from int64 to string"
+	_ = s
+
+	_ = string(b)
+	_ = string(r)
+	_ = string('a')
+	_ = "id " + string(rune(i))
+	_ = "id " + string(b)
+}
@@ -0,0 +1,8 @@
+package pkg
+
+import "fmt"
+
+func fn2(i int, u uint) {
+	_ = "id " + string(u)  // MATCH "conversion from uint to string"
+	fmt.Println(string(i)) // MATCH "conversion from int to string"
+}
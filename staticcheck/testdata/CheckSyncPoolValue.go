@@ -36,3 +36,11 @@ func fn() {
 	var basic int
 	p.Put(basic) // MATCH /argument should be pointer-like/
 }
+
+func fn2() {
+	p := &sync.Pool{}
+	p.Put(struct{}{})
+	p.Put(true)
+	p.Put(fn2)
+	p.Put([]byte(nil)) // MATCH "argument should be pointer-like to avoid allocations: every Put of a []byte allocates"
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	th "html/template"
+	"io"
+	"os"
+	tt "text/template"
+)
+
+type Person struct {
+	Name    string
+	Address Address
+	Tags    map[string]string
+	age     int
+}
+
+type Address struct {
+	City string
+}
+
+func (p Person) Greeting() string { return "hi" }
+
+var t1 = tt.Must(tt.New("").Parse(`{{.Name}} {{.Nmae}} {{.Greeting}} {{.Address.Cty}}`)) // MATCH /template refers to .Nmae, which doesn.t exist on .*Person/
+
+// MATCH:23 "template refers to .Address.Cty"
+var t2 = th.Must(th.New("").Parse(`{{range .Tags}}{{.Anything}}{{$.Nme}}{{end}}{{.Tags.foo}}`)) // MATCH "template refers to $.Nme"
+
+var t3 = tt.Must(tt.New("").Parse(`{{.age}}`)) // MATCH "template refers to .age"
+
+var t4 = tt.Must(tt.New("").Parse(`{{.Foo}}`))
+
+// .Missing exists on one of the types t5 is executed with
+var t5 = tt.Must(tt.New("").Parse(`{{with .Address}}{{.City}}{{end}}{{.Missing}}`))
+
+func fn3(w io.Writer, data interface{}) {
+	p := Person{}
+	t1.Execute(os.Stdout, p) // RELATED /template is executed here with data of type .*Person/
+	t2.Execute(os.Stdout, &p)
+	t3.Execute(os.Stdout, p)
+
+	// t4 is executed with unknown data, so we can't prove anything
+	t4.Execute(w, p)
+	t4.Execute(w, data)
+
+	t5.Execute(w, p)
+	t5.Execute(w, struct{ Missing int }{})
+}
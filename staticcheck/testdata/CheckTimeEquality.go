@@ -0,0 +1,17 @@
+package pkg
+
+import "time"
+
+type T struct {
+	t time.Time
+}
+
+func fn(t1, t2 time.Time, v T, m map[time.Time]int) { // MATCH "as a map key"
+	_ = t1 == t2     // MATCH "use the Equal method instead"
+	_ = t1 != t2     // MATCH "use the Equal method instead"
+	_ = v.t == t1    // MATCH "use the Equal method instead"
+	_ = *(&t1) == t2 // MATCH "use the Equal method instead"
+	_ = t1.Equal(t2)
+	_ = t1.Unix() == t2.Unix()
+	_ = map[int64]int{}
+}
@@ -0,0 +1,32 @@
+package pkg
+
+type T1 struct {
+	A int
+	B []int
+}
+
+type T2 struct {
+	A int
+}
+
+func fn() {
+	m := map[interface{}]int{}
+	m[T1{}] = 1 // MATCH "using a value of type T1 as a map key panics"
+	m[T2{}] = 1
+	m[[]int{}] = 1 // MATCH "using a value of type []int as a map key panics"
+	_ = m[T1{}]    // MATCH "using a value of type T1 as a map key panics"
+	_ = m[1]
+
+	var x, y interface{}
+	x = T1{}
+	y = T1{}
+	_ = x == y // MATCH "comparing interface values holding values of type T1 panics"
+	var z interface{} = 1
+	_ = x == z
+
+	var fn1, fn2 interface{} = func() {}, func() {}
+	_ = fn1 == fn2 // MATCH "comparing interface values holding values of type func() panics"
+
+	_ = interface{}(T1{A: 1}) == interface{}(T1{A: 2}) // MATCH "comparing interface values holding values of type T1 panics"
+	_ = interface{}(T1{}) == interface{}(T2{})
+}
@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+)
+
+type Event struct {
+	Name     string
+	At       time.Time
+	Handlers []Handler
+}
+
+type Handler struct {
+	Name string
+	Fn   func()
+}
+
+type Point struct {
+	X, Y float64
+}
+
+type private struct {
+	name string
+	age  int
+}
+
+type Tagged struct {
+	Name string
+	Done chan struct{} `json:"-"`
+	done chan struct{}
+}
+
+type embedded struct {
+	ID int
+}
+
+type Outer struct {
+	embedded
+}
+
+type Custom struct {
+	c chan int
+}
+
+func (Custom) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Key struct {
+	a, b int
+}
+
+func (k Key) MarshalText() ([]byte, error) { return nil, nil }
+
+type Loop struct {
+	Next *Loop
+	Ch   chan int
+}
+
+func fn() {
+	var ev Event
+	json.Marshal(ev)         // MATCH "json.Marshal can't handle ev: field Handlers[].Fn has the unsupported type func()"
+	json.Unmarshal(nil, &ev) // MATCH "field Handlers[].Fn has the unsupported type func()"
+
+	json.Marshal(map[Point]string{}) // MATCH "the value has map keys of the unsupported type Point"
+	json.Marshal(map[int]string{})
+	json.Marshal(map[Key]string{})
+	json.Marshal(complex(1, 2)) // MATCH "the value has the unsupported type complex128"
+	json.Marshal(private{})     // MATCH "the value of type private has no exported fields, so nothing is encoded or decoded"
+	json.Marshal([]*private{})  // MATCH "has no exported fields"
+	json.Marshal(Tagged{})
+	json.Marshal(Outer{})
+	json.Marshal(Custom{})
+	json.Marshal(struct{}{})
+	json.Marshal(Loop{}) // MATCH "field Ch has the unsupported type chan int"
+	var v interface{} = make(chan int)
+	json.Marshal(v)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.Encode(make(chan int)) // MATCH "enc.Encode can't handle make(chan int): the value has the unsupported type chan int"
+
+	genc := gob.NewEncoder(&buf)
+	genc.Encode(ev)
+	genc.Encode(complex(1, 2))
+	genc.Encode(Handler{})
+	genc.Encode(private{})               // MATCH "the value of type private has no exported fields, which gob rejects"
+	genc.Encode(func() {})               // MATCH "the value has the unsupported type func()"
+	genc.Encode(struct{ Ch chan int }{}) // MATCH "has no exported fields, which gob rejects"
+}
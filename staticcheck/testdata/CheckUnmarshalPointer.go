@@ -1,6 +1,9 @@
 package pkg
 
-import "encoding/json"
+import (
+	"encoding/gob"
+	"encoding/json"
+)
 
 func fn1(i3 interface{}) {
 	var v map[string]interface{}
@@ -15,4 +18,10 @@ func fn1(i3 interface{}) {
 	json.Unmarshal([]byte(`{}`), p)
 
 	json.NewDecoder(nil).Decode(v) // MATCH /Decode expects to unmarshal into a pointer/
+
+	json.Unmarshal([]byte(`{}`), nil) // MATCH "json.Unmarshal expects to unmarshal into a pointer, but the provided value is nil"
+	var np *map[string]interface{}
+	json.Unmarshal([]byte(`{}`), np) // MATCH "json.Unmarshal expects to unmarshal into a pointer, but the provided pointer is nil"
+	gob.NewDecoder(nil).Decode(v)    // MATCH "Decode expects to unmarshal into a pointer, but the provided value is not a pointer"
+	gob.NewDecoder(nil).Decode(&v)
 }
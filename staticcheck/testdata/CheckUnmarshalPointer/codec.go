@@ -0,0 +1,24 @@
+package pkg
+
+type Decoder struct{}
+
+func (*Decoder) Decode(v interface{}) error { return nil }
+
+func Unmarshal(data []byte, v interface{}) error         { return nil }
+func UnmarshalStrict(data []byte, v interface{}) error   { return nil }
+func UnmarshalTyped(data []byte, v map[string]int) error { return nil }
+func Load(path string, v interface{})                    {}
+func Marshal(v interface{}) ([]byte, error)              { return nil, nil }
+
+func fn() {
+	var m map[string]int
+	Unmarshal(nil, m) // MATCH "pkg.Unmarshal expects to unmarshal into a pointer, but the provided value is not a pointer"
+	Unmarshal(nil, &m)
+	UnmarshalStrict(nil, m) // MATCH "pkg.UnmarshalStrict expects to unmarshal into a pointer"
+	UnmarshalTyped(nil, m)
+	var d Decoder
+	d.Decode(m)   // MATCH "Decode expects to unmarshal into a pointer"
+	d.Decode(nil) // MATCH "but the provided value is nil"
+	Load("", m)
+	Marshal(m)
+}
@@ -0,0 +1,25 @@
+package pkg
+
+import (
+	"crypto/tls"
+	t "crypto/tls"
+)
+
+func fn(skip bool) {
+	_ = &tls.Config{InsecureSkipVerify: true} // MATCH "InsecureSkipVerify disables the verification of certificates"
+	_ = tls.Config{InsecureSkipVerify: false}
+	_ = tls.Config{InsecureSkipVerify: skip}
+	_ = tls.Config{MinVersion: tls.VersionTLS10} // MATCH "MinVersion allows versions of TLS older than 1.2"
+	_ = tls.Config{MinVersion: 0x0301}           // MATCH "MinVersion allows versions of TLS older than 1.2"
+	_ = tls.Config{MinVersion: tls.VersionTLS12}
+	_ = tls.Config{MinVersion: t.VersionTLS13}
+	_ = tls.Config{
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_RSA_WITH_RC4_128_SHA,              // MATCH "cipher suite TLS_RSA_WITH_RC4_128_SHA is insecure"
+			tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,         // MATCH "cipher suite TLS_RSA_WITH_3DES_EDE_CBC_SHA is insecure"
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256, // MATCH "cipher suite TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256 is insecure"
+			tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+		},
+	}
+}
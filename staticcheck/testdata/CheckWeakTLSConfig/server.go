@@ -0,0 +1,7 @@
+package pkg
+
+import "crypto/tls"
+
+func fn() {
+	_ = &tls.Config{InsecureSkipVerify: true} // MATCH "InsecureSkipVerify disables the verification of certificates"
+}
@@ -0,0 +1,8 @@
+package pkg
+
+import "crypto/tls"
+
+func fn2() {
+	_ = &tls.Config{InsecureSkipVerify: true}
+	_ = tls.Config{MinVersion: tls.VersionTLS11} // MATCH "MinVersion allows versions of TLS older than 1.2"
+}
@@ -0,0 +1,35 @@
+package pkg
+
+import "testing"
+
+func FuzzNoCorpus(f *testing.F) { // MATCH "FuzzNoCorpus has no seed corpus; add inputs with f.Add or to testdata/fuzz/FuzzNoCorpus"
+	f.Fuzz(func(t *testing.T, data []byte) {
+		t.Log(data)
+	})
+}
+
+func FuzzAdd(f *testing.F) {
+	for _, seed := range []string{"a", "b"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		t.Log(s)
+	})
+}
+
+func addSeeds(f *testing.F) {}
+
+func FuzzHelper(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		t.Log(data)
+	})
+}
+
+func FuzzCorpusFiles(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		t.Log(data)
+	})
+}
+
+func FuzzNotAFuzzTest(f *testing.F) {}
@@ -0,0 +1,54 @@
+package pkg
+
+import "testing"
+
+func parse(data []byte) error { return nil }
+
+func FuzzFailures(f *testing.F) {
+	f.Add([]byte("seed"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := parse(data); err != nil {
+			f.Fatal(err) // MATCH "the fuzz target calls f.Fatal"
+		}
+		f.Logf("%s", data) // MATCH "the fuzz target calls f.Logf"
+		t.Log(len(data))
+	})
+}
+
+func FuzzSkip(f *testing.F) {
+	f.Add([]byte("seed"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		t.Skip("not ready") // MATCH "the fuzz target unconditionally calls Skip"
+		_ = parse(data)
+	})
+}
+
+func FuzzSkipInvalid(f *testing.F) {
+	f.Add([]byte("seed"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 {
+			t.Skip()
+		}
+		if err := parse(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzRecover(f *testing.F) {
+	f.Add([]byte("seed"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer func() {
+			recover() // MATCH "discards the value of recover"
+		}()
+		defer func() {
+			_ = recover() // MATCH "discards the value of recover"
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatal(r)
+			}
+		}()
+		_ = parse(data)
+	})
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import "testing"
+
+func FuzzParams(f *testing.F) {
+	f.Add("a", 1, true)
+	f.Fuzz(func(t *testing.T, s string, n int, b bool) { // MATCH "never uses the parameter b"
+		t.Log(s, n)
+	})
+	f.Fuzz(func(t *testing.T, s string, _ int) {
+		t.Log(s)
+	})
+	f.Fuzz(func(t *testing.T, s, unused string) { // MATCH "never uses the parameter unused"
+		t.Log(s)
+	})
+}
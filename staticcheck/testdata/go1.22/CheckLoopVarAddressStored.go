@@ -0,0 +1,9 @@
+package pkg
+
+func fn(xs []int) {
+	var ptrs []*int
+	for _, x := range xs {
+		ptrs = append(ptrs, &x) // MATCH "escape to the heap"
+	}
+	println(ptrs)
+}
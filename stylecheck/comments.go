@@ -0,0 +1,159 @@
+package stylecheck
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"honnef.co/go/tools/lint"
+)
+
+// exportedDocs calls fn for the doc comments of the exported
+// top-level identifiers in f, including the doc comments of groups
+// of declarations that contain exported identifiers. doc may be nil.
+func exportedDocs(f *ast.File, fn func(name string, doc *ast.CommentGroup)) {
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Name.IsExported() {
+				fn(decl.Name.Name, decl.Doc)
+			}
+		case *ast.GenDecl:
+			groupExported := false
+			for _, spec := range decl.Specs {
+				var names []*ast.Ident
+				var doc *ast.CommentGroup
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = []*ast.Ident{spec.Name}
+					doc = spec.Doc
+				case *ast.ValueSpec:
+					names = spec.Names
+					doc = spec.Doc
+				}
+				for _, name := range names {
+					if name.IsExported() {
+						if !groupExported {
+							groupExported = true
+							fn(name.Name, decl.Doc)
+						}
+						fn(name.Name, doc)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+var directiveRx = regexp.MustCompile(`^//([a-z0-9]+:[a-z0-9]|(line|extern|export|nolint)\b|sys(nb)?[ \t])`)
+
+// isDirective reports whether the comment text is a directive for
+// a tool, such as //go:generate or //lint:ignore, which must not
+// have a space after the slashes.
+func isDirective(text string) bool {
+	return directiveRx.MatchString(text)
+}
+
+func (c *Checker) CheckCommentSpacing(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, cg := range f.Comments {
+			for _, cm := range cg.List {
+				if !strings.HasPrefix(cm.Text, "//") || isDirective(cm.Text) {
+					continue
+				}
+				r, _ := utf8.DecodeRuneInString(cm.Text[2:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					// Comments such as //// or //--- are
+					// decorations, not text.
+					continue
+				}
+				p := j.Errorf(cm, "comment should have a space after //")
+				j.AddFix(p, "add space", j.ReplaceRange(cm.Slash+2, cm.Slash+2, " "))
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckDocPeriod(j *lint.Job) {
+	checkComment := func(name string, cg *ast.CommentGroup) {
+		if cg == nil {
+			return
+		}
+		var last *ast.Comment
+		for _, cm := range cg.List {
+			if !isDirective(cm.Text) {
+				last = cm
+			}
+		}
+		if last == nil || !strings.HasPrefix(last.Text, "//") {
+			return
+		}
+		text := strings.TrimPrefix(last.Text, "//")
+		if strings.HasPrefix(text, " ") {
+			text = text[1:]
+		}
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			// preformatted text, most likely code
+			return
+		}
+		text = strings.TrimRight(text, " \t")
+		if text == "" || strings.ContainsAny(text[len(text)-1:], ".!?:") {
+			return
+		}
+		p := j.Errorf(last, "comment on exported %s should end in a period", name)
+		end := last.Slash + token.Pos(len(strings.TrimRight(last.Text, " \t")))
+		j.AddFix(p, "add period", j.ReplaceRange(end, end, "."))
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		exportedDocs(f, checkComment)
+	}
+}
+
+// defaultTodoPattern matches TODO comments that name an owner or a
+// ticket, as in TODO(dh) or TODO(#123).
+const defaultTodoPattern = `^TODO\([^)]+\)`
+
+func (c *Checker) CheckTodoOwner(j *lint.Job) {
+	rxs := map[string]*regexp.Regexp{}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pattern := defaultTodoPattern
+		if pkg := j.NodePackage(f); pkg != nil && pkg.Config.TodoPattern != "" {
+			pattern = pkg.Config.TodoPattern
+		}
+		rx := rxs[pattern]
+		if rx == nil {
+			var err error
+			rx, err = regexp.Compile(pattern)
+			if err != nil {
+				// Configuration files are validated when they
+				// are loaded.
+				continue
+			}
+			rxs[pattern] = rx
+		}
+		for _, cg := range f.Comments {
+			for _, cm := range cg.List {
+				text := strings.TrimPrefix(strings.TrimPrefix(cm.Text, "//"), "/*")
+				text = strings.TrimLeft(text, " \t")
+				if !strings.HasPrefix(text, "TODO") {
+					continue
+				}
+				if r, _ := utf8.DecodeRuneInString(text[len("TODO"):]); unicode.IsLetter(r) {
+					continue
+				}
+				if rx.MatchString(text) {
+					continue
+				}
+				if pattern == defaultTodoPattern {
+					j.Errorf(cm, "TODO comment should name an owner or a ticket, as in TODO(name)")
+				} else {
+					j.Errorf(cm, "TODO comment should match %q", pattern)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package stylecheck
+
+import "honnef.co/go/tools/lint"
+
+// Docs documents the checks and categories of checks of this checker.
+var Docs = map[string]*lint.Documentation{
+	"ST1": {
+		Title: "Stylistic issues",
+	},
+	"ST1000": {
+		Title: "Incorrect or missing package comment",
+	},
+	"ST1001": {
+		Title: "Dot imports are discouraged",
+	},
+	"ST1002": {
+		Title: "Blank imports should be justified by a comment",
+	},
+	"ST1003": {
+		Title: "Poorly chosen identifier",
+	},
+	"ST1005": {
+		Title: "Incorrectly formatted error string",
+	},
+	"ST1006": {
+		Title: "Poorly chosen receiver name",
+	},
+	"ST1007": {
+		Title: "Use ++ and -- instead of += 1 and -= 1",
+	},
+	"ST1008": {
+		Title: "A function's error value should be its last return value",
+	},
+	"ST1009": {
+		Title: "Exported functions should not return unexported types",
+	},
+	"ST1010": {
+		Title: "context.Context should be the first argument of a function",
+	},
+	"ST1011": {
+		Title: "Poorly chosen name for variable of type time.Duration",
+	},
+	"ST1012": {
+		Title: "Poorly chosen name for error variable",
+	},
+	"ST1013": {
+		Title: "Declaration shadows a predeclared identifier or an import",
+		Text: "Declaring a variable named `len`, `new` or `error`, or one that has\n" +
+			"the same name as an imported package, makes later uses of that name\n" +
+			"in the function confusing to read.\n",
+		NonDefault: true,
+	},
+	"ST1014": {
+		Title: "Misspelled word in the documentation of an exported identifier",
+		Text: "Doc comments of exported identifiers are checked against a list of\n" +
+			"common misspellings. Words that are spelled correctly in the context\n" +
+			"of a project can be added to the `dictionary` option of the\n" +
+			"configuration file.\n",
+		NonDefault: true,
+	},
+	"ST1015": {
+		Title: "Imports aren't grouped and sorted",
+		Text: "Imports should be arranged in groups, separated by blank lines,\n" +
+			"with the imports in each group sorted by import path. By default,\n" +
+			"imports of the standard library come first, followed by all other\n" +
+			"imports. The `import_groups` option of the configuration file\n" +
+			"changes the groups, for example to separate the project's own\n" +
+			"packages from third-party ones:\n" +
+			"\n" +
+			"```\n" +
+			"import_groups = [\"std\", \"*\", \"example.com/project/...\"]\n" +
+			"```\n" +
+			"\n" +
+			"Imports in the wrong order are fixed by rearranging the import\n" +
+			"declaration, unless it contains comments on lines of their own.\n",
+		NonDefault: true,
+	},
+	"ST1016": {
+		Title: "Function is too long",
+		Text: "Functions with more statements or lines than the\n" +
+			"`max_function_statements` and `max_function_lines` options of the\n" +
+			"configuration file, by default 50 statements and 100 lines, are\n" +
+			"flagged. Lines of tables, such as composite literals that list\n" +
+			"test cases or lookup tables, don't count, as they are data rather\n" +
+			"than code. Generated files are skipped.\n",
+		NonDefault: true,
+	},
+	"ST1017": {
+		Title: "File is too long",
+		Text: "Files with more lines than the `max_file_lines` option of the\n" +
+			"configuration file, by default 1000 lines, are flagged. Like for\n" +
+			"ST1016, lines of tables don't count, and generated files are\n" +
+			"skipped.\n",
+		NonDefault: true,
+	},
+	"ST1018": {
+		Title: "Comment without a space after `//`",
+		Text: "Comments should have a space after the slashes, as in `// text`.\n" +
+			"Directives for tools, such as `//go:generate`, `//lint:ignore`\n" +
+			"and `//export`, must not have one and aren't flagged, nor are\n" +
+			"decorations such as `////`.\n",
+		NonDefault: true,
+	},
+	"ST1019": {
+		Title: "Doc comment of an exported identifier doesn't end in a period",
+		Text: "Doc comments are made up of full sentences, which end in a period.\n" +
+			"Comments that end in preformatted text, such as code examples,\n" +
+			"aren't flagged.\n",
+		NonDefault: true,
+	},
+	"ST1020": {
+		Title: "TODO comment without an owner or ticket",
+		Text: "TODO comments should say who is responsible for them, or which\n" +
+			"ticket tracks them, as in `TODO(name): ...`, so that they don't\n" +
+			"get forgotten. The `todo_pattern` option of the configuration\n" +
+			"file sets a regular expression that TODO comments, starting at\n" +
+			"the word TODO, must match instead, for example to require ticket\n" +
+			"numbers:\n" +
+			"\n" +
+			"```\n" +
+			"todo_pattern = '^TODO\\(#\\d+\\)'\n" +
+			"```\n",
+		NonDefault: true,
+	},
+}
@@ -0,0 +1,188 @@
+package stylecheck
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// defaultImportGroups are the import groups of projects that don't
+// configure their own: the standard library, followed by everything
+// else.
+var defaultImportGroups = []string{"std", "*"}
+
+// importGroup returns the index of the group in groups that path
+// belongs to. Patterns of specific packages take precedence, with
+// longer patterns taking precedence over shorter ones, followed by
+// "std" and "*". Paths that match no group belong to a group after
+// all others.
+func importGroup(path string, groups []string) int {
+	best, bestLen := -1, -1
+	std, other := -1, -1
+	for i, g := range groups {
+		switch g {
+		case "std":
+			std = i
+		case "*":
+			other = i
+		default:
+			prefix := strings.TrimSuffix(g, "/...")
+			match := path == prefix || (prefix != g && strings.HasPrefix(path, prefix+"/"))
+			if match && len(prefix) > bestLen {
+				best, bestLen = i, len(prefix)
+			}
+		}
+	}
+	switch {
+	case best != -1:
+		return best
+	case std != -1 && !strings.Contains(strings.SplitN(path, "/", 2)[0], "."):
+		// Like goimports, we assume that paths whose first element
+		// doesn't contain a dot belong to the standard library.
+		return std
+	case other != -1:
+		return other
+	default:
+		return len(groups)
+	}
+}
+
+// importGroupName describes the group with the given index.
+func importGroupName(groups []string, i int) string {
+	if i >= len(groups) {
+		return "other imports"
+	}
+	switch groups[i] {
+	case "std":
+		return "the standard library"
+	case "*":
+		return "third-party imports"
+	default:
+		return groups[i]
+	}
+}
+
+type importSpec struct {
+	spec  *ast.ImportSpec
+	path  string
+	group int
+	// block is the index of the block of imports, separated by
+	// blank lines, that the import is in.
+	block int
+}
+
+type importSpecs []importSpec
+
+func (s importSpecs) Len() int      { return len(s) }
+func (s importSpecs) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s importSpecs) Less(i, j int) bool {
+	if s[i].group != s[j].group {
+		return s[i].group < s[j].group
+	}
+	return s[i].path < s[j].path
+}
+
+func (c *Checker) CheckImportGrouping(j *lint.Job) {
+	fset := j.Program.Prog.Fset
+	// checkDecl checks the imports in decl, which is an import
+	// declaration with parentheses.
+	checkDecl := func(f *ast.File, decl *ast.GenDecl, groups []string) {
+		var specs importSpecs
+		block := 0
+		prevLine := 0
+		for i, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil || path == "C" {
+				// Imports of C have to stay in their own
+				// declarations, with their preambles.
+				return
+			}
+			line := fset.Position(spec.Pos()).Line
+			if spec.Doc != nil {
+				line = fset.Position(spec.Doc.Pos()).Line
+			}
+			if i > 0 && line > prevLine+1 {
+				block++
+			}
+			prevLine = fset.Position(spec.End()).Line
+			if spec.Comment != nil {
+				prevLine = fset.Position(spec.Comment.End()).Line
+			}
+			specs = append(specs, importSpec{spec, path, importGroup(path, groups), block})
+		}
+
+		var p *lint.Problem
+		for i := 1; i < len(specs) && p == nil; i++ {
+			prev, cur := specs[i-1], specs[i]
+			switch {
+			case cur.group < prev.group:
+				p = j.Errorf(cur.spec, "import %q should come before %q; %s should be grouped before %s", cur.path, prev.path, importGroupName(groups, cur.group), importGroupName(groups, prev.group))
+			case cur.group != prev.group && cur.block == prev.block:
+				p = j.Errorf(cur.spec, "import %q should be separated from %q by a blank line, as they belong to different groups", cur.path, prev.path)
+			case cur.group == prev.group && cur.block != prev.block:
+				p = j.Errorf(cur.spec, "import %q shouldn't be separated from %q by a blank line, as they belong to the same group", cur.path, prev.path)
+			case cur.group == prev.group && cur.path < prev.path:
+				p = j.Errorf(cur.spec, "import %q should be sorted before %q", cur.path, prev.path)
+			}
+		}
+		if p == nil {
+			return
+		}
+
+		// Only suggest a fix if all comments in the declaration are
+		// comments at the ends of lines, which move with their
+		// imports.
+		trailing := map[*ast.CommentGroup]bool{}
+		for _, spec := range specs {
+			if spec.spec.Doc != nil {
+				return
+			}
+			trailing[spec.spec.Comment] = true
+		}
+		for _, cg := range f.Comments {
+			if cg.Pos() > decl.Lparen && cg.End() < decl.Rparen && !trailing[cg] {
+				return
+			}
+		}
+		sorted := append(importSpecs(nil), specs...)
+		sort.Stable(sorted)
+		text := "import (\n"
+		for i, spec := range sorted {
+			if i > 0 && spec.group != sorted[i-1].group {
+				text += "\n"
+			}
+			text += "\t"
+			if spec.spec.Name != nil {
+				text += spec.spec.Name.Name + " "
+			}
+			text += spec.spec.Path.Value
+			if spec.spec.Comment != nil {
+				for _, cm := range spec.spec.Comment.List {
+					text += " " + cm.Text
+				}
+			}
+			text += "\n"
+		}
+		text += ")"
+		j.AddFix(p, "group and sort imports", j.Replace(decl, text))
+	}
+
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		groups := defaultImportGroups
+		if pkg := j.NodePackage(f); pkg != nil && len(pkg.Config.ImportGroups) > 0 {
+			groups = pkg.Config.ImportGroups
+		}
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.IMPORT || !decl.Lparen.IsValid() {
+				continue
+			}
+			checkDecl(f, decl, groups)
+		}
+	}
+}
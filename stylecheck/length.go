@@ -0,0 +1,121 @@
+package stylecheck
+
+import (
+	"go/ast"
+	"go/token"
+
+	"honnef.co/go/tools/lint"
+)
+
+// The limits that apply if the configuration doesn't set its own.
+const (
+	defaultMaxFunctionStatements = 50
+	defaultMaxFunctionLines      = 100
+	defaultMaxFileLines          = 1000
+)
+
+// isTable reports whether lit is a table of data, such as a list of
+// test cases or a lookup table: a composite literal of at least two
+// elements that consist only of literals, names and other tables.
+func isTable(lit *ast.CompositeLit) bool {
+	if len(lit.Elts) < 2 {
+		return false
+	}
+	var isData func(expr ast.Expr) bool
+	isData = func(expr ast.Expr) bool {
+		switch expr := expr.(type) {
+		case *ast.BasicLit, *ast.Ident:
+			return true
+		case *ast.SelectorExpr:
+			return isData(expr.X)
+		case *ast.UnaryExpr:
+			return isData(expr.X)
+		case *ast.KeyValueExpr:
+			return isData(expr.Key) && isData(expr.Value)
+		case *ast.CompositeLit:
+			for _, elt := range expr.Elts {
+				if !isData(elt) {
+					return false
+				}
+			}
+			return true
+		default:
+			return false
+		}
+	}
+	return isData(lit)
+}
+
+// tableLines returns the number of lines in node that belong to
+// tables, which are data rather than code. The lines of the braces
+// of tables don't count as part of them.
+func tableLines(fset *token.FileSet, node ast.Node) int {
+	n := 0
+	ast.Inspect(node, func(node ast.Node) bool {
+		lit, ok := node.(*ast.CompositeLit)
+		if !ok || !isTable(lit) {
+			return true
+		}
+		if lines := fset.Position(lit.Rbrace).Line - fset.Position(lit.Lbrace).Line - 1; lines > 0 {
+			n += lines
+		}
+		return false
+	})
+	return n
+}
+
+func (c *Checker) CheckFunctionLength(j *lint.Job) {
+	fset := j.Program.Prog.Fset
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		maxStmts, maxLines := defaultMaxFunctionStatements, defaultMaxFunctionLines
+		if pkg := j.NodePackage(f); pkg != nil {
+			if pkg.Config.MaxFunctionStatements > 0 {
+				maxStmts = pkg.Config.MaxFunctionStatements
+			}
+			if pkg.Config.MaxFunctionLines > 0 {
+				maxLines = pkg.Config.MaxFunctionLines
+			}
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			stmts := 0
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				switch node.(type) {
+				case *ast.BlockStmt, *ast.EmptyStmt:
+				case ast.Stmt:
+					stmts++
+				}
+				return true
+			})
+			lines := fset.Position(fn.Body.Rbrace).Line - fset.Position(fn.Body.Lbrace).Line - 1
+			lines -= tableLines(fset, fn.Body)
+			switch {
+			case stmts > maxStmts:
+				j.Errorf(fn.Name, "function %s has %d statements, more than the maximum of %d; consider splitting it up", fn.Name.Name, stmts, maxStmts)
+			case lines > maxLines:
+				j.Errorf(fn.Name, "function %s has %d lines, more than the maximum of %d; consider splitting it up", fn.Name.Name, lines, maxLines)
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckFileLength(j *lint.Job) {
+	fset := j.Program.Prog.Fset
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		limit := defaultMaxFileLines
+		if pkg := j.NodePackage(f); pkg != nil && pkg.Config.MaxFileLines > 0 {
+			limit = pkg.Config.MaxFileLines
+		}
+		tf := fset.File(f.Pos())
+		if tf == nil {
+			continue
+		}
+		lines := tf.LineCount() - tableLines(fset, f)
+		if lines > limit {
+			j.Errorf(f, "file has %d lines, more than the maximum of %d; consider splitting it up", lines, limit)
+		}
+	}
+}
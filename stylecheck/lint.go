@@ -28,6 +28,8 @@ func NewChecker() *Checker {
 func (*Checker) Name() string   { return "stylecheck" }
 func (*Checker) Prefix() string { return "ST" }
 
+func (*Checker) Docs() map[string]*lint.Documentation { return Docs }
+
 func (c *Checker) Init(prog *lint.Program) {
 }
 
@@ -59,9 +61,21 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"ST1010": c.CheckContextFirstArg,
 		"ST1011": c.CheckTimeNames,
 		"ST1012": c.CheckErrorVarNames,
+		"ST1013": c.CheckShadowedNames,
+		"ST1014": c.CheckDocSpelling,
+		"ST1015": c.CheckImportGrouping,
+		"ST1016": c.CheckFunctionLength,
+		"ST1017": c.CheckFileLength,
+		"ST1018": c.CheckCommentSpacing,
+		"ST1019": c.CheckDocPeriod,
+		"ST1020": c.CheckTodoOwner,
 	}
 }
 
+func (c *Checker) SyntacticChecks() []string {
+	return []string{"ST1000", "ST1001", "ST1002", "ST1003", "ST1007", "ST1014", "ST1015", "ST1016", "ST1017", "ST1018", "ST1019", "ST1020"}
+}
+
 func (c *Checker) CheckPackageComment(j *lint.Job) {
 	// - At least one file in a package should have a package comment
 	//
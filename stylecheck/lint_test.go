@@ -3,6 +3,7 @@ package stylecheck
 import (
 	"testing"
 
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/lint/testutil"
 )
 
@@ -10,3 +11,29 @@ func TestAll(t *testing.T) {
 	c := NewChecker()
 	testutil.TestAll(t, c, "")
 }
+
+func TestImportGrouping(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		ImportGroups: []string{"std", "encoding/..."},
+	}
+	testutil.TestAllConfig(t, c, "CheckImportGrouping", cfg)
+}
+
+func TestLength(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		MaxFunctionStatements: 3,
+		MaxFunctionLines:      6,
+		MaxFileLines:          40,
+	}
+	testutil.TestAllConfig(t, c, "CheckLength", cfg)
+}
+
+func TestTodoOwner(t *testing.T) {
+	c := NewChecker()
+	cfg := config.Config{
+		TodoPattern: `^TODO\(#\d+\)`,
+	}
+	testutil.TestAllConfig(t, c, "CheckTodoOwner", cfg)
+}
@@ -0,0 +1,92 @@
+package stylecheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/lint"
+	. "honnef.co/go/tools/lint/lintdsl"
+)
+
+// shadowedObject returns the predeclared identifier or imported
+// package that obj shadows, if any.
+func shadowedObject(obj types.Object) types.Object {
+	scope := obj.Parent()
+	if scope == nil || scope.Parent() == nil {
+		return nil
+	}
+	_, shadowed := scope.Parent().LookupParent(obj.Name(), obj.Pos())
+	switch shadowed := shadowed.(type) {
+	case *types.PkgName:
+		return shadowed
+	case nil:
+		return nil
+	default:
+		if shadowed.Parent() == types.Universe {
+			return shadowed
+		}
+	}
+	return nil
+}
+
+func (c *Checker) CheckShadowedNames(j *lint.Job) {
+	checkFunc := func(fn ast.Node) {
+		type decl struct {
+			id       *ast.Ident
+			obj      types.Object
+			shadowed types.Object
+		}
+		var decls []decl
+		uses := map[string][]*ast.Ident{}
+		ast.Inspect(fn, func(node ast.Node) bool {
+			id, ok := node.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if obj, ok := j.Program.Info.Defs[id]; ok && obj != nil {
+				if shadowed := shadowedObject(obj); shadowed != nil {
+					decls = append(decls, decl{id, obj, shadowed})
+				}
+				return true
+			}
+			uses[id.Name] = append(uses[id.Name], id)
+			return true
+		})
+
+		for _, d := range decls {
+			// Only flag shadowing if the name gets used after the
+			// declaration, where readers might confuse the two
+			// objects.
+			var use *ast.Ident
+			for _, id := range uses[d.id.Name] {
+				if id.Pos() <= d.id.Pos() {
+					continue
+				}
+				if obj := ObjectOf(j, id); obj == d.obj || obj == d.shadowed {
+					use = id
+					break
+				}
+			}
+			if use == nil {
+				continue
+			}
+
+			var p *lint.Problem
+			if pkgName, ok := d.shadowed.(*types.PkgName); ok {
+				p = j.Errorf(d.id, "declaration of %s shadows the import of package %s", d.id.Name, pkgName.Imported().Path())
+				j.AddRelated(p, pkgName, "package %s is imported here", pkgName.Imported().Path())
+			} else {
+				p = j.Errorf(d.id, "declaration of %s shadows the predeclared identifier %s", d.id.Name, d.shadowed.Name())
+			}
+			j.AddRelated(p, use, "%s is used here", d.id.Name)
+		}
+	}
+
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				checkFunc(fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package stylecheck
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/lint"
+)
+
+// misspellings maps common misspellings of English words to their
+// correct spelling.
+var misspellings = map[string]string{
+	"accesible":       "accessible",
+	"accomodate":      "accommodate",
+	"acheive":         "achieve",
+	"acknowlege":      "acknowledge",
+	"adress":          "address",
+	"agressive":       "aggressive",
+	"alot":            "a lot",
+	"alreay":          "already",
+	"alwasy":          "always",
+	"ammount":         "amount",
+	"appearence":      "appearance",
+	"arguement":       "argument",
+	"assosiated":      "associated",
+	"asynchronus":     "asynchronous",
+	"atleast":         "at least",
+	"availabe":        "available",
+	"availible":       "available",
+	"becuase":         "because",
+	"beggining":       "beginning",
+	"beleive":         "believe",
+	"buisness":        "business",
+	"calender":        "calendar",
+	"cancelation":     "cancellation",
+	"charachter":      "character",
+	"commited":        "committed",
+	"comparision":     "comparison",
+	"compatability":   "compatibility",
+	"compatable":      "compatible",
+	"completly":       "completely",
+	"concurent":       "concurrent",
+	"conection":       "connection",
+	"consistant":      "consistent",
+	"containg":        "containing",
+	"continous":       "continuous",
+	"convertion":      "conversion",
+	"correspoding":    "corresponding",
+	"curent":          "current",
+	"defailt":         "default",
+	"definately":      "definitely",
+	"dependancy":      "dependency",
+	"dependant":       "dependent",
+	"desciption":      "description",
+	"diffrent":        "different",
+	"directoy":        "directory",
+	"doesnt":          "doesn't",
+	"embeded":         "embedded",
+	"enviroment":      "environment",
+	"equivalant":      "equivalent",
+	"existance":       "existence",
+	"existant":        "existent",
+	"explicitely":     "explicitly",
+	"failes":          "fails",
+	"familar":         "familiar",
+	"finaly":          "finally",
+	"folowing":        "following",
+	"formated":        "formatted",
+	"fucntion":        "function",
+	"funtion":         "function",
+	"garantee":        "guarantee",
+	"guarentee":       "guarantee",
+	"happend":         "happened",
+	"heirarchy":       "hierarchy",
+	"identifer":       "identifier",
+	"immediatly":      "immediately",
+	"implemention":    "implementation",
+	"implmentation":   "implementation",
+	"incompatable":    "incompatible",
+	"independant":     "independent",
+	"indentifier":     "identifier",
+	"infomation":      "information",
+	"initalize":       "initialize",
+	"initialze":       "initialize",
+	"instace":         "instance",
+	"intial":          "initial",
+	"inteface":        "interface",
+	"interupt":        "interrupt",
+	"lenght":          "length",
+	"librairy":        "library",
+	"maintainance":    "maintenance",
+	"maximium":        "maximum",
+	"mesage":          "message",
+	"millenium":       "millennium",
+	"minumum":         "minimum",
+	"neccessary":      "necessary",
+	"necesary":        "necessary",
+	"occured":         "occurred",
+	"occurence":       "occurrence",
+	"occuring":        "occurring",
+	"ommitted":        "omitted",
+	"optionnal":       "optional",
+	"overriden":       "overridden",
+	"paramater":       "parameter",
+	"parameteres":     "parameters",
+	"paramter":        "parameter",
+	"particuler":      "particular",
+	"permision":       "permission",
+	"persistant":      "persistent",
+	"posible":         "possible",
+	"preceeding":      "preceding",
+	"prefered":        "preferred",
+	"previosly":       "previously",
+	"priviledge":      "privilege",
+	"proccess":        "process",
+	"programatically": "programmatically",
+	"propogate":       "propagate",
+	"recieve":         "receive",
+	"recieved":        "received",
+	"reciever":        "receiver",
+	"recomend":        "recommend",
+	"recursivly":      "recursively",
+	"refered":         "referred",
+	"relevent":        "relevant",
+	"remeber":         "remember",
+	"reponse":         "response",
+	"requred":         "required",
+	"resouce":         "resource",
+	"respone":         "response",
+	"retreive":        "retrieve",
+	"retrive":         "retrieve",
+	"returnes":        "returns",
+	"seperate":        "separate",
+	"seperated":       "separated",
+	"seperator":       "separator",
+	"sucessful":       "successful",
+	"succesful":       "successful",
+	"successfull":     "successful",
+	"suport":          "support",
+	"supress":         "suppress",
+	"suprise":         "surprise",
+	"syncronous":      "synchronous",
+	"teh":             "the",
+	"threshhold":      "threshold",
+	"transfered":      "transferred",
+	"truely":          "truly",
+	"unkown":          "unknown",
+	"unneccessary":    "unnecessary",
+	"untill":          "until",
+	"usefull":         "useful",
+	"useing":          "using",
+	"valiation":       "validation",
+	"verison":         "version",
+	"whitch":          "which",
+	"wich":            "which",
+	"writen":          "written",
+}
+
+// misspelling returns the correct spelling of word, if word is a
+// known misspelling. The case of the first letter is preserved.
+func misspelling(word string, dict map[string]bool) (string, bool) {
+	lower := strings.ToLower(word)
+	if dict[lower] {
+		return "", false
+	}
+	correct, ok := misspellings[lower]
+	if !ok {
+		return "", false
+	}
+	if lower != word {
+		if word != strings.ToUpper(word) && word[1:] != lower[1:] {
+			// mixed case, likely an identifier
+			return "", false
+		}
+		correct = strings.ToUpper(correct[:1]) + correct[1:]
+	}
+	return correct, true
+}
+
+func (c *Checker) CheckDocSpelling(j *lint.Job) {
+	// Each package may have its own dictionary, if it has its own
+	// configuration.
+	dicts := map[*lint.Pkg]map[string]bool{}
+	var dict map[string]bool
+
+	checkComment := func(name string, cg *ast.CommentGroup) {
+		if cg == nil {
+			return
+		}
+		for _, cm := range cg.List {
+			text := cm.Text
+			if strings.HasPrefix(text, "/*") {
+				// We don't know how block comments are indented,
+				// so we can't reliably skip code in them.
+				continue
+			}
+			text = strings.TrimPrefix(text, "//")
+			if strings.HasPrefix(text, " ") {
+				text = text[1:]
+			}
+			if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+				// preformatted text, most likely code
+				continue
+			}
+			offset := len(cm.Text) - len(text)
+			start := -1
+			for i, r := range text + " " {
+				if unicode.IsLetter(r) || (start != -1 && r == '\'') {
+					if start == -1 {
+						start = i
+					}
+					continue
+				}
+				if start == -1 {
+					continue
+				}
+				word := text[start:i]
+				if correct, ok := misspelling(word, dict); ok {
+					j.Errorf(posNode(cm.Slash+token.Pos(offset+start)), "%q in the documentation of %s is a misspelling of %q", word, name, correct)
+				}
+				start = -1
+			}
+		}
+	}
+
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pkg := j.NodePackage(f)
+		dict = dicts[pkg]
+		if dict == nil {
+			dict = map[string]bool{}
+			for _, w := range pkg.Config.Dictionary {
+				dict[strings.ToLower(w)] = true
+			}
+			dicts[pkg] = dict
+		}
+		exportedDocs(f, checkComment)
+	}
+}
+
+type posNode token.Pos
+
+func (p posNode) Pos() token.Pos { return token.Pos(p) }
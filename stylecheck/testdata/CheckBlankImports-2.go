@@ -49,3 +49,4 @@ var _ = strconv.IntSize
 // MATCH:25 "blank import"
 // MATCH:31 "blank import"
 // MATCH:35 "blank import"
+// MATCH:38 /import "fmt" should be sorted before "strconv"/
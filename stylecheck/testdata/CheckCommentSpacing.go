@@ -0,0 +1,18 @@
+// Package pkg ...
+package pkg
+
+//foo
+// foo
+////
+//---
+//
+
+//go:noinline
+func fn() {
+	//bar
+	//nolint
+	//line file.go:1
+}
+
+// MATCH:4 "comment should have a space after //"
+// MATCH:12 "comment should have a space after //"
@@ -0,0 +1,38 @@
+// Package pkg ...
+package pkg
+
+// Foo does things
+func Foo() {}
+
+// Bar does things.
+func Bar() {}
+
+// Baz does things:
+//
+//	Baz()
+func Baz() {}
+
+// Qux does things
+//go:noinline
+func Qux() {}
+
+// unexported does things
+func unexported() {}
+
+// T is a type
+type T int
+
+// Values
+const (
+	// A is a value
+	A = 1
+)
+
+/* V is a variable */
+var V int
+
+// MATCH:4 "comment on exported Foo should end in a period"
+// MATCH:15 "comment on exported Qux should end in a period"
+// MATCH:22 "comment on exported T should end in a period"
+// MATCH:25 "comment on exported A should end in a period"
+// MATCH:27 "comment on exported A should end in a period"
@@ -0,0 +1,29 @@
+// Package pkg ...
+package pkg
+
+// Fn1 will recieve a value.
+func Fn1() {} // MATCH:4 /"recieve" in the documentation of Fn1 is a misspelling of "receive"/
+
+// Fn2 does things.
+//
+// Seperate things are done here.
+//
+//	recieve() // code isn't checked
+func Fn2() {} // MATCH:9 /"Seperate" in the documentation of Fn2 is a misspelling of "Separate"/
+
+// fn3 will recieve a value.
+func fn3() {}
+
+// T is a type whose fields are initalize lazily.
+type T struct{} // MATCH:17 /"initalize" in the documentation of T/
+
+// Group docs are checked once, wich is enough.
+const (
+	A = 1 // MATCH:20 /"wich" in the documentation of A is a misspelling of "which"/
+	// B is used untill later.
+	B = 2 // MATCH:23 /"untill" in the documentation of B/
+	C = 3
+)
+
+// Fn4 calls Recieve.
+func Fn4() {} // MATCH:28 /"Recieve" in the documentation of Fn4/
@@ -0,0 +1,35 @@
+// Package pkg ...
+package pkg
+
+import (
+	"strings"
+	"fmt" // MATCH /import "fmt" should be sorted before "strings"/
+)
+
+import (
+	"bytes"
+
+	"errors" // MATCH /import "errors" shouldn't be separated from "bytes" by a blank line/
+)
+
+import (
+	"io"
+	"os"
+)
+
+import (
+	// Comments on lines of their own prevent fixes.
+	"sort"
+	"math" // MATCH /import "math" should be sorted before "sort"/
+)
+
+var (
+	_ = strings.Join
+	_ = fmt.Sprint
+	_ = bytes.Equal
+	_ = errors.New
+	_ = io.Copy
+	_ = os.Exit
+	_ = sort.Strings
+	_ = math.Abs
+)
@@ -0,0 +1,27 @@
+// Package pkg ...
+package pkg
+
+import (
+	"encoding/json"
+	"strings" // MATCH /import "strings" should come before "encoding/json"; the standard library should be grouped before encoding/
+)
+
+import (
+	"fmt"
+	"encoding/xml" // MATCH /import "encoding/xml" should be separated from "fmt" by a blank line, as they belong to different groups/
+)
+
+import (
+	"os"
+
+	"encoding/base64"
+)
+
+var (
+	_ = json.Marshal
+	_ = strings.Join
+	_ = fmt.Sprint
+	_ = xml.Marshal
+	_ = os.Exit
+	_ = base64.NewEncoder
+)
@@ -0,0 +1,36 @@
+// Package pkg ...
+package pkg
+
+func few() {
+	println(1)
+	println(2)
+}
+
+func many() { // MATCH "function many has 4 statements, more than the maximum of 3"
+	println(1)
+	println(2)
+	println(3)
+	println(4)
+}
+
+func long() { // MATCH "function long has 7 lines, more than the maximum of 6"
+	x := 1 +
+		2 +
+		3 +
+		4 +
+		5 +
+		6
+	println(x)
+}
+
+func table() {
+	xs := []int{
+		1,
+		2,
+		3,
+		4,
+		5,
+		6,
+	}
+	println(xs)
+}
@@ -0,0 +1,43 @@
+// Package pkg ...
+package pkg // MATCH "file has 43 lines, more than the maximum of 40"
+
+// fn0 does nothing.
+func fn0() {}
+// fn1 does nothing.
+func fn1() {}
+// fn2 does nothing.
+func fn2() {}
+// fn3 does nothing.
+func fn3() {}
+// fn4 does nothing.
+func fn4() {}
+// fn5 does nothing.
+func fn5() {}
+// fn6 does nothing.
+func fn6() {}
+// fn7 does nothing.
+func fn7() {}
+// fn8 does nothing.
+func fn8() {}
+// fn9 does nothing.
+func fn9() {}
+// fn10 does nothing.
+func fn10() {}
+// fn11 does nothing.
+func fn11() {}
+// fn12 does nothing.
+func fn12() {}
+// fn13 does nothing.
+func fn13() {}
+// fn14 does nothing.
+func fn14() {}
+// fn15 does nothing.
+func fn15() {}
+// fn16 does nothing.
+func fn16() {}
+// fn17 does nothing.
+func fn17() {}
+// fn18 does nothing.
+func fn18() {}
+// fn19 does nothing.
+func fn19() {}
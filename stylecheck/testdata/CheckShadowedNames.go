@@ -0,0 +1,36 @@
+// Package pkg ...
+package pkg
+
+import (
+	"fmt"
+	"strings" // RELATED "package strings is imported here"
+)
+
+func fn1() {
+	len := 3 // MATCH "declaration of len shadows the predeclared identifier len"
+	fmt.Println(len)
+}
+
+func fn2() {
+	strings := []string{"a"} // MATCH "declaration of strings shadows the import of package strings"
+	fmt.Println(strings) // RELATED "strings is used here"
+}
+
+func fn3(new int) {}
+
+func fn4(error string) { // MATCH "declaration of error shadows the predeclared identifier error"
+	fmt.Println(error)
+}
+
+func fn5() {
+	if true {
+		fmt := "x" // MATCH "declaration of fmt shadows the import of package fmt"
+		_ = fmt
+	}
+	fmt.Println(strings.ToUpper("x"))
+}
+
+func fn6() {
+	var x int
+	fmt.Println(x)
+}
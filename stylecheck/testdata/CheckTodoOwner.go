@@ -0,0 +1,16 @@
+// Package pkg ...
+package pkg
+
+// TODO: fix this
+// TODO(dh): fine
+// TODOS aren't TODO comments
+
+/* TODO do something */
+
+func fn() {
+	// TODO handle errors
+}
+
+// MATCH:4 "TODO comment should name an owner or a ticket"
+// MATCH:8 "TODO comment should name an owner or a ticket"
+// MATCH:11 "TODO comment should name an owner or a ticket"
@@ -0,0 +1,7 @@
+// Package pkg ...
+package pkg
+
+// TODO(#12): fine
+// TODO(dh): not fine
+
+// MATCH:5 "TODO comment should match"
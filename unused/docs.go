@@ -0,0 +1,24 @@
+package unused
+
+import "honnef.co/go/tools/lint"
+
+// Docs documents the checks and categories of checks of this checker.
+var Docs = map[string]*lint.Documentation{
+	"U1": {
+		Title: "Unused code",
+	},
+	"U1000": {
+		Title: "Unused code",
+	},
+	"U1001": {
+		Title: "Unreachable functions",
+		Text: "Functions and methods that are used, but only by code that is never\n" +
+			"executed, are as dead as unused ones. Starting at main and init\n" +
+			"functions and tests, as well as the exported API of libraries\n" +
+			"unless whole program analysis is enabled, a call graph of the\n" +
+			"program determines which functions may run; all other functions\n" +
+			"are reported. Calls via reflection aren't taken into account.\n" +
+			"\n" +
+			"This check is only run when enabled with the -unreachable flag.\n",
+	},
+}
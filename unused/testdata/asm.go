@@ -0,0 +1,19 @@
+package pkg
+
+import _ "unsafe"
+
+// implemented in asm.s
+func asmAdd(x, y int) int
+
+// called from asm.s
+func asmHelper() {}
+
+var asmTable [4]int
+
+func asmUnused() {} // MATCH /asmUnused is unused/
+
+//go:linkname linkedNow time.now
+func linkedNow() (int64, int32)
+
+//go:linkname pushed
+func pushed() {}
@@ -0,0 +1,10 @@
+#include "textflag.h"
+
+// func asmAdd(x, y int) int
+TEXT ·asmAdd(SB),NOSPLIT,$0-24
+	MOVQ x+0(FP), AX
+	ADDQ y+8(FP), AX
+	MOVQ AX, ret+16(FP)
+	CALL ·asmHelper(SB)
+	MOVQ ·asmTable(SB), AX
+	RET
@@ -0,0 +1,17 @@
+package pkg
+
+func Exported() { fn1() }
+
+func fn1() {}
+
+func fn2() { fn3() } // MATCH "func fn2 is never executed"
+
+func fn3() {} // MATCH "func fn3 is never executed"
+
+var _ = fn2
+
+type T struct{}
+
+func (T) Exported() { fn4() }
+
+func fn4() {}
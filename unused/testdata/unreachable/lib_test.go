@@ -0,0 +1,11 @@
+package pkg
+
+import "testing"
+
+func TestFoo(t *testing.T) { fn5() }
+
+func fn5() {}
+
+func fn6() {} // MATCH "func fn6 is never executed"
+
+var _ = fn6
@@ -0,0 +1,42 @@
+package main
+
+type T struct{}
+
+func (T) used()   {}
+func (T) unused() {} // MATCH "func T.unused is never executed"
+
+type I interface{ M() }
+
+type U struct{}
+
+func (U) M() {} // MATCH "func U.M is never executed"
+
+type V struct{}
+
+func (*V) M() {}
+
+func dead() { // MATCH "func dead is never executed"
+	helper()
+	var t T
+	t.unused()
+	var i I = U{}
+	i.M()
+}
+
+func helper() {} // MATCH "func helper is never executed"
+
+func alive() {
+	var t T
+	t.used()
+	var i I = &V{}
+	i.M()
+}
+
+var hook = func() { dead() }
+
+func main() {
+	alive()
+	f := alive
+	f()
+	_ = hook
+}
@@ -3,14 +3,20 @@ package unused // import "honnef.co/go/tools/unused"
 import (
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/token"
 	"go/types"
 	"io"
+	"io/ioutil"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 
+	"honnef.co/go/tools/callgraph/rta"
 	"honnef.co/go/tools/lint"
 	. "honnef.co/go/tools/lint/lintdsl"
+	"honnef.co/go/tools/ssa"
 
 	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/types/typeutil"
@@ -25,18 +31,51 @@ func NewLintChecker(c *Checker) *LintChecker {
 
 type LintChecker struct {
 	c *Checker
+
+	// once guards unused, the result of c, which is shared by all
+	// checks of a run.
+	once   *sync.Once
+	unused []Unused
 }
 
 func (*LintChecker) Name() string   { return "unused" }
 func (*LintChecker) Prefix() string { return "U" }
 
-func (l *LintChecker) Init(*lint.Program) {}
+func (*LintChecker) Docs() map[string]*lint.Documentation { return Docs }
+
+// ProgramChecks returns the checks that depend on the other packages
+// being linted. In whole program mode, uses of an object in any of them
+// count.
+func (l *LintChecker) ProgramChecks() []string {
+	if !l.c.WholeProgram {
+		return nil
+	}
+	return []string{"U1000", "U1001"}
+}
+
+func (l *LintChecker) Init(prog *lint.Program) {
+	if l.c.Build == nil {
+		l.c.Build = prog.Build
+	}
+	l.once = &sync.Once{}
+	l.unused = nil
+}
 func (l *LintChecker) Funcs() map[string]lint.Func {
 	return map[string]lint.Func{
 		"U1000": l.Lint,
+		"U1001": l.LintUnreachable,
 	}
 }
 
+// check returns the unused objects of the program, running the
+// checker only once per run.
+func (l *LintChecker) check(j *lint.Job) []Unused {
+	l.once.Do(func() {
+		l.unused = l.c.Check(j.Program.Prog)
+	})
+	return l.unused
+}
+
 func typString(obj types.Object) string {
 	switch obj := obj.(type) {
 	case *types.Func:
@@ -56,22 +95,130 @@ func typString(obj types.Object) string {
 	}
 }
 
+// objName returns the name of obj, qualified with the receiver type
+// for methods.
+func objName(obj types.Object) string {
+	name := obj.Name()
+	if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+		switch sig.Recv().Type().(type) {
+		case *types.Named, *types.Pointer:
+			typ := types.TypeString(sig.Recv().Type(), func(*types.Package) string { return "" })
+			if len(typ) > 0 && typ[0] == '*' {
+				name = fmt.Sprintf("(%s).%s", typ, obj.Name())
+			} else if len(typ) > 0 {
+				name = fmt.Sprintf("%s.%s", typ, obj.Name())
+			}
+		}
+	}
+	return name
+}
+
 func (l *LintChecker) Lint(j *lint.Job) {
-	unused := l.c.Check(j.Program.Prog)
+	unused := l.check(j)
 	for _, u := range unused {
-		name := u.Obj.Name()
-		if sig, ok := u.Obj.Type().(*types.Signature); ok && sig.Recv() != nil {
-			switch sig.Recv().Type().(type) {
-			case *types.Named, *types.Pointer:
-				typ := types.TypeString(sig.Recv().Type(), func(*types.Package) string { return "" })
-				if len(typ) > 0 && typ[0] == '*' {
-					name = fmt.Sprintf("(%s).%s", typ, u.Obj.Name())
-				} else if len(typ) > 0 {
-					name = fmt.Sprintf("%s.%s", typ, u.Obj.Name())
+		if j.NodePackage(u.Obj) == nil {
+			// The object belongs to a package that failed to
+			// type-check and isn't part of the program.
+			continue
+		}
+		j.Reportf(u.Obj, "unused", "{{.kind}} {{.name}} is unused", lint.Args{"kind": typString(u.Obj), "name": objName(u.Obj)})
+	}
+}
+
+// isTestFunc reports whether fn is a test, benchmark or example
+// function that the go test command runs.
+func isTestFunc(prog *lint.Program, fn *ssa.Function) bool {
+	if fn.Signature.Recv() != nil || !strings.HasSuffix(prog.DisplayPosition(fn.Pos()).Filename, "_test.go") {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example"} {
+		if strings.HasPrefix(fn.Name(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LintUnreachable reports functions and methods that are referenced,
+// and thus not unused, but that are never executed, because they are
+// only referenced by other code that is never executed. It is based on
+// a call graph of the whole program, rooted in main and init functions
+// and tests, as well as the exported API of packages other than main,
+// unless the checker operates in whole program mode.
+func (l *LintChecker) LintUnreachable(j *lint.Job) {
+	if !l.c.Unreachable {
+		return
+	}
+
+	var roots []*ssa.Function
+	for _, pkg := range j.Program.Packages {
+		if pkg.Package == nil {
+			continue
+		}
+		for _, m := range pkg.Members {
+			fn, ok := m.(*ssa.Function)
+			if !ok {
+				continue
+			}
+			switch {
+			case fn.Name() == "init",
+				fn.Name() == "main" && pkg.Pkg.Name() == "main",
+				isTestFunc(j.Program, fn),
+				!l.c.WholeProgram && pkg.Pkg.Name() != "main" && ast.IsExported(fn.Name()):
+				roots = append(roots, fn)
+			}
+		}
+		if l.c.WholeProgram || pkg.Pkg.Name() == "main" {
+			continue
+		}
+		for _, m := range pkg.Members {
+			T, ok := m.(*ssa.Type)
+			if !ok || !ast.IsExported(T.Name()) {
+				continue
+			}
+			for _, typ := range []types.Type{T.Type(), types.NewPointer(T.Type())} {
+				ms := j.Program.SSA.MethodSets.MethodSet(typ)
+				for i := 0; i < ms.Len(); i++ {
+					if ms.At(i).Obj().Exported() {
+						if fn := j.Program.SSA.MethodValue(ms.At(i)); fn != nil {
+							roots = append(roots, fn)
+						}
+					}
 				}
 			}
 		}
-		j.Errorf(u.Obj, "%s %s is unused", typString(u.Obj), name)
+	}
+	if len(roots) == 0 {
+		return
+	}
+	res := rta.Analyze(roots, false)
+	reachable := map[*ssa.Function]bool{}
+	for _, fn := range roots {
+		// Analyze only records the functions that roots reach.
+		reachable[fn] = true
+	}
+	for fn := range res.Reachable {
+		reachable[fn] = true
+	}
+
+	unused := map[types.Object]bool{}
+	for _, u := range l.check(j) {
+		unused[u.Obj] = true
+	}
+	for _, fn := range j.Program.InitialFunctions {
+		obj := fn.Object()
+		if obj == nil || fn.Synthetic != "" || fn.Parent() != nil || fn.Blocks == nil {
+			// Only report functions and methods declared in
+			// source, with bodies.
+			continue
+		}
+		if reachable[fn] || unused[obj] {
+			continue
+		}
+		if f := j.File(fn); f == nil || IsGenerated(f) {
+			continue
+		}
+		j.Reportf(obj, "unreachable", "{{.kind}} {{.name}} is never executed: it is only used by code that is unreachable", lint.Args{"kind": typString(obj), "name": objName(obj)})
 	}
 }
 
@@ -153,7 +300,13 @@ type Checker struct {
 	Mode               CheckMode
 	WholeProgram       bool
 	ConsiderReflection bool
-	Debug              io.Writer
+	// Unreachable enables check U1001, which reports functions that
+	// are only used by code that is never executed.
+	Unreachable bool
+	Debug       io.Writer
+	// Build is used to find the assembly files of packages. If nil,
+	// build.Default is used.
+	Build *build.Context
 
 	graph *graph
 
@@ -211,6 +364,8 @@ func (c *Checker) Check(lprog *loader.Program) []Unused {
 		c.processTypes(pkg)
 		c.processSelections(pkg)
 		c.processAST(pkg)
+		c.processLinknames(pkg)
+		c.processAssembly(pkg)
 	}
 
 	for _, node := range c.graph.nodes {
@@ -266,9 +421,7 @@ func (c *Checker) Check(lprog *loader.Program) []Unused {
 			if c.lprog.Fset.Position(file.Pos()).Filename != pos.Filename {
 				continue
 			}
-			if len(file.Comments) > 0 {
-				generated = isGenerated(file.Comments[0].Text())
-			}
+			generated = IsGenerated(file)
 			break
 		}
 		if generated {
@@ -717,6 +870,68 @@ func (c *Checker) processCgoExported(pkg *loader.PackageInfo, node ast.Node) {
 	}
 }
 
+// processLinknames marks objects as used if they're the subject of a
+// //go:linkname directive, as they may be referred to by other
+// packages.
+func (c *Checker) processLinknames(pkg *loader.PackageInfo) {
+	for _, f := range pkg.Files {
+		for _, cg := range f.Comments {
+			for _, cmt := range cg.List {
+				if !strings.HasPrefix(cmt.Text, "//go:linkname ") {
+					continue
+				}
+				fields := strings.Fields(cmt.Text)
+				if len(fields) < 2 {
+					continue
+				}
+				if obj := pkg.Pkg.Scope().Lookup(fields[1]); obj != nil {
+					c.graph.roots = append(c.graph.roots, c.graph.getNode(obj))
+				}
+			}
+		}
+	}
+}
+
+// asmSymbol matches references to symbols of the current package in
+// assembly, such as ·foo(SB).
+var asmSymbol = regexp.MustCompile(`(?:^|[^\pL\pN_/.])·([\pL_][\pL\pN_]*)`)
+
+// processAssembly marks objects as used if they're referred to from
+// the package's assembly files. This includes functions that are
+// implemented in assembly, as well as Go functions and variables
+// used by assembly.
+func (c *Checker) processAssembly(pkg *loader.PackageInfo) {
+	if len(pkg.Files) == 0 {
+		return
+	}
+	bctx := c.Build
+	if bctx == nil {
+		bctx = &build.Default
+	}
+	dir := filepath.Dir(c.lprog.Fset.Position(pkg.Files[0].Pos()).Filename)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range fis {
+		if filepath.Ext(fi.Name()) != ".s" {
+			continue
+		}
+		if ok, err := bctx.MatchFile(dir, fi.Name()); err != nil || !ok {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			continue
+		}
+		for _, m := range asmSymbol.FindAllStringSubmatch(string(b), -1) {
+			if obj := pkg.Pkg.Scope().Lookup(m[1]); obj != nil {
+				c.graph.roots = append(c.graph.roots, c.graph.getNode(obj))
+			}
+		}
+	}
+}
+
 func (c *Checker) processVariableDeclaration(pkg *loader.PackageInfo, node ast.Node) {
 	if decl, ok := node.(*ast.GenDecl); ok {
 		for _, spec := range decl.Specs {
@@ -1058,8 +1273,3 @@ func (c *Checker) printDebugGraph(w io.Writer) {
 	}
 	fmt.Fprintln(w, "}")
 }
-
-func isGenerated(comment string) bool {
-	return strings.Contains(comment, "Code generated by") ||
-		strings.Contains(comment, "DO NOT EDIT")
-}
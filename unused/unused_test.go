@@ -21,6 +21,13 @@ func TestAll(t *testing.T) {
 	testutil.TestAll(t, l, "")
 }
 
+func TestUnreachable(t *testing.T) {
+	checker := NewChecker(CheckAll)
+	checker.Unreachable = true
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "unreachable")
+}
+
 type instruction struct {
 	Line int // the line number this applies to
 	IDs  []string